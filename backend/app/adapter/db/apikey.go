@@ -0,0 +1,177 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/short-d/short/app/adapter/db/table"
+	"github.com/short-d/short/app/entity"
+	"github.com/short-d/short/app/usecase/repository"
+)
+
+const scopeSeparator = ","
+
+var _ repository.APIKey = (*APIKeySQL)(nil)
+
+// APIKeySQL accesses personal API keys in api_key table through SQL.
+type APIKeySQL struct {
+	db *sql.DB
+}
+
+// Create inserts a new API key with the hash of its secret into api_key
+// table.
+func (a APIKeySQL) Create(ctx context.Context, apiKey entity.APIKey, keyHash string) error {
+	statement := fmt.Sprintf(`
+INSERT INTO "%s" ("%s","%s","%s","%s","%s","%s")
+VALUES ($1,$2,$3,$4,$5,$6);`,
+		table.APIKey.TableName,
+		table.APIKey.ColumnID,
+		table.APIKey.ColumnUserEmail,
+		table.APIKey.ColumnName,
+		table.APIKey.ColumnKeyHash,
+		table.APIKey.ColumnScopes,
+		table.APIKey.ColumnCreatedAt,
+	)
+
+	_, err := a.db.ExecContext(
+		ctx,
+		statement,
+		apiKey.ID,
+		apiKey.UserEmail,
+		apiKey.Name,
+		keyHash,
+		joinScopes(apiKey.Scopes),
+		apiKey.CreatedAt,
+	)
+	return err
+}
+
+// Delete removes the API key with the given ID from api_key table.
+func (a APIKeySQL) Delete(ctx context.Context, id string) error {
+	statement := fmt.Sprintf(`
+DELETE FROM "%s"
+WHERE "%s"=$1;`,
+		table.APIKey.TableName,
+		table.APIKey.ColumnID,
+	)
+
+	result, err := a.db.ExecContext(ctx, statement, id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("api key not found (id=%s)", id)
+	}
+	return nil
+}
+
+// FindByUser fetches the API keys created by the given user from api_key
+// table in creation order.
+func (a APIKeySQL) FindByUser(ctx context.Context, user entity.User) ([]entity.APIKey, error) {
+	query := fmt.Sprintf(`
+SELECT "%s","%s","%s","%s","%s"
+FROM "%s"
+WHERE "%s"=$1
+ORDER BY "%s", "%s";`,
+		table.APIKey.ColumnID,
+		table.APIKey.ColumnUserEmail,
+		table.APIKey.ColumnName,
+		table.APIKey.ColumnScopes,
+		table.APIKey.ColumnCreatedAt,
+		table.APIKey.TableName,
+		table.APIKey.ColumnUserEmail,
+		table.APIKey.ColumnCreatedAt,
+		table.APIKey.ColumnID,
+	)
+
+	rows, err := a.db.QueryContext(ctx, query, user.Email)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	apiKeys := []entity.APIKey{}
+	for rows.Next() {
+		apiKey, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		apiKeys = append(apiKeys, apiKey)
+	}
+	return apiKeys, rows.Err()
+}
+
+// FindByHash fetches the API key whose secret has the given hash from api_key
+// table.
+func (a APIKeySQL) FindByHash(ctx context.Context, keyHash string) (entity.APIKey, error) {
+	query := fmt.Sprintf(`
+SELECT "%s","%s","%s","%s","%s"
+FROM "%s"
+WHERE "%s"=$1;`,
+		table.APIKey.ColumnID,
+		table.APIKey.ColumnUserEmail,
+		table.APIKey.ColumnName,
+		table.APIKey.ColumnScopes,
+		table.APIKey.ColumnCreatedAt,
+		table.APIKey.TableName,
+		table.APIKey.ColumnKeyHash,
+	)
+
+	return scanAPIKey(a.db.QueryRowContext(ctx, query, keyHash))
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanAPIKey(row rowScanner) (entity.APIKey, error) {
+	apiKey := entity.APIKey{}
+	var scopes string
+	err := row.Scan(
+		&apiKey.ID,
+		&apiKey.UserEmail,
+		&apiKey.Name,
+		&scopes,
+		&apiKey.CreatedAt,
+	)
+	if err != nil {
+		return entity.APIKey{}, err
+	}
+
+	apiKey.CreatedAt = apiKey.CreatedAt.UTC()
+	apiKey.Scopes = splitScopes(scopes)
+	return apiKey, nil
+}
+
+func joinScopes(scopes []entity.APIKeyScope) string {
+	values := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		values = append(values, string(scope))
+	}
+	return strings.Join(values, scopeSeparator)
+}
+
+func splitScopes(scopes string) []entity.APIKeyScope {
+	apiKeyScopes := []entity.APIKeyScope{}
+	if scopes == "" {
+		return apiKeyScopes
+	}
+	for _, scope := range strings.Split(scopes, scopeSeparator) {
+		apiKeyScopes = append(apiKeyScopes, entity.APIKeyScope(scope))
+	}
+	return apiKeyScopes
+}
+
+// NewAPIKeySQL creates APIKeySQL
+func NewAPIKeySQL(db *sql.DB) APIKeySQL {
+	return APIKeySQL{
+		db: db,
+	}
+}
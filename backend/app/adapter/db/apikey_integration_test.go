@@ -0,0 +1,246 @@
+// +build integration all
+
+package db_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/short-d/app/mdtest"
+	"github.com/short-d/short/app/adapter/db"
+	"github.com/short-d/short/app/entity"
+)
+
+func TestAPIKeySQL_Create(t *testing.T) {
+	testCases := []struct {
+		name          string
+		userTableRows []userTableRow
+		apiKeys       []entity.APIKey
+		keyHashes     []string
+		apiKey        entity.APIKey
+		keyHash       string
+		hasErr        bool
+	}{
+		{
+			name:          "user not found",
+			userTableRows: []userTableRow{},
+			apiKey: entity.APIKey{
+				ID:        "0123456789abcdef",
+				Name:      "ci",
+				UserEmail: "alpha@example.com",
+				Scopes:    []entity.APIKeyScope{},
+				CreatedAt: mustParseTime(t, "2020-01-02T03:04:05Z"),
+			},
+			keyHash: "hash",
+			hasErr:  true,
+		},
+		{
+			name: "hash exists",
+			userTableRows: []userTableRow{
+				{id: "alpha", email: "alpha@example.com"},
+			},
+			apiKeys: []entity.APIKey{
+				{
+					ID:        "0123456789abcdef",
+					Name:      "ci",
+					UserEmail: "alpha@example.com",
+					Scopes:    []entity.APIKeyScope{},
+					CreatedAt: mustParseTime(t, "2020-01-02T03:04:05Z"),
+				},
+			},
+			keyHashes: []string{"hash"},
+			apiKey: entity.APIKey{
+				ID:        "fedcba9876543210",
+				Name:      "deploy",
+				UserEmail: "alpha@example.com",
+				Scopes:    []entity.APIKeyScope{},
+				CreatedAt: mustParseTime(t, "2020-01-02T03:04:05Z"),
+			},
+			keyHash: "hash",
+			hasErr:  true,
+		},
+		{
+			name: "create key successfully",
+			userTableRows: []userTableRow{
+				{id: "alpha", email: "alpha@example.com"},
+			},
+			apiKey: entity.APIKey{
+				ID:        "0123456789abcdef",
+				Name:      "ci",
+				UserEmail: "alpha@example.com",
+				Scopes: []entity.APIKeyScope{
+					entity.APIKeyScopeCreateLinks,
+					entity.APIKeyScopeManageLinks,
+				},
+				CreatedAt: mustParseTime(t, "2020-01-02T03:04:05Z"),
+			},
+			keyHash: "hash",
+			hasErr:  false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mdtest.AccessTestDB(
+				dbConnector,
+				dbMigrationTool,
+				dbMigrationRoot,
+				dbConfig,
+				func(sqlDB *sql.DB) {
+					insertUserTableRows(t, sqlDB, testCase.userTableRows)
+
+					apiKeyRepo := db.NewAPIKeySQL(sqlDB)
+					for idx, apiKey := range testCase.apiKeys {
+						err := apiKeyRepo.Create(context.Background(), apiKey, testCase.keyHashes[idx])
+						mdtest.Equal(t, nil, err)
+					}
+
+					err := apiKeyRepo.Create(context.Background(), testCase.apiKey, testCase.keyHash)
+					if testCase.hasErr {
+						mdtest.NotEqual(t, nil, err)
+						return
+					}
+					mdtest.Equal(t, nil, err)
+
+					apiKey, err := apiKeyRepo.FindByHash(context.Background(), testCase.keyHash)
+					mdtest.Equal(t, nil, err)
+					mdtest.Equal(t, testCase.apiKey, apiKey)
+				},
+			)
+		})
+	}
+}
+
+func TestAPIKeySQL_Delete(t *testing.T) {
+	testCases := []struct {
+		name    string
+		apiKeys []entity.APIKey
+		id      string
+		hasErr  bool
+	}{
+		{
+			name:    "key not found",
+			apiKeys: []entity.APIKey{},
+			id:      "0123456789abcdef",
+			hasErr:  true,
+		},
+		{
+			name: "delete key successfully",
+			apiKeys: []entity.APIKey{
+				{
+					ID:        "0123456789abcdef",
+					Name:      "ci",
+					UserEmail: "alpha@example.com",
+					Scopes:    []entity.APIKeyScope{},
+					CreatedAt: mustParseTime(t, "2020-01-02T03:04:05Z"),
+				},
+			},
+			id:     "0123456789abcdef",
+			hasErr: false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mdtest.AccessTestDB(
+				dbConnector,
+				dbMigrationTool,
+				dbMigrationRoot,
+				dbConfig,
+				func(sqlDB *sql.DB) {
+					insertUserTableRows(t, sqlDB, []userTableRow{
+						{id: "alpha", email: "alpha@example.com"},
+					})
+
+					apiKeyRepo := db.NewAPIKeySQL(sqlDB)
+					for _, apiKey := range testCase.apiKeys {
+						err := apiKeyRepo.Create(context.Background(), apiKey, apiKey.ID)
+						mdtest.Equal(t, nil, err)
+					}
+
+					err := apiKeyRepo.Delete(context.Background(), testCase.id)
+					if testCase.hasErr {
+						mdtest.NotEqual(t, nil, err)
+						return
+					}
+					mdtest.Equal(t, nil, err)
+
+					_, err = apiKeyRepo.FindByHash(context.Background(), testCase.id)
+					mdtest.NotEqual(t, nil, err)
+				},
+			)
+		})
+	}
+}
+
+func TestAPIKeySQL_FindByUser(t *testing.T) {
+	alphaCI := entity.APIKey{
+		ID:        "0123456789abcdef",
+		Name:      "ci",
+		UserEmail: "alpha@example.com",
+		Scopes:    []entity.APIKeyScope{entity.APIKeyScopeCreateLinks},
+		CreatedAt: mustParseTime(t, "2020-01-02T03:04:05Z"),
+	}
+	alphaDeploy := entity.APIKey{
+		ID:        "1123456789abcdef",
+		Name:      "deploy",
+		UserEmail: "alpha@example.com",
+		Scopes:    []entity.APIKeyScope{},
+		CreatedAt: mustParseTime(t, "2020-01-01T03:04:05Z"),
+	}
+	betaCI := entity.APIKey{
+		ID:        "2123456789abcdef",
+		Name:      "ci",
+		UserEmail: "beta@example.com",
+		Scopes:    []entity.APIKeyScope{},
+		CreatedAt: mustParseTime(t, "2020-01-03T03:04:05Z"),
+	}
+
+	testCases := []struct {
+		name            string
+		apiKeys         []entity.APIKey
+		user            entity.User
+		expectedAPIKeys []entity.APIKey
+	}{
+		{
+			name:            "user has no key",
+			apiKeys:         []entity.APIKey{betaCI},
+			user:            entity.User{Email: "alpha@example.com"},
+			expectedAPIKeys: []entity.APIKey{},
+		},
+		{
+			name:            "keys in creation order",
+			apiKeys:         []entity.APIKey{alphaCI, alphaDeploy, betaCI},
+			user:            entity.User{Email: "alpha@example.com"},
+			expectedAPIKeys: []entity.APIKey{alphaDeploy, alphaCI},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mdtest.AccessTestDB(
+				dbConnector,
+				dbMigrationTool,
+				dbMigrationRoot,
+				dbConfig,
+				func(sqlDB *sql.DB) {
+					insertUserTableRows(t, sqlDB, []userTableRow{
+						{id: "alpha", email: "alpha@example.com"},
+						{id: "beta", email: "beta@example.com"},
+					})
+
+					apiKeyRepo := db.NewAPIKeySQL(sqlDB)
+					for _, apiKey := range testCase.apiKeys {
+						err := apiKeyRepo.Create(context.Background(), apiKey, apiKey.ID)
+						mdtest.Equal(t, nil, err)
+					}
+
+					apiKeys, err := apiKeyRepo.FindByUser(context.Background(), testCase.user)
+					mdtest.Equal(t, nil, err)
+					mdtest.Equal(t, testCase.expectedAPIKeys, apiKeys)
+				},
+			)
+		})
+	}
+}
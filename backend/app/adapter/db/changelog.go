@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 
@@ -17,7 +18,7 @@ type ChangeLogSQL struct {
 }
 
 // GetChangeLog retrieves full changelog from change_log table.
-func (c ChangeLogSQL) GetChangeLog() ([]entity.Change, error) {
+func (c ChangeLogSQL) GetChangeLog(ctx context.Context) ([]entity.Change, error) {
 	statement := fmt.Sprintf(`
 SELECT "%s","%s","%s","%s" 
 FROM "%s";`,
@@ -28,7 +29,7 @@ FROM "%s";`,
 		table.ChangeLog.TableName,
 	)
 
-	rows, err := c.db.Query(statement)
+	rows, err := c.db.QueryContext(ctx, statement)
 	if err != nil {
 		return []entity.Change{}, err
 	}
@@ -49,7 +50,7 @@ FROM "%s";`,
 }
 
 // CreateChange adds a new Change into change_log table.
-func (c ChangeLogSQL) CreateChange(newChange entity.Change) (entity.Change, error) {
+func (c ChangeLogSQL) CreateChange(ctx context.Context, newChange entity.Change) (entity.Change, error) {
 	statement := fmt.Sprintf(`
 INSERT INTO "%s" ("%s", "%s","%s","%s")
 VALUES ($1, $2, $3, $4);
@@ -61,7 +62,8 @@ VALUES ($1, $2, $3, $4);
 		table.ChangeLog.ColumnReleasedAt,
 	)
 
-	_, err := c.db.Exec(
+	_, err := c.db.ExecContext(
+		ctx,
 		statement,
 		newChange.ID,
 		newChange.Title,
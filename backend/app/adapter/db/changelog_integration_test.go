@@ -3,6 +3,7 @@
 package db_test
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"testing"
@@ -84,7 +85,7 @@ func TestChangeLogSql_GetChangeLog(t *testing.T) {
 					insertChangeLogTableRows(t, sqlDB, testCase.tableRows)
 
 					changeLogRepo := db.NewChangeLogSQL(sqlDB)
-					changeLog, err := changeLogRepo.GetChangeLog()
+					changeLog, err := changeLogRepo.GetChangeLog(context.Background())
 
 					mdtest.Equal(t, nil, err)
 					mdtest.Equal(t, testCase.expectedChangeLog, changeLog)
@@ -170,8 +171,8 @@ func TestChangeLogSql_CreateChange(t *testing.T) {
 
 					changeLogRepo := db.NewChangeLogSQL(sqlDB)
 
-					change, err := changeLogRepo.CreateChange(testCase.change)
-					changeLog, _ := changeLogRepo.GetChangeLog()
+					change, err := changeLogRepo.CreateChange(context.Background(), testCase.change)
+					changeLog, _ := changeLogRepo.GetChangeLog(context.Background())
 
 					mdtest.Equal(t, nil, err)
 					mdtest.Equal(t, testCase.expectedChangeLogSize, len(changeLog))
@@ -0,0 +1,207 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/short-d/short/app/adapter/db/table"
+	"github.com/short-d/short/app/entity"
+	"github.com/short-d/short/app/usecase/repository"
+)
+
+var _ repository.Click = (*ClickSQL)(nil)
+
+// ClickSQL accesses Click information in click table through SQL.
+type ClickSQL struct {
+	db *sql.DB
+}
+
+// CreateClicks inserts a batch of clicks into click table with a single
+// statement. Clicks on aliases missing from url table, such as the ones
+// deleted after being clicked, are skipped so that they don't fail the rest
+// of the batch.
+func (c ClickSQL) CreateClicks(ctx context.Context, clicks []entity.Click) error {
+	if len(clicks) == 0 {
+		return nil
+	}
+
+	const numColumns = 6
+	rows := make([]string, 0, len(clicks))
+	args := make([]interface{}, 0, len(clicks)*numColumns)
+	for idx, click := range clicks {
+		offset := idx * numColumns
+		rows = append(rows, fmt.Sprintf(
+			"($%d::text,$%d::text,$%d::timestamptz,$%d::text,$%d::text,$%d::text)",
+			offset+1,
+			offset+2,
+			offset+3,
+			offset+4,
+			offset+5,
+			offset+6,
+		))
+		args = append(
+			args,
+			click.Domain,
+			click.Alias,
+			click.ClickedAt,
+			click.Referrer,
+			click.UserAgent,
+			click.IPAddress,
+		)
+	}
+
+	statement := fmt.Sprintf(`
+INSERT INTO "%s" ("%s","%s","%s","%s","%s","%s")
+SELECT v.domain, v.alias, v.clicked_at, v.referrer, v.user_agent, v.ip_address
+FROM (VALUES %s) AS v (domain, alias, clicked_at, referrer, user_agent, ip_address)
+JOIN "%s" ON "%s"."%s"=v.domain AND "%s"."%s"=v.alias;`,
+		table.Click.TableName,
+		table.Click.ColumnURLDomain,
+		table.Click.ColumnURLAlias,
+		table.Click.ColumnClickedAt,
+		table.Click.ColumnReferrer,
+		table.Click.ColumnUserAgent,
+		table.Click.ColumnIPAddress,
+		strings.Join(rows, ","),
+		table.URL.TableName,
+		table.URL.TableName,
+		table.URL.ColumnDomain,
+		table.URL.TableName,
+		table.URL.ColumnAlias,
+	)
+
+	_, err := c.db.ExecContext(ctx, statement, args...)
+	return err
+}
+
+// CountClicks counts the clicks on a given alias in click table.
+func (c ClickSQL) CountClicks(ctx context.Context, domain string, alias string) (int, error) {
+	query := fmt.Sprintf(`
+SELECT COUNT(*)
+FROM "%s"
+WHERE "%s"=$1 AND "%s"=$2;`,
+		table.Click.TableName,
+		table.Click.ColumnURLDomain,
+		table.Click.ColumnURLAlias,
+	)
+
+	var count int
+	err := c.db.QueryRowContext(ctx, query, domain, alias).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// CountClicksByDay counts the clicks on a given alias within [from, to) for
+// each UTC day with at least one click.
+func (c ClickSQL) CountClicksByDay(ctx context.Context, domain string, alias string, from time.Time, to time.Time) ([]entity.DailyClicks, error) {
+	query := fmt.Sprintf(`
+SELECT date_trunc('day', "%s" AT TIME ZONE 'UTC') AS day, COUNT(*)
+FROM "%s"
+WHERE "%s"=$1 AND "%s"=$2 AND "%s">=$3 AND "%s"<$4
+GROUP BY day
+ORDER BY day;`,
+		table.Click.ColumnClickedAt,
+		table.Click.TableName,
+		table.Click.ColumnURLDomain,
+		table.Click.ColumnURLAlias,
+		table.Click.ColumnClickedAt,
+		table.Click.ColumnClickedAt,
+	)
+
+	rows, err := c.db.QueryContext(ctx, query, domain, alias, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	dailyClicks := []entity.DailyClicks{}
+	for rows.Next() {
+		daily := entity.DailyClicks{}
+		err = rows.Scan(&daily.Day, &daily.Count)
+		if err != nil {
+			return nil, err
+		}
+		daily.Day = daily.Day.UTC()
+		dailyClicks = append(dailyClicks, daily)
+	}
+	return dailyClicks, rows.Err()
+}
+
+// CountClicksByReferrer counts the clicks on a given alias for the most
+// common referrers.
+func (c ClickSQL) CountClicksByReferrer(ctx context.Context, domain string, alias string, limit int) ([]entity.ReferrerClicks, error) {
+	query := fmt.Sprintf(`
+SELECT COALESCE("%s", ''), COUNT(*) AS count
+FROM "%s"
+WHERE "%s"=$1 AND "%s"=$2
+GROUP BY 1
+ORDER BY count DESC, 1
+LIMIT $3;`,
+		table.Click.ColumnReferrer,
+		table.Click.TableName,
+		table.Click.ColumnURLDomain,
+		table.Click.ColumnURLAlias,
+	)
+
+	rows, err := c.db.QueryContext(ctx, query, domain, alias, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	referrerClicks := []entity.ReferrerClicks{}
+	for rows.Next() {
+		referrer := entity.ReferrerClicks{}
+		err = rows.Scan(&referrer.Referrer, &referrer.Count)
+		if err != nil {
+			return nil, err
+		}
+		referrerClicks = append(referrerClicks, referrer)
+	}
+	return referrerClicks, rows.Err()
+}
+
+// CountClicksByUserAgent counts the clicks on a given alias for each
+// User-Agent.
+func (c ClickSQL) CountClicksByUserAgent(ctx context.Context, domain string, alias string) (map[string]int, error) {
+	query := fmt.Sprintf(`
+SELECT COALESCE("%s", ''), COUNT(*)
+FROM "%s"
+WHERE "%s"=$1 AND "%s"=$2
+GROUP BY 1;`,
+		table.Click.ColumnUserAgent,
+		table.Click.TableName,
+		table.Click.ColumnURLDomain,
+		table.Click.ColumnURLAlias,
+	)
+
+	rows, err := c.db.QueryContext(ctx, query, domain, alias)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var userAgent string
+		var count int
+		err = rows.Scan(&userAgent, &count)
+		if err != nil {
+			return nil, err
+		}
+		counts[userAgent] = count
+	}
+	return counts, rows.Err()
+}
+
+// NewClickSQL creates ClickSQL
+func NewClickSQL(db *sql.DB) ClickSQL {
+	return ClickSQL{
+		db: db,
+	}
+}
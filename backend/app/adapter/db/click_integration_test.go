@@ -0,0 +1,117 @@
+// +build integration all
+
+package db_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/short-d/app/mdtest"
+	"github.com/short-d/short/app/adapter/db"
+	"github.com/short-d/short/app/adapter/db/table"
+	"github.com/short-d/short/app/entity"
+)
+
+var countClicksSQL = fmt.Sprintf(`
+SELECT COUNT(*)
+FROM %s
+WHERE %s=$1`,
+	table.Click.TableName,
+	table.Click.ColumnURLAlias,
+)
+
+func TestClickSQL_CreateClicks(t *testing.T) {
+	now := mustParseTime(t, "2019-05-01T08:02:16Z")
+
+	testCases := []struct {
+		name         string
+		urlTableRows []urlTableRow
+		clicks       []entity.Click
+		alias        string
+		hasErr       bool
+		expCount     int
+	}{
+		{
+			name:         "no clicks",
+			urlTableRows: []urlTableRow{},
+			clicks:       []entity.Click{},
+			alias:        "220uFicCJj",
+			hasErr:       false,
+			expCount:     0,
+		},
+		{
+			name:         "alias not found",
+			urlTableRows: []urlTableRow{},
+			clicks: []entity.Click{
+				{Alias: "220uFicCJj", ClickedAt: now},
+			},
+			alias:    "220uFicCJj",
+			hasErr:   false,
+			expCount: 0,
+		},
+		{
+			name: "skip clicks on deleted alias",
+			urlTableRows: []urlTableRow{
+				{alias: "220uFicCJj"},
+			},
+			clicks: []entity.Click{
+				{Alias: "220uFicCJj", ClickedAt: now},
+				{Alias: "yDOBcj5HIPbUAsw", ClickedAt: now},
+				{Alias: "220uFicCJj", ClickedAt: now},
+			},
+			alias:    "220uFicCJj",
+			hasErr:   false,
+			expCount: 2,
+		},
+		{
+			name: "insert batch",
+			urlTableRows: []urlTableRow{
+				{alias: "220uFicCJj"},
+				{alias: "yDOBcj5HIPbUAsw"},
+			},
+			clicks: []entity.Click{
+				{
+					Alias:     "220uFicCJj",
+					ClickedAt: now,
+					Referrer:  "https://www.google.com/",
+					UserAgent: "Mozilla/5.0",
+					IPAddress: "203.0.113.0",
+				},
+				{Alias: "220uFicCJj", ClickedAt: now},
+				{Alias: "yDOBcj5HIPbUAsw", ClickedAt: now},
+			},
+			alias:    "220uFicCJj",
+			hasErr:   false,
+			expCount: 2,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mdtest.AccessTestDB(
+				dbConnector,
+				dbMigrationTool,
+				dbMigrationRoot,
+				dbConfig,
+				func(sqlDB *sql.DB) {
+					insertURLTableRows(t, sqlDB, testCase.urlTableRows)
+
+					clickRepo := db.NewClickSQL(sqlDB)
+					err := clickRepo.CreateClicks(context.Background(), testCase.clicks)
+					if testCase.hasErr {
+						mdtest.NotEqual(t, nil, err)
+						return
+					}
+					mdtest.Equal(t, nil, err)
+
+					var count int
+					err = sqlDB.QueryRow(countClicksSQL, testCase.alias).Scan(&count)
+					mdtest.Equal(t, nil, err)
+					mdtest.Equal(t, testCase.expCount, count)
+				},
+			)
+		})
+	}
+}
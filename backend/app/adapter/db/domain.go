@@ -0,0 +1,196 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/short-d/short/app/adapter/db/table"
+	"github.com/short-d/short/app/entity"
+	"github.com/short-d/short/app/usecase/repository"
+)
+
+var _ repository.Domain = (*DomainSQL)(nil)
+
+// DomainSQL accesses the custom domains registered by users in domain table
+// through SQL.
+type DomainSQL struct {
+	db *sql.DB
+}
+
+// Create inserts a new domain into domain table.
+func (d DomainSQL) Create(ctx context.Context, domain entity.Domain) error {
+	statement := fmt.Sprintf(`
+INSERT INTO "%s" ("%s","%s","%s","%s","%s")
+VALUES ($1,$2,$3,$4,$5);`,
+		table.Domain.TableName,
+		table.Domain.ColumnHostname,
+		table.Domain.ColumnOwnerEmail,
+		table.Domain.ColumnVerificationToken,
+		table.Domain.ColumnVerifiedAt,
+		table.Domain.ColumnCreatedAt,
+	)
+
+	_, err := d.db.ExecContext(
+		ctx,
+		statement,
+		domain.Hostname,
+		domain.OwnerEmail,
+		domain.VerificationToken,
+		domain.VerifiedAt,
+		domain.CreatedAt,
+	)
+	return err
+}
+
+// GetByHostname fetches the domain with the given hostname from domain table.
+func (d DomainSQL) GetByHostname(ctx context.Context, hostname string) (entity.Domain, error) {
+	query := fmt.Sprintf(`
+SELECT "%s","%s","%s","%s","%s"
+FROM "%s"
+WHERE "%s"=$1;`,
+		table.Domain.ColumnHostname,
+		table.Domain.ColumnOwnerEmail,
+		table.Domain.ColumnVerificationToken,
+		table.Domain.ColumnVerifiedAt,
+		table.Domain.ColumnCreatedAt,
+		table.Domain.TableName,
+		table.Domain.ColumnHostname,
+	)
+
+	domain, err := scanDomain(d.db.QueryRowContext(ctx, query, hostname))
+	if err == sql.ErrNoRows {
+		return entity.Domain{}, repository.ErrDomainNotFound(hostname)
+	}
+	return domain, err
+}
+
+// FindByOwner fetches the domains registered by the given user from domain
+// table in registration order.
+func (d DomainSQL) FindByOwner(ctx context.Context, user entity.User) ([]entity.Domain, error) {
+	query := fmt.Sprintf(`
+SELECT "%s","%s","%s","%s","%s"
+FROM "%s"
+WHERE "%s"=$1
+ORDER BY "%s","%s";`,
+		table.Domain.ColumnHostname,
+		table.Domain.ColumnOwnerEmail,
+		table.Domain.ColumnVerificationToken,
+		table.Domain.ColumnVerifiedAt,
+		table.Domain.ColumnCreatedAt,
+		table.Domain.TableName,
+		table.Domain.ColumnOwnerEmail,
+		table.Domain.ColumnCreatedAt,
+		table.Domain.ColumnHostname,
+	)
+
+	rows, err := d.db.QueryContext(ctx, query, user.Email)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	domains := []entity.Domain{}
+	for rows.Next() {
+		domain, err := scanDomain(rows)
+		if err != nil {
+			return nil, err
+		}
+		domains = append(domains, domain)
+	}
+	return domains, rows.Err()
+}
+
+// MarkVerified records when the ownership of the domain with the given
+// hostname was proven in domain table.
+func (d DomainSQL) MarkVerified(ctx context.Context, hostname string, verifiedAt time.Time) error {
+	statement := fmt.Sprintf(`
+UPDATE "%s"
+SET "%s"=$1
+WHERE "%s"=$2;`,
+		table.Domain.TableName,
+		table.Domain.ColumnVerifiedAt,
+		table.Domain.ColumnHostname,
+	)
+
+	result, err := d.db.ExecContext(ctx, statement, verifiedAt, hostname)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return repository.ErrDomainNotFound(hostname)
+	}
+	return nil
+}
+
+// ReplaceUnverified replaces the registration of domain's hostname in domain
+// table with domain when it is neither verified nor created after
+// createdBefore, in a single statement so that concurrent verifications are
+// never overwritten.
+func (d DomainSQL) ReplaceUnverified(ctx context.Context, domain entity.Domain, createdBefore time.Time) error {
+	statement := fmt.Sprintf(`
+UPDATE "%s"
+SET "%s"=$1,"%s"=$2,"%s"=$3
+WHERE "%s"=$4 AND "%s" IS NULL AND "%s"<=$5;`,
+		table.Domain.TableName,
+		table.Domain.ColumnOwnerEmail,
+		table.Domain.ColumnVerificationToken,
+		table.Domain.ColumnCreatedAt,
+		table.Domain.ColumnHostname,
+		table.Domain.ColumnVerifiedAt,
+		table.Domain.ColumnCreatedAt,
+	)
+
+	result, err := d.db.ExecContext(
+		ctx,
+		statement,
+		domain.OwnerEmail,
+		domain.VerificationToken,
+		domain.CreatedAt,
+		domain.Hostname,
+		createdBefore,
+	)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return repository.ErrDomainNotFound(domain.Hostname)
+	}
+	return nil
+}
+
+func scanDomain(row rowScanner) (entity.Domain, error) {
+	domain := entity.Domain{}
+	err := row.Scan(
+		&domain.Hostname,
+		&domain.OwnerEmail,
+		&domain.VerificationToken,
+		&domain.VerifiedAt,
+		&domain.CreatedAt,
+	)
+	if err != nil {
+		return entity.Domain{}, err
+	}
+
+	domain.VerifiedAt = utc(domain.VerifiedAt)
+	domain.CreatedAt = utc(domain.CreatedAt)
+	return domain, nil
+}
+
+// NewDomainSQL creates DomainSQL
+func NewDomainSQL(db *sql.DB) DomainSQL {
+	return DomainSQL{
+		db: db,
+	}
+}
@@ -0,0 +1,269 @@
+// +build integration all
+
+package db_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/mdtest"
+	"github.com/short-d/short/app/adapter/db"
+	"github.com/short-d/short/app/entity"
+)
+
+func TestDomainSQL_Create(t *testing.T) {
+	createdAt := mustParseTime(t, "2020-01-02T03:04:05Z")
+
+	testCases := []struct {
+		name          string
+		userTableRows []userTableRow
+		domains       []entity.Domain
+		domain        entity.Domain
+		hasErr        bool
+	}{
+		{
+			name:          "owner not found",
+			userTableRows: []userTableRow{},
+			domain: entity.Domain{
+				Hostname:          "go.example.com",
+				OwnerEmail:        "alpha@example.com",
+				VerificationToken: "token",
+				CreatedAt:         &createdAt,
+			},
+			hasErr: true,
+		},
+		{
+			name: "hostname exists",
+			userTableRows: []userTableRow{
+				{id: "alpha", email: "alpha@example.com"},
+				{id: "beta", email: "beta@example.com"},
+			},
+			domains: []entity.Domain{
+				{
+					Hostname:          "go.example.com",
+					OwnerEmail:        "beta@example.com",
+					VerificationToken: "token",
+					CreatedAt:         &createdAt,
+				},
+			},
+			domain: entity.Domain{
+				Hostname:          "go.example.com",
+				OwnerEmail:        "alpha@example.com",
+				VerificationToken: "token",
+				CreatedAt:         &createdAt,
+			},
+			hasErr: true,
+		},
+		{
+			name: "create domain successfully",
+			userTableRows: []userTableRow{
+				{id: "alpha", email: "alpha@example.com"},
+			},
+			domain: entity.Domain{
+				Hostname:          "go.example.com",
+				OwnerEmail:        "alpha@example.com",
+				VerificationToken: "token",
+				CreatedAt:         &createdAt,
+			},
+			hasErr: false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mdtest.AccessTestDB(
+				dbConnector,
+				dbMigrationTool,
+				dbMigrationRoot,
+				dbConfig,
+				func(sqlDB *sql.DB) {
+					insertUserTableRows(t, sqlDB, testCase.userTableRows)
+
+					domainRepo := db.NewDomainSQL(sqlDB)
+					for _, domain := range testCase.domains {
+						err := domainRepo.Create(context.Background(), domain)
+						mdtest.Equal(t, nil, err)
+					}
+
+					err := domainRepo.Create(context.Background(), testCase.domain)
+					if testCase.hasErr {
+						mdtest.NotEqual(t, nil, err)
+						return
+					}
+					mdtest.Equal(t, nil, err)
+
+					domain, err := domainRepo.GetByHostname(context.Background(), testCase.domain.Hostname)
+					mdtest.Equal(t, nil, err)
+					mdtest.Equal(t, testCase.domain, domain)
+				},
+			)
+		})
+	}
+}
+
+func TestDomainSQL_MarkVerified(t *testing.T) {
+	createdAt := mustParseTime(t, "2020-01-02T03:04:05Z")
+	verifiedAt := mustParseTime(t, "2020-01-03T03:04:05Z")
+
+	testCases := []struct {
+		name     string
+		domains  []entity.Domain
+		hostname string
+		hasErr   bool
+	}{
+		{
+			name:     "domain not found",
+			domains:  []entity.Domain{},
+			hostname: "go.example.com",
+			hasErr:   true,
+		},
+		{
+			name: "mark domain verified",
+			domains: []entity.Domain{
+				{
+					Hostname:          "go.example.com",
+					OwnerEmail:        "alpha@example.com",
+					VerificationToken: "token",
+					CreatedAt:         &createdAt,
+				},
+			},
+			hostname: "go.example.com",
+			hasErr:   false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mdtest.AccessTestDB(
+				dbConnector,
+				dbMigrationTool,
+				dbMigrationRoot,
+				dbConfig,
+				func(sqlDB *sql.DB) {
+					insertUserTableRows(t, sqlDB, []userTableRow{
+						{id: "alpha", email: "alpha@example.com"},
+					})
+
+					domainRepo := db.NewDomainSQL(sqlDB)
+					for _, domain := range testCase.domains {
+						err := domainRepo.Create(context.Background(), domain)
+						mdtest.Equal(t, nil, err)
+					}
+
+					err := domainRepo.MarkVerified(context.Background(), testCase.hostname, verifiedAt)
+					if testCase.hasErr {
+						mdtest.NotEqual(t, nil, err)
+						return
+					}
+					mdtest.Equal(t, nil, err)
+
+					domain, err := domainRepo.GetByHostname(context.Background(), testCase.hostname)
+					mdtest.Equal(t, nil, err)
+					mdtest.Equal(t, true, domain.IsVerified())
+				},
+			)
+		})
+	}
+}
+
+func TestDomainSQL_ReplaceUnverified(t *testing.T) {
+	createdAt := mustParseTime(t, "2020-01-02T03:04:05Z")
+	verifiedAt := mustParseTime(t, "2020-01-03T03:04:05Z")
+	replacedAt := mustParseTime(t, "2020-01-06T03:04:05Z")
+
+	testCases := []struct {
+		name          string
+		domains       []entity.Domain
+		createdBefore time.Time
+		hasErr        bool
+	}{
+		{
+			name:          "domain not found",
+			domains:       []entity.Domain{},
+			createdBefore: createdAt,
+			hasErr:        true,
+		},
+		{
+			name: "domain verified",
+			domains: []entity.Domain{
+				{
+					Hostname:          "go.example.com",
+					OwnerEmail:        "alpha@example.com",
+					VerificationToken: "token",
+					VerifiedAt:        &verifiedAt,
+					CreatedAt:         &createdAt,
+				},
+			},
+			createdBefore: createdAt,
+			hasErr:        true,
+		},
+		{
+			name: "domain created recently",
+			domains: []entity.Domain{
+				{
+					Hostname:          "go.example.com",
+					OwnerEmail:        "alpha@example.com",
+					VerificationToken: "token",
+					CreatedAt:         &createdAt,
+				},
+			},
+			createdBefore: createdAt.Add(-time.Hour),
+			hasErr:        true,
+		},
+		{
+			name: "replace unverified domain",
+			domains: []entity.Domain{
+				{
+					Hostname:          "go.example.com",
+					OwnerEmail:        "alpha@example.com",
+					VerificationToken: "token",
+					CreatedAt:         &createdAt,
+				},
+			},
+			createdBefore: createdAt,
+			hasErr:        false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mdtest.AccessTestDB(
+				dbConnector,
+				dbMigrationTool,
+				dbMigrationRoot,
+				dbConfig,
+				func(sqlDB *sql.DB) {
+					insertUserTableRows(t, sqlDB, []userTableRow{
+						{id: "alpha", email: "alpha@example.com"},
+						{id: "beta", email: "beta@example.com"},
+					})
+
+					domainRepo := db.NewDomainSQL(sqlDB)
+					for _, domain := range testCase.domains {
+						err := domainRepo.Create(context.Background(), domain)
+						mdtest.Equal(t, nil, err)
+					}
+
+					domain := entity.Domain{
+						Hostname:          "go.example.com",
+						OwnerEmail:        "beta@example.com",
+						VerificationToken: "new-token",
+						CreatedAt:         &replacedAt,
+					}
+					err := domainRepo.ReplaceUnverified(context.Background(), domain, testCase.createdBefore)
+					if testCase.hasErr {
+						mdtest.NotEqual(t, nil, err)
+						return
+					}
+					mdtest.Equal(t, nil, err)
+
+					gotDomain, err := domainRepo.GetByHostname(context.Background(), domain.Hostname)
+					mdtest.Equal(t, nil, err)
+					mdtest.Equal(t, domain, gotDomain)
+				},
+			)
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 
@@ -21,7 +22,7 @@ type GithubSSOSql struct {
 
 // IsSSOUserExist checks whether mapping for a given Github account exists in
 // the database.
-func (g GithubSSOSql) IsSSOUserExist(ssoUser entity.SSOUser) (bool, error) {
+func (g GithubSSOSql) IsSSOUserExist(ctx context.Context, ssoUser entity.SSOUser) (bool, error) {
 	query := fmt.Sprintf(`
 SELECT "%s"
 FROM "%s"
@@ -32,7 +33,7 @@ WHERE "%s"=$1;
 		table.GithubSSO.ColumnGithubUserID,
 	)
 	var id string
-	err := g.db.QueryRow(query, ssoUser.ID).Scan(&id)
+	err := g.db.QueryRowContext(ctx, query, ssoUser.ID).Scan(&id)
 	if err == nil {
 		return true, err
 	}
@@ -45,7 +46,7 @@ WHERE "%s"=$1;
 
 // CreateMapping creates mapping between user's Github and Short accounts in the
 // database.
-func (g GithubSSOSql) CreateMapping(ssoUser entity.SSOUser, user entity.User) error {
+func (g GithubSSOSql) CreateMapping(ctx context.Context, ssoUser entity.SSOUser, user entity.User) error {
 	statement := fmt.Sprintf(`
 INSERT INTO "%s" ("%s", "%s")
 VALUES ($1, $2);
@@ -54,7 +55,7 @@ VALUES ($1, $2);
 		table.GithubSSO.ColumnGithubUserID,
 		table.GithubSSO.ColumnShortUserID,
 	)
-	_, err := g.db.Exec(statement, ssoUser.ID, user.ID)
+	_, err := g.db.ExecContext(ctx, statement, ssoUser.ID, user.ID)
 	return err
 }
 
@@ -3,6 +3,7 @@
 package db_test
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"testing"
@@ -60,7 +61,7 @@ func TestGithubSSOSql_IsSSOUserExist(t *testing.T) {
 
 					logger := mdtest.NewLoggerFake(mdtest.FakeLoggerArgs{})
 					githubSSORepo := db.NewGithubSSOSql(sqlDB, &logger)
-					gotIsExist, err := githubSSORepo.IsSSOUserExist(testCase.ssoUser)
+					gotIsExist, err := githubSSORepo.IsSSOUserExist(context.Background(), testCase.ssoUser)
 
 					mdtest.Equal(t, nil, err)
 					mdtest.Equal(t, testCase.expectedIsExist, gotIsExist)
@@ -128,7 +129,7 @@ func TestGithubSSOSql_CreateMapping(t *testing.T) {
 					logger := mdtest.NewLoggerFake(mdtest.FakeLoggerArgs{})
 					githubSSORepo := db.NewGithubSSOSql(sqlDB, &logger)
 
-					err := githubSSORepo.CreateMapping(testCase.ssoUser, testCase.shortUser)
+					err := githubSSORepo.CreateMapping(context.Background(), testCase.ssoUser, testCase.shortUser)
 
 					if testCase.hasErr {
 						mdtest.NotEqual(t, nil, err)
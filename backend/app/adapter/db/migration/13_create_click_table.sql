@@ -0,0 +1,16 @@
+-- +migrate Up
+CREATE TABLE click
+(
+    id         BIGSERIAL PRIMARY KEY,
+    url_alias  CHARACTER VARYING(50)    NOT NULL,
+    clicked_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    referrer   TEXT,
+    user_agent TEXT,
+    ip_address CHARACTER VARYING(45),
+    FOREIGN KEY (url_alias) REFERENCES url (alias) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE INDEX click_url_alias_clicked_at_idx ON click (url_alias, clicked_at);
+
+-- +migrate Down
+DROP TABLE click;
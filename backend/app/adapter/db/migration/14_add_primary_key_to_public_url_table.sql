@@ -0,0 +1,7 @@
+-- +migrate Up
+ALTER TABLE public_url
+    ADD CONSTRAINT pk_public_url PRIMARY KEY (alias);
+
+-- +migrate Down
+ALTER TABLE public_url
+    DROP CONSTRAINT pk_public_url;
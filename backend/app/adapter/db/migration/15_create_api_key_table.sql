@@ -0,0 +1,16 @@
+-- +migrate Up
+CREATE TABLE api_key
+(
+    id         CHARACTER VARYING(16)    PRIMARY KEY,
+    user_email CHARACTER VARYING(254)   NOT NULL,
+    name       CHARACTER VARYING(50)    NOT NULL,
+    key_hash   CHARACTER(64)            NOT NULL UNIQUE,
+    scopes     TEXT                     NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    FOREIGN KEY (user_email) REFERENCES "user" (email) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE INDEX api_key_user_email_idx ON api_key (user_email);
+
+-- +migrate Down
+DROP TABLE api_key;
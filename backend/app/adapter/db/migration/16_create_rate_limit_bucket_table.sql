@@ -0,0 +1,10 @@
+-- +migrate Up
+CREATE TABLE rate_limit_bucket
+(
+    key         CHARACTER VARYING(300)   PRIMARY KEY,
+    tokens      DOUBLE PRECISION         NOT NULL,
+    refilled_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+-- +migrate Down
+DROP TABLE rate_limit_bucket;
@@ -0,0 +1,5 @@
+-- +migrate Up
+ALTER TABLE url ADD COLUMN password_hash CHARACTER VARYING(200);
+
+-- +migrate Down
+ALTER TABLE url DROP COLUMN password_hash;
@@ -0,0 +1,7 @@
+-- +migrate Up
+ALTER TABLE url ADD COLUMN max_clicks INTEGER;
+ALTER TABLE url ADD COLUMN click_count INTEGER NOT NULL DEFAULT 0;
+
+-- +migrate Down
+ALTER TABLE url DROP COLUMN click_count;
+ALTER TABLE url DROP COLUMN max_clicks;
@@ -0,0 +1,5 @@
+-- +migrate Up
+ALTER TABLE url ADD COLUMN activate_at TIMESTAMP WITH TIME ZONE;
+
+-- +migrate Down
+ALTER TABLE url DROP COLUMN activate_at;
@@ -0,0 +1,15 @@
+-- +migrate Up
+CREATE TABLE redirect_rule
+(
+    url_alias        CHARACTER VARYING(50) NOT NULL,
+    position         INTEGER               NOT NULL,
+    condition        CHARACTER VARYING(20) NOT NULL,
+    condition_values TEXT                  NOT NULL,
+    weight           INTEGER               NOT NULL,
+    long_link        TEXT                  NOT NULL,
+    PRIMARY KEY (url_alias, position),
+    FOREIGN KEY (url_alias) REFERENCES url (alias) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+-- +migrate Down
+DROP TABLE redirect_rule;
@@ -0,0 +1,9 @@
+-- +migrate Up
+ALTER TABLE url ADD COLUMN forward_query BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE url ADD COLUMN forward_path BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE url ADD COLUMN utm_params TEXT;
+
+-- +migrate Down
+ALTER TABLE url DROP COLUMN utm_params;
+ALTER TABLE url DROP COLUMN forward_path;
+ALTER TABLE url DROP COLUMN forward_query;
@@ -0,0 +1,7 @@
+-- +migrate Up
+ALTER TABLE url ADD COLUMN title VARCHAR(200);
+ALTER TABLE url ADD COLUMN redirect_status INTEGER;
+
+-- +migrate Down
+ALTER TABLE url DROP COLUMN redirect_status;
+ALTER TABLE url DROP COLUMN title;
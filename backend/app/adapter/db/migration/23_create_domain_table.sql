@@ -0,0 +1,15 @@
+-- +migrate Up
+CREATE TABLE domain
+(
+    hostname           CHARACTER VARYING(253) PRIMARY KEY,
+    owner_email        CHARACTER VARYING(254) NOT NULL,
+    verification_token CHARACTER VARYING(64)  NOT NULL,
+    verified_at        TIMESTAMP WITH TIME ZONE,
+    created_at         TIMESTAMP WITH TIME ZONE,
+    FOREIGN KEY (owner_email) REFERENCES "user" (email) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE INDEX domain_owner_email_idx ON domain (owner_email);
+
+-- +migrate Down
+DROP TABLE domain;
@@ -0,0 +1,71 @@
+-- +migrate Up
+ALTER TABLE user_url_relation DROP CONSTRAINT user_url_relation_url_alias_fkey;
+ALTER TABLE public_url DROP CONSTRAINT public_url_alias_fkey;
+ALTER TABLE click DROP CONSTRAINT click_url_alias_fkey;
+ALTER TABLE redirect_rule DROP CONSTRAINT redirect_rule_url_alias_fkey;
+
+ALTER TABLE url ADD COLUMN domain CHARACTER VARYING(253) NOT NULL DEFAULT '';
+ALTER TABLE url DROP CONSTRAINT "Url_pkey";
+ALTER TABLE url ADD CONSTRAINT pk_url PRIMARY KEY (domain, alias);
+
+ALTER TABLE user_url_relation ADD COLUMN url_domain CHARACTER VARYING(253) NOT NULL DEFAULT '';
+ALTER TABLE user_url_relation DROP CONSTRAINT pk_user_url_relation;
+ALTER TABLE user_url_relation
+    ADD CONSTRAINT pk_user_url_relation PRIMARY KEY (user_email, url_domain, url_alias);
+ALTER TABLE user_url_relation
+    ADD FOREIGN KEY (url_domain, url_alias) REFERENCES url (domain, alias) ON DELETE CASCADE ON UPDATE CASCADE;
+
+ALTER TABLE public_url ADD COLUMN domain CHARACTER VARYING(253) NOT NULL DEFAULT '';
+ALTER TABLE public_url DROP CONSTRAINT pk_public_url;
+ALTER TABLE public_url ADD CONSTRAINT pk_public_url PRIMARY KEY (domain, alias);
+ALTER TABLE public_url
+    ADD FOREIGN KEY (domain, alias) REFERENCES url (domain, alias) ON DELETE CASCADE ON UPDATE CASCADE;
+
+ALTER TABLE click ADD COLUMN url_domain CHARACTER VARYING(253) NOT NULL DEFAULT '';
+ALTER TABLE click
+    ADD FOREIGN KEY (url_domain, url_alias) REFERENCES url (domain, alias) ON DELETE CASCADE ON UPDATE CASCADE;
+DROP INDEX click_url_alias_clicked_at_idx;
+CREATE INDEX click_url_domain_url_alias_clicked_at_idx ON click (url_domain, url_alias, clicked_at);
+
+ALTER TABLE redirect_rule ADD COLUMN url_domain CHARACTER VARYING(253) NOT NULL DEFAULT '';
+ALTER TABLE redirect_rule DROP CONSTRAINT redirect_rule_pkey;
+ALTER TABLE redirect_rule ADD PRIMARY KEY (url_domain, url_alias, position);
+ALTER TABLE redirect_rule
+    ADD FOREIGN KEY (url_domain, url_alias) REFERENCES url (domain, alias) ON DELETE CASCADE ON UPDATE CASCADE;
+
+-- +migrate Down
+DELETE FROM url WHERE domain <> '';
+
+ALTER TABLE redirect_rule DROP CONSTRAINT redirect_rule_url_domain_url_alias_fkey;
+ALTER TABLE redirect_rule DROP CONSTRAINT redirect_rule_pkey;
+ALTER TABLE redirect_rule DROP COLUMN url_domain;
+ALTER TABLE redirect_rule ADD PRIMARY KEY (url_alias, position);
+
+ALTER TABLE click DROP CONSTRAINT click_url_domain_url_alias_fkey;
+DROP INDEX click_url_domain_url_alias_clicked_at_idx;
+ALTER TABLE click DROP COLUMN url_domain;
+CREATE INDEX click_url_alias_clicked_at_idx ON click (url_alias, clicked_at);
+
+ALTER TABLE public_url DROP CONSTRAINT public_url_domain_alias_fkey;
+ALTER TABLE public_url DROP CONSTRAINT pk_public_url;
+ALTER TABLE public_url DROP COLUMN domain;
+ALTER TABLE public_url ADD CONSTRAINT pk_public_url PRIMARY KEY (alias);
+
+ALTER TABLE user_url_relation DROP CONSTRAINT user_url_relation_url_domain_url_alias_fkey;
+ALTER TABLE user_url_relation DROP CONSTRAINT pk_user_url_relation;
+ALTER TABLE user_url_relation DROP COLUMN url_domain;
+ALTER TABLE user_url_relation
+    ADD CONSTRAINT pk_user_url_relation PRIMARY KEY (user_email, url_alias);
+
+ALTER TABLE url DROP CONSTRAINT pk_url;
+ALTER TABLE url DROP COLUMN domain;
+ALTER TABLE url ADD CONSTRAINT "Url_pkey" PRIMARY KEY (alias);
+
+ALTER TABLE user_url_relation
+    ADD FOREIGN KEY (url_alias) REFERENCES url (alias) ON DELETE CASCADE ON UPDATE CASCADE;
+ALTER TABLE public_url
+    ADD FOREIGN KEY (alias) REFERENCES url (alias) ON DELETE CASCADE ON UPDATE CASCADE;
+ALTER TABLE click
+    ADD FOREIGN KEY (url_alias) REFERENCES url (alias) ON DELETE CASCADE ON UPDATE CASCADE;
+ALTER TABLE redirect_rule
+    ADD FOREIGN KEY (url_alias) REFERENCES url (alias) ON DELETE CASCADE ON UPDATE CASCADE;
@@ -0,0 +1,9 @@
+-- +migrate Up
+ALTER TABLE rate_limit_bucket ADD COLUMN full_at TIMESTAMP WITH TIME ZONE;
+UPDATE rate_limit_bucket SET full_at = refilled_at;
+ALTER TABLE rate_limit_bucket ALTER COLUMN full_at SET NOT NULL;
+CREATE INDEX rate_limit_bucket_full_at_idx ON rate_limit_bucket (full_at);
+
+-- +migrate Down
+DROP INDEX rate_limit_bucket_full_at_idx;
+ALTER TABLE rate_limit_bucket DROP COLUMN full_at;
@@ -0,0 +1,76 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/short-d/short/app/adapter/db/table"
+)
+
+// MigrationStatusSQL compares the migrations under the migration root with the
+// ones recorded in gorp_migrations table as applied.
+type MigrationStatusSQL struct {
+	db            *sql.DB
+	migrationRoot string
+}
+
+// FindPendingMigrations lists the file names of the migrations which are not
+// applied to the database yet, sorted by name.
+func (m MigrationStatusSQL) FindPendingMigrations(ctx context.Context) ([]string, error) {
+	files, err := ioutil.ReadDir(m.migrationRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`
+SELECT "%s"
+FROM "%s";
+`,
+		table.Migration.ColumnID,
+		table.Migration.TableName,
+	)
+	rows, err := m.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[string]bool)
+	for rows.Next() {
+		var id string
+		err = rows.Scan(&id)
+		if err != nil {
+			return nil, err
+		}
+		applied[id] = true
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []string
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() || !strings.HasSuffix(name, ".sql") {
+			continue
+		}
+		if !applied[name] {
+			pending = append(pending, name)
+		}
+	}
+	sort.Strings(pending)
+	return pending, nil
+}
+
+// NewMigrationStatusSQL creates MigrationStatusSQL
+func NewMigrationStatusSQL(db *sql.DB, migrationRoot string) MigrationStatusSQL {
+	return MigrationStatusSQL{
+		db:            db,
+		migrationRoot: migrationRoot,
+	}
+}
@@ -0,0 +1,62 @@
+// +build integration all
+
+package db_test
+
+import (
+	"context"
+	"database/sql"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/short-d/app/mdtest"
+	"github.com/short-d/short/app/adapter/db"
+)
+
+func TestMigrationStatusSQL_FindPendingMigrations(t *testing.T) {
+	pendingRoot, err := ioutil.TempDir("", "migration")
+	mdtest.Equal(t, nil, err)
+	defer os.RemoveAll(pendingRoot)
+
+	for _, name := range []string{"999_add_pending_table.sql", "README.md"} {
+		err = ioutil.WriteFile(path.Join(pendingRoot, name), []byte{}, 0644)
+		mdtest.Equal(t, nil, err)
+	}
+
+	testCases := []struct {
+		name                      string
+		migrationRoot             string
+		expectedPendingMigrations []string
+	}{
+		{
+			name:                      "all migrations applied",
+			migrationRoot:             dbMigrationRoot,
+			expectedPendingMigrations: nil,
+		},
+		{
+			name:          "migration not applied",
+			migrationRoot: pendingRoot,
+			expectedPendingMigrations: []string{
+				"999_add_pending_table.sql",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mdtest.AccessTestDB(
+				dbConnector,
+				dbMigrationTool,
+				dbMigrationRoot,
+				dbConfig,
+				func(sqlDB *sql.DB) {
+					migrationStatus := db.NewMigrationStatusSQL(sqlDB, testCase.migrationRoot)
+					pendingMigrations, err := migrationStatus.FindPendingMigrations(context.Background())
+					mdtest.Equal(t, nil, err)
+					mdtest.Equal(t, testCase.expectedPendingMigrations, pendingMigrations)
+				},
+			)
+		})
+	}
+}
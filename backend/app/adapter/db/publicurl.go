@@ -0,0 +1,125 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/short-d/short/app/adapter/db/table"
+	"github.com/short-d/short/app/usecase/repository"
+)
+
+var _ repository.PublicURL = (*PublicURLSQL)(nil)
+
+// PublicURLSQL accesses the visibility of URLs in public_url table through
+// SQL.
+type PublicURLSQL struct {
+	db *sql.DB
+}
+
+// Create marks the URL with the given alias as public by inserting it into
+// public_url table.
+func (p PublicURLSQL) Create(ctx context.Context, domain string, alias string) error {
+	statement := fmt.Sprintf(`
+INSERT INTO "%s" ("%s","%s")
+VALUES ($1,$2)
+ON CONFLICT DO NOTHING;`,
+		table.PublicURL.TableName,
+		table.PublicURL.ColumnDomain,
+		table.PublicURL.ColumnAlias,
+	)
+
+	_, err := p.db.ExecContext(ctx, statement, domain, alias)
+	return err
+}
+
+// Delete marks the URL with the given alias as private by removing it from
+// public_url table.
+func (p PublicURLSQL) Delete(ctx context.Context, domain string, alias string) error {
+	statement := fmt.Sprintf(`
+DELETE FROM "%s"
+WHERE "%s"=$1 AND "%s"=$2;`,
+		table.PublicURL.TableName,
+		table.PublicURL.ColumnDomain,
+		table.PublicURL.ColumnAlias,
+	)
+
+	_, err := p.db.ExecContext(ctx, statement, domain, alias)
+	return err
+}
+
+// IsPublic checks whether the URL with the given alias exists in public_url
+// table.
+func (p PublicURLSQL) IsPublic(ctx context.Context, domain string, alias string) (bool, error) {
+	query := fmt.Sprintf(`
+SELECT "%s"
+FROM "%s"
+WHERE "%s"=$1 AND "%s"=$2;`,
+		table.PublicURL.ColumnAlias,
+		table.PublicURL.TableName,
+		table.PublicURL.ColumnDomain,
+		table.PublicURL.ColumnAlias,
+	)
+
+	err := p.db.QueryRowContext(ctx, query, domain, alias).Scan(&alias)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// FindKeys fetches the keys of at most limit public URLs in alphabetical
+// order of their aliases and then domains, starting after the given key.
+func (p PublicURLSQL) FindKeys(ctx context.Context, limit int, after *repository.URLKey) ([]repository.URLKey, error) {
+	condition := ""
+	args := []interface{}{limit}
+	if after != nil {
+		condition = fmt.Sprintf(
+			`WHERE ("%s","%s")>($2,$3)`,
+			table.PublicURL.ColumnAlias,
+			table.PublicURL.ColumnDomain,
+		)
+		args = append(args, after.Alias, after.Domain)
+	}
+
+	query := fmt.Sprintf(`
+SELECT "%s","%s"
+FROM "%s"
+%s
+ORDER BY "%s","%s"
+LIMIT $1;`,
+		table.PublicURL.ColumnDomain,
+		table.PublicURL.ColumnAlias,
+		table.PublicURL.TableName,
+		condition,
+		table.PublicURL.ColumnAlias,
+		table.PublicURL.ColumnDomain,
+	)
+
+	rows, err := p.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []repository.URLKey
+	for rows.Next() {
+		var key repository.URLKey
+		err = rows.Scan(&key.Domain, &key.Alias)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+// NewPublicURLSQL creates PublicURLSQL
+func NewPublicURLSQL(db *sql.DB) PublicURLSQL {
+	return PublicURLSQL{
+		db: db,
+	}
+}
@@ -0,0 +1,214 @@
+// +build integration all
+
+package db_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/short-d/app/mdtest"
+	"github.com/short-d/short/app/adapter/db"
+	"github.com/short-d/short/app/adapter/db/table"
+	"github.com/short-d/short/app/usecase/repository"
+)
+
+var insertPublicURLRowSQL = fmt.Sprintf(`
+INSERT INTO %s (%s)
+VALUES ($1)`,
+	table.PublicURL.TableName,
+	table.PublicURL.ColumnAlias,
+)
+
+func TestPublicURLSQL_Create(t *testing.T) {
+	testCases := []struct {
+		name          string
+		urlTableRows  []urlTableRow
+		publicAliases []string
+		alias         string
+		hasErr        bool
+	}{
+		{
+			name:         "alias not found",
+			urlTableRows: []urlTableRow{},
+			alias:        "220uFicCJj",
+			hasErr:       true,
+		},
+		{
+			name: "make url public",
+			urlTableRows: []urlTableRow{
+				{alias: "220uFicCJj"},
+			},
+			alias:  "220uFicCJj",
+			hasErr: false,
+		},
+		{
+			name: "url already public",
+			urlTableRows: []urlTableRow{
+				{alias: "220uFicCJj"},
+			},
+			publicAliases: []string{"220uFicCJj"},
+			alias:         "220uFicCJj",
+			hasErr:        false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mdtest.AccessTestDB(
+				dbConnector,
+				dbMigrationTool,
+				dbMigrationRoot,
+				dbConfig,
+				func(sqlDB *sql.DB) {
+					insertURLTableRows(t, sqlDB, testCase.urlTableRows)
+
+					insertPublicURLTableRows(t, sqlDB, testCase.publicAliases)
+
+					publicURLRepo := db.NewPublicURLSQL(sqlDB)
+
+					err := publicURLRepo.Create(context.Background(), "", testCase.alias)
+					if testCase.hasErr {
+						mdtest.NotEqual(t, nil, err)
+						return
+					}
+					mdtest.Equal(t, nil, err)
+
+					isPublic, err := publicURLRepo.IsPublic(context.Background(), "", testCase.alias)
+					mdtest.Equal(t, nil, err)
+					mdtest.Equal(t, true, isPublic)
+				},
+			)
+		})
+	}
+}
+
+func TestPublicURLSQL_Delete(t *testing.T) {
+	testCases := []struct {
+		name          string
+		urlTableRows  []urlTableRow
+		publicAliases []string
+		alias         string
+	}{
+		{
+			name: "make url private",
+			urlTableRows: []urlTableRow{
+				{alias: "220uFicCJj"},
+			},
+			publicAliases: []string{"220uFicCJj"},
+			alias:         "220uFicCJj",
+		},
+		{
+			name: "url already private",
+			urlTableRows: []urlTableRow{
+				{alias: "220uFicCJj"},
+			},
+			alias: "220uFicCJj",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mdtest.AccessTestDB(
+				dbConnector,
+				dbMigrationTool,
+				dbMigrationRoot,
+				dbConfig,
+				func(sqlDB *sql.DB) {
+					insertURLTableRows(t, sqlDB, testCase.urlTableRows)
+
+					insertPublicURLTableRows(t, sqlDB, testCase.publicAliases)
+
+					publicURLRepo := db.NewPublicURLSQL(sqlDB)
+
+					err := publicURLRepo.Delete(context.Background(), "", testCase.alias)
+					mdtest.Equal(t, nil, err)
+
+					isPublic, err := publicURLRepo.IsPublic(context.Background(), "", testCase.alias)
+					mdtest.Equal(t, nil, err)
+					mdtest.Equal(t, false, isPublic)
+				},
+			)
+		})
+	}
+}
+
+func TestPublicURLSQL_FindKeys(t *testing.T) {
+	afterKey := repository.URLKey{Alias: "abc"}
+
+	testCases := []struct {
+		name          string
+		urlTableRows  []urlTableRow
+		publicAliases []string
+		limit         int
+		afterKey      *repository.URLKey
+		hasErr        bool
+		expectedKeys  []repository.URLKey
+	}{
+		{
+			name:         "no public url",
+			urlTableRows: []urlTableRow{{alias: "abc"}},
+			limit:        2,
+			hasErr:       false,
+			expectedKeys: nil,
+		},
+		{
+			name: "first page",
+			urlTableRows: []urlTableRow{
+				{alias: "xyz"},
+				{alias: "abc"},
+				{alias: "def"},
+				{alias: "private"},
+			},
+			publicAliases: []string{"xyz", "abc", "def"},
+			limit:         2,
+			hasErr:        false,
+			expectedKeys:  []repository.URLKey{{Alias: "abc"}, {Alias: "def"}},
+		},
+		{
+			name: "after alias",
+			urlTableRows: []urlTableRow{
+				{alias: "xyz"},
+				{alias: "abc"},
+				{alias: "def"},
+			},
+			publicAliases: []string{"xyz", "abc", "def"},
+			limit:         2,
+			afterKey:      &afterKey,
+			hasErr:        false,
+			expectedKeys:  []repository.URLKey{{Alias: "def"}, {Alias: "xyz"}},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mdtest.AccessTestDB(
+				dbConnector,
+				dbMigrationTool,
+				dbMigrationRoot,
+				dbConfig,
+				func(sqlDB *sql.DB) {
+					insertURLTableRows(t, sqlDB, testCase.urlTableRows)
+					insertPublicURLTableRows(t, sqlDB, testCase.publicAliases)
+
+					publicURLRepo := db.NewPublicURLSQL(sqlDB)
+					keys, err := publicURLRepo.FindKeys(context.Background(), testCase.limit, testCase.afterKey)
+					if testCase.hasErr {
+						mdtest.NotEqual(t, nil, err)
+						return
+					}
+					mdtest.Equal(t, nil, err)
+					mdtest.Equal(t, testCase.expectedKeys, keys)
+				},
+			)
+		})
+	}
+}
+
+func insertPublicURLTableRows(t *testing.T, sqlDB *sql.DB, aliases []string) {
+	for _, alias := range aliases {
+		_, err := sqlDB.Exec(insertPublicURLRowSQL, alias)
+		mdtest.Equal(t, nil, err)
+	}
+}
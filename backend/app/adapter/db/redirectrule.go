@@ -0,0 +1,162 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/short-d/short/app/adapter/db/table"
+	"github.com/short-d/short/app/entity"
+	"github.com/short-d/short/app/usecase/repository"
+)
+
+const conditionValueSeparator = ","
+
+var _ repository.RedirectRule = (*RedirectRuleSQL)(nil)
+
+// RedirectRuleSQL accesses the redirect rules of short links in redirect_rule
+// table through SQL.
+type RedirectRuleSQL struct {
+	db *sql.DB
+}
+
+// FindByAlias fetches the redirect rules of the short link with the given
+// alias from redirect_rule table in evaluation order.
+func (r RedirectRuleSQL) FindByAlias(ctx context.Context, domain string, alias string) ([]entity.RedirectRule, error) {
+	query := fmt.Sprintf(`
+SELECT "%s","%s","%s","%s"
+FROM "%s"
+WHERE "%s"=$1 AND "%s"=$2
+ORDER BY "%s";`,
+		table.RedirectRule.ColumnCondition,
+		table.RedirectRule.ColumnConditionValues,
+		table.RedirectRule.ColumnWeight,
+		table.RedirectRule.ColumnLongLink,
+		table.RedirectRule.TableName,
+		table.RedirectRule.ColumnURLDomain,
+		table.RedirectRule.ColumnURLAlias,
+		table.RedirectRule.ColumnPosition,
+	)
+
+	rows, err := r.db.QueryContext(ctx, query, domain, alias)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []entity.RedirectRule{}
+	for rows.Next() {
+		rule := entity.RedirectRule{}
+		var values string
+		err = rows.Scan(&rule.Condition, &values, &rule.Weight, &rule.LongLink)
+		if err != nil {
+			return nil, err
+		}
+
+		rule.Values = splitConditionValues(values)
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
+
+// ReplaceRules replaces all redirect rules of the short link with the given
+// alias in redirect_rule table within a single transaction.
+func (r RedirectRuleSQL) ReplaceRules(ctx context.Context, domain string, alias string, rules []entity.RedirectRule) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	err = r.deleteRules(ctx, tx, domain, alias)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = r.insertRules(ctx, tx, domain, alias, rules)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r RedirectRuleSQL) deleteRules(ctx context.Context, tx *sql.Tx, domain string, alias string) error {
+	statement := fmt.Sprintf(`
+DELETE FROM "%s"
+WHERE "%s"=$1 AND "%s"=$2;`,
+		table.RedirectRule.TableName,
+		table.RedirectRule.ColumnURLDomain,
+		table.RedirectRule.ColumnURLAlias,
+	)
+
+	_, err := tx.ExecContext(ctx, statement, domain, alias)
+	return err
+}
+
+func (r RedirectRuleSQL) insertRules(
+	ctx context.Context,
+	tx *sql.Tx,
+	domain string,
+	alias string,
+	rules []entity.RedirectRule,
+) error {
+	if len(rules) == 0 {
+		return nil
+	}
+
+	const numColumns = 5
+	rows := make([]string, 0, len(rules))
+	args := []interface{}{domain, alias}
+	for idx, rule := range rules {
+		offset := idx*numColumns + 2
+		rows = append(rows, fmt.Sprintf(
+			"($1,$2,$%d,$%d,$%d,$%d,$%d)",
+			offset+1,
+			offset+2,
+			offset+3,
+			offset+4,
+			offset+5,
+		))
+		args = append(
+			args,
+			idx,
+			string(rule.Condition),
+			strings.Join(rule.Values, conditionValueSeparator),
+			rule.Weight,
+			rule.LongLink,
+		)
+	}
+
+	statement := fmt.Sprintf(`
+INSERT INTO "%s" ("%s","%s","%s","%s","%s","%s","%s")
+VALUES %s;`,
+		table.RedirectRule.TableName,
+		table.RedirectRule.ColumnURLDomain,
+		table.RedirectRule.ColumnURLAlias,
+		table.RedirectRule.ColumnPosition,
+		table.RedirectRule.ColumnCondition,
+		table.RedirectRule.ColumnConditionValues,
+		table.RedirectRule.ColumnWeight,
+		table.RedirectRule.ColumnLongLink,
+		strings.Join(rows, ","),
+	)
+
+	_, err := tx.ExecContext(ctx, statement, args...)
+	return err
+}
+
+func splitConditionValues(values string) []string {
+	if values == "" {
+		return []string{}
+	}
+	return strings.Split(values, conditionValueSeparator)
+}
+
+// NewRedirectRuleSQL creates RedirectRuleSQL
+func NewRedirectRuleSQL(db *sql.DB) RedirectRuleSQL {
+	return RedirectRuleSQL{
+		db: db,
+	}
+}
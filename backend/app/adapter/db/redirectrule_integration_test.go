@@ -0,0 +1,106 @@
+// +build integration all
+
+package db_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/short-d/app/mdtest"
+	"github.com/short-d/short/app/adapter/db"
+	"github.com/short-d/short/app/entity"
+)
+
+func TestRedirectRuleSQL_ReplaceRules(t *testing.T) {
+	testCases := []struct {
+		name          string
+		urlTableRows  []urlTableRow
+		existingRules []entity.RedirectRule
+		alias         string
+		rules         []entity.RedirectRule
+		hasErr        bool
+	}{
+		{
+			name:         "url not found",
+			urlTableRows: []urlTableRow{},
+			alias:        "220uFicCJj",
+			rules: []entity.RedirectRule{
+				{
+					Condition: entity.RedirectConditionDevice,
+					Values:    []string{"mobile"},
+					LongLink:  "https://m.example.com",
+				},
+			},
+			hasErr: true,
+		},
+		{
+			name: "add rules in order",
+			urlTableRows: []urlTableRow{
+				{alias: "220uFicCJj", longLink: "https://www.example.com"},
+			},
+			alias: "220uFicCJj",
+			rules: []entity.RedirectRule{
+				{
+					Condition: entity.RedirectConditionLanguage,
+					Values:    []string{"fr", "de"},
+					LongLink:  "https://www.example.com/eu",
+				},
+				{
+					Condition: entity.RedirectConditionSplit,
+					Values:    []string{},
+					Weight:    50,
+					LongLink:  "https://www.example.com/b",
+				},
+			},
+			hasErr: false,
+		},
+		{
+			name: "replace existing rules",
+			urlTableRows: []urlTableRow{
+				{alias: "220uFicCJj", longLink: "https://www.example.com"},
+			},
+			existingRules: []entity.RedirectRule{
+				{
+					Condition: entity.RedirectConditionCountry,
+					Values:    []string{"CA"},
+					LongLink:  "https://www.example.ca",
+				},
+			},
+			alias:  "220uFicCJj",
+			rules:  []entity.RedirectRule{},
+			hasErr: false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mdtest.AccessTestDB(
+				dbConnector,
+				dbMigrationTool,
+				dbMigrationRoot,
+				dbConfig,
+				func(sqlDB *sql.DB) {
+					insertURLTableRows(t, sqlDB, testCase.urlTableRows)
+
+					ruleRepo := db.NewRedirectRuleSQL(sqlDB)
+					if testCase.existingRules != nil {
+						err := ruleRepo.ReplaceRules(context.Background(), "", testCase.alias, testCase.existingRules)
+						mdtest.Equal(t, nil, err)
+					}
+
+					err := ruleRepo.ReplaceRules(context.Background(), "", testCase.alias, testCase.rules)
+					if testCase.hasErr {
+						mdtest.NotEqual(t, nil, err)
+						return
+					}
+					mdtest.Equal(t, nil, err)
+
+					rules, err := ruleRepo.FindByAlias(context.Background(), "", testCase.alias)
+					mdtest.Equal(t, nil, err)
+					mdtest.Equal(t, testCase.rules, rules)
+				},
+			)
+		})
+	}
+}
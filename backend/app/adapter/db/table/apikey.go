@@ -0,0 +1,20 @@
+package table
+
+// APIKey represents database table columns for 'api_key' table
+var APIKey = struct {
+	TableName       string
+	ColumnID        string
+	ColumnUserEmail string
+	ColumnName      string
+	ColumnKeyHash   string
+	ColumnScopes    string
+	ColumnCreatedAt string
+}{
+	TableName:       "api_key",
+	ColumnID:        "id",
+	ColumnUserEmail: "user_email",
+	ColumnName:      "name",
+	ColumnKeyHash:   "key_hash",
+	ColumnScopes:    "scopes",
+	ColumnCreatedAt: "created_at",
+}
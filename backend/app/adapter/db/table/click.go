@@ -0,0 +1,22 @@
+package table
+
+// Click represents database table columns for 'click' table
+var Click = struct {
+	TableName       string
+	ColumnID        string
+	ColumnURLDomain string
+	ColumnURLAlias  string
+	ColumnClickedAt string
+	ColumnReferrer  string
+	ColumnUserAgent string
+	ColumnIPAddress string
+}{
+	TableName:       "click",
+	ColumnID:        "id",
+	ColumnURLDomain: "url_domain",
+	ColumnURLAlias:  "url_alias",
+	ColumnClickedAt: "clicked_at",
+	ColumnReferrer:  "referrer",
+	ColumnUserAgent: "user_agent",
+	ColumnIPAddress: "ip_address",
+}
@@ -0,0 +1,18 @@
+package table
+
+// Domain represents database table columns for 'domain' table
+var Domain = struct {
+	TableName               string
+	ColumnHostname          string
+	ColumnOwnerEmail        string
+	ColumnVerificationToken string
+	ColumnVerifiedAt        string
+	ColumnCreatedAt         string
+}{
+	TableName:               "domain",
+	ColumnHostname:          "hostname",
+	ColumnOwnerEmail:        "owner_email",
+	ColumnVerificationToken: "verification_token",
+	ColumnVerifiedAt:        "verified_at",
+	ColumnCreatedAt:         "created_at",
+}
@@ -0,0 +1,11 @@
+package table
+
+// Migration represents database table columns for 'gorp_migrations' table,
+// which records the migrations applied by the migration tool
+var Migration = struct {
+	TableName string
+	ColumnID  string
+}{
+	TableName: "gorp_migrations",
+	ColumnID:  "id",
+}
@@ -0,0 +1,12 @@
+package table
+
+// PublicURL represents database table columns for 'public_url' table
+var PublicURL = struct {
+	TableName    string
+	ColumnDomain string
+	ColumnAlias  string
+}{
+	TableName:    "public_url",
+	ColumnDomain: "domain",
+	ColumnAlias:  "alias",
+}
@@ -0,0 +1,22 @@
+package table
+
+// RedirectRule represents database table columns for 'redirect_rule' table
+var RedirectRule = struct {
+	TableName             string
+	ColumnURLDomain       string
+	ColumnURLAlias        string
+	ColumnPosition        string
+	ColumnCondition       string
+	ColumnConditionValues string
+	ColumnWeight          string
+	ColumnLongLink        string
+}{
+	TableName:             "redirect_rule",
+	ColumnURLDomain:       "url_domain",
+	ColumnURLAlias:        "url_alias",
+	ColumnPosition:        "position",
+	ColumnCondition:       "condition",
+	ColumnConditionValues: "condition_values",
+	ColumnWeight:          "weight",
+	ColumnLongLink:        "long_link",
+}
@@ -0,0 +1,16 @@
+package table
+
+// TokenBucket represents database table columns for 'rate_limit_bucket' table
+var TokenBucket = struct {
+	TableName        string
+	ColumnKey        string
+	ColumnTokens     string
+	ColumnRefilledAt string
+	ColumnFullAt     string
+}{
+	TableName:        "rate_limit_bucket",
+	ColumnKey:        "key",
+	ColumnTokens:     "tokens",
+	ColumnRefilledAt: "refilled_at",
+	ColumnFullAt:     "full_at",
+}
@@ -2,17 +2,37 @@ package table
 
 // URL represents database table columns for 'url' table
 var URL = struct {
-	TableName         string
-	ColumnAlias       string
-	ColumnOriginalURL string
-	ColumnCreatedAt   string
-	ColumnExpireAt    string
-	ColumnUpdatedAt   string
+	TableName            string
+	ColumnDomain         string
+	ColumnAlias          string
+	ColumnOriginalURL    string
+	ColumnCreatedAt      string
+	ColumnExpireAt       string
+	ColumnUpdatedAt      string
+	ColumnPasswordHash   string
+	ColumnMaxClicks      string
+	ColumnClickCount     string
+	ColumnActivateAt     string
+	ColumnForwardQuery   string
+	ColumnForwardPath    string
+	ColumnUTMParams      string
+	ColumnTitle          string
+	ColumnRedirectStatus string
 }{
-	TableName:         "url",
-	ColumnAlias:       "alias",
-	ColumnOriginalURL: "original_url",
-	ColumnCreatedAt:   "created_at",
-	ColumnExpireAt:    "expire_at",
-	ColumnUpdatedAt:   "updated_at",
+	TableName:            "url",
+	ColumnDomain:         "domain",
+	ColumnAlias:          "alias",
+	ColumnOriginalURL:    "original_url",
+	ColumnCreatedAt:      "created_at",
+	ColumnExpireAt:       "expire_at",
+	ColumnUpdatedAt:      "updated_at",
+	ColumnPasswordHash:   "password_hash",
+	ColumnMaxClicks:      "max_clicks",
+	ColumnClickCount:     "click_count",
+	ColumnActivateAt:     "activate_at",
+	ColumnForwardQuery:   "forward_query",
+	ColumnForwardPath:    "forward_path",
+	ColumnUTMParams:      "utm_params",
+	ColumnTitle:          "title",
+	ColumnRedirectStatus: "redirect_status",
 }
@@ -4,9 +4,11 @@ package table
 var UserURLRelation = struct {
 	TableName       string
 	ColumnUserEmail string
+	ColumnURLDomain string
 	ColumnURLAlias  string
 }{
 	TableName:       "user_url_relation",
 	ColumnUserEmail: "user_email",
+	ColumnURLDomain: "url_domain",
 	ColumnURLAlias:  "url_alias",
 }
@@ -0,0 +1,139 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/short-d/short/app/adapter/db/table"
+	"github.com/short-d/short/app/entity"
+	"github.com/short-d/short/app/usecase/repository"
+)
+
+const tokenBucketSweepInterval = time.Minute
+
+// refilledTokens computes the tokens of a bucket refilled up to $4, given the
+// capacity $2 and the refill rate $3 in tokens per second.
+var refilledTokens = fmt.Sprintf(
+	`LEAST($2, "bucket"."%s" + GREATEST(EXTRACT(EPOCH FROM ($4 - "bucket"."%s"))::DOUBLE PRECISION, 0) * $3)`,
+	table.TokenBucket.ColumnTokens,
+	table.TokenBucket.ColumnRefilledAt,
+)
+
+var _ repository.TokenBucket = (*TokenBucketSQL)(nil)
+
+// TokenBucketSQL accesses token buckets in rate_limit_bucket table through
+// SQL so that rate limits are shared across instances. Each row records when
+// the bucket becomes full again, so that fully refilled buckets can be deleted
+// periodically since they are indistinguishable from new ones.
+type TokenBucketSQL struct {
+	db          *sql.DB
+	mutex       *sync.Mutex
+	lastSweptAt *time.Time
+}
+
+// TakeToken refills and consumes a token from the bucket with the given key in
+// a single statement, creating a full bucket if it doesn't exist. The bucket
+// is left unchanged when it doesn't have enough tokens.
+func (t TokenBucketSQL) TakeToken(ctx context.Context, key string, limit entity.RateLimit, now time.Time) (bool, error) {
+	err := t.sweep(ctx, now)
+	if err != nil {
+		return false, err
+	}
+
+	refilledAt := fmt.Sprintf(`GREATEST("bucket"."%s", $4)`, table.TokenBucket.ColumnRefilledAt)
+	statement := fmt.Sprintf(`
+INSERT INTO "%s" AS "bucket" ("%s","%s","%s","%s")
+VALUES ($1, $2 - 1, $4, $4 + INTERVAL '1 second' / $3::DOUBLE PRECISION)
+ON CONFLICT ("%s") DO UPDATE
+SET "%s"=%s - 1,
+    "%s"=%s,
+    "%s"=%s + INTERVAL '1 second' * (($2 - (%s - 1)) / $3)
+WHERE %s >= 1
+RETURNING "bucket"."%s";`,
+		table.TokenBucket.TableName,
+		table.TokenBucket.ColumnKey,
+		table.TokenBucket.ColumnTokens,
+		table.TokenBucket.ColumnRefilledAt,
+		table.TokenBucket.ColumnFullAt,
+		table.TokenBucket.ColumnKey,
+		table.TokenBucket.ColumnTokens,
+		refilledTokens,
+		table.TokenBucket.ColumnRefilledAt,
+		refilledAt,
+		table.TokenBucket.ColumnFullAt,
+		refilledAt,
+		refilledTokens,
+		refilledTokens,
+		table.TokenBucket.ColumnTokens,
+	)
+
+	capacity := float64(limit.Requests)
+	refillRate := capacity / limit.Period.Seconds()
+
+	var tokens float64
+	err = t.db.QueryRowContext(ctx, statement, key, capacity, refillRate, now).Scan(&tokens)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// HasToken checks whether a token can be taken from the bucket with the given
+// key without consuming it. Buckets which don't exist are full.
+func (t TokenBucketSQL) HasToken(ctx context.Context, key string, limit entity.RateLimit, now time.Time) (bool, error) {
+	statement := fmt.Sprintf(`
+SELECT %s >= 1
+FROM "%s" AS "bucket"
+WHERE "bucket"."%s"=$1;`,
+		refilledTokens,
+		table.TokenBucket.TableName,
+		table.TokenBucket.ColumnKey,
+	)
+
+	capacity := float64(limit.Requests)
+	refillRate := capacity / limit.Period.Seconds()
+
+	var hasToken bool
+	err := t.db.QueryRowContext(ctx, statement, key, capacity, refillRate, now).Scan(&hasToken)
+	if err == sql.ErrNoRows {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return hasToken, nil
+}
+
+func (t TokenBucketSQL) sweep(ctx context.Context, now time.Time) error {
+	t.mutex.Lock()
+	if now.Sub(*t.lastSweptAt) < tokenBucketSweepInterval {
+		t.mutex.Unlock()
+		return nil
+	}
+	*t.lastSweptAt = now
+	t.mutex.Unlock()
+
+	statement := fmt.Sprintf(`
+DELETE FROM "%s"
+WHERE "%s"<=$1;`,
+		table.TokenBucket.TableName,
+		table.TokenBucket.ColumnFullAt,
+	)
+	_, err := t.db.ExecContext(ctx, statement, now)
+	return err
+}
+
+// NewTokenBucketSQL creates TokenBucketSQL
+func NewTokenBucketSQL(db *sql.DB) TokenBucketSQL {
+	return TokenBucketSQL{
+		db:          db,
+		mutex:       &sync.Mutex{},
+		lastSweptAt: &time.Time{},
+	}
+}
@@ -0,0 +1,184 @@
+// +build integration all
+
+package db_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/mdtest"
+	"github.com/short-d/short/app/adapter/db"
+	"github.com/short-d/short/app/adapter/db/table"
+	"github.com/short-d/short/app/entity"
+)
+
+var countTokenBucketsSQL = fmt.Sprintf(`
+SELECT COUNT(*)
+FROM %s
+WHERE "%s"=$1`,
+	table.TokenBucket.TableName,
+	table.TokenBucket.ColumnKey,
+)
+
+func TestTokenBucketSQL_TakeToken(t *testing.T) {
+	now := mustParseTime(t, "2020-01-02T03:04:05Z")
+	limit := entity.RateLimit{Requests: 2, Period: time.Minute}
+
+	type take struct {
+		key           string
+		elapsed       time.Duration
+		expectedTaken bool
+	}
+
+	testCases := []struct {
+		name  string
+		takes []take
+	}{
+		{
+			name: "burst exceeds limit",
+			takes: []take{
+				{key: "redirect:ip:127.0.0.1", expectedTaken: true},
+				{key: "redirect:ip:127.0.0.1", expectedTaken: true},
+				{key: "redirect:ip:127.0.0.1", expectedTaken: false},
+			},
+		},
+		{
+			name: "keys have separate buckets",
+			takes: []take{
+				{key: "redirect:ip:127.0.0.1", expectedTaken: true},
+				{key: "redirect:ip:127.0.0.1", expectedTaken: true},
+				{key: "redirect:ip:127.0.0.2", expectedTaken: true},
+			},
+		},
+		{
+			name: "tokens refill over time",
+			takes: []take{
+				{key: "redirect:ip:127.0.0.1", expectedTaken: true},
+				{key: "redirect:ip:127.0.0.1", expectedTaken: true},
+				{key: "redirect:ip:127.0.0.1", elapsed: 20 * time.Second, expectedTaken: false},
+				{key: "redirect:ip:127.0.0.1", elapsed: 10 * time.Second, expectedTaken: true},
+				{key: "redirect:ip:127.0.0.1", expectedTaken: false},
+			},
+		},
+		{
+			name: "tokens never exceed limit",
+			takes: []take{
+				{key: "redirect:ip:127.0.0.1", expectedTaken: true},
+				{key: "redirect:ip:127.0.0.1", elapsed: time.Hour, expectedTaken: true},
+				{key: "redirect:ip:127.0.0.1", expectedTaken: true},
+				{key: "redirect:ip:127.0.0.1", expectedTaken: false},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mdtest.AccessTestDB(
+				dbConnector,
+				dbMigrationTool,
+				dbMigrationRoot,
+				dbConfig,
+				func(sqlDB *sql.DB) {
+					tokenBucketRepo := db.NewTokenBucketSQL(sqlDB)
+
+					current := now
+					for _, take := range testCase.takes {
+						current = current.Add(take.elapsed)
+						isTaken, err := tokenBucketRepo.TakeToken(context.Background(), take.key, limit, current)
+						mdtest.Equal(t, nil, err)
+						mdtest.Equal(t, take.expectedTaken, isTaken)
+					}
+				},
+			)
+		})
+	}
+}
+
+func TestTokenBucketSQL_HasToken(t *testing.T) {
+	now := mustParseTime(t, "2020-01-02T03:04:05Z")
+	limit := entity.RateLimit{Requests: 1, Period: time.Minute}
+	key := "unlock-url:ip:127.0.0.1:alias:220uFicCJj"
+
+	mdtest.AccessTestDB(
+		dbConnector,
+		dbMigrationTool,
+		dbMigrationRoot,
+		dbConfig,
+		func(sqlDB *sql.DB) {
+			tokenBucketRepo := db.NewTokenBucketSQL(sqlDB)
+
+			hasToken, err := tokenBucketRepo.HasToken(context.Background(), key, limit, now)
+			mdtest.Equal(t, nil, err)
+			mdtest.Equal(t, true, hasToken)
+
+			isTaken, err := tokenBucketRepo.TakeToken(context.Background(), key, limit, now)
+			mdtest.Equal(t, nil, err)
+			mdtest.Equal(t, true, isTaken)
+
+			hasToken, err = tokenBucketRepo.HasToken(context.Background(), key, limit, now)
+			mdtest.Equal(t, nil, err)
+			mdtest.Equal(t, false, hasToken)
+
+			hasToken, err = tokenBucketRepo.HasToken(context.Background(), key, limit, now.Add(time.Minute))
+			mdtest.Equal(t, nil, err)
+			mdtest.Equal(t, true, hasToken)
+		},
+	)
+}
+
+func TestTokenBucketSQL_Sweep(t *testing.T) {
+	now := mustParseTime(t, "2020-01-02T03:04:05Z")
+	limit := entity.RateLimit{Requests: 2, Period: time.Minute}
+
+	testCases := []struct {
+		name          string
+		elapsed       time.Duration
+		expectedCount int
+	}{
+		{
+			name:          "keep bucket being refilled",
+			elapsed:       10 * time.Second,
+			expectedCount: 1,
+		},
+		{
+			name:          "keep bucket until next sweep",
+			elapsed:       50 * time.Second,
+			expectedCount: 1,
+		},
+		{
+			name:          "delete full bucket",
+			elapsed:       2 * time.Minute,
+			expectedCount: 0,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mdtest.AccessTestDB(
+				dbConnector,
+				dbMigrationTool,
+				dbMigrationRoot,
+				dbConfig,
+				func(sqlDB *sql.DB) {
+					tokenBucketRepo := db.NewTokenBucketSQL(sqlDB)
+
+					isTaken, err := tokenBucketRepo.TakeToken(context.Background(), "redirect:ip:127.0.0.1", limit, now)
+					mdtest.Equal(t, nil, err)
+					mdtest.Equal(t, true, isTaken)
+
+					isTaken, err = tokenBucketRepo.TakeToken(context.Background(), "redirect:ip:127.0.0.2", limit, now.Add(testCase.elapsed))
+					mdtest.Equal(t, nil, err)
+					mdtest.Equal(t, true, isTaken)
+
+					var count int
+					err = sqlDB.QueryRow(countTokenBucketsSQL, "redirect:ip:127.0.0.1").Scan(&count)
+					mdtest.Equal(t, nil, err)
+					mdtest.Equal(t, testCase.expectedCount, count)
+				},
+			)
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"strings"
@@ -18,17 +19,18 @@ type URLSql struct {
 }
 
 // IsAliasExist checks whether a given alias exist in url table.
-func (u URLSql) IsAliasExist(alias string) (bool, error) {
+func (u URLSql) IsAliasExist(ctx context.Context, domain string, alias string) (bool, error) {
 	query := fmt.Sprintf(`
 SELECT "%s" 
 FROM "%s" 
-WHERE "%s"=$1;`,
+WHERE "%s"=$1 AND "%s"=$2;`,
 		table.URL.ColumnAlias,
 		table.URL.TableName,
+		table.URL.ColumnDomain,
 		table.URL.ColumnAlias,
 	)
 
-	err := u.db.QueryRow(query, alias).Scan(&alias)
+	err := u.db.QueryRowContext(ctx, query, domain, alias).Scan(&alias)
 	if err == sql.ErrNoRows {
 		return false, nil
 	}
@@ -39,53 +41,97 @@ WHERE "%s"=$1;`,
 }
 
 // Create inserts a new URL into url table.
-func (u *URLSql) Create(url entity.URL) error {
+func (u *URLSql) Create(ctx context.Context, url entity.URL) error {
 	statement := fmt.Sprintf(`
-INSERT INTO "%s" ("%s","%s","%s","%s","%s")
-VALUES ($1, $2, $3, $4, $5);`,
+INSERT INTO "%s" ("%s","%s","%s","%s","%s","%s","%s","%s","%s","%s","%s","%s","%s","%s")
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14);`,
 		table.URL.TableName,
+		table.URL.ColumnDomain,
 		table.URL.ColumnAlias,
 		table.URL.ColumnOriginalURL,
 		table.URL.ColumnExpireAt,
 		table.URL.ColumnCreatedAt,
 		table.URL.ColumnUpdatedAt,
+		table.URL.ColumnPasswordHash,
+		table.URL.ColumnMaxClicks,
+		table.URL.ColumnActivateAt,
+		table.URL.ColumnForwardQuery,
+		table.URL.ColumnForwardPath,
+		table.URL.ColumnUTMParams,
+		table.URL.ColumnTitle,
+		table.URL.ColumnRedirectStatus,
 	)
-	_, err := u.db.Exec(
+	_, err := u.db.ExecContext(
+		ctx,
 		statement,
+		url.Domain,
 		url.Alias,
 		url.OriginalURL,
 		url.ExpireAt,
 		url.CreatedAt,
 		url.UpdatedAt,
+		url.PasswordHash,
+		url.MaxClicks,
+		url.ActivateAt,
+		url.ForwardQuery,
+		url.ForwardPath,
+		encodeUTM(url.UTM),
+		url.Title,
+		url.RedirectStatus,
 	)
 	return err
 }
 
 // GetByAlias finds an URL in url table given alias.
-func (u URLSql) GetByAlias(alias string) (entity.URL, error) {
+func (u URLSql) GetByAlias(ctx context.Context, domain string, alias string) (entity.URL, error) {
 	statement := fmt.Sprintf(`
-SELECT "%s","%s","%s","%s","%s" 
+SELECT "%s","%s","%s","%s","%s","%s","%s","%s","%s","%s","%s","%s","%s","%s","%s"
 FROM "%s" 
-WHERE "%s"=$1;`,
+WHERE "%s"=$1 AND "%s"=$2;`,
+		table.URL.ColumnDomain,
 		table.URL.ColumnAlias,
 		table.URL.ColumnOriginalURL,
 		table.URL.ColumnExpireAt,
 		table.URL.ColumnCreatedAt,
 		table.URL.ColumnUpdatedAt,
+		table.URL.ColumnPasswordHash,
+		table.URL.ColumnMaxClicks,
+		table.URL.ColumnClickCount,
+		table.URL.ColumnActivateAt,
+		table.URL.ColumnForwardQuery,
+		table.URL.ColumnForwardPath,
+		table.URL.ColumnUTMParams,
+		table.URL.ColumnTitle,
+		table.URL.ColumnRedirectStatus,
 		table.URL.TableName,
+		table.URL.ColumnDomain,
 		table.URL.ColumnAlias,
 	)
 
-	row := u.db.QueryRow(statement, alias)
+	row := u.db.QueryRowContext(ctx, statement, domain, alias)
 
 	url := entity.URL{}
+	var utmParams *string
 	err := row.Scan(
+		&url.Domain,
 		&url.Alias,
 		&url.OriginalURL,
 		&url.ExpireAt,
 		&url.CreatedAt,
 		&url.UpdatedAt,
+		&url.PasswordHash,
+		&url.MaxClicks,
+		&url.ClickCount,
+		&url.ActivateAt,
+		&url.ForwardQuery,
+		&url.ForwardPath,
+		&utmParams,
+		&url.Title,
+		&url.RedirectStatus,
 	)
+	if err == sql.ErrNoRows {
+		return entity.URL{}, repository.ErrURLNotFound(alias)
+	}
 	if err != nil {
 		return entity.URL{}, err
 	}
@@ -93,61 +139,85 @@ WHERE "%s"=$1;`,
 	url.CreatedAt = utc(url.CreatedAt)
 	url.UpdatedAt = utc(url.UpdatedAt)
 	url.ExpireAt = utc(url.ExpireAt)
+	url.ActivateAt = utc(url.ActivateAt)
+	url.UTM = decodeUTM(utmParams)
 
 	return url, nil
 }
 
-// GetByAliases finds URLs for a list of aliases
-func (u URLSql) GetByAliases(aliases []string) ([]entity.URL, error) {
-	if len(aliases) == 0 {
+// GetByKeys finds URLs for a list of keys
+func (u URLSql) GetByKeys(ctx context.Context, keys []repository.URLKey) ([]entity.URL, error) {
+	if len(keys) == 0 {
 		return []entity.URL{}, nil
 	}
 
-	parameterStr := u.composeParamList(len(aliases))
+	parameterStr := u.composeKeyList(len(keys))
 
-	// create a list of interface{} to hold aliases for db.Query()
-	aliasesInterface := []interface{}{}
-	for _, alias := range aliases {
-		aliasesInterface = append(aliasesInterface, alias)
+	// create a list of interface{} to hold keys for db.QueryContext(ctx, )
+	keysInterface := []interface{}{}
+	for _, key := range keys {
+		keysInterface = append(keysInterface, key.Domain, key.Alias)
 	}
 
 	var urls []entity.URL
 
 	// TODO: compare performance between Query and QueryRow. Prefer QueryRow for readability
 	statement := fmt.Sprintf(`
-SELECT "%s","%s","%s","%s","%s" 
+SELECT "%s","%s","%s","%s","%s","%s","%s","%s","%s","%s","%s","%s","%s","%s","%s"
 FROM "%s"
-WHERE "%s" IN (%s);`,
+WHERE ("%s","%s") IN (%s);`,
+		table.URL.ColumnDomain,
 		table.URL.ColumnAlias,
 		table.URL.ColumnOriginalURL,
 		table.URL.ColumnExpireAt,
 		table.URL.ColumnCreatedAt,
 		table.URL.ColumnUpdatedAt,
+		table.URL.ColumnPasswordHash,
+		table.URL.ColumnMaxClicks,
+		table.URL.ColumnClickCount,
+		table.URL.ColumnActivateAt,
+		table.URL.ColumnForwardQuery,
+		table.URL.ColumnForwardPath,
+		table.URL.ColumnUTMParams,
+		table.URL.ColumnTitle,
+		table.URL.ColumnRedirectStatus,
 		table.URL.TableName,
+		table.URL.ColumnDomain,
 		table.URL.ColumnAlias,
 		parameterStr,
 	)
 
-	stmt, err := u.db.Prepare(statement)
+	stmt, err := u.db.PrepareContext(ctx, statement)
 	if err != nil {
 		return urls, err
 	}
 	defer stmt.Close()
 
-	rows, err := stmt.Query(aliasesInterface...)
+	rows, err := stmt.QueryContext(ctx, keysInterface...)
 	if err != nil {
-		return urls, nil
+		return urls, err
 	}
 
 	defer rows.Close()
 	for rows.Next() {
 		url := entity.URL{}
+		var utmParams *string
 		err := rows.Scan(
+			&url.Domain,
 			&url.Alias,
 			&url.OriginalURL,
 			&url.ExpireAt,
 			&url.CreatedAt,
 			&url.UpdatedAt,
+			&url.PasswordHash,
+			&url.MaxClicks,
+			&url.ClickCount,
+			&url.ActivateAt,
+			&url.ForwardQuery,
+			&url.ForwardPath,
+			&utmParams,
+			&url.Title,
+			&url.RedirectStatus,
 		)
 		if err != nil {
 			return urls, err
@@ -156,18 +226,123 @@ WHERE "%s" IN (%s);`,
 		url.CreatedAt = utc(url.CreatedAt)
 		url.UpdatedAt = utc(url.UpdatedAt)
 		url.ExpireAt = utc(url.ExpireAt)
+		url.ActivateAt = utc(url.ActivateAt)
+		url.UTM = decodeUTM(utmParams)
 
 		urls = append(urls, url)
 	}
 
-	return urls, nil
+	return urls, rows.Err()
+}
+
+// Update modifies the long link, activation time, expiration time, title,
+// redirect status and forwarding options of an existing URL in url table.
+func (u *URLSql) Update(ctx context.Context, url entity.URL) error {
+	statement := fmt.Sprintf(`
+UPDATE "%s"
+SET "%s"=$1,"%s"=$2,"%s"=$3,"%s"=$4,"%s"=$5,"%s"=$6,"%s"=$7,"%s"=$8,"%s"=$9
+WHERE "%s"=$10 AND "%s"=$11;`,
+		table.URL.TableName,
+		table.URL.ColumnOriginalURL,
+		table.URL.ColumnExpireAt,
+		table.URL.ColumnActivateAt,
+		table.URL.ColumnForwardQuery,
+		table.URL.ColumnForwardPath,
+		table.URL.ColumnUTMParams,
+		table.URL.ColumnTitle,
+		table.URL.ColumnRedirectStatus,
+		table.URL.ColumnUpdatedAt,
+		table.URL.ColumnDomain,
+		table.URL.ColumnAlias,
+	)
+
+	result, err := u.db.ExecContext(
+		ctx,
+		statement,
+		url.OriginalURL,
+		url.ExpireAt,
+		url.ActivateAt,
+		url.ForwardQuery,
+		url.ForwardPath,
+		encodeUTM(url.UTM),
+		url.Title,
+		url.RedirectStatus,
+		url.UpdatedAt,
+		url.Domain,
+		url.Alias,
+	)
+	if err != nil {
+		return err
+	}
+	return checkAffected(result, url.Alias)
+}
+
+// IncrementClickCount increases the click count of an URL in url table
+// unless the URL has used up its maximum clicks. The click count is checked
+// and increased in a single statement so that concurrent clicks never exceed
+// the maximum.
+func (u *URLSql) IncrementClickCount(ctx context.Context, domain string, alias string) (bool, error) {
+	statement := fmt.Sprintf(`
+UPDATE "%s"
+SET "%s"="%s"+1
+WHERE "%s"=$1 AND "%s"=$2 AND ("%s" IS NULL OR "%s"<"%s");`,
+		table.URL.TableName,
+		table.URL.ColumnClickCount,
+		table.URL.ColumnClickCount,
+		table.URL.ColumnDomain,
+		table.URL.ColumnAlias,
+		table.URL.ColumnMaxClicks,
+		table.URL.ColumnClickCount,
+		table.URL.ColumnMaxClicks,
+	)
+
+	result, err := u.db.ExecContext(ctx, statement, domain, alias)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// Delete removes an URL from url table given alias. The relations of the URL
+// in other tables are removed through cascading.
+func (u *URLSql) Delete(ctx context.Context, domain string, alias string) error {
+	statement := fmt.Sprintf(`
+DELETE FROM "%s"
+WHERE "%s"=$1 AND "%s"=$2;`,
+		table.URL.TableName,
+		table.URL.ColumnDomain,
+		table.URL.ColumnAlias,
+	)
+
+	result, err := u.db.ExecContext(ctx, statement, domain, alias)
+	if err != nil {
+		return err
+	}
+	return checkAffected(result, alias)
+}
+
+func checkAffected(result sql.Result, alias string) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("alias not found (alias=%s)", alias)
+	}
+	return nil
 }
 
-// composeParamList converts an slice to a parameters string with format: $1, $2, $3, ...
-func (u URLSql) composeParamList(numParams int) string {
-	params := make([]string, 0, numParams)
-	for i := 0; i < numParams; i++ {
-		params = append(params, fmt.Sprintf("$%d", i+1))
+// composeKeyList converts an slice of keys to a parameters string with format:
+// ($1, $2), ($3, $4), ...
+func (u URLSql) composeKeyList(numKeys int) string {
+	params := make([]string, 0, numKeys)
+	for i := 0; i < numKeys; i++ {
+		params = append(params, fmt.Sprintf("($%d, $%d)", 2*i+1, 2*i+2))
 	}
 
 	parameterStr := strings.Join(params, ", ")
@@ -3,8 +3,10 @@
 package db_test
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"sync"
 	"testing"
 	"time"
 
@@ -12,6 +14,7 @@ import (
 	"github.com/short-d/short/app/adapter/db"
 	"github.com/short-d/short/app/adapter/db/table"
 	"github.com/short-d/short/app/entity"
+	"github.com/short-d/short/app/usecase/repository"
 )
 
 var insertURLRowSQL = fmt.Sprintf(`
@@ -67,7 +70,7 @@ func TestURLSql_IsAliasExist(t *testing.T) {
 					insertURLTableRows(t, sqlDB, testCase.tableRows)
 
 					urlRepo := db.NewURLSql(sqlDB)
-					gotIsExist, err := urlRepo.IsAliasExist(testCase.alias)
+					gotIsExist, err := urlRepo.IsAliasExist(context.Background(), "", testCase.alias)
 					mdtest.Equal(t, nil, err)
 					mdtest.Equal(t, testCase.expIsExist, gotIsExist)
 				})
@@ -161,7 +164,7 @@ func TestURLSql_GetByAlias(t *testing.T) {
 					insertURLTableRows(t, sqlDB, testCase.tableRows)
 
 					urlRepo := db.NewURLSql(sqlDB)
-					url, err := urlRepo.GetByAlias(testCase.alias)
+					url, err := urlRepo.GetByAlias(context.Background(), "", testCase.alias)
 
 					if testCase.hasErr {
 						mdtest.NotEqual(t, nil, err)
@@ -177,6 +180,9 @@ func TestURLSql_GetByAlias(t *testing.T) {
 
 func TestURLSql_Create(t *testing.T) {
 	now := mustParseTime(t, "2019-05-01T08:02:16-07:00")
+	passwordHash := "pbkdf2-sha256$100000$c2FsdA$a2V5"
+	title := "Search engine"
+	redirectStatus := 301
 
 	testCases := []struct {
 		name      string
@@ -216,6 +222,43 @@ func TestURLSql_Create(t *testing.T) {
 			},
 			hasErr: false,
 		},
+		{
+			name:      "successfully create password protected url",
+			tableRows: []urlTableRow{},
+			url: entity.URL{
+				Alias:        "220uFicCJj",
+				OriginalURL:  "http://www.google.com",
+				PasswordHash: &passwordHash,
+			},
+			hasErr: false,
+		},
+		{
+			name:      "successfully create url forwarding visits",
+			tableRows: []urlTableRow{},
+			url: entity.URL{
+				Alias:        "220uFicCJj",
+				OriginalURL:  "http://www.google.com",
+				ForwardQuery: true,
+				ForwardPath:  true,
+				UTM: &entity.UTM{
+					Source:   "newsletter",
+					Medium:   "email",
+					Campaign: "spring sale & more",
+				},
+			},
+			hasErr: false,
+		},
+		{
+			name:      "successfully create url with title and redirect status",
+			tableRows: []urlTableRow{},
+			url: entity.URL{
+				Alias:          "220uFicCJk",
+				OriginalURL:    "http://www.google.com",
+				Title:          &title,
+				RedirectStatus: &redirectStatus,
+			},
+			hasErr: false,
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -229,34 +272,43 @@ func TestURLSql_Create(t *testing.T) {
 					insertURLTableRows(t, sqlDB, testCase.tableRows)
 
 					urlRepo := db.NewURLSql(sqlDB)
-					err := urlRepo.Create(testCase.url)
+					err := urlRepo.Create(context.Background(), testCase.url)
 
 					if testCase.hasErr {
 						mdtest.NotEqual(t, nil, err)
 						return
 					}
 					mdtest.Equal(t, nil, err)
+
+					url, err := urlRepo.GetByAlias(context.Background(), "", testCase.url.Alias)
+					mdtest.Equal(t, nil, err)
+					mdtest.Equal(t, testCase.url.PasswordHash, url.PasswordHash)
+					mdtest.Equal(t, testCase.url.ForwardQuery, url.ForwardQuery)
+					mdtest.Equal(t, testCase.url.ForwardPath, url.ForwardPath)
+					mdtest.Equal(t, testCase.url.UTM, url.UTM)
+					mdtest.Equal(t, testCase.url.Title, url.Title)
+					mdtest.Equal(t, testCase.url.RedirectStatus, url.RedirectStatus)
 				},
 			)
 		})
 	}
 }
 
-func TestURLSql_GetByAliases(t *testing.T) {
+func TestURLSql_GetByKeys(t *testing.T) {
 	twoYearsAgo := mustParseTime(t, "2017-05-01T08:02:16-07:00")
 	now := mustParseTime(t, "2019-05-01T08:02:16-07:00")
 
 	testCases := []struct {
 		name         string
 		tableRows    []urlTableRow
-		aliases      []string
+		keys         []repository.URLKey
 		hasErr       bool
 		expectedURLs []entity.URL
 	}{
 		{
 			name:      "alias not found",
 			tableRows: []urlTableRow{},
-			aliases:   []string{"220uFicCJj"},
+			keys:      []repository.URLKey{{Alias: "220uFicCJj"}},
 			hasErr:    false,
 		},
 		{
@@ -277,8 +329,11 @@ func TestURLSql_GetByAliases(t *testing.T) {
 					updatedAt: &now,
 				},
 			},
-			aliases: []string{"220uFicCJj", "yDOBcj5HIPbUAsw"},
-			hasErr:  false,
+			keys: []repository.URLKey{
+				{Alias: "220uFicCJj"},
+				{Alias: "yDOBcj5HIPbUAsw"},
+			},
+			hasErr: false,
 			expectedURLs: []entity.URL{
 				{
 					Alias:       "220uFicCJj",
@@ -309,7 +364,7 @@ func TestURLSql_GetByAliases(t *testing.T) {
 					insertURLTableRows(t, sqlDB, testCase.tableRows)
 
 					urlRepo := db.NewURLSql(sqlDB)
-					urls, err := urlRepo.GetByAliases(testCase.aliases)
+					urls, err := urlRepo.GetByKeys(context.Background(), testCase.keys)
 
 					if testCase.hasErr {
 						mdtest.NotEqual(t, nil, err)
@@ -323,6 +378,211 @@ func TestURLSql_GetByAliases(t *testing.T) {
 	}
 }
 
+func TestURLSql_Update(t *testing.T) {
+	twoYearsAgo := mustParseTime(t, "2017-05-01T08:02:16-07:00")
+	now := mustParseTime(t, "2019-05-01T08:02:16-07:00")
+
+	testCases := []struct {
+		name        string
+		tableRows   []urlTableRow
+		url         entity.URL
+		hasErr      bool
+		expectedURL entity.URL
+	}{
+		{
+			name:      "alias not found",
+			tableRows: []urlTableRow{},
+			url: entity.URL{
+				Alias:       "220uFicCJj",
+				OriginalURL: "http://www.google.com",
+			},
+			hasErr: true,
+		},
+		{
+			name: "successfully update url",
+			tableRows: []urlTableRow{
+				{
+					alias:     "220uFicCJj",
+					longLink:  "http://www.facebook.com",
+					createdAt: &twoYearsAgo,
+					updatedAt: &twoYearsAgo,
+				},
+			},
+			url: entity.URL{
+				Alias:       "220uFicCJj",
+				OriginalURL: "http://www.google.com",
+				ExpireAt:    &now,
+				UpdatedAt:   &now,
+			},
+			hasErr: false,
+			expectedURL: entity.URL{
+				Alias:       "220uFicCJj",
+				OriginalURL: "http://www.google.com",
+				CreatedAt:   &twoYearsAgo,
+				ExpireAt:    &now,
+				UpdatedAt:   &now,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mdtest.AccessTestDB(
+				dbConnector,
+				dbMigrationTool,
+				dbMigrationRoot,
+				dbConfig,
+				func(sqlDB *sql.DB) {
+					insertURLTableRows(t, sqlDB, testCase.tableRows)
+
+					urlRepo := db.NewURLSql(sqlDB)
+					err := urlRepo.Update(context.Background(), testCase.url)
+
+					if testCase.hasErr {
+						mdtest.NotEqual(t, nil, err)
+						return
+					}
+					mdtest.Equal(t, nil, err)
+
+					url, err := urlRepo.GetByAlias(context.Background(), "", testCase.url.Alias)
+					mdtest.Equal(t, nil, err)
+					mdtest.Equal(t, testCase.expectedURL, url)
+				},
+			)
+		})
+	}
+}
+
+func TestURLSql_IncrementClickCount(t *testing.T) {
+	maxClicks := 3
+
+	testCases := []struct {
+		name               string
+		url                entity.URL
+		concurrentClicks   int
+		expectedCounted    int
+		expectedClickCount int
+	}{
+		{
+			name: "unlimited clicks",
+			url: entity.URL{
+				Alias:       "220uFicCJj",
+				OriginalURL: "http://www.google.com",
+			},
+			concurrentClicks:   10,
+			expectedCounted:    10,
+			expectedClickCount: 10,
+		},
+		{
+			name: "concurrent clicks never exceed max clicks",
+			url: entity.URL{
+				Alias:       "220uFicCJj",
+				OriginalURL: "http://www.google.com",
+				MaxClicks:   &maxClicks,
+			},
+			concurrentClicks:   10,
+			expectedCounted:    3,
+			expectedClickCount: 3,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mdtest.AccessTestDB(
+				dbConnector,
+				dbMigrationTool,
+				dbMigrationRoot,
+				dbConfig,
+				func(sqlDB *sql.DB) {
+					urlRepo := db.NewURLSql(sqlDB)
+					err := urlRepo.Create(context.Background(), testCase.url)
+					mdtest.Equal(t, nil, err)
+
+					var (
+						wg      sync.WaitGroup
+						mutex   sync.Mutex
+						counted int
+					)
+					for i := 0; i < testCase.concurrentClicks; i++ {
+						wg.Add(1)
+						go func() {
+							defer wg.Done()
+
+							isCounted, err := urlRepo.IncrementClickCount(context.Background(), "", testCase.url.Alias)
+							if err != nil || !isCounted {
+								return
+							}
+							mutex.Lock()
+							counted++
+							mutex.Unlock()
+						}()
+					}
+					wg.Wait()
+					mdtest.Equal(t, testCase.expectedCounted, counted)
+
+					url, err := urlRepo.GetByAlias(context.Background(), "", testCase.url.Alias)
+					mdtest.Equal(t, nil, err)
+					mdtest.Equal(t, testCase.expectedClickCount, url.ClickCount)
+				},
+			)
+		})
+	}
+}
+
+func TestURLSql_Delete(t *testing.T) {
+	testCases := []struct {
+		name      string
+		tableRows []urlTableRow
+		alias     string
+		hasErr    bool
+	}{
+		{
+			name:      "alias not found",
+			tableRows: []urlTableRow{},
+			alias:     "220uFicCJj",
+			hasErr:    true,
+		},
+		{
+			name: "successfully delete url",
+			tableRows: []urlTableRow{
+				{
+					alias:    "220uFicCJj",
+					longLink: "http://www.google.com",
+				},
+			},
+			alias:  "220uFicCJj",
+			hasErr: false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mdtest.AccessTestDB(
+				dbConnector,
+				dbMigrationTool,
+				dbMigrationRoot,
+				dbConfig,
+				func(sqlDB *sql.DB) {
+					insertURLTableRows(t, sqlDB, testCase.tableRows)
+
+					urlRepo := db.NewURLSql(sqlDB)
+					err := urlRepo.Delete(context.Background(), "", testCase.alias)
+
+					if testCase.hasErr {
+						mdtest.NotEqual(t, nil, err)
+						return
+					}
+					mdtest.Equal(t, nil, err)
+
+					isExist, err := urlRepo.IsAliasExist(context.Background(), "", testCase.alias)
+					mdtest.Equal(t, nil, err)
+					mdtest.Equal(t, false, isExist)
+				},
+			)
+		})
+	}
+}
+
 func insertURLTableRows(t *testing.T, sqlDB *sql.DB, tableRows []urlTableRow) {
 	for _, tableRow := range tableRows {
 		_, err := sqlDB.Exec(
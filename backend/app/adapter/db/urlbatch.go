@@ -0,0 +1,142 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/short-d/short/app/adapter/db/table"
+	"github.com/short-d/short/app/entity"
+	"github.com/short-d/short/app/usecase/repository"
+)
+
+var _ repository.URLBatch = (*URLBatchSQL)(nil)
+
+// URLBatchSQL persists URLs together with their owner in url and
+// user_url_relation tables through SQL.
+type URLBatchSQL struct {
+	db *sql.DB
+}
+
+// CreateURLs inserts URLs into url table and links them to the owner in
+// user_url_relation table within a single transaction.
+func (u URLBatchSQL) CreateURLs(ctx context.Context, urls []entity.URL, owner entity.User) error {
+	if len(urls) == 0 {
+		return nil
+	}
+
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	err = u.insertURLs(ctx, tx, urls)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = u.insertRelations(ctx, tx, urls, owner)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func (u URLBatchSQL) insertURLs(ctx context.Context, tx *sql.Tx, urls []entity.URL) error {
+	const numColumns = 14
+	rows := make([]string, 0, len(urls))
+	args := make([]interface{}, 0, len(urls)*numColumns)
+	for idx, url := range urls {
+		offset := idx * numColumns
+		rows = append(rows, fmt.Sprintf(
+			"($%d,$%d,$%d,$%d,$%d,$%d,$%d,$%d,$%d,$%d,$%d,$%d,$%d,$%d)",
+			offset+1,
+			offset+2,
+			offset+3,
+			offset+4,
+			offset+5,
+			offset+6,
+			offset+7,
+			offset+8,
+			offset+9,
+			offset+10,
+			offset+11,
+			offset+12,
+			offset+13,
+			offset+14,
+		))
+		args = append(
+			args,
+			url.Domain,
+			url.Alias,
+			url.OriginalURL,
+			url.ExpireAt,
+			url.CreatedAt,
+			url.UpdatedAt,
+			url.PasswordHash,
+			url.MaxClicks,
+			url.ActivateAt,
+			url.ForwardQuery,
+			url.ForwardPath,
+			encodeUTM(url.UTM),
+			url.Title,
+			url.RedirectStatus,
+		)
+	}
+
+	statement := fmt.Sprintf(`
+INSERT INTO "%s" ("%s","%s","%s","%s","%s","%s","%s","%s","%s","%s","%s","%s","%s","%s")
+VALUES %s;`,
+		table.URL.TableName,
+		table.URL.ColumnDomain,
+		table.URL.ColumnAlias,
+		table.URL.ColumnOriginalURL,
+		table.URL.ColumnExpireAt,
+		table.URL.ColumnCreatedAt,
+		table.URL.ColumnUpdatedAt,
+		table.URL.ColumnPasswordHash,
+		table.URL.ColumnMaxClicks,
+		table.URL.ColumnActivateAt,
+		table.URL.ColumnForwardQuery,
+		table.URL.ColumnForwardPath,
+		table.URL.ColumnUTMParams,
+		table.URL.ColumnTitle,
+		table.URL.ColumnRedirectStatus,
+		strings.Join(rows, ","),
+	)
+
+	_, err := tx.ExecContext(ctx, statement, args...)
+	return err
+}
+
+func (u URLBatchSQL) insertRelations(ctx context.Context, tx *sql.Tx, urls []entity.URL, owner entity.User) error {
+	rows := make([]string, 0, len(urls))
+	args := []interface{}{owner.Email}
+	for idx, url := range urls {
+		rows = append(rows, fmt.Sprintf("($1,$%d,$%d)", 2*idx+2, 2*idx+3))
+		args = append(args, url.Domain, url.Alias)
+	}
+
+	statement := fmt.Sprintf(`
+INSERT INTO "%s" ("%s","%s","%s")
+VALUES %s;`,
+		table.UserURLRelation.TableName,
+		table.UserURLRelation.ColumnUserEmail,
+		table.UserURLRelation.ColumnURLDomain,
+		table.UserURLRelation.ColumnURLAlias,
+		strings.Join(rows, ","),
+	)
+
+	_, err := tx.ExecContext(ctx, statement, args...)
+	return err
+}
+
+// NewURLBatchSQL creates URLBatchSQL
+func NewURLBatchSQL(db *sql.DB) URLBatchSQL {
+	return URLBatchSQL{
+		db: db,
+	}
+}
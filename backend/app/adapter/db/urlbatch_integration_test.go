@@ -0,0 +1,93 @@
+// +build integration all
+
+package db_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/short-d/app/mdtest"
+	"github.com/short-d/short/app/adapter/db"
+	"github.com/short-d/short/app/entity"
+)
+
+func TestURLBatchSQL_CreateURLs(t *testing.T) {
+	now := mustParseTime(t, "2019-05-01T08:02:16Z")
+	owner := entity.User{Email: "alpha@example.com"}
+
+	testCases := []struct {
+		name          string
+		userTableRows []userTableRow
+		urlTableRows  []urlTableRow
+		urls          []entity.URL
+		hasErr        bool
+	}{
+		{
+			name: "alias exists",
+			userTableRows: []userTableRow{
+				{email: "alpha@example.com"},
+			},
+			urlTableRows: []urlTableRow{
+				{alias: "220uFicCJj"},
+			},
+			urls: []entity.URL{
+				{Alias: "abc", OriginalURL: "http://www.google.com"},
+				{Alias: "220uFicCJj", OriginalURL: "http://www.google.com"},
+			},
+			hasErr: true,
+		},
+		{
+			name: "create urls successfully",
+			userTableRows: []userTableRow{
+				{email: "alpha@example.com"},
+			},
+			urlTableRows: []urlTableRow{},
+			urls: []entity.URL{
+				{Alias: "abc", OriginalURL: "http://www.google.com", CreatedAt: &now},
+				{Alias: "220uFicCJj", OriginalURL: "http://www.facebook.com", CreatedAt: &now},
+			},
+			hasErr: false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mdtest.AccessTestDB(
+				dbConnector,
+				dbMigrationTool,
+				dbMigrationRoot,
+				dbConfig,
+				func(sqlDB *sql.DB) {
+					insertUserTableRows(t, sqlDB, testCase.userTableRows)
+					insertURLTableRows(t, sqlDB, testCase.urlTableRows)
+
+					urlBatchRepo := db.NewURLBatchSQL(sqlDB)
+					err := urlBatchRepo.CreateURLs(context.Background(), testCase.urls, owner)
+
+					urlRepo := db.NewURLSql(sqlDB)
+					userURLRelationRepo := db.NewUserURLRelationSQL(sqlDB)
+					if testCase.hasErr {
+						mdtest.NotEqual(t, nil, err)
+
+						isExist, err := urlRepo.IsAliasExist(context.Background(), "", testCase.urls[0].Alias)
+						mdtest.Equal(t, nil, err)
+						mdtest.Equal(t, false, isExist)
+						return
+					}
+					mdtest.Equal(t, nil, err)
+
+					for _, url := range testCase.urls {
+						savedURL, err := urlRepo.GetByAlias(context.Background(), "", url.Alias)
+						mdtest.Equal(t, nil, err)
+						mdtest.Equal(t, url, savedURL)
+
+						isOwner, err := userURLRelationRepo.IsAliasOwner(context.Background(), owner, "", url.Alias)
+						mdtest.Equal(t, nil, err)
+						mdtest.Equal(t, true, isOwner)
+					}
+				},
+			)
+		})
+	}
+}
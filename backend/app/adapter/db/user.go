@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 
@@ -17,7 +18,7 @@ type UserSQL struct {
 }
 
 // IsIDExist checks whether a given user ID exists in user table.
-func (u UserSQL) IsIDExist(id string) (bool, error) {
+func (u UserSQL) IsIDExist(ctx context.Context, id string) (bool, error) {
 	query := fmt.Sprintf(`
 SELECT "%s" 
 FROM "%s" 
@@ -28,7 +29,7 @@ WHERE "%s"=$1;
 		table.User.ColumnID,
 	)
 
-	err := u.db.QueryRow(query, id).Scan(&id)
+	err := u.db.QueryRowContext(ctx, query, id).Scan(&id)
 	if err == sql.ErrNoRows {
 		return false, nil
 	}
@@ -39,7 +40,7 @@ WHERE "%s"=$1;
 }
 
 // IsEmailExist checks whether a given email exists in user table.
-func (u UserSQL) IsEmailExist(email string) (bool, error) {
+func (u UserSQL) IsEmailExist(ctx context.Context, email string) (bool, error) {
 	query := fmt.Sprintf(`
 SELECT "%s" 
 FROM "%s" 
@@ -50,7 +51,7 @@ WHERE "%s"=$1;
 		table.User.ColumnEmail,
 	)
 
-	err := u.db.QueryRow(query, email).Scan(&email)
+	err := u.db.QueryRowContext(ctx, query, email).Scan(&email)
 	if err == sql.ErrNoRows {
 		return false, nil
 	}
@@ -61,7 +62,7 @@ WHERE "%s"=$1;
 }
 
 // GetUserByID finds an User in user table given user ID.
-func (u UserSQL) GetUserByID(id string) (entity.User, error) {
+func (u UserSQL) GetUserByID(ctx context.Context, id string) (entity.User, error) {
 	query := fmt.Sprintf(`
 SELECT "%s","%s","%s","%s","%s", "%s"
 FROM "%s" 
@@ -77,7 +78,7 @@ WHERE "%s"=$1;
 		table.User.ColumnID,
 	)
 
-	row := u.db.QueryRow(query, id)
+	row := u.db.QueryRowContext(ctx, query, id)
 
 	user := entity.User{}
 	err := row.Scan(
@@ -100,7 +101,7 @@ WHERE "%s"=$1;
 }
 
 // GetUserByEmail finds an User in user table given email.
-func (u UserSQL) GetUserByEmail(email string) (entity.User, error) {
+func (u UserSQL) GetUserByEmail(ctx context.Context, email string) (entity.User, error) {
 	query := fmt.Sprintf(`
 SELECT "%s","%s","%s","%s","%s", "%s"
 FROM "%s" 
@@ -116,7 +117,7 @@ WHERE "%s"=$1;
 		table.User.ColumnEmail,
 	)
 
-	row := u.db.QueryRow(query, email)
+	row := u.db.QueryRowContext(ctx, query, email)
 
 	user := entity.User{}
 	err := row.Scan(
@@ -139,7 +140,7 @@ WHERE "%s"=$1;
 }
 
 // CreateUser inserts a new User into user table.
-func (u *UserSQL) CreateUser(user entity.User) error {
+func (u *UserSQL) CreateUser(ctx context.Context, user entity.User) error {
 	statement := fmt.Sprintf(`
 INSERT INTO "%s" ("%s", "%s","%s","%s","%s","%s")
 VALUES ($1, $2, $3, $4, $5, $6)
@@ -153,7 +154,8 @@ VALUES ($1, $2, $3, $4, $5, $6)
 		table.User.ColumnUpdatedAt,
 	)
 
-	_, err := u.db.Exec(
+	_, err := u.db.ExecContext(
+		ctx,
 		statement,
 		user.ID,
 		user.Email,
@@ -166,8 +168,8 @@ VALUES ($1, $2, $3, $4, $5, $6)
 }
 
 // UpdateUserID updates the ID of an user in user table with given email address.
-func (u UserSQL) UpdateUserID(email string, userID string) error {
-	isExist, err := u.IsEmailExist(email)
+func (u UserSQL) UpdateUserID(ctx context.Context, email string, userID string) error {
+	isExist, err := u.IsEmailExist(ctx, email)
 	if err != nil {
 		return err
 	}
@@ -183,7 +185,7 @@ WHERE "%s"=$2
 		table.User.TableName,
 		table.User.ColumnID,
 		table.User.ColumnEmail)
-	_, err = u.db.Exec(statement, userID, email)
+	_, err = u.db.ExecContext(ctx, statement, userID, email)
 	return err
 }
 
@@ -3,6 +3,7 @@
 package db_test
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"testing"
@@ -67,7 +68,7 @@ func TestUserSql_IsIDExist(t *testing.T) {
 					insertUserTableRows(t, sqlDB, testCase.tableRows)
 
 					userRepo := db.NewUserSQL(sqlDB)
-					gotIsExist, err := userRepo.IsIDExist(testCase.id)
+					gotIsExist, err := userRepo.IsIDExist(context.Background(), testCase.id)
 					mdtest.Equal(t, nil, err)
 					mdtest.Equal(t, testCase.expIsExist, gotIsExist)
 				})
@@ -107,7 +108,7 @@ func TestUserSql_IsEmailExist(t *testing.T) {
 					insertUserTableRows(t, sqlDB, testCase.tableRows)
 
 					userRepo := db.NewUserSQL(sqlDB)
-					gotIsExist, err := userRepo.IsEmailExist(testCase.email)
+					gotIsExist, err := userRepo.IsEmailExist(context.Background(), testCase.email)
 					mdtest.Equal(t, nil, err)
 					mdtest.Equal(t, testCase.expIsExist, gotIsExist)
 				})
@@ -190,7 +191,7 @@ func TestUserSql_GetUserByID(t *testing.T) {
 					insertUserTableRows(t, sqlDB, testCase.tableRows)
 
 					userRepo := db.NewUserSQL(sqlDB)
-					gotUser, err := userRepo.GetUserByID(testCase.id)
+					gotUser, err := userRepo.GetUserByID(context.Background(), testCase.id)
 					if testCase.hasErr {
 						mdtest.NotEqual(t, nil, err)
 						return
@@ -277,7 +278,7 @@ func TestUserSql_GetUserByEmail(t *testing.T) {
 					insertUserTableRows(t, sqlDB, testCase.tableRows)
 
 					userRepo := db.NewUserSQL(sqlDB)
-					gotUser, err := userRepo.GetUserByEmail(testCase.email)
+					gotUser, err := userRepo.GetUserByEmail(context.Background(), testCase.email)
 					if testCase.hasErr {
 						mdtest.NotEqual(t, nil, err)
 						return
@@ -329,7 +330,7 @@ func TestUserSql_CreateUser(t *testing.T) {
 
 					userRepo := db.NewUserSQL(sqlDB)
 
-					err := userRepo.CreateUser(testCase.user)
+					err := userRepo.CreateUser(context.Background(), testCase.user)
 					if testCase.hasErr {
 						mdtest.NotEqual(t, nil, err)
 						return
@@ -375,7 +376,7 @@ func TestUserSQL_UpdateUserID(t *testing.T) {
 
 					userRepo := db.NewUserSQL(sqlDB)
 
-					err := userRepo.UpdateUserID(testCase.email, "alpha")
+					err := userRepo.UpdateUserID(context.Background(), testCase.email, "alpha")
 					if testCase.hasErr {
 						mdtest.NotEqual(t, nil, err)
 						return
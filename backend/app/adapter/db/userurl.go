@@ -1,8 +1,10 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 
 	"github.com/short-d/short/app/adapter/db/table"
 	"github.com/short-d/short/app/entity"
@@ -19,47 +21,304 @@ type UserURLRelationSQL struct {
 
 // CreateRelation establishes bi-directional relationship between a user and a
 // url in user_url_relation table.
-func (u UserURLRelationSQL) CreateRelation(user entity.User, url entity.URL) error {
+func (u UserURLRelationSQL) CreateRelation(ctx context.Context, user entity.User, url entity.URL) error {
 	statement := fmt.Sprintf(`
-INSERT INTO "%s" ("%s","%s")
-VALUES ($1,$2)
+INSERT INTO "%s" ("%s","%s","%s")
+VALUES ($1,$2,$3)
 `,
 		table.UserURLRelation.TableName,
 		table.UserURLRelation.ColumnUserEmail,
+		table.UserURLRelation.ColumnURLDomain,
 		table.UserURLRelation.ColumnURLAlias,
 	)
 
-	_, err := u.db.Exec(statement, user.Email, url.Alias)
+	_, err := u.db.ExecContext(ctx, statement, user.Email, url.Domain, url.Alias)
 	return err
 }
 
-// FindAliasesByUser fetches the aliases of all the URLs created by the given user.
-// TODO(issue#260): allow API client to filter urls based on visibility.
-func (u UserURLRelationSQL) FindAliasesByUser(user entity.User) ([]string, error) {
-	statement := fmt.Sprintf(`SELECT "%s" FROM "%s" WHERE "%s"=$1;`,
+// FindKeysByUser fetches the keys of all the URLs created by the given user.
+// Only the URLs with matching visibility are included when isPublic is
+// provided.
+func (u UserURLRelationSQL) FindKeysByUser(ctx context.Context, user entity.User, isPublic *bool) ([]repository.URLKey, error) {
+	statement := fmt.Sprintf(`
+SELECT "r"."%s","r"."%s"
+FROM "%s" "r"
+LEFT JOIN "%s" "p" ON "p"."%s"="r"."%s" AND "p"."%s"="r"."%s"
+WHERE "r"."%s"=$1%s;`,
+		table.UserURLRelation.ColumnURLDomain,
 		table.UserURLRelation.ColumnURLAlias,
 		table.UserURLRelation.TableName,
+		table.PublicURL.TableName,
+		table.PublicURL.ColumnDomain,
+		table.UserURLRelation.ColumnURLDomain,
+		table.PublicURL.ColumnAlias,
+		table.UserURLRelation.ColumnURLAlias,
 		table.UserURLRelation.ColumnUserEmail,
+		visibilityCondition(isPublic),
 	)
 
-	var aliases []string
-	rows, err := u.db.Query(statement, user.Email)
+	var keys []repository.URLKey
+	rows, err := u.db.QueryContext(ctx, statement, user.Email)
 	defer rows.Close()
 	if err != nil {
-		return aliases, nil
+		return keys, nil
 	}
 
 	for rows.Next() {
-		var alias string
-		err = rows.Scan(&alias)
+		var key repository.URLKey
+		err = rows.Scan(&key.Domain, &key.Alias)
 		if err != nil {
-			return aliases, err
+			return keys, err
 		}
 
-		aliases = append(aliases, alias)
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// FindURLsByUser fetches at most limit URLs created by the given user which
+// match the query, joining user_url_relation with url table.
+func (u UserURLRelationSQL) FindURLsByUser(
+	ctx context.Context,
+	user entity.User,
+	query repository.URLQuery,
+	limit int,
+) ([]entity.URL, error) {
+	params := sqlParams{}
+	conditions := []string{
+		fmt.Sprintf(`"r"."%s"=%s`, table.UserURLRelation.ColumnUserEmail, params.add(user.Email)),
+	}
+
+	if query.Search != "" {
+		pattern := params.add("%" + escapeLikePattern(query.Search) + "%")
+		conditions = append(conditions, fmt.Sprintf(
+			`("u"."%s" ILIKE %s OR "u"."%s" ILIKE %s)`,
+			table.URL.ColumnAlias,
+			pattern,
+			table.URL.ColumnOriginalURL,
+			pattern,
+		))
+	}
+
+	sortColumn := urlSortColumn(query.SortBy)
+	if query.After != nil {
+		conditions = append(conditions, afterCursorCondition(sortColumn, query, &params))
+	}
+
+	direction := "ASC"
+	if query.Descending {
+		direction = "DESC"
+	}
+	orderBy := fmt.Sprintf(
+		`"u"."%s" %s,"u"."%s" %s`,
+		table.URL.ColumnAlias,
+		direction,
+		table.URL.ColumnDomain,
+		direction,
+	)
+	if sortColumn != table.URL.ColumnAlias {
+		orderBy = fmt.Sprintf(`"u"."%s" %s NULLS LAST,%s`, sortColumn, direction, orderBy)
+	}
+
+	statement := fmt.Sprintf(`
+SELECT "u"."%s","u"."%s","u"."%s","u"."%s","u"."%s","u"."%s","u"."%s","u"."%s","u"."%s","u"."%s","u"."%s","u"."%s","u"."%s","u"."%s","u"."%s"
+FROM "%s" "r"
+JOIN "%s" "u" ON "u"."%s"="r"."%s" AND "u"."%s"="r"."%s"
+LEFT JOIN "%s" "p" ON "p"."%s"="r"."%s" AND "p"."%s"="r"."%s"
+WHERE %s%s
+ORDER BY %s
+LIMIT %s;`,
+		table.URL.ColumnDomain,
+		table.URL.ColumnAlias,
+		table.URL.ColumnOriginalURL,
+		table.URL.ColumnExpireAt,
+		table.URL.ColumnCreatedAt,
+		table.URL.ColumnUpdatedAt,
+		table.URL.ColumnPasswordHash,
+		table.URL.ColumnMaxClicks,
+		table.URL.ColumnClickCount,
+		table.URL.ColumnActivateAt,
+		table.URL.ColumnForwardQuery,
+		table.URL.ColumnForwardPath,
+		table.URL.ColumnUTMParams,
+		table.URL.ColumnTitle,
+		table.URL.ColumnRedirectStatus,
+		table.UserURLRelation.TableName,
+		table.URL.TableName,
+		table.URL.ColumnDomain,
+		table.UserURLRelation.ColumnURLDomain,
+		table.URL.ColumnAlias,
+		table.UserURLRelation.ColumnURLAlias,
+		table.PublicURL.TableName,
+		table.PublicURL.ColumnDomain,
+		table.UserURLRelation.ColumnURLDomain,
+		table.PublicURL.ColumnAlias,
+		table.UserURLRelation.ColumnURLAlias,
+		strings.Join(conditions, " AND "),
+		visibilityCondition(query.IsPublic),
+		orderBy,
+		params.add(limit),
+	)
+
+	rows, err := u.db.QueryContext(ctx, statement, params.values...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	urls := []entity.URL{}
+	for rows.Next() {
+		url := entity.URL{}
+		var utmParams *string
+		err = rows.Scan(
+			&url.Domain,
+			&url.Alias,
+			&url.OriginalURL,
+			&url.ExpireAt,
+			&url.CreatedAt,
+			&url.UpdatedAt,
+			&url.PasswordHash,
+			&url.MaxClicks,
+			&url.ClickCount,
+			&url.ActivateAt,
+			&url.ForwardQuery,
+			&url.ForwardPath,
+			&utmParams,
+			&url.Title,
+			&url.RedirectStatus,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		url.CreatedAt = utc(url.CreatedAt)
+		url.UpdatedAt = utc(url.UpdatedAt)
+		url.ExpireAt = utc(url.ExpireAt)
+		url.ActivateAt = utc(url.ActivateAt)
+		url.UTM = decodeUTM(utmParams)
+		urls = append(urls, url)
+	}
+	return urls, rows.Err()
+}
+
+func urlSortColumn(sortBy repository.URLSortField) string {
+	switch sortBy {
+	case repository.URLSortByCreatedAt:
+		return table.URL.ColumnCreatedAt
+	case repository.URLSortByExpireAt:
+		return table.URL.ColumnExpireAt
+	default:
+		return table.URL.ColumnAlias
+	}
+}
+
+// afterCursorCondition selects the URLs listed after the cursor, assuming
+// URLs without a value for the sort column are listed last. URLs with the
+// same alias are ordered by domain.
+func afterCursorCondition(sortColumn string, query repository.URLQuery, params *sqlParams) string {
+	operator := ">"
+	if query.Descending {
+		operator = "<"
+	}
+	alias := params.add(query.After.Alias)
+	domain := params.add(query.After.Domain)
+	aliasCondition := fmt.Sprintf(
+		`("u"."%s","u"."%s")%s(%s,%s)`,
+		table.URL.ColumnAlias,
+		table.URL.ColumnDomain,
+		operator,
+		alias,
+		domain,
+	)
+
+	if sortColumn == table.URL.ColumnAlias {
+		return aliasCondition
+	}
+
+	if query.After.SortValue == nil {
+		return fmt.Sprintf(`("u"."%s" IS NULL AND %s)`, sortColumn, aliasCondition)
+	}
+
+	sortValue := params.add(*query.After.SortValue)
+	return fmt.Sprintf(
+		`("u"."%s"%s%s OR ("u"."%s"=%s AND %s) OR "u"."%s" IS NULL)`,
+		sortColumn,
+		operator,
+		sortValue,
+		sortColumn,
+		sortValue,
+		aliasCondition,
+		sortColumn,
+	)
+}
+
+func escapeLikePattern(pattern string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+	return replacer.Replace(pattern)
+}
+
+// sqlParams collects the values of positional parameters in a SQL statement.
+type sqlParams struct {
+	values []interface{}
+}
+
+// add appends a value and returns its positional placeholder.
+func (s *sqlParams) add(value interface{}) string {
+	s.values = append(s.values, value)
+	return fmt.Sprintf("$%d", len(s.values))
+}
+
+func visibilityCondition(isPublic *bool) string {
+	if isPublic == nil {
+		return ""
+	}
+	if *isPublic {
+		return fmt.Sprintf(` AND "p"."%s" IS NOT NULL`, table.PublicURL.ColumnAlias)
 	}
+	return fmt.Sprintf(` AND "p"."%s" IS NULL`, table.PublicURL.ColumnAlias)
+}
+
+// IsAliasOwner checks whether the given user created the URL with the given
+// alias.
+func (u UserURLRelationSQL) IsAliasOwner(ctx context.Context, user entity.User, domain string, alias string) (bool, error) {
+	query := fmt.Sprintf(`
+SELECT "%s"
+FROM "%s"
+WHERE "%s"=$1 AND "%s"=$2 AND "%s"=$3;`,
+		table.UserURLRelation.ColumnURLAlias,
+		table.UserURLRelation.TableName,
+		table.UserURLRelation.ColumnUserEmail,
+		table.UserURLRelation.ColumnURLDomain,
+		table.UserURLRelation.ColumnURLAlias,
+	)
+
+	err := u.db.QueryRowContext(ctx, query, user.Email, domain, alias).Scan(&alias)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// FindOwnerEmail fetches the email of the user who created the URL with the
+// given alias from user_url_relation table.
+func (u UserURLRelationSQL) FindOwnerEmail(ctx context.Context, domain string, alias string) (string, error) {
+	query := fmt.Sprintf(`
+SELECT "%s"
+FROM "%s"
+WHERE "%s"=$1 AND "%s"=$2;`,
+		table.UserURLRelation.ColumnUserEmail,
+		table.UserURLRelation.TableName,
+		table.UserURLRelation.ColumnURLDomain,
+		table.UserURLRelation.ColumnURLAlias,
+	)
 
-	return aliases, nil
+	var email string
+	err := u.db.QueryRowContext(ctx, query, domain, alias).Scan(&email)
+	return email, err
 }
 
 // NewUserURLRelationSQL creates UserURLRelationSQL
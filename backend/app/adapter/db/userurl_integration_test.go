@@ -3,6 +3,7 @@
 package db_test
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"testing"
@@ -11,6 +12,7 @@ import (
 	"github.com/short-d/short/app/adapter/db"
 	"github.com/short-d/short/app/adapter/db/table"
 	"github.com/short-d/short/app/entity"
+	"github.com/short-d/short/app/usecase/repository"
 )
 
 var insertUserURLRelationRowSQL = fmt.Sprintf(`
@@ -26,17 +28,21 @@ type userURLRelationTableRow struct {
 	userEmail string
 }
 
-func TestListURLSql_FindAliasesByUser(t *testing.T) {
+func TestListURLSql_FindKeysByUser(t *testing.T) {
 	now := mustParseTime(t, "2019-05-01T08:02:16Z")
+	isPublic := true
+	isPrivate := false
 
 	testCases := []struct {
 		name              string
 		userTableRows     []userTableRow
 		urlTableRows      []urlTableRow
 		relationTableRows []userURLRelationTableRow
+		publicAliases     []string
 		user              entity.User
+		isPublic          *bool
 		hasErr            bool
-		expectedAliases   []string
+		expectedKeys      []repository.URLKey
 	}{
 		{
 			name:              "no alias found",
@@ -50,8 +56,8 @@ func TestListURLSql_FindAliasesByUser(t *testing.T) {
 				CreatedAt:      &now,
 				UpdatedAt:      &now,
 			},
-			hasErr:          false,
-			expectedAliases: nil,
+			hasErr:       false,
+			expectedKeys: nil,
 		},
 		{
 			name: "aliases found",
@@ -75,10 +81,276 @@ func TestListURLSql_FindAliasesByUser(t *testing.T) {
 				UpdatedAt:      &now,
 			},
 			hasErr: false,
-			expectedAliases: []string{
-				"abcd-123-xyz",
+			expectedKeys: []repository.URLKey{
+				{Alias: "abcd-123-xyz"},
 			},
 		},
+		{
+			name: "public aliases found",
+			userTableRows: []userTableRow{
+				{email: "test@example.com"},
+			},
+			urlTableRows: []urlTableRow{
+				{alias: "abcd-123-xyz"},
+				{alias: "efgh-456-uvw"},
+			},
+			relationTableRows: []userURLRelationTableRow{
+				{
+					alias:     "abcd-123-xyz",
+					userEmail: "test@example.com",
+				},
+				{
+					alias:     "efgh-456-uvw",
+					userEmail: "test@example.com",
+				},
+			},
+			publicAliases: []string{"efgh-456-uvw"},
+			user: entity.User{
+				Email: "test@example.com",
+			},
+			isPublic: &isPublic,
+			hasErr:   false,
+			expectedKeys: []repository.URLKey{
+				{Alias: "efgh-456-uvw"},
+			},
+		},
+		{
+			name: "private aliases found",
+			userTableRows: []userTableRow{
+				{email: "test@example.com"},
+			},
+			urlTableRows: []urlTableRow{
+				{alias: "abcd-123-xyz"},
+				{alias: "efgh-456-uvw"},
+			},
+			relationTableRows: []userURLRelationTableRow{
+				{
+					alias:     "abcd-123-xyz",
+					userEmail: "test@example.com",
+				},
+				{
+					alias:     "efgh-456-uvw",
+					userEmail: "test@example.com",
+				},
+			},
+			publicAliases: []string{"efgh-456-uvw"},
+			user: entity.User{
+				Email: "test@example.com",
+			},
+			isPublic: &isPrivate,
+			hasErr:   false,
+			expectedKeys: []repository.URLKey{
+				{Alias: "abcd-123-xyz"},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mdtest.AccessTestDB(
+				dbConnector,
+				dbMigrationTool,
+				dbMigrationRoot,
+				dbConfig,
+				func(sqlDB *sql.DB) {
+					insertUserTableRows(t, sqlDB, testCase.userTableRows)
+					insertURLTableRows(t, sqlDB, testCase.urlTableRows)
+					insertUserURLRelationTableRows(t, sqlDB, testCase.relationTableRows)
+					insertPublicURLTableRows(t, sqlDB, testCase.publicAliases)
+
+					userURLRelationRepo := db.NewUserURLRelationSQL(sqlDB)
+					result, err := userURLRelationRepo.FindKeysByUser(context.Background(), testCase.user, testCase.isPublic)
+
+					if testCase.hasErr {
+						mdtest.NotEqual(t, nil, err)
+						return
+					}
+					mdtest.Equal(t, nil, err)
+					mdtest.Equal(t, testCase.expectedKeys, result)
+				})
+		})
+	}
+}
+
+func TestUserURLRelationSQL_FindURLsByUser(t *testing.T) {
+	twoDaysAgo := mustParseTime(t, "2019-05-01T08:02:16Z")
+	yesterday := mustParseTime(t, "2019-05-02T08:02:16Z")
+
+	urlTableRows := []urlTableRow{
+		{
+			alias:     "google",
+			longLink:  "https://www.google.com/",
+			createdAt: &twoDaysAgo,
+			expireAt:  &yesterday,
+		},
+		{
+			alias:     "short",
+			longLink:  "https://github.com/short-d/short/",
+			createdAt: &yesterday,
+		},
+		{
+			alias:    "mozilla",
+			longLink: "https://www.mozilla.org/",
+			expireAt: &twoDaysAgo,
+		},
+		{
+			alias:     "docs",
+			longLink:  "https://docs.google.com/",
+			createdAt: &yesterday,
+		},
+		{
+			alias:    "other",
+			longLink: "https://www.google.com/",
+		},
+	}
+	userTableRows := []userTableRow{
+		{email: "test@example.com"},
+		{email: "test2@example.com"},
+	}
+	relationTableRows := []userURLRelationTableRow{
+		{alias: "google", userEmail: "test@example.com"},
+		{alias: "short", userEmail: "test@example.com"},
+		{alias: "mozilla", userEmail: "test@example.com"},
+		{alias: "docs", userEmail: "test@example.com"},
+		{alias: "other", userEmail: "test2@example.com"},
+	}
+
+	testCases := []struct {
+		name            string
+		query           repository.URLQuery
+		limit           int
+		expectedAliases []string
+	}{
+		{
+			name:            "sort by alias",
+			query:           repository.URLQuery{SortBy: repository.URLSortByAlias},
+			limit:           3,
+			expectedAliases: []string{"docs", "google", "mozilla"},
+		},
+		{
+			name: "sort by alias descending after cursor",
+			query: repository.URLQuery{
+				SortBy:     repository.URLSortByAlias,
+				Descending: true,
+				After:      &repository.URLCursor{Alias: "mozilla"},
+			},
+			limit:           3,
+			expectedAliases: []string{"google", "docs"},
+		},
+		{
+			name: "sort by created time descending",
+			query: repository.URLQuery{
+				SortBy:     repository.URLSortByCreatedAt,
+				Descending: true,
+			},
+			limit:           4,
+			expectedAliases: []string{"short", "docs", "google", "mozilla"},
+		},
+		{
+			name: "sort by created time descending after cursor",
+			query: repository.URLQuery{
+				SortBy:     repository.URLSortByCreatedAt,
+				Descending: true,
+				After: &repository.URLCursor{
+					Alias:     "short",
+					SortValue: &yesterday,
+				},
+			},
+			limit:           2,
+			expectedAliases: []string{"docs", "google"},
+		},
+		{
+			name: "sort by expiration time after cursor without expiration",
+			query: repository.URLQuery{
+				SortBy: repository.URLSortByExpireAt,
+				After:  &repository.URLCursor{Alias: "docs"},
+			},
+			limit:           4,
+			expectedAliases: []string{"short"},
+		},
+		{
+			name: "search alias and long link",
+			query: repository.URLQuery{
+				SortBy: repository.URLSortByAlias,
+				Search: "GOOGLE",
+			},
+			limit:           4,
+			expectedAliases: []string{"docs", "google"},
+		},
+		{
+			name: "search with wildcard characters",
+			query: repository.URLQuery{
+				SortBy: repository.URLSortByAlias,
+				Search: "%",
+			},
+			limit:           4,
+			expectedAliases: []string{},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mdtest.AccessTestDB(
+				dbConnector,
+				dbMigrationTool,
+				dbMigrationRoot,
+				dbConfig,
+				func(sqlDB *sql.DB) {
+					insertUserTableRows(t, sqlDB, userTableRows)
+					insertURLTableRows(t, sqlDB, urlTableRows)
+					insertUserURLRelationTableRows(t, sqlDB, relationTableRows)
+
+					userURLRelationRepo := db.NewUserURLRelationSQL(sqlDB)
+					user := entity.User{Email: "test@example.com"}
+					urls, err := userURLRelationRepo.FindURLsByUser(context.Background(), user, testCase.query, testCase.limit)
+					mdtest.Equal(t, nil, err)
+
+					aliases := []string{}
+					for _, url := range urls {
+						aliases = append(aliases, url.Alias)
+					}
+					mdtest.Equal(t, testCase.expectedAliases, aliases)
+				})
+		})
+	}
+}
+
+func TestUserURLRelationSQL_FindOwnerEmail(t *testing.T) {
+	testCases := []struct {
+		name              string
+		userTableRows     []userTableRow
+		urlTableRows      []urlTableRow
+		relationTableRows []userURLRelationTableRow
+		alias             string
+		hasErr            bool
+		expectedEmail     string
+	}{
+		{
+			name:              "owner not found",
+			userTableRows:     []userTableRow{},
+			urlTableRows:      []urlTableRow{},
+			relationTableRows: []userURLRelationTableRow{},
+			alias:             "abcd-123-xyz",
+			hasErr:            true,
+		},
+		{
+			name: "owner found",
+			userTableRows: []userTableRow{
+				{email: "test@example.com"},
+			},
+			urlTableRows: []urlTableRow{
+				{alias: "abcd-123-xyz"},
+			},
+			relationTableRows: []userURLRelationTableRow{
+				{
+					alias:     "abcd-123-xyz",
+					userEmail: "test@example.com",
+				},
+			},
+			alias:         "abcd-123-xyz",
+			hasErr:        false,
+			expectedEmail: "test@example.com",
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -94,14 +366,14 @@ func TestListURLSql_FindAliasesByUser(t *testing.T) {
 					insertUserURLRelationTableRows(t, sqlDB, testCase.relationTableRows)
 
 					userURLRelationRepo := db.NewUserURLRelationSQL(sqlDB)
-					result, err := userURLRelationRepo.FindAliasesByUser(testCase.user)
+					email, err := userURLRelationRepo.FindOwnerEmail(context.Background(), "", testCase.alias)
 
 					if testCase.hasErr {
 						mdtest.NotEqual(t, nil, err)
 						return
 					}
 					mdtest.Equal(t, nil, err)
-					mdtest.Equal(t, testCase.expectedAliases, result)
+					mdtest.Equal(t, testCase.expectedEmail, email)
 				})
 		})
 	}
@@ -0,0 +1,50 @@
+package db
+
+import (
+	netURL "net/url"
+
+	"github.com/short-d/short/app/entity"
+)
+
+const (
+	utmSource   = "utm_source"
+	utmMedium   = "utm_medium"
+	utmCampaign = "utm_campaign"
+	utmTerm     = "utm_term"
+	utmContent  = "utm_content"
+)
+
+// encodeUTM serializes UTM parameters into a query string so that they fit
+// in a single column.
+func encodeUTM(utm *entity.UTM) *string {
+	if utm == nil {
+		return nil
+	}
+
+	params := netURL.Values{}
+	params.Set(utmSource, utm.Source)
+	params.Set(utmMedium, utm.Medium)
+	params.Set(utmCampaign, utm.Campaign)
+	params.Set(utmTerm, utm.Term)
+	params.Set(utmContent, utm.Content)
+	encoded := params.Encode()
+	return &encoded
+}
+
+func decodeUTM(encoded *string) *entity.UTM {
+	if encoded == nil {
+		return nil
+	}
+
+	params, err := netURL.ParseQuery(*encoded)
+	if err != nil {
+		return nil
+	}
+	return &entity.UTM{
+		Source:   params.Get(utmSource),
+		Medium:   params.Get(utmMedium),
+		Campaign: params.Get(utmCampaign),
+		Term:     params.Get(utmTerm),
+		Content:  params.Get(utmContent),
+	}
+}
@@ -0,0 +1,80 @@
+package domainverifier
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/short-d/short/app/adapter/redirect"
+	"github.com/short-d/short/app/usecase/service"
+)
+
+const maxFileSize = 1024
+
+var _ service.DomainVerifier = (*Verifier)(nil)
+
+// Verifier fetches the challenges of custom domains through DNS and HTTP. It
+// refuses to connect to private networks so that users can't probe internal
+// services through it.
+type Verifier struct {
+	resolver *net.Resolver
+	client   *http.Client
+}
+
+// LookupTXT resolves the TXT records of the given name. No record is returned
+// when the name doesn't exist.
+func (v Verifier) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	records, err := v.resolver.LookupTXT(ctx, name)
+	if dnsErr, ok := err.(*net.DNSError); ok && dnsErr.IsNotFound {
+		return nil, nil
+	}
+	return records, err
+}
+
+// FetchFile downloads the beginning of the file at the given link. An empty
+// string is returned when the server doesn't respond with 200 OK.
+func (v Verifier) FetchFile(ctx context.Context, link string) (string, error) {
+	req, err := http.NewRequest(http.MethodGet, link, nil)
+	if err != nil {
+		return "", err
+	}
+
+	res, err := v.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return "", nil
+	}
+
+	content, err := ioutil.ReadAll(io.LimitReader(res.Body, maxFileSize))
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+func newVerifier(timeout time.Duration, dialer *net.Dialer) Verifier {
+	transport := &http.Transport{
+		DialContext:         dialer.DialContext,
+		TLSHandshakeTimeout: timeout,
+	}
+	return Verifier{
+		resolver: net.DefaultResolver,
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   timeout,
+		},
+	}
+}
+
+// NewVerifier creates domain verifier which gives up each HTTP request after
+// the given timeout.
+func NewVerifier(timeout time.Duration) Verifier {
+	return newVerifier(timeout, redirect.NewPublicDialer(timeout))
+}
@@ -0,0 +1,87 @@
+// +build !integration all
+
+package domainverifier
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/mdtest"
+	"github.com/short-d/short/app/adapter/redirect"
+)
+
+func TestVerifier_FetchFile(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("token\n"))
+	})
+	mux.HandleFunc("/moved", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/token", http.StatusFound)
+	})
+	mux.HandleFunc("/large", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Repeat("a", maxFileSize+1)))
+	})
+
+	testCases := []struct {
+		name            string
+		path            string
+		dialer          *net.Dialer
+		expectedContent string
+		hasErr          bool
+	}{
+		{
+			name:            "file exists",
+			path:            "/token",
+			dialer:          &net.Dialer{},
+			expectedContent: "token\n",
+		},
+		{
+			name:            "file moved",
+			path:            "/moved",
+			dialer:          &net.Dialer{},
+			expectedContent: "token\n",
+		},
+		{
+			name:            "file not found",
+			path:            "/missing",
+			dialer:          &net.Dialer{},
+			expectedContent: "",
+		},
+		{
+			name:            "file too large",
+			path:            "/large",
+			dialer:          &net.Dialer{},
+			expectedContent: strings.Repeat("a", maxFileSize),
+		},
+		{
+			name:   "private network",
+			path:   "/token",
+			dialer: redirect.NewPublicDialer(time.Second),
+			hasErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			verifier := newVerifier(time.Second, testCase.dialer)
+			content, err := verifier.FetchFile(context.Background(), server.URL+testCase.path)
+			if testCase.hasErr {
+				mdtest.NotEqual(t, nil, err)
+				return
+			}
+			mdtest.Equal(t, nil, err)
+			mdtest.Equal(t, testCase.expectedContent, content)
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package geoip
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/short-d/short/app/usecase/service"
+)
+
+var _ service.GeoLocator = (*Locator)(nil)
+
+// ipRange represents a contiguous block of IP addresses located in the same
+// country. Addresses are stored in their 16-byte form so that IPv4 and IPv6
+// ranges sort together.
+type ipRange struct {
+	start   net.IP
+	end     net.IP
+	country string
+}
+
+// Locator finds countries of IP addresses from a local GeoIP database, kept
+// in memory as sorted IP ranges.
+type Locator struct {
+	ranges []ipRange
+}
+
+// GetCountry finds the ISO 3166-1 alpha-2 code of the country the IP address
+// is located in.
+func (l Locator) GetCountry(ipAddress string) (string, error) {
+	ip := net.ParseIP(ipAddress)
+	if ip == nil {
+		return "", fmt.Errorf("invalid IP address (ip=%s)", ipAddress)
+	}
+	ip = ip.To16()
+
+	idx := sort.Search(len(l.ranges), func(idx int) bool {
+		return bytes.Compare(l.ranges[idx].end, ip) >= 0
+	})
+	if idx == len(l.ranges) || bytes.Compare(l.ranges[idx].start, ip) > 0 {
+		return "", fmt.Errorf("country not found (ip=%s)", ipAddress)
+	}
+	return l.ranges[idx].country, nil
+}
+
+// ParseDatabase reads a GeoIP database in CSV format with one IP range per
+// record, such as "1.0.0.0,1.0.0.255,AU". Ranges must not overlap.
+func ParseDatabase(reader io.Reader) (Locator, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = 3
+	csvReader.ReuseRecord = true
+
+	var ranges []ipRange
+	for recordNum := 1; ; recordNum++ {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return Locator{}, err
+		}
+
+		start := net.ParseIP(strings.TrimSpace(record[0]))
+		end := net.ParseIP(strings.TrimSpace(record[1]))
+		if start == nil || end == nil {
+			return Locator{}, fmt.Errorf("invalid IP range (record=%d)", recordNum)
+		}
+
+		ranges = append(ranges, ipRange{
+			start:   start.To16(),
+			end:     end.To16(),
+			country: strings.ToUpper(strings.TrimSpace(record[2])),
+		})
+	}
+
+	sort.Slice(ranges, func(i, j int) bool {
+		return bytes.Compare(ranges[i].start, ranges[j].start) < 0
+	})
+	for idx, current := range ranges {
+		if bytes.Compare(current.start, current.end) > 0 {
+			return Locator{}, errors.New("IP range starts after it ends")
+		}
+		if idx > 0 && bytes.Compare(ranges[idx-1].end, current.start) >= 0 {
+			return Locator{}, errors.New("IP ranges overlap")
+		}
+	}
+	return Locator{ranges: ranges}, nil
+}
@@ -0,0 +1,127 @@
+// +build !integration all
+
+package geoip
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/short-d/app/mdtest"
+)
+
+func TestLocator_GetCountry(t *testing.T) {
+	t.Parallel()
+
+	database := `203.0.113.0,203.0.113.255,ca
+1.0.0.0,1.0.0.255,AU
+2001:db8::,2001:db8::ffff,FR
+`
+	locator, err := ParseDatabase(strings.NewReader(database))
+	mdtest.Equal(t, nil, err)
+
+	testCases := []struct {
+		name            string
+		ipAddress       string
+		expHasErr       bool
+		expectedCountry string
+	}{
+		{
+			name:            "first address in range",
+			ipAddress:       "1.0.0.0",
+			expHasErr:       false,
+			expectedCountry: "AU",
+		},
+		{
+			name:            "last address in range",
+			ipAddress:       "203.0.113.255",
+			expHasErr:       false,
+			expectedCountry: "CA",
+		},
+		{
+			name:            "ipv6 address",
+			ipAddress:       "2001:db8::1",
+			expHasErr:       false,
+			expectedCountry: "FR",
+		},
+		{
+			name:      "address between ranges",
+			ipAddress: "8.8.8.8",
+			expHasErr: true,
+		},
+		{
+			name:      "address after all ranges",
+			ipAddress: "2001:db9::1",
+			expHasErr: true,
+		},
+		{
+			name:      "invalid address",
+			ipAddress: "localhost",
+			expHasErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			country, err := locator.GetCountry(testCase.ipAddress)
+			if testCase.expHasErr {
+				mdtest.NotEqual(t, nil, err)
+				return
+			}
+			mdtest.Equal(t, nil, err)
+			mdtest.Equal(t, testCase.expectedCountry, country)
+		})
+	}
+}
+
+func TestParseDatabase(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name      string
+		database  string
+		expHasErr bool
+	}{
+		{
+			name:      "empty database",
+			database:  "",
+			expHasErr: false,
+		},
+		{
+			name:      "invalid address",
+			database:  "1.0.0.0,1.0.0.x,AU\n",
+			expHasErr: true,
+		},
+		{
+			name:      "missing country",
+			database:  "1.0.0.0,1.0.0.255\n",
+			expHasErr: true,
+		},
+		{
+			name:      "reversed range",
+			database:  "1.0.0.255,1.0.0.0,AU\n",
+			expHasErr: true,
+		},
+		{
+			name:      "overlapping ranges",
+			database:  "1.0.0.0,1.0.0.255,AU\n1.0.0.128,1.0.1.255,CN\n",
+			expHasErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := ParseDatabase(strings.NewReader(testCase.database))
+			if testCase.expHasErr {
+				mdtest.NotEqual(t, nil, err)
+				return
+			}
+			mdtest.Equal(t, nil, err)
+		})
+	}
+}
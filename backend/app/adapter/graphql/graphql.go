@@ -2,8 +2,12 @@ package graphql
 
 import (
 	"github.com/short-d/short/app/adapter/graphql/resolver"
+	"github.com/short-d/short/app/usecase/analytics"
+	"github.com/short-d/short/app/usecase/apikey"
 	"github.com/short-d/short/app/usecase/auth"
 	"github.com/short-d/short/app/usecase/changelog"
+	"github.com/short-d/short/app/usecase/customdomain"
+	"github.com/short-d/short/app/usecase/ratelimit"
 	"github.com/short-d/short/app/usecase/requester"
 	"github.com/short-d/short/app/usecase/url"
 
@@ -33,9 +37,17 @@ func NewShort(
 	tracer fw.Tracer,
 	urlRetriever url.Retriever,
 	urlCreator url.Creator,
+	urlUpdater url.Updater,
+	urlDeleter url.Deleter,
+	ruleEditor url.RuleEditor,
+	urlPreviewer url.Previewer,
 	changeLog changelog.ChangeLog,
 	requesterVerifier requester.Verifier,
 	authenticator auth.Authenticator,
+	analyticsRetriever analytics.Retriever,
+	apiKeyManager apikey.Manager,
+	domainManager customdomain.Manager,
+	rateLimiter ratelimit.Limiter,
 ) Short {
 	r := resolver.NewResolver(
 		logger,
@@ -43,8 +55,16 @@ func NewShort(
 		changeLog,
 		urlRetriever,
 		urlCreator,
+		urlUpdater,
+		urlDeleter,
+		ruleEditor,
+		urlPreviewer,
 		requesterVerifier,
 		authenticator,
+		analyticsRetriever,
+		apiKeyManager,
+		domainManager,
+		rateLimiter,
 	)
 	return Short{
 		resolver: &r,
@@ -8,9 +8,15 @@ import (
 
 	"github.com/short-d/app/mdtest"
 	"github.com/short-d/short/app/adapter/db"
+	"github.com/short-d/short/app/usecase/analytics"
+	"github.com/short-d/short/app/usecase/apikey"
 	"github.com/short-d/short/app/usecase/auth"
 	"github.com/short-d/short/app/usecase/changelog"
+	"github.com/short-d/short/app/usecase/customdomain"
 	"github.com/short-d/short/app/usecase/keygen"
+	"github.com/short-d/short/app/usecase/linksafety"
+	"github.com/short-d/short/app/usecase/password"
+	"github.com/short-d/short/app/usecase/ratelimit"
 	"github.com/short-d/short/app/usecase/requester"
 	"github.com/short-d/short/app/usecase/service"
 	"github.com/short-d/short/app/usecase/url"
@@ -25,20 +31,57 @@ func TestGraphQlAPI(t *testing.T) {
 
 	urlRepo := db.NewURLSql(sqlDB)
 	urlRelationRepo := db.NewUserURLRelationSQL(sqlDB)
-	retriever := url.NewRetrieverPersist(urlRepo, urlRelationRepo)
+	publicURLRepo := db.NewPublicURLSQL(sqlDB)
+	retriever := url.NewRetrieverPersist(urlRepo, urlRelationRepo, publicURLRepo)
 	keyFetcher := service.NewKeyFetcherFake([]service.Key{})
-	keyGen, err := keygen.NewKeyGenerator(2, &keyFetcher)
+	keyGen, err := keygen.NewKeyGenerator(2, 0, &keyFetcher, service.NewCounterFake(), mdtest.NewTimerFake(now))
 	mdtest.Equal(t, nil, err)
 	longLinkValidator := validator.NewLongLink()
 	customAliasValidator := validator.NewCustomAlias()
+	linkChecker := linksafety.NewChecker(
+		linksafety.Blocklist{},
+		nil,
+		service.NewRedirectTracerFake(nil),
+		0,
+		0,
+	)
+	timerFake := mdtest.NewTimerFake(now)
+	urlBatchRepo := db.NewURLBatchSQL(sqlDB)
+	domainRepo := db.NewDomainSQL(sqlDB)
 	creator := url.NewCreatorPersist(
 		urlRepo,
 		urlRelationRepo,
+		publicURLRepo,
+		urlBatchRepo,
+		domainRepo,
 		keyGen,
 		longLinkValidator,
 		customAliasValidator,
+		linkChecker,
+		password.NewHasher(),
+		timerFake,
+	)
+	updater := url.NewUpdaterPersist(
+		urlRepo,
+		urlRelationRepo,
+		publicURLRepo,
+		longLinkValidator,
+		linkChecker,
+		timerFake,
+	)
+	deleter := url.NewDeleterPersist(urlRepo, urlRelationRepo)
+	redirectRuleRepo := db.NewRedirectRuleSQL(sqlDB)
+	ruleEditor := url.NewRuleEditorPersist(
+		urlRepo,
+		urlRelationRepo,
+		redirectRuleRepo,
+		longLinkValidator,
+		linkChecker,
 	)
 
+	userRepo := db.NewUserSQL(sqlDB)
+	previewer := url.NewPreviewerPersist(retriever, urlRelationRepo, userRepo, timerFake)
+
 	s := service.NewReCaptchaFake(service.VerifyResponse{})
 	verifier := requester.NewVerifier(s)
 	authenticator := auth.NewAuthenticatorFake(time.Now(), time.Hour)
@@ -46,9 +89,32 @@ func TestGraphQlAPI(t *testing.T) {
 	logger := mdtest.NewLoggerFake(mdtest.FakeLoggerArgs{})
 	tracer := mdtest.NewTracerFake()
 
-	timerFake := mdtest.NewTimerFake(now)
 	changeLogRepo := db.NewChangeLogSQL(sqlDB)
 	changeLog := changelog.NewPersist(keyGen, timerFake, changeLogRepo)
-	graphqlAPI := NewShort(&logger, &tracer, retriever, creator, changeLog, verifier, authenticator)
+	clickRepo := db.NewClickSQL(sqlDB)
+	apiKeyRepo := db.NewAPIKeySQL(sqlDB)
+	apiKeyManager := apikey.NewManager(apiKeyRepo, timerFake)
+	tokenBucketRepo := db.NewTokenBucketSQL(sqlDB)
+	rateLimiter := ratelimit.NewLimiter(tokenBucketRepo, timerFake, nil)
+	analyticsRetriever := analytics.NewRetrieverPersist(clickRepo, urlRelationRepo)
+	domainVerifier := service.NewDomainVerifierFake(nil, nil)
+	domainManager := customdomain.NewManager(domainRepo, domainVerifier, timerFake, nil)
+	graphqlAPI := NewShort(
+		&logger,
+		&tracer,
+		retriever,
+		creator,
+		updater,
+		deleter,
+		ruleEditor,
+		previewer,
+		changeLog,
+		verifier,
+		authenticator,
+		analyticsRetriever,
+		apiKeyManager,
+		domainManager,
+		rateLimiter,
+	)
 	mdtest.Equal(t, true, mdtest.IsGraphQlAPIValid(graphqlAPI))
 }
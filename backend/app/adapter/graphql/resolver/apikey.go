@@ -0,0 +1,75 @@
+package resolver
+
+import (
+	"github.com/short-d/short/app/adapter/graphql/scalar"
+	"github.com/short-d/short/app/entity"
+)
+
+var apiKeyScopes = map[string]entity.APIKeyScope{
+	"READ_ONLY":    entity.APIKeyScopeReadOnly,
+	"CREATE_LINKS": entity.APIKeyScopeCreateLinks,
+	"MANAGE_LINKS": entity.APIKeyScopeManageLinks,
+}
+
+var gqlAPIKeyScopes = map[entity.APIKeyScope]string{
+	entity.APIKeyScopeReadOnly:    "READ_ONLY",
+	entity.APIKeyScopeCreateLinks: "CREATE_LINKS",
+	entity.APIKeyScopeManageLinks: "MANAGE_LINKS",
+}
+
+// APIKey retrieves requested fields of a personal API key.
+type APIKey struct {
+	apiKey entity.APIKey
+}
+
+// ID retrieves the ID of APIKey entity.
+func (a APIKey) ID() string {
+	return a.apiKey.ID
+}
+
+// Name retrieves the name of APIKey entity.
+func (a APIKey) Name() string {
+	return a.apiKey.Name
+}
+
+// Scopes retrieves the operations APIKey entity is allowed to perform.
+func (a APIKey) Scopes() []string {
+	scopes := make([]string, 0, len(a.apiKey.Scopes))
+	for _, scope := range a.apiKey.Scopes {
+		scopes = append(scopes, gqlAPIKeyScopes[scope])
+	}
+	return scopes
+}
+
+// CreatedAt retrieves the creation time of APIKey entity.
+func (a APIKey) CreatedAt() scalar.Time {
+	return scalar.Time{Time: a.apiKey.CreatedAt}
+}
+
+// CreatedAPIKey retrieves a newly created API key along with its secret.
+type CreatedAPIKey struct {
+	apiKey APIKey
+	key    string
+}
+
+// APIKey retrieves the attributes of the created API key.
+func (c CreatedAPIKey) APIKey() APIKey {
+	return c.apiKey
+}
+
+// Key retrieves the secret of the created API key. It can't be retrieved
+// again later.
+func (c CreatedAPIKey) Key() string {
+	return c.key
+}
+
+func newAPIKey(apiKey entity.APIKey) APIKey {
+	return APIKey{apiKey: apiKey}
+}
+
+func newCreatedAPIKey(apiKey entity.APIKey, key string) CreatedAPIKey {
+	return CreatedAPIKey{
+		apiKey: newAPIKey(apiKey),
+		key:    key,
+	}
+}
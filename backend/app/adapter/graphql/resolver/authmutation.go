@@ -1,28 +1,50 @@
 package resolver
 
 import (
+	"context"
 	"time"
 
 	"github.com/short-d/short/app/entity"
-	"github.com/short-d/short/app/usecase/auth"
+	"github.com/short-d/short/app/usecase/analytics"
+	"github.com/short-d/short/app/usecase/apikey"
 	"github.com/short-d/short/app/usecase/changelog"
+	"github.com/short-d/short/app/usecase/customdomain"
+	"github.com/short-d/short/app/usecase/linksafety"
+	"github.com/short-d/short/app/usecase/ratelimit"
+	"github.com/short-d/short/app/usecase/redirectrule"
 	"github.com/short-d/short/app/usecase/url"
 )
 
 // AuthMutation represents GraphQL mutation resolver that acts differently based
 // on the identify of the user
 type AuthMutation struct {
-	authToken     *string
-	authenticator auth.Authenticator
-	changeLog     changelog.ChangeLog
-	urlCreator    url.Creator
+	credential         credential
+	changeLog          changelog.ChangeLog
+	urlRetriever       url.Retriever
+	urlCreator         url.Creator
+	urlUpdater         url.Updater
+	urlDeleter         url.Deleter
+	ruleEditor         url.RuleEditor
+	analyticsRetriever analytics.Retriever
+	apiKeyManager      apikey.Manager
+	domainManager      customdomain.Manager
+	rateLimiter        ratelimit.Limiter
 }
 
 // URLInput represents possible URL attributes
 type URLInput struct {
-	OriginalURL string
-	CustomAlias *string
-	ExpireAt    *time.Time
+	OriginalURL    string
+	Domain         *string
+	CustomAlias    *string
+	ExpireAt       *time.Time
+	ActivateAt     *time.Time
+	Password       *string
+	MaxClicks      *int32
+	ForwardQuery   *bool
+	ForwardPath    *bool
+	UTM            *UTMInput
+	Title          *string
+	RedirectStatus *int32
 }
 
 // CreateURLArgs represents the possible parameters for CreateURL endpoint
@@ -31,6 +53,46 @@ type CreateURLArgs struct {
 	IsPublic bool
 }
 
+// CreateURLsArgs represents the possible parameters for CreateURLs endpoint
+type CreateURLsArgs struct {
+	URLs []URLInput
+}
+
+// URLPatch represents the URL attributes that can be changed
+type URLPatch struct {
+	OriginalURL    *string
+	ExpireAt       *time.Time
+	ActivateAt     *time.Time
+	IsPublic       *bool
+	ForwardQuery   *bool
+	ForwardPath    *bool
+	UTM            *UTMInput
+	Title          *string
+	RedirectStatus *int32
+	Clear          *[]string
+}
+
+var urlPatchFields = map[string]url.Field{
+	"EXPIRE_AT":       url.FieldExpireAt,
+	"ACTIVATE_AT":     url.FieldActivateAt,
+	"UTM":             url.FieldUTM,
+	"TITLE":           url.FieldTitle,
+	"REDIRECT_STATUS": url.FieldRedirectStatus,
+}
+
+// UpdateURLArgs represents the possible parameters for UpdateURL endpoint
+type UpdateURLArgs struct {
+	Alias  string
+	Domain *string
+	Patch  URLPatch
+}
+
+// DeleteURLArgs represents the possible parameters for DeleteURL endpoint
+type DeleteURLArgs struct {
+	Alias  string
+	Domain *string
+}
+
 // CreateChangeArgs represents the possible parameters for CreateChange endpoint
 type CreateChangeArgs struct {
 	Change ChangeInput
@@ -42,54 +104,463 @@ type ChangeInput struct {
 	SummaryMarkdown *string
 }
 
+// CreateAPIKeyArgs represents the possible parameters for CreateAPIKey endpoint
+type CreateAPIKeyArgs struct {
+	Name   string
+	Scopes []string
+}
+
+// RevokeAPIKeyArgs represents the possible parameters for RevokeAPIKey endpoint
+type RevokeAPIKeyArgs struct {
+	ID string
+}
+
+// RedirectRuleInput represents possible redirect rule attributes
+type RedirectRuleInput struct {
+	Condition string
+	Values    *[]string
+	Weight    *int32
+	LongLink  string
+}
+
+// UpdateRedirectRulesArgs represents the possible parameters for
+// UpdateRedirectRules endpoint
+type UpdateRedirectRulesArgs struct {
+	Alias  string
+	Domain *string
+	Rules  []RedirectRuleInput
+}
+
+// RegisterDomainArgs represents the possible parameters for RegisterDomain
+// endpoint
+type RegisterDomainArgs struct {
+	Hostname string
+}
+
+// VerifyDomainArgs represents the possible parameters for VerifyDomain
+// endpoint
+type VerifyDomainArgs struct {
+	Hostname  string
+	Challenge string
+}
+
 // CreateURL creates mapping between an alias and a long link for a given user
-func (a AuthMutation) CreateURL(args *CreateURLArgs) (*URL, error) {
-	user, err := viewer(a.authToken, a.authenticator)
+func (a AuthMutation) CreateURL(ctx context.Context, args *CreateURLArgs) (*URL, error) {
+	user, subject, err := a.credential.identify(ctx, entity.APIKeyScopeCreateLinks)
 	if err != nil {
-		return nil, ErrInvalidAuthToken{}
+		return nil, newViewerError(err)
+	}
+
+	err = a.rateLimiter.Allow(ctx, ratelimit.ActionCreateURL, subject)
+	if err != nil {
+		return nil, newRateLimitError(err)
 	}
 
 	customAlias := args.URL.CustomAlias
 	u := entity.URL{
-		OriginalURL: args.URL.OriginalURL,
-		ExpireAt:    args.URL.ExpireAt,
+		Domain:         newHostname(args.URL.Domain),
+		OriginalURL:    args.URL.OriginalURL,
+		ExpireAt:       args.URL.ExpireAt,
+		ActivateAt:     args.URL.ActivateAt,
+		MaxClicks:      newMaxClicks(args.URL.MaxClicks),
+		ForwardQuery:   isTrue(args.URL.ForwardQuery),
+		ForwardPath:    isTrue(args.URL.ForwardPath),
+		UTM:            newUTM(args.URL.UTM),
+		Title:          args.URL.Title,
+		RedirectStatus: newRedirectStatus(args.URL.RedirectStatus),
 	}
 
 	isPublic := args.IsPublic
 
-	newURL, err := a.urlCreator.CreateURL(u, customAlias, user, isPublic)
+	createdURL, err := a.urlCreator.CreateURL(ctx, u, customAlias, args.URL.Password, user, isPublic)
 	if err == nil {
-		return &URL{url: newURL}, nil
+		gqlURL := newURL(createdURL, a.credential, a.urlRetriever, a.analyticsRetriever)
+		return &gqlURL, nil
 	}
 
 	switch err.(type) {
 	case url.ErrAliasExist:
+		if customAlias == nil {
+			return nil, ErrUnknown{}
+		}
 		return nil, ErrURLAliasExist(*customAlias)
+	case url.ErrDomainNotVerified:
+		return nil, ErrDomainNotVerified(u.Domain)
 	case url.ErrInvalidLongLink:
 		return nil, ErrInvalidLongLink(u.OriginalURL)
+	case linksafety.ErrUnsafeLink:
+		return nil, ErrUnsafeLongLink(err.(linksafety.ErrUnsafeLink))
 	case url.ErrInvalidCustomAlias:
 		return nil, ErrInvalidCustomAlias(*customAlias)
+	case url.ErrInvalidPassword:
+		return nil, ErrInvalidPassword{}
+	case url.ErrInvalidMaxClicks:
+		return nil, ErrInvalidMaxClicks(err.(url.ErrInvalidMaxClicks))
+	case url.ErrInvalidActivateAt:
+		return nil, ErrInvalidActivateAt{}
+	case url.ErrInvalidRedirectStatus:
+		return nil, ErrInvalidRedirectStatus(err.(url.ErrInvalidRedirectStatus))
+	case url.ErrInvalidTitle:
+		return nil, ErrInvalidTitle{}
 	default:
 		return nil, ErrUnknown{}
 	}
 }
 
+// CreateURLs creates mappings between aliases and long links for a given user
+// at once, reporting the outcome of each mapping. Each mapping is counted
+// against the rate limit of creating URLs, and the ones past the limit are
+// reported as rate limited.
+func (a AuthMutation) CreateURLs(ctx context.Context, args *CreateURLsArgs) ([]CreateURLResult, error) {
+	user, subject, err := a.credential.identify(ctx, entity.APIKeyScopeCreateLinks)
+	if err != nil {
+		return nil, newViewerError(err)
+	}
+
+	err = url.CheckBulkSize(len(args.URLs))
+	if err != nil {
+		return nil, ErrTooManyURLs(err.Error())
+	}
+
+	bulkURLs := make([]url.BulkURL, 0, len(args.URLs))
+	for _, input := range args.URLs {
+		bulkURLs = append(bulkURLs, url.BulkURL{
+			URL: entity.URL{
+				Domain:         newHostname(input.Domain),
+				OriginalURL:    input.OriginalURL,
+				ExpireAt:       input.ExpireAt,
+				ActivateAt:     input.ActivateAt,
+				MaxClicks:      newMaxClicks(input.MaxClicks),
+				ForwardQuery:   isTrue(input.ForwardQuery),
+				ForwardPath:    isTrue(input.ForwardPath),
+				UTM:            newUTM(input.UTM),
+				Title:          input.Title,
+				RedirectStatus: newRedirectStatus(input.RedirectStatus),
+			},
+			CustomAlias: input.CustomAlias,
+			Password:    input.Password,
+		})
+	}
+
+	allowedCount, err := a.rateLimiter.AllowN(ctx, ratelimit.ActionCreateURL, subject, len(bulkURLs))
+	if err != nil {
+		return nil, ErrUnknown{}
+	}
+
+	results, err := a.urlCreator.CreateURLs(ctx, bulkURLs[:allowedCount], user)
+	if err != nil {
+		switch err.(type) {
+		case url.ErrTooManyURLs:
+			return nil, ErrTooManyURLs(err.Error())
+		default:
+			return nil, ErrUnknown{}
+		}
+	}
+	for range bulkURLs[allowedCount:] {
+		results = append(results, url.BulkResult{
+			Err: ratelimit.ErrRateLimited(ratelimit.ActionCreateURL),
+		})
+	}
+
+	gqlResults := make([]CreateURLResult, 0, len(results))
+	for _, result := range results {
+		gqlResults = append(gqlResults, newCreateURLResult(
+			result,
+			a.credential,
+			a.urlRetriever,
+			a.analyticsRetriever,
+		))
+	}
+	return gqlResults, nil
+}
+
+// UpdateURL changes the attributes of a short link created by the user
+func (a AuthMutation) UpdateURL(ctx context.Context, args *UpdateURLArgs) (*URL, error) {
+	user, err := a.credential.viewer(ctx, entity.APIKeyScopeManageLinks)
+	if err != nil {
+		return nil, newViewerError(err)
+	}
+
+	patch := url.Patch{
+		OriginalURL:    args.Patch.OriginalURL,
+		ExpireAt:       args.Patch.ExpireAt,
+		ActivateAt:     args.Patch.ActivateAt,
+		IsPublic:       args.Patch.IsPublic,
+		ForwardQuery:   args.Patch.ForwardQuery,
+		ForwardPath:    args.Patch.ForwardPath,
+		UTM:            newUTM(args.Patch.UTM),
+		Title:          args.Patch.Title,
+		RedirectStatus: newRedirectStatus(args.Patch.RedirectStatus),
+		Clear:          newClearedFields(args.Patch.Clear),
+	}
+
+	updatedURL, err := a.urlUpdater.UpdateURL(ctx, newHostname(args.Domain), args.Alias, patch, user)
+	if err == nil {
+		gqlURL := newURL(updatedURL, a.credential, a.urlRetriever, a.analyticsRetriever)
+		return &gqlURL, nil
+	}
+
+	switch err.(type) {
+	case url.ErrURLNotFound:
+		return nil, ErrURLNotFound(args.Alias)
+	case url.ErrNotURLOwner:
+		return nil, ErrNotURLOwner{}
+	case url.ErrInvalidLongLink:
+		return nil, ErrInvalidLongLink(*args.Patch.OriginalURL)
+	case linksafety.ErrUnsafeLink:
+		return nil, ErrUnsafeLongLink(err.(linksafety.ErrUnsafeLink))
+	case url.ErrInvalidActivateAt:
+		return nil, ErrInvalidActivateAt{}
+	case url.ErrInvalidRedirectStatus:
+		return nil, ErrInvalidRedirectStatus(err.(url.ErrInvalidRedirectStatus))
+	case url.ErrInvalidTitle:
+		return nil, ErrInvalidTitle{}
+	case url.ErrConflictingPatch:
+		return nil, ErrConflictingPatch(err.(url.ErrConflictingPatch))
+	default:
+		return nil, ErrUnknown{}
+	}
+}
+
+func newClearedFields(fields *[]string) []url.Field {
+	if fields == nil {
+		return nil
+	}
+
+	clearedFields := make([]url.Field, 0, len(*fields))
+	for _, field := range *fields {
+		clearedFields = append(clearedFields, urlPatchFields[field])
+	}
+	return clearedFields
+}
+
+// DeleteURL removes a short link created by the user
+func (a AuthMutation) DeleteURL(ctx context.Context, args *DeleteURLArgs) (bool, error) {
+	user, err := a.credential.viewer(ctx, entity.APIKeyScopeManageLinks)
+	if err != nil {
+		return false, newViewerError(err)
+	}
+
+	err = a.urlDeleter.DeleteURL(ctx, newHostname(args.Domain), args.Alias, user)
+	if err == nil {
+		return true, nil
+	}
+
+	switch err.(type) {
+	case url.ErrURLNotFound:
+		return false, ErrURLNotFound(args.Alias)
+	case url.ErrNotURLOwner:
+		return false, ErrNotURLOwner{}
+	default:
+		return false, ErrUnknown{}
+	}
+}
+
 // CreateChange creates a Change in the change log
-func (a AuthMutation) CreateChange(args *CreateChangeArgs) (Change, error) {
-	change, err := a.changeLog.CreateChange(args.Change.Title, args.Change.SummaryMarkdown)
+func (a AuthMutation) CreateChange(ctx context.Context, args *CreateChangeArgs) (Change, error) {
+	change, err := a.changeLog.CreateChange(ctx, args.Change.Title, args.Change.SummaryMarkdown)
 	return newChange(change), err
 }
 
+// CreateAPIKey issues a personal API key for the user. The secret of the key
+// is only returned once. API keys can't be used to create API keys.
+func (a AuthMutation) CreateAPIKey(ctx context.Context, args *CreateAPIKeyArgs) (*CreatedAPIKey, error) {
+	user, err := a.credential.signedInViewer()
+	if err != nil {
+		return nil, ErrInvalidAuthToken{}
+	}
+
+	scopes := make([]entity.APIKeyScope, 0, len(args.Scopes))
+	for _, scope := range args.Scopes {
+		scopes = append(scopes, apiKeyScopes[scope])
+	}
+
+	apiKey, key, err := a.apiKeyManager.CreateKey(ctx, user, args.Name, scopes)
+	if err == nil {
+		createdAPIKey := newCreatedAPIKey(apiKey, key)
+		return &createdAPIKey, nil
+	}
+
+	switch err.(type) {
+	case apikey.ErrInvalidName:
+		return nil, ErrInvalidAPIKeyName(args.Name)
+	default:
+		return nil, ErrUnknown{}
+	}
+}
+
+// RevokeAPIKey permanently disables a personal API key created by the user.
+// API keys can't be used to revoke API keys.
+func (a AuthMutation) RevokeAPIKey(ctx context.Context, args *RevokeAPIKeyArgs) (bool, error) {
+	user, err := a.credential.signedInViewer()
+	if err != nil {
+		return false, ErrInvalidAuthToken{}
+	}
+
+	err = a.apiKeyManager.RevokeKey(ctx, args.ID, user)
+	if err == nil {
+		return true, nil
+	}
+
+	switch err.(type) {
+	case apikey.ErrAPIKeyNotFound:
+		return false, ErrAPIKeyNotFound(args.ID)
+	default:
+		return false, ErrUnknown{}
+	}
+}
+
+// UpdateRedirectRules replaces the redirect rules of a short link created by
+// the user. Rules are evaluated in the given order.
+func (a AuthMutation) UpdateRedirectRules(ctx context.Context, args *UpdateRedirectRulesArgs) ([]RedirectRule, error) {
+	user, err := a.credential.viewer(ctx, entity.APIKeyScopeManageLinks)
+	if err != nil {
+		return nil, newViewerError(err)
+	}
+
+	rules := make([]entity.RedirectRule, 0, len(args.Rules))
+	for _, input := range args.Rules {
+		rule := entity.RedirectRule{
+			Condition: redirectConditions[input.Condition],
+			Values:    []string{},
+			LongLink:  input.LongLink,
+		}
+		if input.Values != nil {
+			rule.Values = *input.Values
+		}
+		if input.Weight != nil {
+			rule.Weight = int(*input.Weight)
+		}
+		rules = append(rules, rule)
+	}
+
+	updatedRules, err := a.ruleEditor.UpdateRules(ctx, newHostname(args.Domain), args.Alias, rules, user)
+	if err == nil {
+		return newRedirectRules(updatedRules), nil
+	}
+
+	switch err.(type) {
+	case url.ErrURLNotFound:
+		return nil, ErrURLNotFound(args.Alias)
+	case url.ErrNotURLOwner:
+		return nil, ErrNotURLOwner{}
+	case redirectrule.ErrInvalidRule:
+		return nil, ErrInvalidRedirectRule(err.(redirectrule.ErrInvalidRule))
+	case url.ErrInvalidLongLink:
+		return nil, ErrInvalidLongLink(err.(url.ErrInvalidLongLink))
+	case linksafety.ErrUnsafeLink:
+		return nil, ErrUnsafeLongLink(err.(linksafety.ErrUnsafeLink))
+	default:
+		return nil, ErrUnknown{}
+	}
+}
+
+// RegisterDomain records the intent of the user to serve short links under a
+// custom domain, returning the token to prove the ownership of the domain
+// with. API keys can't be used to register domains.
+func (a AuthMutation) RegisterDomain(ctx context.Context, args *RegisterDomainArgs) (*Domain, error) {
+	user, err := a.credential.signedInViewer()
+	if err != nil {
+		return nil, ErrInvalidAuthToken{}
+	}
+
+	domain, err := a.domainManager.RegisterDomain(ctx, user, args.Hostname)
+	if err == nil {
+		gqlDomain := newDomain(domain)
+		return &gqlDomain, nil
+	}
+
+	switch err.(type) {
+	case customdomain.ErrInvalidHostname:
+		return nil, ErrInvalidHostname(args.Hostname)
+	case customdomain.ErrDomainExist:
+		return nil, ErrDomainAlreadyExist(args.Hostname)
+	default:
+		return nil, ErrUnknown{}
+	}
+}
+
+// VerifyDomain proves the ownership of a custom domain registered by the user
+// through the challenge, so that short links can be created under it. API
+// keys can't be used to verify domains.
+func (a AuthMutation) VerifyDomain(ctx context.Context, args *VerifyDomainArgs) (*Domain, error) {
+	user, err := a.credential.signedInViewer()
+	if err != nil {
+		return nil, ErrInvalidAuthToken{}
+	}
+
+	challenge := domainChallenges[args.Challenge]
+	domain, err := a.domainManager.VerifyDomain(ctx, user, args.Hostname, challenge)
+	if err == nil {
+		gqlDomain := newDomain(domain)
+		return &gqlDomain, nil
+	}
+
+	switch err.(type) {
+	case customdomain.ErrDomainNotFound:
+		return nil, ErrDomainNotFound(args.Hostname)
+	case customdomain.ErrChallengeFailed:
+		return nil, ErrChallengeFailed(args.Hostname)
+	default:
+		return nil, ErrUnknown{}
+	}
+}
+
+func newMaxClicks(maxClicks *int32) *int {
+	if maxClicks == nil {
+		return nil
+	}
+
+	clicks := int(*maxClicks)
+	return &clicks
+}
+
+func newRedirectStatus(redirectStatus *int32) *int {
+	if redirectStatus == nil {
+		return nil
+	}
+
+	status := int(*redirectStatus)
+	return &status
+}
+
+func isTrue(value *bool) bool {
+	return value != nil && *value
+}
+
+func newRateLimitError(err error) error {
+	switch err.(type) {
+	case ratelimit.ErrRateLimited:
+		return ErrRateLimited(err.(ratelimit.ErrRateLimited))
+	default:
+		return ErrUnknown{}
+	}
+}
+
 func newAuthMutation(
-	authToken *string,
-	authenticator auth.Authenticator,
+	credential credential,
 	changeLog changelog.ChangeLog,
+	urlRetriever url.Retriever,
 	urlCreator url.Creator,
+	urlUpdater url.Updater,
+	urlDeleter url.Deleter,
+	ruleEditor url.RuleEditor,
+	analyticsRetriever analytics.Retriever,
+	apiKeyManager apikey.Manager,
+	domainManager customdomain.Manager,
+	rateLimiter ratelimit.Limiter,
 ) AuthMutation {
 	return AuthMutation{
-		authToken:     authToken,
-		authenticator: authenticator,
-		changeLog:     changeLog,
-		urlCreator:    urlCreator,
+		credential:         credential,
+		changeLog:          changeLog,
+		urlRetriever:       urlRetriever,
+		urlCreator:         urlCreator,
+		urlUpdater:         urlUpdater,
+		urlDeleter:         urlDeleter,
+		ruleEditor:         ruleEditor,
+		analyticsRetriever: analyticsRetriever,
+		apiKeyManager:      apiKeyManager,
+		domainManager:      domainManager,
+		rateLimiter:        rateLimiter,
 	}
 }
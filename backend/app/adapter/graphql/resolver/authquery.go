@@ -1,80 +1,273 @@
 package resolver
 
 import (
+	"context"
 	"time"
 
 	"github.com/short-d/short/app/adapter/graphql/scalar"
-	"github.com/short-d/short/app/usecase/auth"
+	"github.com/short-d/short/app/entity"
+	"github.com/short-d/short/app/usecase/analytics"
+	"github.com/short-d/short/app/usecase/apikey"
 	"github.com/short-d/short/app/usecase/changelog"
+	"github.com/short-d/short/app/usecase/customdomain"
+	"github.com/short-d/short/app/usecase/repository"
 	"github.com/short-d/short/app/usecase/url"
 )
 
+var urlSortFields = map[string]repository.URLSortField{
+	"CREATED_AT": repository.URLSortByCreatedAt,
+	"EXPIRE_AT":  repository.URLSortByExpireAt,
+	"ALIAS":      repository.URLSortByAlias,
+}
+
 // AuthQuery represents GraphQL query resolver that acts differently based
 // on the identify of the user
 type AuthQuery struct {
-	authToken     *string
-	authenticator auth.Authenticator
-	changeLog     changelog.ChangeLog
-	urlRetriever  url.Retriever
+	credential         credential
+	changeLog          changelog.ChangeLog
+	urlRetriever       url.Retriever
+	ruleEditor         url.RuleEditor
+	urlPreviewer       url.Previewer
+	analyticsRetriever analytics.Retriever
+	apiKeyManager      apikey.Manager
+	domainManager      customdomain.Manager
 }
 
 // URLArgs represents possible parameters for URL endpoint
 type URLArgs struct {
 	Alias       string
+	Domain      *string
 	ExpireAfter *scalar.Time
 }
 
-// URL retrieves an URL persistent storage given alias and expiration time.
-func (v AuthQuery) URL(args *URLArgs) (*URL, error) {
+// URL retrieves an URL persistent storage given alias, domain and expiration
+// time. Short links under the default host are retrieved when domain is
+// omitted.
+func (v AuthQuery) URL(ctx context.Context, args *URLArgs) (*URL, error) {
 	var expireAt *time.Time
 	if args.ExpireAfter != nil {
 		expireAt = &args.ExpireAfter.Time
 	}
 
-	u, err := v.urlRetriever.GetURL(args.Alias, expireAt)
+	u, err := v.urlRetriever.GetURL(ctx, newHostname(args.Domain), args.Alias, expireAt)
 	if err != nil {
 		return nil, err
 	}
-	return &URL{url: u}, nil
+	gqlURL := newURL(u, v.credential, v.urlRetriever, v.analyticsRetriever)
+	return &gqlURL, nil
 }
 
 // ChangeLog retrieves full ChangeLog from persistent storage
-func (v AuthQuery) ChangeLog() (ChangeLog, error) {
-	changeLog, err := v.changeLog.GetChangeLog()
-	lastViewedAt := v.changeLog.GetLastViewedAt()
+func (v AuthQuery) ChangeLog(ctx context.Context) (ChangeLog, error) {
+	changeLog, err := v.changeLog.GetChangeLog(ctx)
+	lastViewedAt := v.changeLog.GetLastViewedAt(ctx)
 	return newChangeLog(changeLog, lastViewedAt), err
 }
 
-// URLs retrieves urls created by a given user from persistent storage
-func (v AuthQuery) URLs() ([]URL, error) {
-	user, err := viewer(v.authToken, v.authenticator)
+// URLOrder represents the order of URLs in a list
+type URLOrder struct {
+	Field     string
+	Direction string
+}
+
+// URLsArgs represents possible parameters for URLs endpoint
+type URLsArgs struct {
+	First    int32
+	After    *string
+	OrderBy  *URLOrder
+	Search   *string
+	IsPublic *bool
+}
+
+// URLs retrieves a page of urls created by a given user from persistent
+// storage. The urls are sorted by creation time in descending order unless
+// specified otherwise.
+func (v AuthQuery) URLs(ctx context.Context, args *URLsArgs) (*URLConnection, error) {
+	user, err := v.credential.viewer(ctx, entity.APIKeyScopeReadOnly)
+	if err != nil {
+		return nil, newViewerError(err)
+	}
+
+	query := repository.URLQuery{
+		SortBy:     repository.URLSortByCreatedAt,
+		Descending: true,
+		IsPublic:   args.IsPublic,
+	}
+	if args.OrderBy != nil {
+		query.SortBy = urlSortFields[args.OrderBy.Field]
+		query.Descending = args.OrderBy.Direction == "DESC"
+	}
+	if args.Search != nil {
+		query.Search = *args.Search
+	}
+
+	query.After, err = decodeSortCursor(args.After, query.SortBy)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := v.urlRetriever.GetURLPageByUser(ctx, user, query, int(args.First))
+	if err == nil {
+		connection := newURLConnection(
+			page,
+			newSortCursorEncoder(query.SortBy),
+			v.credential,
+			v.urlRetriever,
+			v.analyticsRetriever,
+		)
+		return &connection, nil
+	}
+
+	switch err.(type) {
+	case url.ErrInvalidPageSize:
+		return nil, ErrInvalidLimit(err.Error())
+	default:
+		return nil, ErrUnknown{}
+	}
+}
+
+// PublicURLsArgs represents possible parameters for PublicURLs endpoint
+type PublicURLsArgs struct {
+	First int32
+	After *string
+}
+
+// PublicURLs retrieves a page of public urls from persistent storage
+func (v AuthQuery) PublicURLs(ctx context.Context, args *PublicURLsArgs) (*URLConnection, error) {
+	after, err := decodeKeyCursor(args.After)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := v.urlRetriever.GetPublicURLs(ctx, int(args.First), after)
+	if err == nil {
+		connection := newURLConnection(
+			page,
+			encodeKeyCursor,
+			v.credential,
+			v.urlRetriever,
+			v.analyticsRetriever,
+		)
+		return &connection, nil
+	}
+
+	switch err.(type) {
+	case url.ErrInvalidPageSize:
+		return nil, ErrInvalidLimit(err.Error())
+	default:
+		return nil, ErrUnknown{}
+	}
+}
+
+// APIKeys retrieves the personal API keys created by the user. The keys are
+// only visible to users signed in with auth token.
+func (v AuthQuery) APIKeys(ctx context.Context) ([]APIKey, error) {
+	user, err := v.credential.signedInViewer()
 	if err != nil {
-		return []URL{}, err
+		return nil, ErrInvalidAuthToken{}
 	}
 
-	urls, err := v.urlRetriever.GetURLsByUser(user)
+	apiKeys, err := v.apiKeyManager.ListKeys(ctx, user)
 	if err != nil {
-		return []URL{}, err
+		return nil, ErrUnknown{}
 	}
 
-	var gqlURLs []URL
-	for _, v := range urls {
-		gqlURLs = append(gqlURLs, newURL(v))
+	gqlAPIKeys := make([]APIKey, 0, len(apiKeys))
+	for _, apiKey := range apiKeys {
+		gqlAPIKeys = append(gqlAPIKeys, newAPIKey(apiKey))
+	}
+	return gqlAPIKeys, nil
+}
+
+// Domains retrieves the custom domains registered by the user.
+func (v AuthQuery) Domains(ctx context.Context) ([]Domain, error) {
+	user, err := v.credential.viewer(ctx, entity.APIKeyScopeReadOnly)
+	if err != nil {
+		return nil, newViewerError(err)
 	}
 
-	return gqlURLs, nil
+	domains, err := v.domainManager.ListDomains(ctx, user)
+	if err != nil {
+		return nil, ErrUnknown{}
+	}
+
+	gqlDomains := make([]Domain, 0, len(domains))
+	for _, domain := range domains {
+		gqlDomains = append(gqlDomains, newDomain(domain))
+	}
+	return gqlDomains, nil
+}
+
+// RedirectRulesArgs represents possible parameters for RedirectRules endpoint
+type RedirectRulesArgs struct {
+	Alias  string
+	Domain *string
+}
+
+// RedirectRules retrieves the redirect rules of a short link created by the
+// user in evaluation order.
+func (v AuthQuery) RedirectRules(ctx context.Context, args *RedirectRulesArgs) ([]RedirectRule, error) {
+	user, err := v.credential.viewer(ctx, entity.APIKeyScopeReadOnly)
+	if err != nil {
+		return nil, newViewerError(err)
+	}
+
+	rules, err := v.ruleEditor.GetRules(ctx, newHostname(args.Domain), args.Alias, user)
+	if err == nil {
+		return newRedirectRules(rules), nil
+	}
+
+	switch err.(type) {
+	case url.ErrURLNotFound:
+		return nil, ErrURLNotFound(args.Alias)
+	case url.ErrNotURLOwner:
+		return nil, ErrNotURLOwner{}
+	default:
+		return nil, ErrUnknown{}
+	}
+}
+
+// URLPreviewArgs represents possible parameters for URLPreview endpoint
+type URLPreviewArgs struct {
+	Alias  string
+	Domain *string
+}
+
+// URLPreview describes a short link to visitors before they follow it. Short
+// links which can't be visited right now are reported as not found.
+func (v AuthQuery) URLPreview(ctx context.Context, args *URLPreviewArgs) (*URLPreview, error) {
+	preview, err := v.urlPreviewer.PreviewURL(ctx, newHostname(args.Domain), args.Alias)
+	if err == nil {
+		gqlPreview := newURLPreview(preview)
+		return &gqlPreview, nil
+	}
+
+	switch err.(type) {
+	case url.ErrURLNotFound, url.ErrURLExpired, url.ErrURLExhausted, url.ErrURLNotActive:
+		return nil, ErrURLNotFound(args.Alias)
+	default:
+		return nil, ErrUnknown{}
+	}
 }
 
 func newAuthQuery(
-	authToken *string,
-	authenticator auth.Authenticator,
+	credential credential,
 	changeLog changelog.ChangeLog,
 	urlRetriever url.Retriever,
+	ruleEditor url.RuleEditor,
+	urlPreviewer url.Previewer,
+	analyticsRetriever analytics.Retriever,
+	apiKeyManager apikey.Manager,
+	domainManager customdomain.Manager,
 ) AuthQuery {
 	return AuthQuery{
-		authToken:     authToken,
-		authenticator: authenticator,
-		changeLog:     changeLog,
-		urlRetriever:  urlRetriever,
+		credential:         credential,
+		changeLog:          changeLog,
+		urlRetriever:       urlRetriever,
+		ruleEditor:         ruleEditor,
+		urlPreviewer:       urlPreviewer,
+		analyticsRetriever: analyticsRetriever,
+		apiKeyManager:      apiKeyManager,
+		domainManager:      domainManager,
 	}
 }
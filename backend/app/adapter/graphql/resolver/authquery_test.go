@@ -3,6 +3,8 @@
 package resolver
 
 import (
+	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -12,11 +14,16 @@ import (
 	"github.com/short-d/short/app/adapter/db"
 	"github.com/short-d/short/app/adapter/graphql/scalar"
 	"github.com/short-d/short/app/entity"
+	"github.com/short-d/short/app/usecase/analytics"
+	"github.com/short-d/short/app/usecase/apikey"
 	"github.com/short-d/short/app/usecase/changelog"
+	"github.com/short-d/short/app/usecase/customdomain"
 	"github.com/short-d/short/app/usecase/keygen"
+	"github.com/short-d/short/app/usecase/linksafety"
 	"github.com/short-d/short/app/usecase/repository"
 	"github.com/short-d/short/app/usecase/service"
 	"github.com/short-d/short/app/usecase/url"
+	"github.com/short-d/short/app/usecase/validator"
 )
 
 type urlMap = map[string]entity.URL
@@ -91,11 +98,27 @@ func TestAuthQuery_URL(t *testing.T) {
 			defer sqlDB.Close()
 
 			fakeURLRepo := repository.NewURLFake(testCase.urls)
-			fakeUserURLRelationRepo := repository.NewUserURLRepoFake(nil, nil)
-			retrieverFake := url.NewRetrieverPersist(&fakeURLRepo, &fakeUserURLRelationRepo)
+			fakePublicURLRepo := repository.NewPublicURLFake(nil)
+			fakeUserURLRelationRepo := repository.NewUserURLRepoFake(nil, nil, &fakePublicURLRepo)
+			retrieverFake := url.NewRetrieverPersist(&fakeURLRepo, &fakeUserURLRelationRepo, &fakePublicURLRepo)
+			fakeRedirectRuleRepo := repository.NewRedirectRuleFake(nil)
+			ruleEditor := url.NewRuleEditorPersist(
+				&fakeURLRepo,
+				&fakeUserURLRelationRepo,
+				&fakeRedirectRuleRepo,
+				validator.NewLongLink(),
+				linksafety.Checker{},
+			)
+			fakeUserRepo := repository.NewUserFake(nil)
+			urlPreviewer := url.NewPreviewerPersist(
+				retrieverFake,
+				&fakeUserURLRelationRepo,
+				&fakeUserRepo,
+				mdtest.NewTimerFake(time.Now()),
+			)
 
 			keyFetcher := service.NewKeyFetcherFake([]service.Key{})
-			keyGen, err := keygen.NewKeyGenerator(2, &keyFetcher)
+			keyGen, err := keygen.NewKeyGenerator(2, 0, &keyFetcher, service.NewCounterFake(), mdtest.NewTimerFake(time.Now()))
 			mdtest.Equal(t, nil, err)
 
 			timerFake := mdtest.NewTimerFake(now)
@@ -109,20 +132,84 @@ func TestAuthQuery_URL(t *testing.T) {
 			authToken, err := authenticator.GenerateToken(testCase.user)
 			mdtest.Equal(t, nil, err)
 
-			query := newAuthQuery(&authToken, authenticator, changeLog, retrieverFake)
+			fakeClickRepo := repository.NewClickFake(nil)
+			analyticsRetriever := analytics.NewRetrieverPersist(&fakeClickRepo, &fakeUserURLRelationRepo)
+
+			apiKeyRepo := repository.NewAPIKeyFake()
+			apiKeyManager := apikey.NewManager(&apiKeyRepo, timer)
+
+			domainRepo := repository.NewDomainFake(nil)
+			domainManager := customdomain.NewManager(&domainRepo, service.NewDomainVerifierFake(nil, nil), timer, nil)
+
+			query := newAuthQuery(
+				newCredential(&authToken, nil, authenticator, apiKeyManager),
+				changeLog,
+				retrieverFake,
+				ruleEditor,
+				urlPreviewer,
+				analyticsRetriever,
+				apiKeyManager,
+				domainManager,
+			)
 
 			urlArgs := &URLArgs{
 				Alias:       testCase.alias,
 				ExpireAfter: testCase.expireAfter,
 			}
 
-			u, err := query.URL(urlArgs)
+			u, err := query.URL(context.Background(), urlArgs)
 
 			if testCase.hasErr {
 				mdtest.NotEqual(t, nil, err)
 				return
 			}
-			mdtest.Equal(t, testCase.expectedURL, u)
+			mdtest.Equal(t, testCase.expectedURL.url, u.url)
+		})
+	}
+}
+
+// previewerFake fails every preview with err.
+type previewerFake struct {
+	err error
+}
+
+func (p previewerFake) PreviewURL(ctx context.Context, domain string, alias string) (url.Preview, error) {
+	return url.Preview{}, p.err
+}
+
+func TestAuthQuery_URLPreview(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name        string
+		previewErr  error
+		expectedErr error
+	}{
+		{
+			name:        "url not found",
+			previewErr:  url.ErrURLNotFound("google"),
+			expectedErr: ErrURLNotFound("google"),
+		},
+		{
+			name:        "url expired",
+			previewErr:  url.ErrURLExpired("google"),
+			expectedErr: ErrURLNotFound("google"),
+		},
+		{
+			name:        "storage failure",
+			previewErr:  url.ErrStorageFailure{Err: errors.New("connection refused")},
+			expectedErr: ErrUnknown{},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			query := AuthQuery{urlPreviewer: previewerFake{err: testCase.previewErr}}
+			_, err := query.URLPreview(context.Background(), &URLPreviewArgs{Alias: "google"})
+			mdtest.Equal(t, testCase.expectedErr, err)
 		})
 	}
 }
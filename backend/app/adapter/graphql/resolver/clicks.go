@@ -0,0 +1,65 @@
+package resolver
+
+import (
+	"github.com/short-d/short/app/adapter/graphql/scalar"
+	"github.com/short-d/short/app/entity"
+)
+
+// DailyClicks retrieves the number of clicks on a URL during a day.
+type DailyClicks struct {
+	dailyClicks entity.DailyClicks
+}
+
+// Day retrieves the beginning of the day in UTC.
+func (d DailyClicks) Day() scalar.Time {
+	return scalar.Time{Time: d.dailyClicks.Day}
+}
+
+// Count retrieves the number of clicks during the day.
+func (d DailyClicks) Count() int32 {
+	return int32(d.dailyClicks.Count)
+}
+
+func newDailyClicks(dailyClicks entity.DailyClicks) DailyClicks {
+	return DailyClicks{dailyClicks: dailyClicks}
+}
+
+// ReferrerClicks retrieves the number of clicks on a URL coming from a
+// referrer.
+type ReferrerClicks struct {
+	referrerClicks entity.ReferrerClicks
+}
+
+// Referrer retrieves the referring page. It is empty for direct visits.
+func (r ReferrerClicks) Referrer() string {
+	return r.referrerClicks.Referrer
+}
+
+// Count retrieves the number of clicks coming from the referrer.
+func (r ReferrerClicks) Count() int32 {
+	return int32(r.referrerClicks.Count)
+}
+
+func newReferrerClicks(referrerClicks entity.ReferrerClicks) ReferrerClicks {
+	return ReferrerClicks{referrerClicks: referrerClicks}
+}
+
+// DeviceClicks retrieves the number of clicks on a URL made from a class of
+// devices.
+type DeviceClicks struct {
+	deviceClicks entity.DeviceClicks
+}
+
+// Device retrieves the class of devices, such as desktop or mobile.
+func (d DeviceClicks) Device() string {
+	return d.deviceClicks.Device
+}
+
+// Count retrieves the number of clicks made from the class of devices.
+func (d DeviceClicks) Count() int32 {
+	return int32(d.deviceClicks.Count)
+}
+
+func newDeviceClicks(deviceClicks entity.DeviceClicks) DeviceClicks {
+	return DeviceClicks{deviceClicks: deviceClicks}
+}
@@ -0,0 +1,177 @@
+package resolver
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"github.com/short-d/short/app/entity"
+	"github.com/short-d/short/app/usecase/analytics"
+	"github.com/short-d/short/app/usecase/repository"
+	"github.com/short-d/short/app/usecase/url"
+)
+
+// URLConnection retrieves a page of URLs following the Relay cursor
+// connection specification.
+type URLConnection struct {
+	edges    []URLEdge
+	pageInfo PageInfo
+}
+
+// Edges retrieves the URLs in the page together with their cursors.
+func (u URLConnection) Edges() []URLEdge {
+	return u.edges
+}
+
+// PageInfo retrieves the pagination status of the page.
+func (u URLConnection) PageInfo() PageInfo {
+	return u.pageInfo
+}
+
+// URLEdge retrieves a URL together with its position in the list.
+type URLEdge struct {
+	node   URL
+	cursor string
+}
+
+// Node retrieves the URL.
+func (u URLEdge) Node() URL {
+	return u.node
+}
+
+// Cursor retrieves the opaque position of the URL in the list.
+func (u URLEdge) Cursor() string {
+	return u.cursor
+}
+
+// PageInfo retrieves the pagination status of a page.
+type PageInfo struct {
+	hasNextPage bool
+	endCursor   *string
+}
+
+// HasNextPage checks whether more items exist after the page.
+func (p PageInfo) HasNextPage() bool {
+	return p.hasNextPage
+}
+
+// EndCursor retrieves the cursor of the last item in the page.
+func (p PageInfo) EndCursor() *string {
+	return p.endCursor
+}
+
+func newURLConnection(
+	page url.Page,
+	encodeCursor func(u entity.URL) string,
+	credential credential,
+	urlRetriever url.Retriever,
+	analyticsRetriever analytics.Retriever,
+) URLConnection {
+	edges := make([]URLEdge, 0, len(page.URLs))
+	for _, u := range page.URLs {
+		edges = append(edges, URLEdge{
+			node:   newURL(u, credential, urlRetriever, analyticsRetriever),
+			cursor: encodeCursor(u),
+		})
+	}
+
+	pageInfo := PageInfo{hasNextPage: page.HasNextPage}
+	if len(edges) > 0 {
+		pageInfo.endCursor = &edges[len(edges)-1].cursor
+	}
+	return URLConnection{
+		edges:    edges,
+		pageInfo: pageInfo,
+	}
+}
+
+// keyCursor represents the position of a URL in a list sorted by alias, then
+// domain.
+type keyCursor struct {
+	Domain string `json:"domain,omitempty"`
+	Alias  string `json:"alias"`
+}
+
+func encodeKeyCursor(u entity.URL) string {
+	buf, err := json.Marshal(keyCursor{
+		Domain: u.Domain,
+		Alias:  u.Alias,
+	})
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
+
+func decodeKeyCursor(cursor *string) (*repository.URLKey, error) {
+	if cursor == nil {
+		return nil, nil
+	}
+
+	buf, err := base64.RawURLEncoding.DecodeString(*cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor(*cursor)
+	}
+
+	var decoded keyCursor
+	err = json.Unmarshal(buf, &decoded)
+	if err != nil {
+		return nil, ErrInvalidCursor(*cursor)
+	}
+	return &repository.URLKey{
+		Domain: decoded.Domain,
+		Alias:  decoded.Alias,
+	}, nil
+}
+
+// sortCursor represents the position of a URL in a list sorted by a field.
+type sortCursor struct {
+	SortBy    repository.URLSortField `json:"sortBy"`
+	Domain    string                  `json:"domain,omitempty"`
+	Alias     string                  `json:"alias"`
+	SortValue *time.Time              `json:"sortValue,omitempty"`
+}
+
+func newSortCursorEncoder(sortBy repository.URLSortField) func(u entity.URL) string {
+	return func(u entity.URL) string {
+		cursor := sortCursor{
+			SortBy: sortBy,
+			Domain: u.Domain,
+			Alias:  u.Alias,
+		}
+		switch sortBy {
+		case repository.URLSortByCreatedAt:
+			cursor.SortValue = u.CreatedAt
+		case repository.URLSortByExpireAt:
+			cursor.SortValue = u.ExpireAt
+		}
+
+		buf, err := json.Marshal(cursor)
+		if err != nil {
+			return ""
+		}
+		return base64.RawURLEncoding.EncodeToString(buf)
+	}
+}
+
+func decodeSortCursor(cursor *string, sortBy repository.URLSortField) (*repository.URLCursor, error) {
+	if cursor == nil {
+		return nil, nil
+	}
+
+	buf, err := base64.RawURLEncoding.DecodeString(*cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor(*cursor)
+	}
+
+	var decoded sortCursor
+	err = json.Unmarshal(buf, &decoded)
+	if err != nil || decoded.SortBy != sortBy {
+		return nil, ErrInvalidCursor(*cursor)
+	}
+	return &repository.URLCursor{
+		Domain:    decoded.Domain,
+		Alias:     decoded.Alias,
+		SortValue: decoded.SortValue,
+	}, nil
+}
@@ -0,0 +1,66 @@
+package resolver
+
+import (
+	"github.com/short-d/short/app/usecase/analytics"
+	"github.com/short-d/short/app/usecase/linksafety"
+	"github.com/short-d/short/app/usecase/ratelimit"
+	"github.com/short-d/short/app/usecase/url"
+)
+
+// CreateURLResult represents the outcome of creating a URL in bulk
+type CreateURLResult struct {
+	url       *URL
+	errorCode *string
+}
+
+// URL retrieves the created URL, or nil when the creation failed.
+func (c CreateURLResult) URL() *URL {
+	return c.url
+}
+
+// ErrorCode retrieves the reason of the failure, or nil when the URL is
+// created.
+func (c CreateURLResult) ErrorCode() *string {
+	return c.errorCode
+}
+
+func newCreateURLResult(
+	result url.BulkResult,
+	credential credential,
+	urlRetriever url.Retriever,
+	analyticsRetriever analytics.Retriever,
+) CreateURLResult {
+	if result.Err == nil {
+		gqlURL := newURL(result.URL, credential, urlRetriever, analyticsRetriever)
+		return CreateURLResult{url: &gqlURL}
+	}
+
+	var errCode string
+	switch result.Err.(type) {
+	case url.ErrAliasExist:
+		errCode = ErrCodeAliasAlreadyExist
+	case url.ErrInvalidLongLink:
+		errCode = ErrCodeInvalidLongLink
+	case linksafety.ErrUnsafeLink:
+		errCode = ErrCodeUnsafeLongLink
+	case url.ErrInvalidCustomAlias:
+		errCode = ErrCodeInvalidCustomAlias
+	case url.ErrInvalidPassword:
+		errCode = ErrCodeInvalidPassword
+	case url.ErrInvalidMaxClicks:
+		errCode = ErrCodeInvalidMaxClicks
+	case url.ErrInvalidActivateAt:
+		errCode = ErrCodeInvalidActivateAt
+	case url.ErrInvalidRedirectStatus:
+		errCode = ErrCodeInvalidRedirectStatus
+	case url.ErrInvalidTitle:
+		errCode = ErrCodeInvalidTitle
+	case url.ErrDomainNotVerified:
+		errCode = ErrCodeDomainNotVerified
+	case ratelimit.ErrRateLimited:
+		errCode = ErrCodeRateLimited
+	default:
+		errCode = string(ErrCodeUnknown)
+	}
+	return CreateURLResult{errorCode: &errCode}
+}
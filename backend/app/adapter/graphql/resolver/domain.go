@@ -0,0 +1,81 @@
+package resolver
+
+import (
+	"fmt"
+
+	"github.com/short-d/short/app/adapter/graphql/scalar"
+	"github.com/short-d/short/app/entity"
+	"github.com/short-d/short/app/usecase/customdomain"
+)
+
+var domainChallenges = map[string]customdomain.Challenge{
+	"DNS":  customdomain.ChallengeDNS,
+	"HTTP": customdomain.ChallengeHTTP,
+}
+
+// Domain retrieves requested fields of a custom domain.
+type Domain struct {
+	domain entity.Domain
+}
+
+// Hostname retrieves the host which short links are served under.
+func (d Domain) Hostname() string {
+	return d.domain.Hostname
+}
+
+// VerificationToken retrieves the token proving the ownership of the domain.
+func (d Domain) VerificationToken() string {
+	return d.domain.VerificationToken
+}
+
+// DNSRecordName retrieves the name of the TXT record to publish for the DNS
+// challenge.
+func (d Domain) DNSRecordName() string {
+	return customdomain.ChallengeRecordPrefix + d.domain.Hostname
+}
+
+// DNSRecordValue retrieves the value of the TXT record to publish for the DNS
+// challenge.
+func (d Domain) DNSRecordValue() string {
+	return customdomain.ChallengeRecordValuePrefix + d.domain.VerificationToken
+}
+
+// HTTPFileURL retrieves the link of the file to serve the verification token
+// from for the HTTP challenge.
+func (d Domain) HTTPFileURL() string {
+	return fmt.Sprintf("http://%s%s", d.domain.Hostname, customdomain.ChallengeFilePath)
+}
+
+// IsVerified checks whether the ownership of the domain has been proven.
+func (d Domain) IsVerified() bool {
+	return d.domain.IsVerified()
+}
+
+// VerifiedAt retrieves the time when the ownership of the domain was proven.
+func (d Domain) VerifiedAt() *scalar.Time {
+	if d.domain.VerifiedAt == nil {
+		return nil
+	}
+	return &scalar.Time{Time: *d.domain.VerifiedAt}
+}
+
+// CreatedAt retrieves the registration time of the domain.
+func (d Domain) CreatedAt() *scalar.Time {
+	if d.domain.CreatedAt == nil {
+		return nil
+	}
+	return &scalar.Time{Time: *d.domain.CreatedAt}
+}
+
+func newDomain(domain entity.Domain) Domain {
+	return Domain{domain: domain}
+}
+
+// newHostname converts the optional domain argument of short links to the
+// hostname they are stored under, which is empty for the default host.
+func newHostname(domain *string) string {
+	if domain == nil {
+		return ""
+	}
+	return customdomain.NormalizeHostname(*domain)
+}
@@ -1,16 +1,42 @@
 package resolver
 
+import "github.com/short-d/short/app/usecase/redirectrule"
+
 // ErrCode represents an unique string identifying a GraphQL api error.
 type ErrCode string
 
 // The constants enumerate all supported error codes.
 const (
-	ErrCodeUnknown            ErrCode = "unknown"
-	ErrCodeAliasAlreadyExist          = "aliasAlreadyExist"
-	ErrCodeRequesterNotHuman          = "requesterNotHuman"
-	ErrCodeInvalidLongLink            = "invalidLongLink"
-	ErrCodeInvalidCustomAlias         = "invalidCustomAlias"
-	ErrCodeInvalidAuthToken           = "invalidAuthToken"
+	ErrCodeUnknown               ErrCode = "unknown"
+	ErrCodeAliasAlreadyExist             = "aliasAlreadyExist"
+	ErrCodeRequesterNotHuman             = "requesterNotHuman"
+	ErrCodeInvalidLongLink               = "invalidLongLink"
+	ErrCodeInvalidCustomAlias            = "invalidCustomAlias"
+	ErrCodeInvalidAuthToken              = "invalidAuthToken"
+	ErrCodeNotURLOwner                   = "notURLOwner"
+	ErrCodeInvalidTimeRange              = "invalidTimeRange"
+	ErrCodeInvalidLimit                  = "invalidLimit"
+	ErrCodeURLNotFound                   = "urlNotFound"
+	ErrCodeInvalidCursor                 = "invalidCursor"
+	ErrCodeTooManyURLs                   = "tooManyURLs"
+	ErrCodeInvalidAPIKey                 = "invalidAPIKey"
+	ErrCodeInsufficientScope             = "insufficientScope"
+	ErrCodeInvalidAPIKeyName             = "invalidAPIKeyName"
+	ErrCodeAPIKeyNotFound                = "apiKeyNotFound"
+	ErrCodeRateLimited                   = "rateLimited"
+	ErrCodeUnsafeLongLink                = "unsafeLongLink"
+	ErrCodeInvalidPassword               = "invalidPassword"
+	ErrCodeInvalidMaxClicks              = "invalidMaxClicks"
+	ErrCodeInvalidActivateAt             = "invalidActivateAt"
+	ErrCodeInvalidRedirectRule           = "invalidRedirectRule"
+	ErrCodeInvalidRedirectStatus         = "invalidRedirectStatus"
+	ErrCodeInvalidTitle                  = "invalidTitle"
+	ErrCodeInvalidHostname               = "invalidHostname"
+	ErrCodeDomainAlreadyExist            = "domainAlreadyExist"
+	ErrCodeDomainNotFound                = "domainNotFound"
+	ErrCodeDomainNotVerified             = "domainNotVerified"
+	ErrCodeChallengeFailed               = "challengeFailed"
+	ErrCodeConflictingPatch              = "conflictingPatch"
 )
 
 // GraphQlError represents a GraphAPI error.
@@ -131,3 +157,471 @@ func (e ErrInvalidAuthToken) Extensions() map[string]interface{} {
 func (e ErrInvalidAuthToken) Error() string {
 	return "auth token is invalid"
 }
+
+// ErrNotURLOwner signifies that the user is not the owner of the requested
+// URL.
+type ErrNotURLOwner struct{}
+
+var _ GraphQlError = (*ErrNotURLOwner)(nil)
+
+// Extensions keeps structured error metadata so that the clients can reliably
+// handle the error.
+func (e ErrNotURLOwner) Extensions() map[string]interface{} {
+	return map[string]interface{}{
+		"code": ErrCodeNotURLOwner,
+	}
+}
+
+// Error retrieves the human readable error message.
+func (e ErrNotURLOwner) Error() string {
+	return "user is not the owner of the url"
+}
+
+// ErrURLNotFound signifies that the requested short link does not exist.
+type ErrURLNotFound string
+
+var _ GraphQlError = (*ErrURLNotFound)(nil)
+
+// Extensions keeps structured error metadata so that the clients can reliably
+// handle the error.
+func (e ErrURLNotFound) Extensions() map[string]interface{} {
+	return map[string]interface{}{
+		"code":  ErrCodeURLNotFound,
+		"alias": string(e),
+	}
+}
+
+// Error retrieves the human readable error message.
+func (e ErrURLNotFound) Error() string {
+	return "url not found"
+}
+
+// ErrInvalidTimeRange signifies that the provided time range is invalid.
+type ErrInvalidTimeRange string
+
+var _ GraphQlError = (*ErrInvalidTimeRange)(nil)
+
+// Extensions keeps structured error metadata so that the clients can reliably
+// handle the error.
+func (e ErrInvalidTimeRange) Extensions() map[string]interface{} {
+	return map[string]interface{}{
+		"code":   ErrCodeInvalidTimeRange,
+		"reason": string(e),
+	}
+}
+
+// Error retrieves the human readable error message.
+func (e ErrInvalidTimeRange) Error() string {
+	return "time range is invalid"
+}
+
+// ErrInvalidLimit signifies that the provided result size limit is invalid.
+type ErrInvalidLimit string
+
+var _ GraphQlError = (*ErrInvalidLimit)(nil)
+
+// Extensions keeps structured error metadata so that the clients can reliably
+// handle the error.
+func (e ErrInvalidLimit) Extensions() map[string]interface{} {
+	return map[string]interface{}{
+		"code":   ErrCodeInvalidLimit,
+		"reason": string(e),
+	}
+}
+
+// Error retrieves the human readable error message.
+func (e ErrInvalidLimit) Error() string {
+	return "limit is invalid"
+}
+
+// ErrInvalidCursor signifies that the provided pagination cursor is malformed.
+type ErrInvalidCursor string
+
+var _ GraphQlError = (*ErrInvalidCursor)(nil)
+
+// Extensions keeps structured error metadata so that the clients can reliably
+// handle the error.
+func (e ErrInvalidCursor) Extensions() map[string]interface{} {
+	return map[string]interface{}{
+		"code":   ErrCodeInvalidCursor,
+		"cursor": string(e),
+	}
+}
+
+// Error retrieves the human readable error message.
+func (e ErrInvalidCursor) Error() string {
+	return "cursor is invalid"
+}
+
+// ErrTooManyURLs signifies that too many URLs are requested at once.
+type ErrTooManyURLs string
+
+var _ GraphQlError = (*ErrTooManyURLs)(nil)
+
+// Extensions keeps structured error metadata so that the clients can reliably
+// handle the error.
+func (e ErrTooManyURLs) Extensions() map[string]interface{} {
+	return map[string]interface{}{
+		"code":   ErrCodeTooManyURLs,
+		"reason": string(e),
+	}
+}
+
+// Error retrieves the human readable error message.
+func (e ErrTooManyURLs) Error() string {
+	return "too many urls"
+}
+
+// ErrInvalidAPIKey signifies the provided API key is malformed or revoked.
+type ErrInvalidAPIKey struct{}
+
+var _ GraphQlError = (*ErrInvalidAPIKey)(nil)
+
+// Extensions keeps structured error metadata so that the clients can reliably
+// handle the error.
+func (e ErrInvalidAPIKey) Extensions() map[string]interface{} {
+	return map[string]interface{}{
+		"code": ErrCodeInvalidAPIKey,
+	}
+}
+
+// Error retrieves the human readable error message.
+func (e ErrInvalidAPIKey) Error() string {
+	return "api key is invalid"
+}
+
+// ErrInsufficientScope signifies the provided API key is not allowed to
+// perform the requested operation.
+type ErrInsufficientScope string
+
+var _ GraphQlError = (*ErrInsufficientScope)(nil)
+
+// Extensions keeps structured error metadata so that the clients can reliably
+// handle the error.
+func (e ErrInsufficientScope) Extensions() map[string]interface{} {
+	return map[string]interface{}{
+		"code":  ErrCodeInsufficientScope,
+		"scope": string(e),
+	}
+}
+
+// Error retrieves the human readable error message.
+func (e ErrInsufficientScope) Error() string {
+	return "api key lacks required scope"
+}
+
+// ErrInvalidAPIKeyName signifies the name of an API key is empty or too long.
+type ErrInvalidAPIKeyName string
+
+var _ GraphQlError = (*ErrInvalidAPIKeyName)(nil)
+
+// Extensions keeps structured error metadata so that the clients can reliably
+// handle the error.
+func (e ErrInvalidAPIKeyName) Extensions() map[string]interface{} {
+	return map[string]interface{}{
+		"code": ErrCodeInvalidAPIKeyName,
+		"name": string(e),
+	}
+}
+
+// Error retrieves the human readable error message.
+func (e ErrInvalidAPIKeyName) Error() string {
+	return "api key name is invalid"
+}
+
+// ErrAPIKeyNotFound signifies the API key does not exist or belongs to
+// another user.
+type ErrAPIKeyNotFound string
+
+var _ GraphQlError = (*ErrAPIKeyNotFound)(nil)
+
+// Extensions keeps structured error metadata so that the clients can reliably
+// handle the error.
+func (e ErrAPIKeyNotFound) Extensions() map[string]interface{} {
+	return map[string]interface{}{
+		"code": ErrCodeAPIKeyNotFound,
+		"id":   string(e),
+	}
+}
+
+// Error retrieves the human readable error message.
+func (e ErrAPIKeyNotFound) Error() string {
+	return "api key not found"
+}
+
+// ErrRateLimited signifies that the requester performs an action more often
+// than allowed.
+type ErrRateLimited string
+
+var _ GraphQlError = (*ErrRateLimited)(nil)
+
+// Extensions keeps structured error metadata so that the clients can reliably
+// handle the error.
+func (e ErrRateLimited) Extensions() map[string]interface{} {
+	return map[string]interface{}{
+		"code":   ErrCodeRateLimited,
+		"action": string(e),
+	}
+}
+
+// Error retrieves the human readable error message.
+func (e ErrRateLimited) Error() string {
+	return "too many requests"
+}
+
+// ErrUnsafeLongLink signifies that the provided long link may harm the
+// visitors of the short link.
+type ErrUnsafeLongLink string
+
+var _ GraphQlError = (*ErrUnsafeLongLink)(nil)
+
+// Extensions keeps structured error metadata so that the clients can reliably
+// handle the error.
+func (e ErrUnsafeLongLink) Extensions() map[string]interface{} {
+	return map[string]interface{}{
+		"code":   ErrCodeUnsafeLongLink,
+		"reason": string(e),
+	}
+}
+
+// Error retrieves the human readable error message.
+func (e ErrUnsafeLongLink) Error() string {
+	return "long link is unsafe"
+}
+
+// ErrInvalidPassword signifies that the password protecting a short link is
+// empty or too long.
+type ErrInvalidPassword struct{}
+
+var _ GraphQlError = (*ErrInvalidPassword)(nil)
+
+// Extensions keeps structured error metadata so that the clients can reliably
+// handle the error.
+func (e ErrInvalidPassword) Extensions() map[string]interface{} {
+	return map[string]interface{}{
+		"code": ErrCodeInvalidPassword,
+	}
+}
+
+// Error retrieves the human readable error message.
+func (e ErrInvalidPassword) Error() string {
+	return "password is invalid"
+}
+
+// ErrInvalidMaxClicks signifies that a short link is limited to less than one
+// click.
+type ErrInvalidMaxClicks int
+
+var _ GraphQlError = (*ErrInvalidMaxClicks)(nil)
+
+// Extensions keeps structured error metadata so that the clients can reliably
+// handle the error.
+func (e ErrInvalidMaxClicks) Extensions() map[string]interface{} {
+	return map[string]interface{}{
+		"code":      ErrCodeInvalidMaxClicks,
+		"maxClicks": int(e),
+	}
+}
+
+// Error retrieves the human readable error message.
+func (e ErrInvalidMaxClicks) Error() string {
+	return "max clicks must be positive"
+}
+
+// ErrInvalidActivateAt signifies that a short link is activated at or after
+// its expiration time.
+type ErrInvalidActivateAt struct{}
+
+var _ GraphQlError = (*ErrInvalidActivateAt)(nil)
+
+// Extensions keeps structured error metadata so that the clients can reliably
+// handle the error.
+func (e ErrInvalidActivateAt) Extensions() map[string]interface{} {
+	return map[string]interface{}{
+		"code": ErrCodeInvalidActivateAt,
+	}
+}
+
+// Error retrieves the human readable error message.
+func (e ErrInvalidActivateAt) Error() string {
+	return "url must be activated before it expires"
+}
+
+// ErrInvalidRedirectRule signifies that a redirect rule can't be evaluated.
+type ErrInvalidRedirectRule redirectrule.ErrInvalidRule
+
+var _ GraphQlError = (*ErrInvalidRedirectRule)(nil)
+
+// Extensions keeps structured error metadata so that the clients can reliably
+// handle the error.
+func (e ErrInvalidRedirectRule) Extensions() map[string]interface{} {
+	return map[string]interface{}{
+		"code":     ErrCodeInvalidRedirectRule,
+		"position": e.Position,
+		"reason":   e.Reason,
+	}
+}
+
+// Error retrieves the human readable error message.
+func (e ErrInvalidRedirectRule) Error() string {
+	return "redirect rule is invalid"
+}
+
+// ErrInvalidRedirectStatus signifies that visitors of a short link can't be
+// redirected with the requested status code.
+type ErrInvalidRedirectStatus int
+
+var _ GraphQlError = (*ErrInvalidRedirectStatus)(nil)
+
+// Extensions keeps structured error metadata so that the clients can reliably
+// handle the error.
+func (e ErrInvalidRedirectStatus) Extensions() map[string]interface{} {
+	return map[string]interface{}{
+		"code":           ErrCodeInvalidRedirectStatus,
+		"redirectStatus": int(e),
+	}
+}
+
+// Error retrieves the human readable error message.
+func (e ErrInvalidRedirectStatus) Error() string {
+	return "redirect status must be one of 301, 302, 303, 307 and 308"
+}
+
+// ErrInvalidTitle signifies that the title of a short link is empty or too
+// long.
+type ErrInvalidTitle struct{}
+
+var _ GraphQlError = (*ErrInvalidTitle)(nil)
+
+// Extensions keeps structured error metadata so that the clients can reliably
+// handle the error.
+func (e ErrInvalidTitle) Extensions() map[string]interface{} {
+	return map[string]interface{}{
+		"code": ErrCodeInvalidTitle,
+	}
+}
+
+// Error retrieves the human readable error message.
+func (e ErrInvalidTitle) Error() string {
+	return "title is invalid"
+}
+
+// ErrInvalidHostname signifies that the hostname of a custom domain is
+// malformed.
+type ErrInvalidHostname string
+
+var _ GraphQlError = (*ErrInvalidHostname)(nil)
+
+// Extensions keeps structured error metadata so that the clients can reliably
+// handle the error.
+func (e ErrInvalidHostname) Extensions() map[string]interface{} {
+	return map[string]interface{}{
+		"code":     ErrCodeInvalidHostname,
+		"hostname": string(e),
+	}
+}
+
+// Error retrieves the human readable error message.
+func (e ErrInvalidHostname) Error() string {
+	return "hostname is invalid"
+}
+
+// ErrDomainAlreadyExist signifies that the custom domain is registered by
+// another user.
+type ErrDomainAlreadyExist string
+
+var _ GraphQlError = (*ErrDomainAlreadyExist)(nil)
+
+// Extensions keeps structured error metadata so that the clients can reliably
+// handle the error.
+func (e ErrDomainAlreadyExist) Extensions() map[string]interface{} {
+	return map[string]interface{}{
+		"code":     ErrCodeDomainAlreadyExist,
+		"hostname": string(e),
+	}
+}
+
+// Error retrieves the human readable error message.
+func (e ErrDomainAlreadyExist) Error() string {
+	return "domain already exists"
+}
+
+// ErrDomainNotFound signifies that the custom domain is not registered or
+// belongs to another user.
+type ErrDomainNotFound string
+
+var _ GraphQlError = (*ErrDomainNotFound)(nil)
+
+// Extensions keeps structured error metadata so that the clients can reliably
+// handle the error.
+func (e ErrDomainNotFound) Extensions() map[string]interface{} {
+	return map[string]interface{}{
+		"code":     ErrCodeDomainNotFound,
+		"hostname": string(e),
+	}
+}
+
+// Error retrieves the human readable error message.
+func (e ErrDomainNotFound) Error() string {
+	return "domain not found"
+}
+
+// ErrDomainNotVerified signifies that short links can't be created under a
+// custom domain before the user proves the ownership of it.
+type ErrDomainNotVerified string
+
+var _ GraphQlError = (*ErrDomainNotVerified)(nil)
+
+// Extensions keeps structured error metadata so that the clients can reliably
+// handle the error.
+func (e ErrDomainNotVerified) Extensions() map[string]interface{} {
+	return map[string]interface{}{
+		"code":     ErrCodeDomainNotVerified,
+		"hostname": string(e),
+	}
+}
+
+// Error retrieves the human readable error message.
+func (e ErrDomainNotVerified) Error() string {
+	return "domain is not verified"
+}
+
+// ErrChallengeFailed signifies that the custom domain doesn't publish its
+// verification token through the requested challenge.
+type ErrChallengeFailed string
+
+var _ GraphQlError = (*ErrChallengeFailed)(nil)
+
+// Extensions keeps structured error metadata so that the clients can reliably
+// handle the error.
+func (e ErrChallengeFailed) Extensions() map[string]interface{} {
+	return map[string]interface{}{
+		"code":     ErrCodeChallengeFailed,
+		"hostname": string(e),
+	}
+}
+
+// Error retrieves the human readable error message.
+func (e ErrChallengeFailed) Error() string {
+	return "verification token not found"
+}
+
+// ErrConflictingPatch signifies that a field of a short link is both set and
+// cleared in the same patch.
+type ErrConflictingPatch string
+
+var _ GraphQlError = (*ErrConflictingPatch)(nil)
+
+// Extensions keeps structured error metadata so that the clients can reliably
+// handle the error.
+func (e ErrConflictingPatch) Extensions() map[string]interface{} {
+	return map[string]interface{}{
+		"code":  ErrCodeConflictingPatch,
+		"field": string(e),
+	}
+}
+
+// Error retrieves the human readable error message.
+func (e ErrConflictingPatch) Error() string {
+	return "field can't be both set and cleared"
+}
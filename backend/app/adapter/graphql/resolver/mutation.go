@@ -1,59 +1,125 @@
 package resolver
 
 import (
+	"context"
+
 	"github.com/short-d/app/fw"
+	"github.com/short-d/short/app/entity"
+	"github.com/short-d/short/app/usecase/analytics"
+	"github.com/short-d/short/app/usecase/apikey"
 	"github.com/short-d/short/app/usecase/auth"
 	"github.com/short-d/short/app/usecase/changelog"
+	"github.com/short-d/short/app/usecase/customdomain"
+	"github.com/short-d/short/app/usecase/ratelimit"
 	"github.com/short-d/short/app/usecase/requester"
 	"github.com/short-d/short/app/usecase/url"
 )
 
 // Mutation represents GraphQL mutation resolver
 type Mutation struct {
-	logger            fw.Logger
-	tracer            fw.Tracer
-	urlCreator        url.Creator
-	requesterVerifier requester.Verifier
-	authenticator     auth.Authenticator
-	changeLog         changelog.ChangeLog
+	logger             fw.Logger
+	tracer             fw.Tracer
+	urlRetriever       url.Retriever
+	urlCreator         url.Creator
+	urlUpdater         url.Updater
+	urlDeleter         url.Deleter
+	ruleEditor         url.RuleEditor
+	requesterVerifier  requester.Verifier
+	authenticator      auth.Authenticator
+	changeLog          changelog.ChangeLog
+	analyticsRetriever analytics.Retriever
+	apiKeyManager      apikey.Manager
+	domainManager      customdomain.Manager
+	rateLimiter        ratelimit.Limiter
 }
 
 // AuthMutationArgs represents possible parameters for AuthMutation endpoint
 type AuthMutationArgs struct {
 	AuthToken       *string
-	CaptchaResponse string
+	APIKey          *string
+	CaptchaResponse *string
+}
+
+// AuthMutation extracts user information from authentication token or API
+// key. Requests without API key need to prove they are sent by a human.
+func (m Mutation) AuthMutation(ctx context.Context, args *AuthMutationArgs) (*AuthMutation, error) {
+	err := m.verifyRequester(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+
+	authMutation := newAuthMutation(
+		newCredential(args.AuthToken, args.APIKey, m.authenticator, m.apiKeyManager),
+		m.changeLog,
+		m.urlRetriever,
+		m.urlCreator,
+		m.urlUpdater,
+		m.urlDeleter,
+		m.ruleEditor,
+		m.analyticsRetriever,
+		m.apiKeyManager,
+		m.domainManager,
+		m.rateLimiter,
+	)
+	return &authMutation, nil
 }
 
-// AuthMutation extracts user information from authentication token
-func (m Mutation) AuthMutation(args *AuthMutationArgs) (*AuthMutation, error) {
-	isHuman, err := m.requesterVerifier.IsHuman(args.CaptchaResponse)
+// verifyRequester skips reCAPTCHA verification for requests carrying a valid
+// API key so that scripts can call the API.
+func (m Mutation) verifyRequester(ctx context.Context, args *AuthMutationArgs) error {
+	if args.APIKey != nil {
+		_, err := m.apiKeyManager.GetUser(ctx, *args.APIKey, entity.APIKeyScopeReadOnly)
+		if err != nil {
+			return newViewerError(err)
+		}
+		return nil
+	}
+
+	if args.CaptchaResponse == nil {
+		return ErrNotHuman{}
+	}
 
+	isHuman, err := m.requesterVerifier.IsHuman(*args.CaptchaResponse)
 	if err != nil {
-		return nil, ErrUnknown{}
+		return ErrUnknown{}
 	}
 
 	if !isHuman {
-		return nil, ErrNotHuman{}
+		return ErrNotHuman{}
 	}
-
-	authMutation := newAuthMutation(args.AuthToken, m.authenticator, m.changeLog, m.urlCreator)
-	return &authMutation, nil
+	return nil
 }
 
 func newMutation(
 	logger fw.Logger,
 	tracer fw.Tracer,
 	changeLog changelog.ChangeLog,
+	urlRetriever url.Retriever,
 	urlCreator url.Creator,
+	urlUpdater url.Updater,
+	urlDeleter url.Deleter,
+	ruleEditor url.RuleEditor,
 	requesterVerifier requester.Verifier,
 	authenticator auth.Authenticator,
+	analyticsRetriever analytics.Retriever,
+	apiKeyManager apikey.Manager,
+	domainManager customdomain.Manager,
+	rateLimiter ratelimit.Limiter,
 ) Mutation {
 	return Mutation{
-		logger:            logger,
-		tracer:            tracer,
-		changeLog:         changeLog,
-		urlCreator:        urlCreator,
-		requesterVerifier: requesterVerifier,
-		authenticator:     authenticator,
+		logger:             logger,
+		tracer:             tracer,
+		changeLog:          changeLog,
+		urlRetriever:       urlRetriever,
+		urlCreator:         urlCreator,
+		urlUpdater:         urlUpdater,
+		urlDeleter:         urlDeleter,
+		ruleEditor:         ruleEditor,
+		requesterVerifier:  requesterVerifier,
+		authenticator:      authenticator,
+		analyticsRetriever: analyticsRetriever,
+		apiKeyManager:      apiKeyManager,
+		domainManager:      domainManager,
+		rateLimiter:        rateLimiter,
 	}
 }
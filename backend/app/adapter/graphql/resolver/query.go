@@ -2,28 +2,46 @@ package resolver
 
 import (
 	"github.com/short-d/app/fw"
+	"github.com/short-d/short/app/usecase/analytics"
+	"github.com/short-d/short/app/usecase/apikey"
 	"github.com/short-d/short/app/usecase/auth"
 	"github.com/short-d/short/app/usecase/changelog"
+	"github.com/short-d/short/app/usecase/customdomain"
 	"github.com/short-d/short/app/usecase/url"
 )
 
 // Query represents GraphQL query resolver
 type Query struct {
-	logger        fw.Logger
-	tracer        fw.Tracer
-	authenticator auth.Authenticator
-	changeLog     changelog.ChangeLog
-	urlRetriever  url.Retriever
+	logger             fw.Logger
+	tracer             fw.Tracer
+	authenticator      auth.Authenticator
+	changeLog          changelog.ChangeLog
+	urlRetriever       url.Retriever
+	ruleEditor         url.RuleEditor
+	urlPreviewer       url.Previewer
+	analyticsRetriever analytics.Retriever
+	apiKeyManager      apikey.Manager
+	domainManager      customdomain.Manager
 }
 
 // AuthQueryArgs represents possible parameters for AuthQuery endpoint
 type AuthQueryArgs struct {
 	AuthToken *string
+	APIKey    *string
 }
 
-// AuthQuery extracts user information from authentication token
+// AuthQuery extracts user information from authentication token or API key
 func (q Query) AuthQuery(args *AuthQueryArgs) (*AuthQuery, error) {
-	authQuery := newAuthQuery(args.AuthToken, q.authenticator, q.changeLog, q.urlRetriever)
+	authQuery := newAuthQuery(
+		newCredential(args.AuthToken, args.APIKey, q.authenticator, q.apiKeyManager),
+		q.changeLog,
+		q.urlRetriever,
+		q.ruleEditor,
+		q.urlPreviewer,
+		q.analyticsRetriever,
+		q.apiKeyManager,
+		q.domainManager,
+	)
 	return &authQuery, nil
 }
 
@@ -33,12 +51,22 @@ func newQuery(
 	authenticator auth.Authenticator,
 	changeLog changelog.ChangeLog,
 	urlRetriever url.Retriever,
+	ruleEditor url.RuleEditor,
+	urlPreviewer url.Previewer,
+	analyticsRetriever analytics.Retriever,
+	apiKeyManager apikey.Manager,
+	domainManager customdomain.Manager,
 ) Query {
 	return Query{
-		logger:        logger,
-		tracer:        tracer,
-		authenticator: authenticator,
-		changeLog:     changeLog,
-		urlRetriever:  urlRetriever,
+		logger:             logger,
+		tracer:             tracer,
+		authenticator:      authenticator,
+		changeLog:          changeLog,
+		urlRetriever:       urlRetriever,
+		ruleEditor:         ruleEditor,
+		urlPreviewer:       urlPreviewer,
+		analyticsRetriever: analyticsRetriever,
+		apiKeyManager:      apiKeyManager,
+		domainManager:      domainManager,
 	}
 }
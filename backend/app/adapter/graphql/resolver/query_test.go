@@ -9,12 +9,17 @@ import (
 	"github.com/short-d/app/mdtest"
 	"github.com/short-d/short/app/adapter/db"
 	"github.com/short-d/short/app/entity"
+	"github.com/short-d/short/app/usecase/analytics"
+	"github.com/short-d/short/app/usecase/apikey"
 	"github.com/short-d/short/app/usecase/auth"
 	"github.com/short-d/short/app/usecase/changelog"
+	"github.com/short-d/short/app/usecase/customdomain"
 	"github.com/short-d/short/app/usecase/keygen"
+	"github.com/short-d/short/app/usecase/linksafety"
 	"github.com/short-d/short/app/usecase/repository"
 	"github.com/short-d/short/app/usecase/service"
 	"github.com/short-d/short/app/usecase/url"
+	"github.com/short-d/short/app/usecase/validator"
 )
 
 func TestQuery_AuthQuery(t *testing.T) {
@@ -61,21 +66,57 @@ func TestQuery_AuthQuery(t *testing.T) {
 			defer sqlDB.Close()
 
 			fakeURLRepo := repository.NewURLFake(map[string]entity.URL{})
-			fakeUserURLRelationRepo := repository.NewUserURLRepoFake(nil, nil)
+			fakePublicURLRepo := repository.NewPublicURLFake(nil)
+			fakeUserURLRelationRepo := repository.NewUserURLRepoFake(nil, nil, &fakePublicURLRepo)
 			authenticator := auth.NewAuthenticatorFake(time.Now(), time.Hour)
-			retrieverFake := url.NewRetrieverPersist(&fakeURLRepo, &fakeUserURLRelationRepo)
+			retrieverFake := url.NewRetrieverPersist(&fakeURLRepo, &fakeUserURLRelationRepo, &fakePublicURLRepo)
+			fakeRedirectRuleRepo := repository.NewRedirectRuleFake(nil)
+			ruleEditor := url.NewRuleEditorPersist(
+				&fakeURLRepo,
+				&fakeUserURLRelationRepo,
+				&fakeRedirectRuleRepo,
+				validator.NewLongLink(),
+				linksafety.Checker{},
+			)
+			fakeUserRepo := repository.NewUserFake(nil)
+			urlPreviewer := url.NewPreviewerPersist(
+				retrieverFake,
+				&fakeUserURLRelationRepo,
+				&fakeUserRepo,
+				mdtest.NewTimerFake(time.Now()),
+			)
 			logger := mdtest.NewLoggerFake(mdtest.FakeLoggerArgs{})
 			tracer := mdtest.NewTracerFake()
 
 			keyFetcher := service.NewKeyFetcherFake([]service.Key{})
-			keyGen, err := keygen.NewKeyGenerator(2, &keyFetcher)
+			keyGen, err := keygen.NewKeyGenerator(2, 0, &keyFetcher, service.NewCounterFake(), mdtest.NewTimerFake(time.Now()))
 			mdtest.Equal(t, nil, err)
 
 			timerFake := mdtest.NewTimerFake(now)
 			changeLogRepo := db.NewChangeLogSQL(sqlDB)
 			changeLog := changelog.NewPersist(keyGen, timerFake, changeLogRepo)
 
-			query := newQuery(&logger, &tracer, authenticator, changeLog, retrieverFake)
+			fakeClickRepo := repository.NewClickFake(nil)
+			analyticsRetriever := analytics.NewRetrieverPersist(&fakeClickRepo, &fakeUserURLRelationRepo)
+
+			apiKeyRepo := repository.NewAPIKeyFake()
+			apiKeyManager := apikey.NewManager(&apiKeyRepo, timerFake)
+
+			domainRepo := repository.NewDomainFake(nil)
+			domainManager := customdomain.NewManager(&domainRepo, service.NewDomainVerifierFake(nil, nil), timerFake, nil)
+
+			query := newQuery(
+				&logger,
+				&tracer,
+				authenticator,
+				changeLog,
+				retrieverFake,
+				ruleEditor,
+				urlPreviewer,
+				analyticsRetriever,
+				apiKeyManager,
+				domainManager,
+			)
 
 			mdtest.Equal(t, nil, err)
 			authQueryArgs := AuthQueryArgs{AuthToken: testCase.authToken}
@@ -0,0 +1,53 @@
+package resolver
+
+import "github.com/short-d/short/app/entity"
+
+var redirectConditions = map[string]entity.RedirectCondition{
+	"LANGUAGE": entity.RedirectConditionLanguage,
+	"DEVICE":   entity.RedirectConditionDevice,
+	"COUNTRY":  entity.RedirectConditionCountry,
+	"SPLIT":    entity.RedirectConditionSplit,
+}
+
+var gqlRedirectConditions = map[entity.RedirectCondition]string{
+	entity.RedirectConditionLanguage: "LANGUAGE",
+	entity.RedirectConditionDevice:   "DEVICE",
+	entity.RedirectConditionCountry:  "COUNTRY",
+	entity.RedirectConditionSplit:    "SPLIT",
+}
+
+// RedirectRule retrieves requested fields of a redirect rule.
+type RedirectRule struct {
+	rule entity.RedirectRule
+}
+
+// Condition retrieves the property of a visit RedirectRule entity is matched
+// against.
+func (r RedirectRule) Condition() string {
+	return gqlRedirectConditions[r.rule.Condition]
+}
+
+// Values retrieves the languages, devices or countries accepted by
+// RedirectRule entity.
+func (r RedirectRule) Values() []string {
+	return append([]string{}, r.rule.Values...)
+}
+
+// Weight retrieves the percentage of visits sent by a split RedirectRule
+// entity.
+func (r RedirectRule) Weight() int32 {
+	return int32(r.rule.Weight)
+}
+
+// LongLink retrieves the destination of RedirectRule entity.
+func (r RedirectRule) LongLink() string {
+	return r.rule.LongLink
+}
+
+func newRedirectRules(rules []entity.RedirectRule) []RedirectRule {
+	gqlRules := make([]RedirectRule, 0, len(rules))
+	for _, rule := range rules {
+		gqlRules = append(gqlRules, RedirectRule{rule: rule})
+	}
+	return gqlRules
+}
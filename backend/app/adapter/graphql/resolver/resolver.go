@@ -2,8 +2,12 @@ package resolver
 
 import (
 	"github.com/short-d/app/fw"
+	"github.com/short-d/short/app/usecase/analytics"
+	"github.com/short-d/short/app/usecase/apikey"
 	"github.com/short-d/short/app/usecase/auth"
 	"github.com/short-d/short/app/usecase/changelog"
+	"github.com/short-d/short/app/usecase/customdomain"
+	"github.com/short-d/short/app/usecase/ratelimit"
 	"github.com/short-d/short/app/usecase/requester"
 	"github.com/short-d/short/app/usecase/url"
 )
@@ -21,18 +25,45 @@ func NewResolver(
 	changeLog changelog.ChangeLog,
 	urlRetriever url.Retriever,
 	urlCreator url.Creator,
+	urlUpdater url.Updater,
+	urlDeleter url.Deleter,
+	ruleEditor url.RuleEditor,
+	urlPreviewer url.Previewer,
 	requesterVerifier requester.Verifier,
 	authenticator auth.Authenticator,
+	analyticsRetriever analytics.Retriever,
+	apiKeyManager apikey.Manager,
+	domainManager customdomain.Manager,
+	rateLimiter ratelimit.Limiter,
 ) Resolver {
 	return Resolver{
-		Query: newQuery(logger, tracer, authenticator, changeLog, urlRetriever),
+		Query: newQuery(
+			logger,
+			tracer,
+			authenticator,
+			changeLog,
+			urlRetriever,
+			ruleEditor,
+			urlPreviewer,
+			analyticsRetriever,
+			apiKeyManager,
+			domainManager,
+		),
 		Mutation: newMutation(
 			logger,
 			tracer,
 			changeLog,
+			urlRetriever,
 			urlCreator,
+			urlUpdater,
+			urlDeleter,
+			ruleEditor,
 			requesterVerifier,
 			authenticator,
+			analyticsRetriever,
+			apiKeyManager,
+			domainManager,
+			rateLimiter,
 		),
 	}
 }
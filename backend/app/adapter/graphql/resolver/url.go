@@ -1,13 +1,37 @@
 package resolver
 
 import (
+	"context"
+
 	"github.com/short-d/short/app/adapter/graphql/scalar"
 	"github.com/short-d/short/app/entity"
+	"github.com/short-d/short/app/usecase/analytics"
+	"github.com/short-d/short/app/usecase/url"
 )
 
 // URL retrieves requested fields of URL entity.
 type URL struct {
-	url entity.URL
+	url                entity.URL
+	credential         credential
+	urlRetriever       url.Retriever
+	analyticsRetriever analytics.Retriever
+}
+
+// ClicksByDayArgs represents possible parameters for ClicksByDay endpoint
+type ClicksByDayArgs struct {
+	From scalar.Time
+	To   scalar.Time
+}
+
+// TopReferrersArgs represents possible parameters for TopReferrers endpoint
+type TopReferrersArgs struct {
+	Limit int32
+}
+
+// Domain retrieves the custom domain URL entity is served under, or nil for
+// the default host.
+func (u URL) Domain() *string {
+	return optionalString(u.url.Domain)
 }
 
 // Alias retrieves the alias of URL entity.
@@ -15,9 +39,27 @@ func (u URL) Alias() *string {
 	return &u.url.Alias
 }
 
-// OriginalURL retrieves the long link of URL entity.
-func (u URL) OriginalURL() *string {
-	return &u.url.OriginalURL
+// OriginalURL retrieves the long link of URL entity. The long link of a
+// password protected URL is nil for everyone but its owner, so that it can't
+// be read without entering the password.
+func (u URL) OriginalURL(ctx context.Context) (*string, error) {
+	if u.url.PasswordHash == nil {
+		return &u.url.OriginalURL, nil
+	}
+
+	user, ok := u.viewer(ctx)
+	if !ok {
+		return nil, nil
+	}
+
+	isOwner, err := u.urlRetriever.IsOwner(ctx, u.url.Domain, u.url.Alias, user)
+	if err != nil {
+		return nil, ErrUnknown{}
+	}
+	if !isOwner {
+		return nil, nil
+	}
+	return &u.url.OriginalURL, nil
 }
 
 // ExpireAt retrieves the expiration time of URL entity.
@@ -29,6 +71,218 @@ func (u URL) ExpireAt() *scalar.Time {
 	return &scalar.Time{Time: *u.url.ExpireAt}
 }
 
-func newURL(url entity.URL) URL {
-	return URL{url: url}
+// ActivateAt retrieves the time before which the URL doesn't redirect to the
+// long link.
+func (u URL) ActivateAt() *scalar.Time {
+	if u.url.ActivateAt == nil {
+		return nil
+	}
+
+	return &scalar.Time{Time: *u.url.ActivateAt}
+}
+
+// IsPasswordProtected checks whether visitors must enter a password before
+// being redirected to the long link.
+func (u URL) IsPasswordProtected() bool {
+	return u.url.PasswordHash != nil
+}
+
+// ForwardQuery checks whether the query parameters of visits are forwarded
+// to the long link.
+func (u URL) ForwardQuery() bool {
+	return u.url.ForwardQuery
+}
+
+// ForwardPath checks whether the path following the alias is appended to the
+// long link.
+func (u URL) ForwardPath() bool {
+	return u.url.ForwardPath
+}
+
+// UTM retrieves the UTM parameters appended to the long link, or nil when
+// none is set.
+func (u URL) UTM() *UTM {
+	if u.url.UTM == nil {
+		return nil
+	}
+	return &UTM{utm: *u.url.UTM}
+}
+
+// Title retrieves the human readable name of the URL. It is nil when the URL
+// isn't named.
+func (u URL) Title() *string {
+	return u.url.Title
+}
+
+// RedirectStatus retrieves the HTTP status code visitors of the URL are
+// redirected with. It is nil when the system default is used.
+func (u URL) RedirectStatus() *int32 {
+	if u.url.RedirectStatus == nil {
+		return nil
+	}
+
+	redirectStatus := int32(*u.url.RedirectStatus)
+	return &redirectStatus
+}
+
+// MaxClicks retrieves the number of visits after which the URL expires. It is
+// nil when the URL can be visited any number of times.
+func (u URL) MaxClicks() *int32 {
+	if u.url.MaxClicks == nil {
+		return nil
+	}
+
+	maxClicks := int32(*u.url.MaxClicks)
+	return &maxClicks
+}
+
+// RemainingClicks retrieves the number of visits left before the URL expires.
+// It is nil when the URL can be visited any number of times.
+func (u URL) RemainingClicks() *int32 {
+	if u.url.MaxClicks == nil {
+		return nil
+	}
+
+	remainingClicks := int32(*u.url.MaxClicks - u.url.ClickCount)
+	if remainingClicks < 0 {
+		remainingClicks = 0
+	}
+	return &remainingClicks
+}
+
+// ClickCount retrieves the total number of clicks on the URL. It is nil for
+// everyone but the owner of the URL.
+func (u URL) ClickCount(ctx context.Context) (*int32, error) {
+	user, ok := u.viewer(ctx)
+	if !ok {
+		return nil, nil
+	}
+
+	count, err := u.analyticsRetriever.GetClickCount(ctx, u.url.Domain, u.url.Alias, user)
+	if isNotURLOwner(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, newAnalyticsError(err)
+	}
+
+	clickCount := int32(count)
+	return &clickCount, nil
+}
+
+// ClicksByDay retrieves the number of clicks on the URL for each day within
+// the given time range. It is nil for everyone but the owner of the URL.
+func (u URL) ClicksByDay(ctx context.Context, args *ClicksByDayArgs) (*[]DailyClicks, error) {
+	user, ok := u.viewer(ctx)
+	if !ok {
+		return nil, nil
+	}
+
+	dailyClicks, err := u.analyticsRetriever.GetClicksByDay(
+		ctx,
+		u.url.Domain,
+		u.url.Alias,
+		args.From.Time,
+		args.To.Time,
+		user,
+	)
+	if isNotURLOwner(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, newAnalyticsError(err)
+	}
+
+	gqlDailyClicks := make([]DailyClicks, 0, len(dailyClicks))
+	for _, daily := range dailyClicks {
+		gqlDailyClicks = append(gqlDailyClicks, newDailyClicks(daily))
+	}
+	return &gqlDailyClicks, nil
+}
+
+// TopReferrers retrieves the referrers bringing the most clicks to the URL.
+// It is nil for everyone but the owner of the URL.
+func (u URL) TopReferrers(ctx context.Context, args *TopReferrersArgs) (*[]ReferrerClicks, error) {
+	user, ok := u.viewer(ctx)
+	if !ok {
+		return nil, nil
+	}
+
+	referrers, err := u.analyticsRetriever.GetTopReferrers(ctx, u.url.Domain, u.url.Alias, int(args.Limit), user)
+	if isNotURLOwner(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, newAnalyticsError(err)
+	}
+
+	gqlReferrers := make([]ReferrerClicks, 0, len(referrers))
+	for _, referrer := range referrers {
+		gqlReferrers = append(gqlReferrers, newReferrerClicks(referrer))
+	}
+	return &gqlReferrers, nil
+}
+
+// DeviceBreakdown retrieves the number of clicks on the URL for each class of
+// devices. It is nil for everyone but the owner of the URL.
+func (u URL) DeviceBreakdown(ctx context.Context) (*[]DeviceClicks, error) {
+	user, ok := u.viewer(ctx)
+	if !ok {
+		return nil, nil
+	}
+
+	devices, err := u.analyticsRetriever.GetDeviceBreakdown(ctx, u.url.Domain, u.url.Alias, user)
+	if isNotURLOwner(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, newAnalyticsError(err)
+	}
+
+	gqlDevices := make([]DeviceClicks, 0, len(devices))
+	for _, device := range devices {
+		gqlDevices = append(gqlDevices, newDeviceClicks(device))
+	}
+	return &gqlDevices, nil
+}
+
+// viewer finds the user viewing the URL. Viewers who can't be identified are
+// treated as strangers, so that the URL can still be listed to them without
+// its owner only fields.
+func (u URL) viewer(ctx context.Context) (entity.User, bool) {
+	user, err := u.credential.viewer(ctx, entity.APIKeyScopeReadOnly)
+	if err != nil {
+		return entity.User{}, false
+	}
+	return user, true
+}
+
+func isNotURLOwner(err error) bool {
+	_, ok := err.(analytics.ErrNotURLOwner)
+	return ok
+}
+
+func newAnalyticsError(err error) error {
+	switch err.(type) {
+	case analytics.ErrInvalidTimeRange:
+		return ErrInvalidTimeRange(err.Error())
+	case analytics.ErrInvalidLimit:
+		return ErrInvalidLimit(err.Error())
+	default:
+		return ErrUnknown{}
+	}
+}
+
+func newURL(
+	url entity.URL,
+	credential credential,
+	urlRetriever url.Retriever,
+	analyticsRetriever analytics.Retriever,
+) URL {
+	return URL{
+		url:                url,
+		credential:         credential,
+		urlRetriever:       urlRetriever,
+		analyticsRetriever: analyticsRetriever,
+	}
 }
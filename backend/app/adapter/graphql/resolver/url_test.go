@@ -3,12 +3,18 @@
 package resolver
 
 import (
+	"context"
 	"testing"
 	"time"
 
 	"github.com/short-d/app/mdtest"
 	"github.com/short-d/short/app/adapter/graphql/scalar"
 	"github.com/short-d/short/app/entity"
+	"github.com/short-d/short/app/usecase/analytics"
+	"github.com/short-d/short/app/usecase/apikey"
+	"github.com/short-d/short/app/usecase/auth"
+	"github.com/short-d/short/app/usecase/repository"
+	"github.com/short-d/short/app/usecase/url"
 )
 
 func TestURL_Alias(t *testing.T) {
@@ -20,11 +26,81 @@ func TestURL_Alias(t *testing.T) {
 }
 
 func TestURL_OriginalURL(t *testing.T) {
-	urlResolver := URL{url: entity.URL{OriginalURL: "TestOriginalUrl"}}
+	now := time.Now()
+	authenticator := auth.NewAuthenticatorFake(now, time.Hour)
+	owner := entity.User{Email: "alpha@example.com"}
+	stranger := entity.User{ID: "beta", Email: "beta@example.com"}
 
-	expected := urlResolver.url.OriginalURL
-	got := *urlResolver.OriginalURL()
-	mdtest.Equal(t, got, expected, "*urlResolver.OriginalURL() = %v; want %v", expected, got)
+	ownerToken, err := authenticator.GenerateToken(owner)
+	mdtest.Equal(t, nil, err)
+	strangerToken, err := authenticator.GenerateToken(stranger)
+	mdtest.Equal(t, nil, err)
+
+	longLink := "https://www.google.com"
+	passwordHash := "hash"
+
+	testCases := []struct {
+		name             string
+		passwordHash     *string
+		authToken        *string
+		expectedLongLink *string
+	}{
+		{
+			name:             "without password",
+			passwordHash:     nil,
+			authToken:        nil,
+			expectedLongLink: &longLink,
+		},
+		{
+			name:             "password protected without auth token",
+			passwordHash:     &passwordHash,
+			authToken:        nil,
+			expectedLongLink: nil,
+		},
+		{
+			name:             "password protected and viewer is not the owner",
+			passwordHash:     &passwordHash,
+			authToken:        &strangerToken,
+			expectedLongLink: nil,
+		},
+		{
+			name:             "password protected and viewer is the owner",
+			passwordHash:     &passwordHash,
+			authToken:        &ownerToken,
+			expectedLongLink: &longLink,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			u := entity.URL{
+				Alias:        "220uFicCJj",
+				OriginalURL:  longLink,
+				PasswordHash: testCase.passwordHash,
+			}
+			urlRepo := repository.NewURLFake(map[string]entity.URL{u.Alias: u})
+			userURLRelationRepo := repository.NewUserURLRepoFake(
+				[]entity.User{owner},
+				[]entity.URL{u},
+				nil,
+			)
+			publicURLRepo := repository.NewPublicURLFake(nil)
+			urlRetriever := url.NewRetrieverPersist(&urlRepo, &userURLRelationRepo, &publicURLRepo)
+
+			apiKeyRepo := repository.NewAPIKeyFake()
+			apiKeyManager := apikey.NewManager(&apiKeyRepo, mdtest.NewTimerFake(now))
+
+			urlResolver := newURL(
+				u,
+				newCredential(testCase.authToken, nil, authenticator, apiKeyManager),
+				urlRetriever,
+				analytics.RetrieverPersist{},
+			)
+			gotLongLink, err := urlResolver.OriginalURL(context.Background())
+			mdtest.Equal(t, nil, err)
+			mdtest.Equal(t, testCase.expectedLongLink, gotLongLink)
+		})
+	}
 }
 
 func TestURL_ExpireAt(t *testing.T) {
@@ -45,3 +121,67 @@ func TestURL_ExpireAt(t *testing.T) {
 		mdtest.Equal(t, testCase.expected, testCase.url.ExpireAt())
 	}
 }
+
+func TestURL_ClickCount(t *testing.T) {
+	now := time.Now()
+	authenticator := auth.NewAuthenticatorFake(now, time.Hour)
+	owner := entity.User{Email: "alpha@example.com"}
+	stranger := entity.User{ID: "beta", Email: "beta@example.com"}
+
+	ownerToken, err := authenticator.GenerateToken(owner)
+	mdtest.Equal(t, nil, err)
+	strangerToken, err := authenticator.GenerateToken(stranger)
+	mdtest.Equal(t, nil, err)
+
+	expectedCount := int32(2)
+
+	testCases := []struct {
+		name          string
+		authToken     *string
+		expectedCount *int32
+	}{
+		{
+			name:          "without auth token",
+			authToken:     nil,
+			expectedCount: nil,
+		},
+		{
+			name:          "viewer is not the owner",
+			authToken:     &strangerToken,
+			expectedCount: nil,
+		},
+		{
+			name:          "viewer is the owner",
+			authToken:     &ownerToken,
+			expectedCount: &expectedCount,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			clickRepo := repository.NewClickFake([]entity.Click{
+				{Alias: "220uFicCJj", ClickedAt: now},
+				{Alias: "220uFicCJj", ClickedAt: now},
+			})
+			userURLRelationRepo := repository.NewUserURLRepoFake(
+				[]entity.User{owner},
+				[]entity.URL{{Alias: "220uFicCJj"}},
+				nil,
+			)
+			analyticsRetriever := analytics.NewRetrieverPersist(&clickRepo, &userURLRelationRepo)
+
+			apiKeyRepo := repository.NewAPIKeyFake()
+			apiKeyManager := apikey.NewManager(&apiKeyRepo, mdtest.NewTimerFake(now))
+
+			urlResolver := newURL(
+				entity.URL{Alias: "220uFicCJj"},
+				newCredential(testCase.authToken, nil, authenticator, apiKeyManager),
+				url.RetrieverPersist{},
+				analyticsRetriever,
+			)
+			count, err := urlResolver.ClickCount(context.Background())
+			mdtest.Equal(t, nil, err)
+			mdtest.Equal(t, testCase.expectedCount, count)
+		})
+	}
+}
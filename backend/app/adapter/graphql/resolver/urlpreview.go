@@ -0,0 +1,45 @@
+package resolver
+
+import "github.com/short-d/short/app/usecase/url"
+
+// URLPreview retrieves requested fields of the preview of a short link.
+type URLPreview struct {
+	preview url.Preview
+}
+
+// Domain retrieves the custom domain the short link is served under, or nil
+// for the default host.
+func (u URLPreview) Domain() *string {
+	return optionalString(u.preview.Domain)
+}
+
+// Alias retrieves the alias of the short link.
+func (u URLPreview) Alias() string {
+	return u.preview.Alias
+}
+
+// LongLink retrieves the destination of the short link. It is nil when the
+// short link is password protected.
+func (u URLPreview) LongLink() *string {
+	return u.preview.LongLink
+}
+
+// Title retrieves the human readable name of the short link.
+func (u URLPreview) Title() *string {
+	return u.preview.Title
+}
+
+// OwnerName retrieves the name of the user who created the short link.
+func (u URLPreview) OwnerName() *string {
+	return optionalString(u.preview.Owner.Name)
+}
+
+// IsProtected checks whether visitors must enter a password before being
+// redirected.
+func (u URLPreview) IsProtected() bool {
+	return u.preview.IsProtected
+}
+
+func newURLPreview(preview url.Preview) URLPreview {
+	return URLPreview{preview: preview}
+}
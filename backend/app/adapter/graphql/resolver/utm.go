@@ -0,0 +1,73 @@
+package resolver
+
+import "github.com/short-d/short/app/entity"
+
+// UTM retrieves requested fields of the UTM parameters of a short link.
+type UTM struct {
+	utm entity.UTM
+}
+
+// Source retrieves the utm_source parameter.
+func (u UTM) Source() *string {
+	return optionalString(u.utm.Source)
+}
+
+// Medium retrieves the utm_medium parameter.
+func (u UTM) Medium() *string {
+	return optionalString(u.utm.Medium)
+}
+
+// Campaign retrieves the utm_campaign parameter.
+func (u UTM) Campaign() *string {
+	return optionalString(u.utm.Campaign)
+}
+
+// Term retrieves the utm_term parameter.
+func (u UTM) Term() *string {
+	return optionalString(u.utm.Term)
+}
+
+// Content retrieves the utm_content parameter.
+func (u UTM) Content() *string {
+	return optionalString(u.utm.Content)
+}
+
+// UTMInput represents possible UTM parameters
+type UTMInput struct {
+	Source   *string
+	Medium   *string
+	Campaign *string
+	Term     *string
+	Content  *string
+}
+
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+func newUTM(input *UTMInput) *entity.UTM {
+	if input == nil {
+		return nil
+	}
+
+	utm := entity.UTM{}
+	if input.Source != nil {
+		utm.Source = *input.Source
+	}
+	if input.Medium != nil {
+		utm.Medium = *input.Medium
+	}
+	if input.Campaign != nil {
+		utm.Campaign = *input.Campaign
+	}
+	if input.Term != nil {
+		utm.Term = *input.Term
+	}
+	if input.Content != nil {
+		utm.Content = *input.Content
+	}
+	return &utm
+}
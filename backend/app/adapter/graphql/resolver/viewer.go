@@ -1,16 +1,81 @@
 package resolver
 
 import (
+	"context"
 	"errors"
 
 	"github.com/short-d/short/app/entity"
+	"github.com/short-d/short/app/usecase/apikey"
 	"github.com/short-d/short/app/usecase/auth"
+	"github.com/short-d/short/app/usecase/ratelimit"
 )
 
-func viewer(authToken *string, authenticator auth.Authenticator) (entity.User, error) {
-	if authToken == nil {
+// credential represents the secrets a request carries to identify its user,
+// either an auth token issued after signing in or a personal API key.
+type credential struct {
+	authToken     *string
+	apiKey        *string
+	authenticator auth.Authenticator
+	apiKeyManager apikey.Manager
+}
+
+// viewer finds the user sending the request. API keys take precedence over
+// auth tokens and must be allowed to perform operations under the given
+// scope.
+func (c credential) viewer(ctx context.Context, scope entity.APIKeyScope) (entity.User, error) {
+	user, _, err := c.identify(ctx, scope)
+	return user, err
+}
+
+// identify finds the user sending the request along with the subject its
+// requests are rate limited by, which is the API key when the request carries
+// one.
+func (c credential) identify(ctx context.Context, scope entity.APIKeyScope) (entity.User, ratelimit.Subject, error) {
+	if c.apiKey == nil {
+		user, err := c.signedInViewer()
+		return user, ratelimit.UserSubject(user), err
+	}
+
+	apiKey, err := c.apiKeyManager.GetKey(ctx, *c.apiKey, scope)
+	if err != nil {
+		return entity.User{}, "", err
+	}
+	user := entity.User{Email: apiKey.UserEmail}
+	return user, ratelimit.APIKeySubject(apiKey), nil
+}
+
+// signedInViewer finds the user sending the request with auth token only, so
+// that API keys can't be used to manage API keys.
+func (c credential) signedInViewer() (entity.User, error) {
+	if c.authToken == nil {
 		return entity.User{}, errors.New("auth token can't be empty")
 	}
 
-	return authenticator.GetUser(*authToken)
+	return c.authenticator.GetUser(*c.authToken)
+}
+
+func newCredential(
+	authToken *string,
+	apiKey *string,
+	authenticator auth.Authenticator,
+	apiKeyManager apikey.Manager,
+) credential {
+	return credential{
+		authToken:     authToken,
+		apiKey:        apiKey,
+		authenticator: authenticator,
+		apiKeyManager: apiKeyManager,
+	}
+}
+
+func newViewerError(err error) error {
+	switch err.(type) {
+	case apikey.ErrInvalidAPIKey:
+		return ErrInvalidAPIKey{}
+	case apikey.ErrInsufficientScope:
+		scope := entity.APIKeyScope(err.(apikey.ErrInsufficientScope))
+		return ErrInsufficientScope(gqlAPIKeyScopes[scope])
+	default:
+		return ErrInvalidAuthToken{}
+	}
 }
@@ -0,0 +1,147 @@
+// +build !integration all
+
+package resolver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/mdtest"
+	"github.com/short-d/short/app/entity"
+	"github.com/short-d/short/app/usecase/apikey"
+	"github.com/short-d/short/app/usecase/auth"
+	"github.com/short-d/short/app/usecase/repository"
+)
+
+func TestCredential_Viewer(t *testing.T) {
+	now := time.Now()
+	user := entity.User{Email: "alpha@example.com"}
+
+	authenticator := auth.NewAuthenticatorFake(now, time.Hour)
+	authToken, err := authenticator.GenerateToken(user)
+	mdtest.Equal(t, nil, err)
+
+	apiKeyRepo := repository.NewAPIKeyFake()
+	apiKeyManager := apikey.NewManager(&apiKeyRepo, mdtest.NewTimerFake(now))
+	_, readOnlyKey, err := apiKeyManager.CreateKey(context.Background(), user, "read", nil)
+	mdtest.Equal(t, nil, err)
+	_, creatorKey, err := apiKeyManager.CreateKey(
+		context.Background(),
+		user,
+		"create",
+		[]entity.APIKeyScope{entity.APIKeyScopeCreateLinks},
+	)
+	mdtest.Equal(t, nil, err)
+	invalidKey := "short_invalid"
+
+	testCases := []struct {
+		name         string
+		authToken    *string
+		apiKey       *string
+		scope        entity.APIKeyScope
+		expectedUser entity.User
+		expectedErr  error
+	}{
+		{
+			name:        "no credential",
+			scope:       entity.APIKeyScopeReadOnly,
+			expectedErr: ErrInvalidAuthToken{},
+		},
+		{
+			name:         "auth token",
+			authToken:    &authToken,
+			scope:        entity.APIKeyScopeManageLinks,
+			expectedUser: user,
+		},
+		{
+			name:         "read only key reads",
+			apiKey:       &readOnlyKey,
+			scope:        entity.APIKeyScopeReadOnly,
+			expectedUser: user,
+		},
+		{
+			name:        "read only key creates links",
+			apiKey:      &readOnlyKey,
+			scope:       entity.APIKeyScopeCreateLinks,
+			expectedErr: ErrInsufficientScope("CREATE_LINKS"),
+		},
+		{
+			name:         "key creates links",
+			apiKey:       &creatorKey,
+			scope:        entity.APIKeyScopeCreateLinks,
+			expectedUser: user,
+		},
+		{
+			name:        "invalid key takes precedence over auth token",
+			authToken:   &authToken,
+			apiKey:      &invalidKey,
+			scope:       entity.APIKeyScopeReadOnly,
+			expectedErr: ErrInvalidAPIKey{},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			cred := newCredential(testCase.authToken, testCase.apiKey, authenticator, apiKeyManager)
+			viewerUser, err := cred.viewer(context.Background(), testCase.scope)
+			if testCase.expectedErr != nil {
+				mdtest.Equal(t, testCase.expectedErr, newViewerError(err))
+				return
+			}
+			mdtest.Equal(t, nil, err)
+			mdtest.Equal(t, testCase.expectedUser.Email, viewerUser.Email)
+		})
+	}
+}
+
+func TestCredential_SignedInViewer(t *testing.T) {
+	now := time.Now()
+	user := entity.User{Email: "alpha@example.com"}
+
+	authenticator := auth.NewAuthenticatorFake(now, time.Hour)
+	authToken, err := authenticator.GenerateToken(user)
+	mdtest.Equal(t, nil, err)
+
+	apiKeyRepo := repository.NewAPIKeyFake()
+	apiKeyManager := apikey.NewManager(&apiKeyRepo, mdtest.NewTimerFake(now))
+	_, key, err := apiKeyManager.CreateKey(context.Background(), user, "ci", nil)
+	mdtest.Equal(t, nil, err)
+
+	testCases := []struct {
+		name      string
+		authToken *string
+		apiKey    *string
+		hasErr    bool
+	}{
+		{
+			name:      "auth token",
+			authToken: &authToken,
+			hasErr:    false,
+		},
+		{
+			name:   "api key",
+			apiKey: &key,
+			hasErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			cred := newCredential(testCase.authToken, testCase.apiKey, authenticator, apiKeyManager)
+			viewerUser, err := cred.signedInViewer()
+			if testCase.hasErr {
+				mdtest.NotEqual(t, nil, err)
+				return
+			}
+			mdtest.Equal(t, nil, err)
+			mdtest.Equal(t, user.Email, viewerUser.Email)
+		})
+	}
+}
@@ -7,17 +7,22 @@ schema {
 }
 
 type Query {
-	authQuery(authToken: String): AuthQuery
+	authQuery(authToken: String, apiKey: String): AuthQuery
 }
 
 type Mutation {
-	authMutation(authToken: String, captchaResponse: String!): AuthMutation
+	authMutation(authToken: String, apiKey: String, captchaResponse: String): AuthMutation
 }
 
 type AuthQuery {
-	URL(alias: String!, expireAfter: Time): URL
+	URL(alias: String!, domain: String, expireAfter: Time): URL
 	changeLog: ChangeLog!
-	URLs: [URL!]!
+	urls(first: Int!, after: String, orderBy: URLOrder, search: String, isPublic: Boolean): URLConnection!
+	publicURLs(first: Int!, after: String): URLConnection!
+	apiKeys: [APIKey!]!
+	redirectRules(alias: String!, domain: String): [RedirectRule!]!
+	urlPreview(alias: String!, domain: String): URLPreview
+	domains: [Domain!]!
 }
 
 type ChangeLog {
@@ -34,13 +39,56 @@ type Change {
 
 type AuthMutation {
 	createURL(url: URLInput!, isPublic: Boolean!): URL
+	createURLs(urls: [URLInput!]!): [CreateURLResult!]!
+	updateURL(alias: String!, domain: String, patch: URLPatch!): URL
+	deleteURL(alias: String!, domain: String): Boolean!
 	createChange(change: ChangeInput!): Change!
+	createAPIKey(name: String!, scopes: [APIKeyScope!]!): CreatedAPIKey
+	revokeAPIKey(id: String!): Boolean!
+	updateRedirectRules(alias: String!, domain: String, rules: [RedirectRuleInput!]!): [RedirectRule!]!
+	registerDomain(hostname: String!): Domain
+	verifyDomain(hostname: String!, challenge: DomainChallenge!): Domain
 }
 
 input URLInput {
 	originalURL: String!
+	domain: String
 	customAlias: String
 	expireAt: Time
+	activateAt: Time
+	password: String
+	maxClicks: Int
+	forwardQuery: Boolean
+	forwardPath: Boolean
+	utm: UTMInput
+	title: String
+	redirectStatus: Int
+}
+
+type CreateURLResult {
+	url: URL
+	errorCode: String
+}
+
+input URLPatch {
+	originalURL: String
+	expireAt: Time
+	activateAt: Time
+	isPublic: Boolean
+	forwardQuery: Boolean
+	forwardPath: Boolean
+	utm: UTMInput
+	title: String
+	redirectStatus: Int
+	clear: [URLPatchField!]
+}
+
+enum URLPatchField {
+	EXPIRE_AT
+	ACTIVATE_AT
+	UTM
+	TITLE
+	REDIRECT_STATUS
 }
 
 input ChangeInput {
@@ -49,9 +97,149 @@ input ChangeInput {
 }
 
 type URL {
+	domain: String
 	alias: String
 	originalURL: String
 	expireAt: Time
+	activateAt: Time
+	isPasswordProtected: Boolean!
+	maxClicks: Int
+	remainingClicks: Int
+	forwardQuery: Boolean!
+	forwardPath: Boolean!
+	utm: UTM
+	title: String
+	redirectStatus: Int
+	clickCount: Int
+	clicksByDay(from: Time!, to: Time!): [DailyClicks!]
+	topReferrers(limit: Int!): [ReferrerClicks!]
+	deviceBreakdown: [DeviceClicks!]
+}
+
+type URLPreview {
+	domain: String
+	alias: String!
+	longLink: String
+	title: String
+	ownerName: String
+	isProtected: Boolean!
+}
+
+type UTM {
+	source: String
+	medium: String
+	campaign: String
+	term: String
+	content: String
+}
+
+input UTMInput {
+	source: String
+	medium: String
+	campaign: String
+	term: String
+	content: String
+}
+
+input URLOrder {
+	field: URLOrderField!
+	direction: OrderDirection!
+}
+
+enum URLOrderField {
+	CREATED_AT
+	EXPIRE_AT
+	ALIAS
+}
+
+enum OrderDirection {
+	ASC
+	DESC
+}
+
+type URLConnection {
+	edges: [URLEdge!]!
+	pageInfo: PageInfo!
+}
+
+type URLEdge {
+	node: URL!
+	cursor: String!
+}
+
+type PageInfo {
+	hasNextPage: Boolean!
+	endCursor: String
+}
+
+type APIKey {
+	id: String!
+	name: String!
+	scopes: [APIKeyScope!]!
+	createdAt: Time!
+}
+
+type CreatedAPIKey {
+	apiKey: APIKey!
+	key: String!
+}
+
+enum APIKeyScope {
+	READ_ONLY
+	CREATE_LINKS
+	MANAGE_LINKS
+}
+
+type RedirectRule {
+	condition: RedirectCondition!
+	values: [String!]!
+	weight: Int!
+	longLink: String!
+}
+
+input RedirectRuleInput {
+	condition: RedirectCondition!
+	values: [String!]
+	weight: Int
+	longLink: String!
+}
+
+enum RedirectCondition {
+	LANGUAGE
+	DEVICE
+	COUNTRY
+	SPLIT
+}
+
+type Domain {
+	hostname: String!
+	verificationToken: String!
+	dnsRecordName: String!
+	dnsRecordValue: String!
+	httpFileURL: String!
+	isVerified: Boolean!
+	verifiedAt: Time
+	createdAt: Time
+}
+
+enum DomainChallenge {
+	DNS
+	HTTP
+}
+
+type DailyClicks {
+	day: Time!
+	count: Int!
+}
+
+type ReferrerClicks {
+	referrer: String!
+	count: Int!
+}
+
+type DeviceClicks {
+	device: String!
+	count: Int!
 }
 
 scalar Time
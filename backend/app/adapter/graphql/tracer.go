@@ -0,0 +1,61 @@
+package graphql
+
+import (
+	"context"
+
+	"github.com/graph-gophers/graphql-go/errors"
+	"github.com/graph-gophers/graphql-go/introspection"
+	"github.com/graph-gophers/graphql-go/trace"
+	"github.com/short-d/app/fw"
+	"github.com/short-d/short/app/usecase/service"
+)
+
+var _ trace.Tracer = (*LatencyTracer)(nil)
+
+// LatencyTracer measures how long each GraphQL field takes to resolve, in
+// seconds, labeled by the type and the name of the field. Fields which are
+// read directly from structs are not measured.
+type LatencyTracer struct {
+	latency service.Histogram
+	timer   fw.Timer
+}
+
+// TraceQuery leaves queries untraced since their latency is the sum of the
+// fields resolved.
+func (l LatencyTracer) TraceQuery(
+	ctx context.Context,
+	queryString string,
+	operationName string,
+	variables map[string]interface{},
+	varTypes map[string]*introspection.Type,
+) (context.Context, trace.TraceQueryFinishFunc) {
+	return ctx, func([]*errors.QueryError) {}
+}
+
+// TraceField starts timing a field, recording the latency once the field is
+// resolved.
+func (l LatencyTracer) TraceField(
+	ctx context.Context,
+	label string,
+	typeName string,
+	fieldName string,
+	trivial bool,
+	args map[string]interface{},
+) (context.Context, trace.TraceFieldFinishFunc) {
+	if trivial {
+		return ctx, func(*errors.QueryError) {}
+	}
+
+	startAt := l.timer.Now()
+	return ctx, func(*errors.QueryError) {
+		l.latency.Observe(l.timer.Now().Sub(startAt).Seconds(), typeName, fieldName)
+	}
+}
+
+// NewLatencyTracer creates LatencyTracer
+func NewLatencyTracer(latency service.Histogram, timer fw.Timer) LatencyTracer {
+	return LatencyTracer{
+		latency: latency,
+		timer:   timer,
+	}
+}
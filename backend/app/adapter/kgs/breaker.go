@@ -0,0 +1,94 @@
+package kgs
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/short-d/app/fw"
+)
+
+// ErrCircuitOpen represents the failure of calling key generation service
+// while the circuit breaker is open, failing fast until the given time.
+type ErrCircuitOpen time.Time
+
+func (e ErrCircuitOpen) Error() string {
+	return fmt.Sprintf("key generation service unavailable, circuit open until %s", time.Time(e).Format(time.RFC3339))
+}
+
+// CircuitBreakerConfig includes how many consecutive failed calls open the
+// circuit and how long the circuit stays open before a call is let through to
+// probe key generation service again.
+type CircuitBreakerConfig struct {
+	FailureThreshold int
+	OpenDuration     time.Duration
+}
+
+// circuitBreaker stops calls to key generation service after too many
+// consecutive failures. Once OpenDuration elapses, the circuit is half open:
+// a single call probes the service, closing the circuit when it succeeds and
+// opening it again when it fails.
+type circuitBreaker struct {
+	mutex            *sync.Mutex
+	timer            fw.Timer
+	failureThreshold int
+	openDuration     time.Duration
+	failures         int
+	openUntil        time.Time
+	isProbing        bool
+}
+
+// allow fails with ErrCircuitOpen unless the call can go through.
+func (c *circuitBreaker) allow() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.failures < c.failureThreshold {
+		return nil
+	}
+	if c.isProbing || c.timer.Now().Before(c.openUntil) {
+		return ErrCircuitOpen(c.openUntil)
+	}
+	c.isProbing = true
+	return nil
+}
+
+// succeed closes the circuit.
+func (c *circuitBreaker) succeed() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.failures = 0
+	c.isProbing = false
+}
+
+// fail opens the circuit once failureThreshold is reached, or again after a
+// failed probe.
+func (c *circuitBreaker) fail() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.failures++
+	c.isProbing = false
+	if c.failures >= c.failureThreshold {
+		c.openUntil = c.timer.Now().Add(c.openDuration)
+	}
+}
+
+// release lets another call probe the service when the caller gave up
+// before the service answered, which says nothing about its health.
+func (c *circuitBreaker) release() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.isProbing = false
+}
+
+func newCircuitBreaker(config CircuitBreakerConfig, timer fw.Timer) *circuitBreaker {
+	return &circuitBreaker{
+		mutex:            &sync.Mutex{},
+		timer:            timer,
+		failureThreshold: config.FailureThreshold,
+		openDuration:     config.OpenDuration,
+	}
+}
@@ -2,29 +2,95 @@ package kgs
 
 import (
 	"context"
-	"crypto/tls"
+	"errors"
 	"fmt"
+	"time"
 
+	"github.com/short-d/app/fw"
 	"github.com/short-d/kgs/app/adapter/rpc/proto"
 	"github.com/short-d/short/app/usecase/service"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials"
 )
 
 var _ service.KeyFetcher = (*RPC)(nil)
 
+// Config includes the address of key generation service, how the connection
+// to it is secured, and how calls to it are bounded, retried and cut off
+// while it is down.
+type Config struct {
+	Hostname       string
+	Port           int
+	TLS            TLSConfig
+	CallTimeout    time.Duration
+	Retry          RetryConfig
+	CircuitBreaker CircuitBreakerConfig
+}
+
 // RPC represents remote procedure calls which interact with key generation
 // service.
 type RPC struct {
-	gRPCClient proto.KeyGenClient
+	connection  *grpc.ClientConn
+	gRPCClient  proto.KeyGenClient
+	callTimeout time.Duration
+	retry       RetryConfig
+	breaker     *circuitBreaker
+}
+
+// FetchKeys retrieves keys in batch from key generation service. Each attempt
+// is bounded by the call timeout, and attempts failing with transient errors
+// are retried with exponential backoff. Calls fail fast with ErrCircuitOpen
+// while key generation service is considered down.
+func (k RPC) FetchKeys(ctx context.Context, maxCount int) ([]service.Key, error) {
+	err := k.breaker.allow()
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := k.allocateKeys(ctx, maxCount)
+	switch {
+	case err == nil:
+		k.breaker.succeed()
+	case ctx.Err() != nil:
+		k.breaker.release()
+	default:
+		k.breaker.fail()
+	}
+	return keys, err
 }
 
-// FetchKeys retrieves keys in batch from key generation service.
-func (k RPC) FetchKeys(maxCount int) ([]service.Key, error) {
+// allocateKeys retries allocating keys until an attempt succeeds, fails with
+// a permanent error, or the attempts run out. Keys allocated by an attempt
+// which timed out before its response arrived are lost, but never handed out
+// twice.
+func (k RPC) allocateKeys(ctx context.Context, maxCount int) ([]service.Key, error) {
+	backoff := k.retry.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		keys, err := k.allocateKeysOnce(ctx, maxCount)
+		if err == nil {
+			return keys, nil
+		}
+		if attempt >= k.retry.MaxAttempts || !isRetryable(err) || ctx.Err() != nil {
+			return nil, err
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		}
+		backoff = k.retry.nextBackoff(backoff)
+	}
+}
+
+func (k RPC) allocateKeysOnce(ctx context.Context, maxCount int) ([]service.Key, error) {
+	ctx, cancel := context.WithTimeout(ctx, k.callTimeout)
+	defer cancel()
+
 	req := proto.AllocateKeysRequest{
 		MaxKeyCount: uint32(maxCount),
 	}
-	ctx := context.Background()
 	res, err := k.gRPCClient.AllocateKeys(ctx, &req)
 
 	if err != nil {
@@ -38,20 +104,48 @@ func (k RPC) FetchKeys(maxCount int) ([]service.Key, error) {
 	return keys, nil
 }
 
+// Close tears down the connection to key generation service.
+func (k RPC) Close() error {
+	return k.connection.Close()
+}
+
 // NewRPC initializes GRPC client for key generation service APIs.
-func NewRPC(hostname string, port int) (RPC, error) {
-	target := fmt.Sprintf("%s:%d", hostname, port)
+func NewRPC(config Config, timer fw.Timer) (RPC, error) {
+	target := fmt.Sprintf("%s:%d", config.Hostname, config.Port)
+	return newRPC(target, config, timer)
+}
 
-	config := &tls.Config{
-		InsecureSkipVerify: true,
+func newRPC(
+	target string,
+	config Config,
+	timer fw.Timer,
+	dialOptions ...grpc.DialOption,
+) (RPC, error) {
+	if config.CallTimeout <= 0 {
+		return RPC{}, errors.New("call timeout must be positive")
+	}
+	if config.Retry.MaxAttempts < 1 {
+		return RPC{}, errors.New("max attempts can't be less than 1")
+	}
+	if config.CircuitBreaker.FailureThreshold < 1 {
+		return RPC{}, errors.New("circuit breaker failure threshold can't be less than 1")
+	}
+
+	credentials, err := config.TLS.dialOption()
+	if err != nil {
+		return RPC{}, err
 	}
 
-	gRPCTls := credentials.NewTLS(config)
-	gRPCCredentials := grpc.WithTransportCredentials(gRPCTls)
-	connection, err := grpc.Dial(target, gRPCCredentials)
+	connection, err := grpc.Dial(target, append(dialOptions, credentials)...)
 	if err != nil {
 		return RPC{}, err
 	}
 	gRPCClient := proto.NewKeyGenClient(connection)
-	return RPC{gRPCClient: gRPCClient}, nil
+	return RPC{
+		connection:  connection,
+		gRPCClient:  gRPCClient,
+		callTimeout: config.CallTimeout,
+		retry:       config.Retry,
+		breaker:     newCircuitBreaker(config.CircuitBreaker, timer),
+	}, nil
 }
@@ -0,0 +1,458 @@
+// +build !integration all
+
+package kgs
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/mdtest"
+	"github.com/short-d/kgs/app/adapter/rpc/proto"
+	"github.com/short-d/short/app/usecase/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const serverName = "kgs.test"
+
+// step represents how the stand-in key generation service answers a call.
+type step struct {
+	delay time.Duration
+	code  codes.Code
+}
+
+// keyGenServerStub answers calls following steps in order, repeating the last
+// step once they run out.
+type keyGenServerStub struct {
+	proto.UnimplementedKeyGenServer
+	mutex *sync.Mutex
+	steps []step
+	calls int
+}
+
+func (k *keyGenServerStub) AllocateKeys(
+	ctx context.Context,
+	req *proto.AllocateKeysRequest,
+) (*proto.AllocateKeysResponse, error) {
+	k.mutex.Lock()
+	current := k.steps[len(k.steps)-1]
+	if k.calls < len(k.steps) {
+		current = k.steps[k.calls]
+	}
+	k.calls++
+	k.mutex.Unlock()
+
+	select {
+	case <-time.After(current.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	if current.code != codes.OK {
+		return nil, status.Error(current.code, current.code.String())
+	}
+	keys := make([]string, 0, req.MaxKeyCount)
+	for idx := 0; idx < int(req.MaxKeyCount); idx++ {
+		keys = append(keys, string(rune('A'+idx)))
+	}
+	return &proto.AllocateKeysResponse{Keys: keys}, nil
+}
+
+func (k *keyGenServerStub) callCount() int {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+	return k.calls
+}
+
+func (k *keyGenServerStub) setSteps(steps []step) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+	k.steps = steps
+}
+
+func newKeyGenServerStub(steps []step) *keyGenServerStub {
+	return &keyGenServerStub{mutex: &sync.Mutex{}, steps: steps}
+}
+
+// startKeyGenServer serves keyGenServer in process, returning the option
+// dialing it and the function stopping it.
+func startKeyGenServer(keyGenServer proto.KeyGenServer, options ...grpc.ServerOption) (grpc.DialOption, func()) {
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer(options...)
+	proto.RegisterKeyGenServer(server, keyGenServer)
+	go server.Serve(listener)
+
+	dialer := grpc.WithContextDialer(func(ctx context.Context, target string) (net.Conn, error) {
+		return listener.Dial()
+	})
+	return dialer, server.Stop
+}
+
+func newTestConfig() Config {
+	return Config{
+		TLS:         TLSConfig{Plaintext: true},
+		CallTimeout: 100 * time.Millisecond,
+		Retry: RetryConfig{
+			MaxAttempts:    3,
+			InitialBackoff: time.Millisecond,
+			MaxBackoff:     4 * time.Millisecond,
+		},
+		CircuitBreaker: CircuitBreakerConfig{
+			FailureThreshold: 5,
+			OpenDuration:     time.Minute,
+		},
+	}
+}
+
+func TestNewRPC(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name   string
+		config func(config Config) Config
+	}{
+		{
+			name: "no call timeout",
+			config: func(config Config) Config {
+				config.CallTimeout = 0
+				return config
+			},
+		},
+		{
+			name: "no attempt",
+			config: func(config Config) Config {
+				config.Retry.MaxAttempts = 0
+				return config
+			},
+		},
+		{
+			name: "no failure threshold",
+			config: func(config Config) Config {
+				config.CircuitBreaker.FailureThreshold = 0
+				return config
+			},
+		},
+		{
+			name: "client certificate without key",
+			config: func(config Config) Config {
+				config.TLS = TLSConfig{ClientCertFile: "client.crt"}
+				return config
+			},
+		},
+		{
+			name: "CA file missing",
+			config: func(config Config) Config {
+				config.TLS = TLSConfig{CACertFile: "missing.crt"}
+				return config
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			timer := mdtest.NewTimerFake(time.Now())
+			_, err := NewRPC(testCase.config(newTestConfig()), timer)
+			mdtest.NotEqual(t, nil, err)
+		})
+	}
+}
+
+func TestRPC_FetchKeys(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		steps         []step
+		hasErr        bool
+		expectedKeys  []service.Key
+		expectedCalls int
+	}{
+		{
+			name:          "keys allocated",
+			steps:         []step{{code: codes.OK}},
+			expectedKeys:  []service.Key{"A", "B"},
+			expectedCalls: 1,
+		},
+		{
+			name: "transient failures retried",
+			steps: []step{
+				{code: codes.Unavailable},
+				{code: codes.ResourceExhausted},
+				{code: codes.OK},
+			},
+			expectedKeys:  []service.Key{"A", "B"},
+			expectedCalls: 3,
+		},
+		{
+			name: "slow call retried",
+			steps: []step{
+				{delay: time.Second, code: codes.OK},
+				{code: codes.OK},
+			},
+			expectedKeys:  []service.Key{"A", "B"},
+			expectedCalls: 2,
+		},
+		{
+			name:          "attempts run out",
+			steps:         []step{{code: codes.Unavailable}},
+			hasErr:        true,
+			expectedCalls: 3,
+		},
+		{
+			name:          "permanent failure not retried",
+			steps:         []step{{code: codes.InvalidArgument}},
+			hasErr:        true,
+			expectedCalls: 1,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			keyGenServer := newKeyGenServerStub(testCase.steps)
+			dialer, stop := startKeyGenServer(keyGenServer)
+			defer stop()
+			timer := mdtest.NewTimerFake(time.Now())
+			rpc, err := newRPC("bufnet", newTestConfig(), timer, dialer)
+			mdtest.Equal(t, nil, err)
+			defer rpc.Close()
+
+			keys, err := rpc.FetchKeys(context.Background(), 2)
+			mdtest.Equal(t, testCase.expectedCalls, keyGenServer.callCount())
+			if testCase.hasErr {
+				mdtest.NotEqual(t, nil, err)
+				return
+			}
+			mdtest.Equal(t, nil, err)
+			mdtest.Equal(t, testCase.expectedKeys, keys)
+		})
+	}
+}
+
+func TestRPC_FetchKeysContextDone(t *testing.T) {
+	t.Parallel()
+
+	keyGenServer := newKeyGenServerStub([]step{{delay: time.Second, code: codes.OK}})
+	dialer, stop := startKeyGenServer(keyGenServer)
+	defer stop()
+	timer := mdtest.NewTimerFake(time.Now())
+	rpc, err := newRPC("bufnet", newTestConfig(), timer, dialer)
+	mdtest.Equal(t, nil, err)
+	defer rpc.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err = rpc.FetchKeys(ctx, 2)
+	mdtest.NotEqual(t, nil, err)
+	mdtest.Equal(t, 1, keyGenServer.callCount())
+}
+
+func TestRPC_CircuitBreaker(t *testing.T) {
+	t.Parallel()
+
+	keyGenServer := newKeyGenServerStub([]step{{code: codes.Unavailable}})
+	dialer, stop := startKeyGenServer(keyGenServer)
+	defer stop()
+
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	timer := mdtest.NewTimerFake(now)
+	config := newTestConfig()
+	config.Retry.MaxAttempts = 1
+	config.CircuitBreaker.FailureThreshold = 2
+	rpc, err := newRPC("bufnet", config, &timer, dialer)
+	mdtest.Equal(t, nil, err)
+	defer rpc.Close()
+
+	for idx := 0; idx < 2; idx++ {
+		_, err = rpc.FetchKeys(context.Background(), 2)
+		mdtest.Equal(t, codes.Unavailable, status.Code(err))
+	}
+
+	_, err = rpc.FetchKeys(context.Background(), 2)
+	mdtest.Equal(t, ErrCircuitOpen(now.Add(time.Minute)), err)
+	mdtest.Equal(t, 2, keyGenServer.callCount())
+
+	timer.CurrentTime = now.Add(time.Minute)
+	_, err = rpc.FetchKeys(context.Background(), 2)
+	mdtest.Equal(t, codes.Unavailable, status.Code(err))
+	mdtest.Equal(t, 3, keyGenServer.callCount())
+
+	_, err = rpc.FetchKeys(context.Background(), 2)
+	mdtest.Equal(t, ErrCircuitOpen(now.Add(2*time.Minute)), err)
+
+	keyGenServer.setSteps([]step{{code: codes.OK}})
+	timer.CurrentTime = now.Add(2 * time.Minute)
+	keys, err := rpc.FetchKeys(context.Background(), 2)
+	mdtest.Equal(t, nil, err)
+	mdtest.Equal(t, []service.Key{"A", "B"}, keys)
+
+	keys, err = rpc.FetchKeys(context.Background(), 2)
+	mdtest.Equal(t, nil, err)
+	mdtest.Equal(t, []service.Key{"A", "B"}, keys)
+	mdtest.Equal(t, 5, keyGenServer.callCount())
+}
+
+func TestRPC_MutualTLS(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "kgs")
+	mdtest.Equal(t, nil, err)
+	defer os.RemoveAll(dir)
+
+	caCert, caKey := newCertificate(t, nil, nil, "ca")
+	serverCert, serverKey := newCertificate(t, caCert, caKey, serverName)
+	clientCert, clientKey := newCertificate(t, caCert, caKey, "short")
+	otherCACert, _ := newCertificate(t, nil, nil, "other-ca")
+
+	caFile := writePEM(t, dir, "ca.crt", "CERTIFICATE", caCert.Raw)
+	otherCAFile := writePEM(t, dir, "other-ca.crt", "CERTIFICATE", otherCACert.Raw)
+	clientCertFile := writePEM(t, dir, "client.crt", "CERTIFICATE", clientCert.Raw)
+	clientKeyFile := writePEM(t, dir, "client.key", "EC PRIVATE KEY", marshalKey(t, clientKey))
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(caCert)
+	serverTLS := &tls.Config{
+		Certificates: []tls.Certificate{{
+			Certificate: [][]byte{serverCert.Raw},
+			PrivateKey:  serverKey,
+		}},
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  clientCAs,
+	}
+
+	testCases := []struct {
+		name      string
+		tlsConfig TLSConfig
+		hasErr    bool
+	}{
+		{
+			name: "client certificate presented",
+			tlsConfig: TLSConfig{
+				CACertFile:     caFile,
+				ClientCertFile: clientCertFile,
+				ClientKeyFile:  clientKeyFile,
+				ServerName:     serverName,
+			},
+		},
+		{
+			name: "client certificate missing",
+			tlsConfig: TLSConfig{
+				CACertFile: caFile,
+				ServerName: serverName,
+			},
+			hasErr: true,
+		},
+		{
+			name: "server certificate signed by unknown CA",
+			tlsConfig: TLSConfig{
+				CACertFile:     otherCAFile,
+				ClientCertFile: clientCertFile,
+				ClientKeyFile:  clientKeyFile,
+				ServerName:     serverName,
+			},
+			hasErr: true,
+		},
+		{
+			name:      "plaintext",
+			tlsConfig: TLSConfig{Plaintext: true},
+			hasErr:    true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		// The certificates are removed once the test returns, so the cases
+		// don't run in parallel.
+		t.Run(testCase.name, func(t *testing.T) {
+			keyGenServer := newKeyGenServerStub([]step{{code: codes.OK}})
+			dialer, stop := startKeyGenServer(keyGenServer, grpc.Creds(credentials.NewTLS(serverTLS)))
+			defer stop()
+			timer := mdtest.NewTimerFake(time.Now())
+			config := newTestConfig()
+			config.TLS = testCase.tlsConfig
+			config.Retry.MaxAttempts = 1
+			rpc, err := newRPC("bufnet", config, timer, dialer)
+			mdtest.Equal(t, nil, err)
+			defer rpc.Close()
+
+			keys, err := rpc.FetchKeys(context.Background(), 2)
+			if testCase.hasErr {
+				mdtest.NotEqual(t, nil, err)
+				return
+			}
+			mdtest.Equal(t, nil, err)
+			mdtest.Equal(t, []service.Key{"A", "B"}, keys)
+		})
+	}
+}
+
+// newCertificate creates a certificate for commonName signed by parent, or a
+// self-signed CA certificate when parent is nil.
+func newCertificate(
+	t *testing.T,
+	parent *x509.Certificate,
+	parentKey *ecdsa.PrivateKey,
+	commonName string,
+) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	mdtest.Equal(t, nil, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     []string{commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		parent = template
+		parentKey = key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	mdtest.Equal(t, nil, err)
+	cert, err := x509.ParseCertificate(der)
+	mdtest.Equal(t, nil, err)
+	return cert, key
+}
+
+func marshalKey(t *testing.T, key *ecdsa.PrivateKey) []byte {
+	der, err := x509.MarshalECPrivateKey(key)
+	mdtest.Equal(t, nil, err)
+	return der
+}
+
+func writePEM(t *testing.T, dir string, name string, blockType string, der []byte) string {
+	path := filepath.Join(dir, name)
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	err := ioutil.WriteFile(path, data, 0600)
+	mdtest.Equal(t, nil, err)
+	return path
+}
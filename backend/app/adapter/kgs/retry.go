@@ -0,0 +1,35 @@
+package kgs
+
+import (
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RetryConfig includes how many times a call to key generation service is
+// attempted, and how long to wait before the first retry. The wait doubles
+// after each retry, up to MaxBackoff.
+type RetryConfig struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+func (r RetryConfig) nextBackoff(backoff time.Duration) time.Duration {
+	backoff *= 2
+	if backoff > r.MaxBackoff {
+		return r.MaxBackoff
+	}
+	return backoff
+}
+
+// isRetryable reports whether a failed call may succeed when attempted again.
+func isRetryable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Aborted:
+		return true
+	default:
+		return false
+	}
+}
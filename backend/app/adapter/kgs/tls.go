@@ -0,0 +1,65 @@
+package kgs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// TLSConfig includes the certificates securing the connection to key
+// generation service. The certificate of the service is verified against
+// CACertFile, or against the system roots when it is empty. The client
+// presents ClientCertFile when both it and ClientKeyFile are set, enabling
+// mutual TLS. Plaintext disables TLS altogether, for local development only.
+type TLSConfig struct {
+	Plaintext      bool
+	CACertFile     string
+	ClientCertFile string
+	ClientKeyFile  string
+	ServerName     string
+}
+
+func (t TLSConfig) dialOption() (grpc.DialOption, error) {
+	if t.Plaintext {
+		return grpc.WithInsecure(), nil
+	}
+
+	config, err := t.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+	return grpc.WithTransportCredentials(credentials.NewTLS(config)), nil
+}
+
+func (t TLSConfig) tlsConfig() (*tls.Config, error) {
+	config := &tls.Config{ServerName: t.ServerName}
+
+	if t.CACertFile != "" {
+		caCert, err := ioutil.ReadFile(t.CACertFile)
+		if err != nil {
+			return nil, err
+		}
+		rootCAs := x509.NewCertPool()
+		if !rootCAs.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("no certificate found in CA file (file=%s)", t.CACertFile)
+		}
+		config.RootCAs = rootCAs
+	}
+
+	if (t.ClientCertFile == "") != (t.ClientKeyFile == "") {
+		return nil, errors.New("client certificate and key must be provided together")
+	}
+	if t.ClientCertFile != "" {
+		clientCert, err := tls.LoadX509KeyPair(t.ClientCertFile, t.ClientKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{clientCert}
+	}
+	return config, nil
+}
@@ -0,0 +1,74 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/short-d/short/app/entity"
+	"github.com/short-d/short/app/usecase/repository"
+)
+
+var _ repository.Domain = (*CachedDomain)(nil)
+
+// CachedDomain reads custom domains by hostname through URLCache before
+// falling back to another Domain repository, such as the database, so that
+// resolving the host of every redirect doesn't query the storage.
+type CachedDomain struct {
+	domainRepo repository.Domain
+	cache      URLCache
+}
+
+// Create stores a new domain in the underlying repository.
+func (c CachedDomain) Create(ctx context.Context, domain entity.Domain) error {
+	defer c.cache.invalidate(domainKey(domain.Hostname))
+	return c.domainRepo.Create(ctx, domain)
+}
+
+// GetByHostname finds the domain with the given hostname, remembering the
+// result for subsequent look ups.
+func (c CachedDomain) GetByHostname(ctx context.Context, hostname string) (entity.Domain, error) {
+	entry, ok := c.cache.get(domainKey(hostname))
+	if ok {
+		if entry.isMissing {
+			return entity.Domain{}, repository.ErrDomainNotFound(hostname)
+		}
+		return entry.domain, nil
+	}
+
+	domain, err := c.domainRepo.GetByHostname(ctx, hostname)
+	switch err.(type) {
+	case nil:
+		c.cache.putDomain(domain)
+	case repository.ErrDomainNotFound:
+		c.cache.putMissing(domainKey(hostname))
+	}
+	return domain, err
+}
+
+// FindByOwner retrieves the domains registered by the given user from the
+// underlying repository.
+func (c CachedDomain) FindByOwner(ctx context.Context, user entity.User) ([]entity.Domain, error) {
+	return c.domainRepo.FindByOwner(ctx, user)
+}
+
+// MarkVerified records the time when the domain with the given hostname is
+// verified in the underlying repository.
+func (c CachedDomain) MarkVerified(ctx context.Context, hostname string, verifiedAt time.Time) error {
+	defer c.cache.invalidate(domainKey(hostname))
+	return c.domainRepo.MarkVerified(ctx, hostname, verifiedAt)
+}
+
+// ReplaceUnverified replaces the unverified registration of domain's hostname
+// in the underlying repository.
+func (c CachedDomain) ReplaceUnverified(ctx context.Context, domain entity.Domain, createdBefore time.Time) error {
+	defer c.cache.invalidate(domainKey(domain.Hostname))
+	return c.domainRepo.ReplaceUnverified(ctx, domain, createdBefore)
+}
+
+// NewCachedDomain creates CachedDomain
+func NewCachedDomain(domainRepo repository.Domain, cache URLCache) CachedDomain {
+	return CachedDomain{
+		domainRepo: domainRepo,
+		cache:      cache,
+	}
+}
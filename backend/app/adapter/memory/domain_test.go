@@ -0,0 +1,50 @@
+// +build !integration all
+
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/mdtest"
+	"github.com/short-d/short/app/entity"
+	"github.com/short-d/short/app/usecase/repository"
+)
+
+func TestCachedDomain_GetByHostname(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	domain := entity.Domain{
+		Hostname:          "go.example.com",
+		OwnerEmail:        "alpha@example.com",
+		VerificationToken: "token",
+		CreatedAt:         &now,
+	}
+
+	timer := mdtest.NewTimerFake(now)
+	cache := NewURLCache(10, time.Minute, time.Minute, &timer)
+	domainRepo := repository.NewDomainFake(nil)
+	cachedDomainRepo := NewCachedDomain(&domainRepo, cache)
+
+	_, err := cachedDomainRepo.GetByHostname(context.Background(), "go.example.com")
+	mdtest.Equal(t, repository.ErrDomainNotFound("go.example.com"), err)
+	_, err = cachedDomainRepo.GetByHostname(context.Background(), "go.example.com")
+	mdtest.Equal(t, repository.ErrDomainNotFound("go.example.com"), err)
+	mdtest.Equal(t, CacheStats{Hits: 1, Misses: 1, Size: 1}, cache.Stats())
+
+	err = cachedDomainRepo.Create(context.Background(), domain)
+	mdtest.Equal(t, nil, err)
+	gotDomain, err := cachedDomainRepo.GetByHostname(context.Background(), "go.example.com")
+	mdtest.Equal(t, nil, err)
+	mdtest.Equal(t, domain, gotDomain)
+	mdtest.Equal(t, CacheStats{Hits: 1, Misses: 2, Size: 1}, cache.Stats())
+
+	err = cachedDomainRepo.MarkVerified(context.Background(), "go.example.com", now)
+	mdtest.Equal(t, nil, err)
+	gotDomain, err = cachedDomainRepo.GetByHostname(context.Background(), "go.example.com")
+	mdtest.Equal(t, nil, err)
+	mdtest.Equal(t, true, gotDomain.IsVerified())
+	mdtest.Equal(t, CacheStats{Hits: 1, Misses: 3, Size: 1}, cache.Stats())
+}
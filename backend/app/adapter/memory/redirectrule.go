@@ -0,0 +1,51 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/short-d/short/app/entity"
+	"github.com/short-d/short/app/usecase/repository"
+)
+
+var _ repository.RedirectRule = (*CachedRedirectRule)(nil)
+
+// CachedRedirectRule reads the redirect rules of short links through URLCache
+// before falling back to another RedirectRule repository, such as the
+// database, so that redirecting doesn't query the storage for rules on every
+// visit.
+type CachedRedirectRule struct {
+	ruleRepo repository.RedirectRule
+	cache    URLCache
+}
+
+// FindByAlias fetches the redirect rules of the short link with the given
+// alias in evaluation order, remembering them for subsequent look ups.
+func (c CachedRedirectRule) FindByAlias(ctx context.Context, domain string, alias string) ([]entity.RedirectRule, error) {
+	key := repository.URLKey{Domain: domain, Alias: alias}
+	entry, ok := c.cache.get(redirectRuleKey(key))
+	if ok {
+		return append([]entity.RedirectRule{}, entry.rules...), nil
+	}
+
+	rules, err := c.ruleRepo.FindByAlias(ctx, domain, alias)
+	if err != nil {
+		return nil, err
+	}
+	c.cache.putRules(key, append([]entity.RedirectRule{}, rules...))
+	return rules, nil
+}
+
+// ReplaceRules replaces all redirect rules of the short link with the given
+// alias in the underlying repository.
+func (c CachedRedirectRule) ReplaceRules(ctx context.Context, domain string, alias string, rules []entity.RedirectRule) error {
+	defer c.cache.invalidate(redirectRuleKey(repository.URLKey{Domain: domain, Alias: alias}))
+	return c.ruleRepo.ReplaceRules(ctx, domain, alias, rules)
+}
+
+// NewCachedRedirectRule creates CachedRedirectRule
+func NewCachedRedirectRule(ruleRepo repository.RedirectRule, cache URLCache) CachedRedirectRule {
+	return CachedRedirectRule{
+		ruleRepo: ruleRepo,
+		cache:    cache,
+	}
+}
@@ -0,0 +1,62 @@
+// +build !integration all
+
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/mdtest"
+	"github.com/short-d/short/app/entity"
+	"github.com/short-d/short/app/usecase/repository"
+)
+
+func TestCachedRedirectRule_FindByAlias(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	frenchRules := []entity.RedirectRule{
+		{
+			Condition: entity.RedirectConditionLanguage,
+			Values:    []string{"fr"},
+			LongLink:  "https://www.google.fr",
+		},
+	}
+	mobileRules := []entity.RedirectRule{
+		{
+			Condition: entity.RedirectConditionDevice,
+			Values:    []string{"mobile"},
+			LongLink:  "https://m.google.com",
+		},
+	}
+	google := entity.URL{Alias: "google", OriginalURL: "https://www.google.com"}
+
+	timer := mdtest.NewTimerFake(now)
+	cache := NewURLCache(10, time.Minute, 0, &timer)
+	ruleRepo := repository.NewRedirectRuleFake(map[string][]entity.RedirectRule{
+		"google": frenchRules,
+	})
+	cachedRuleRepo := NewCachedRedirectRule(&ruleRepo, cache)
+
+	rules, err := cachedRuleRepo.FindByAlias(context.Background(), "", "google")
+	mdtest.Equal(t, nil, err)
+	mdtest.Equal(t, frenchRules, rules)
+	rules, err = cachedRuleRepo.FindByAlias(context.Background(), "", "google")
+	mdtest.Equal(t, nil, err)
+	mdtest.Equal(t, frenchRules, rules)
+	mdtest.Equal(t, CacheStats{Hits: 1, Misses: 1, Size: 1}, cache.Stats())
+
+	err = cachedRuleRepo.ReplaceRules(context.Background(), "", "google", mobileRules)
+	mdtest.Equal(t, nil, err)
+	rules, err = cachedRuleRepo.FindByAlias(context.Background(), "", "google")
+	mdtest.Equal(t, nil, err)
+	mdtest.Equal(t, mobileRules, rules)
+	mdtest.Equal(t, CacheStats{Hits: 1, Misses: 2, Size: 1}, cache.Stats())
+
+	urlRepo := repository.NewURLFake(map[string]entity.URL{"google": google})
+	cachedURLRepo := NewCachedURL(&urlRepo, cache)
+	err = cachedURLRepo.Delete(context.Background(), "", "google")
+	mdtest.Equal(t, nil, err)
+	mdtest.Equal(t, CacheStats{Hits: 1, Misses: 2, Size: 0}, cache.Stats())
+}
@@ -0,0 +1,84 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/short-d/short/app/entity"
+	"github.com/short-d/short/app/usecase/repository"
+)
+
+const sweepInterval = time.Minute
+
+var _ repository.TokenBucket = (*TokenBucket)(nil)
+
+type limitedBucket struct {
+	bucket entity.TokenBucket
+	limit  entity.RateLimit
+}
+
+// TokenBucket keeps token buckets in the memory of a single instance.
+// Fully refilled buckets are evicted periodically since they are
+// indistinguishable from new ones.
+type TokenBucket struct {
+	mutex       *sync.Mutex
+	buckets     map[string]limitedBucket
+	lastSweptAt *time.Time
+}
+
+// TakeToken consumes a token from the bucket with the given key, creating a
+// full bucket if it doesn't exist.
+func (t TokenBucket) TakeToken(ctx context.Context, key string, limit entity.RateLimit, now time.Time) (bool, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.sweep(now)
+
+	limited, ok := t.buckets[key]
+	if !ok {
+		limited.bucket = entity.NewTokenBucket(limit, now)
+	}
+
+	bucket, isTaken := limited.bucket.Take(limit, now)
+	t.buckets[key] = limitedBucket{
+		bucket: bucket,
+		limit:  limit,
+	}
+	return isTaken, nil
+}
+
+// HasToken checks whether a token can be taken from the bucket with the given
+// key without consuming it.
+func (t TokenBucket) HasToken(ctx context.Context, key string, limit entity.RateLimit, now time.Time) (bool, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	limited, ok := t.buckets[key]
+	if !ok {
+		return true, nil
+	}
+	return limited.bucket.HasToken(limit, now), nil
+}
+
+func (t TokenBucket) sweep(now time.Time) {
+	if now.Sub(*t.lastSweptAt) < sweepInterval {
+		return
+	}
+
+	for key, limited := range t.buckets {
+		if limited.bucket.IsFull(limited.limit, now) {
+			delete(t.buckets, key)
+		}
+	}
+	*t.lastSweptAt = now
+}
+
+// NewTokenBucket creates in memory TokenBucket
+func NewTokenBucket() TokenBucket {
+	return TokenBucket{
+		mutex:       &sync.Mutex{},
+		buckets:     make(map[string]limitedBucket),
+		lastSweptAt: &time.Time{},
+	}
+}
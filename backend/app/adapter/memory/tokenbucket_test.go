@@ -0,0 +1,104 @@
+// +build !integration all
+
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/mdtest"
+	"github.com/short-d/short/app/entity"
+)
+
+func TestTokenBucket_TakeToken(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	limit := entity.RateLimit{Requests: 2, Period: time.Minute}
+
+	type take struct {
+		key           string
+		elapsed       time.Duration
+		expectedTaken bool
+	}
+
+	testCases := []struct {
+		name            string
+		takes           []take
+		expectedBuckets int
+	}{
+		{
+			name: "burst exceeds limit",
+			takes: []take{
+				{key: "redirect:ip:127.0.0.1", expectedTaken: true},
+				{key: "redirect:ip:127.0.0.1", expectedTaken: true},
+				{key: "redirect:ip:127.0.0.1", expectedTaken: false},
+			},
+			expectedBuckets: 1,
+		},
+		{
+			name: "tokens refill over time",
+			takes: []take{
+				{key: "redirect:ip:127.0.0.1", expectedTaken: true},
+				{key: "redirect:ip:127.0.0.1", expectedTaken: true},
+				{key: "redirect:ip:127.0.0.1", elapsed: 30 * time.Second, expectedTaken: true},
+				{key: "redirect:ip:127.0.0.1", expectedTaken: false},
+			},
+			expectedBuckets: 1,
+		},
+		{
+			name: "full buckets are evicted",
+			takes: []take{
+				{key: "redirect:ip:127.0.0.1", expectedTaken: true},
+				{key: "redirect:ip:127.0.0.2", elapsed: 50 * time.Second, expectedTaken: true},
+				{key: "redirect:ip:127.0.0.3", elapsed: 25 * time.Second, expectedTaken: true},
+			},
+			expectedBuckets: 2,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			tokenBucket := NewTokenBucket()
+
+			current := now
+			for _, take := range testCase.takes {
+				current = current.Add(take.elapsed)
+				isTaken, err := tokenBucket.TakeToken(context.Background(), take.key, limit, current)
+				mdtest.Equal(t, nil, err)
+				mdtest.Equal(t, take.expectedTaken, isTaken)
+			}
+			mdtest.Equal(t, testCase.expectedBuckets, len(tokenBucket.buckets))
+		})
+	}
+}
+
+func TestTokenBucket_HasToken(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	limit := entity.RateLimit{Requests: 1, Period: time.Minute}
+	key := "unlock-url:ip:127.0.0.1:alias:220uFicCJj"
+	tokenBucket := NewTokenBucket()
+
+	hasToken, err := tokenBucket.HasToken(context.Background(), key, limit, now)
+	mdtest.Equal(t, nil, err)
+	mdtest.Equal(t, true, hasToken)
+	mdtest.Equal(t, 0, len(tokenBucket.buckets))
+
+	isTaken, err := tokenBucket.TakeToken(context.Background(), key, limit, now)
+	mdtest.Equal(t, nil, err)
+	mdtest.Equal(t, true, isTaken)
+
+	hasToken, err = tokenBucket.HasToken(context.Background(), key, limit, now)
+	mdtest.Equal(t, nil, err)
+	mdtest.Equal(t, false, hasToken)
+
+	hasToken, err = tokenBucket.HasToken(context.Background(), key, limit, now.Add(time.Minute))
+	mdtest.Equal(t, nil, err)
+	mdtest.Equal(t, true, hasToken)
+}
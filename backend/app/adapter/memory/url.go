@@ -0,0 +1,117 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/short-d/short/app/entity"
+	"github.com/short-d/short/app/usecase/repository"
+)
+
+var _ repository.URL = (*CachedURL)(nil)
+var _ repository.URLBatch = (*CachedURLBatch)(nil)
+
+// CachedURL reads URLs through URLCache before falling back to another URL
+// repository, such as the database. Cached entries are invalidated whenever
+// the URLs change through this repository, while changes made by other
+// instances become visible once the entries expire.
+type CachedURL struct {
+	urlRepo repository.URL
+	cache   URLCache
+}
+
+// IsAliasExist checks whether a given alias exists in the underlying
+// repository, bypassing the cache so that new aliases are never reused.
+func (c CachedURL) IsAliasExist(ctx context.Context, domain string, alias string) (bool, error) {
+	return c.urlRepo.IsAliasExist(ctx, domain, alias)
+}
+
+// GetByAlias finds an URL given alias, remembering the result for subsequent
+// look ups.
+func (c CachedURL) GetByAlias(ctx context.Context, domain string, alias string) (entity.URL, error) {
+	key := repository.URLKey{Domain: domain, Alias: alias}
+	entry, ok := c.cache.get(key)
+	if ok {
+		if entry.isMissing {
+			return entity.URL{}, repository.ErrURLNotFound(alias)
+		}
+		return entry.url, nil
+	}
+
+	url, err := c.urlRepo.GetByAlias(ctx, domain, alias)
+	switch err.(type) {
+	case nil:
+		c.cache.putURL(url)
+	case repository.ErrURLNotFound:
+		c.cache.putMissing(key)
+	}
+	return url, err
+}
+
+// Create inserts a new URL into the underlying repository.
+func (c CachedURL) Create(ctx context.Context, url entity.URL) error {
+	defer c.cache.invalidate(repository.NewURLKey(url))
+	return c.urlRepo.Create(ctx, url)
+}
+
+// GetByKeys finds URLs for a list of keys in the underlying repository.
+func (c CachedURL) GetByKeys(ctx context.Context, keys []repository.URLKey) ([]entity.URL, error) {
+	return c.urlRepo.GetByKeys(ctx, keys)
+}
+
+// Update modifies an existing URL in the underlying repository.
+func (c CachedURL) Update(ctx context.Context, url entity.URL) error {
+	defer c.cache.invalidate(repository.NewURLKey(url))
+	return c.urlRepo.Update(ctx, url)
+}
+
+// IncrementClickCount increases the click count of an URL in the underlying
+// repository.
+func (c CachedURL) IncrementClickCount(ctx context.Context, domain string, alias string) (bool, error) {
+	defer c.cache.invalidate(repository.URLKey{Domain: domain, Alias: alias})
+	return c.urlRepo.IncrementClickCount(ctx, domain, alias)
+}
+
+// Delete removes an URL from the underlying repository given alias, along
+// with the cached redirect rules deleted together with it.
+func (c CachedURL) Delete(ctx context.Context, domain string, alias string) error {
+	key := repository.URLKey{Domain: domain, Alias: alias}
+	defer func() {
+		c.cache.invalidate(key)
+		c.cache.invalidate(redirectRuleKey(key))
+	}()
+	return c.urlRepo.Delete(ctx, domain, alias)
+}
+
+// NewCachedURL creates CachedURL
+func NewCachedURL(urlRepo repository.URL, cache URLCache) CachedURL {
+	return CachedURL{
+		urlRepo: urlRepo,
+		cache:   cache,
+	}
+}
+
+// CachedURLBatch invalidates the URLs in URLCache after creating them through
+// another URLBatch repository, so that cached misses don't hide them.
+type CachedURLBatch struct {
+	urlBatchRepo repository.URLBatch
+	cache        URLCache
+}
+
+// CreateURLs inserts many URLs owned by the given user into the underlying
+// repository at once.
+func (c CachedURLBatch) CreateURLs(ctx context.Context, urls []entity.URL, owner entity.User) error {
+	defer func() {
+		for _, url := range urls {
+			c.cache.invalidate(repository.NewURLKey(url))
+		}
+	}()
+	return c.urlBatchRepo.CreateURLs(ctx, urls, owner)
+}
+
+// NewCachedURLBatch creates CachedURLBatch
+func NewCachedURLBatch(urlBatchRepo repository.URLBatch, cache URLCache) CachedURLBatch {
+	return CachedURLBatch{
+		urlBatchRepo: urlBatchRepo,
+		cache:        cache,
+	}
+}
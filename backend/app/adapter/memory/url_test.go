@@ -0,0 +1,161 @@
+// +build !integration all
+
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/mdtest"
+	"github.com/short-d/short/app/entity"
+	"github.com/short-d/short/app/usecase/repository"
+)
+
+func TestCachedURL_GetByAlias(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	google := entity.URL{Alias: "google", OriginalURL: "https://www.google.com"}
+	bing := entity.URL{Alias: "bing", OriginalURL: "https://www.bing.com"}
+	updatedGoogle := entity.URL{Alias: "google", OriginalURL: "https://www.google.ca"}
+
+	type step struct {
+		elapsed       time.Duration
+		update        *entity.URL
+		create        *entity.URL
+		alias         string
+		hasErr        bool
+		expectedURL   entity.URL
+		expectedStats CacheStats
+	}
+
+	testCases := []struct {
+		name        string
+		urls        map[string]entity.URL
+		capacity    int
+		negativeTTL time.Duration
+		steps       []step
+	}{
+		{
+			name:     "repeated look ups hit cache",
+			urls:     map[string]entity.URL{"google": google},
+			capacity: 10,
+			steps: []step{
+				{alias: "google", expectedURL: google, expectedStats: CacheStats{Misses: 1, Size: 1}},
+				{alias: "google", expectedURL: google, expectedStats: CacheStats{Hits: 1, Misses: 1, Size: 1}},
+			},
+		},
+		{
+			name:     "entries expire after ttl",
+			urls:     map[string]entity.URL{"google": google},
+			capacity: 10,
+			steps: []step{
+				{alias: "google", expectedURL: google, expectedStats: CacheStats{Misses: 1, Size: 1}},
+				{elapsed: time.Minute, alias: "google", expectedURL: google, expectedStats: CacheStats{Misses: 2, Size: 1}},
+			},
+		},
+		{
+			name:     "least recently used entry is evicted",
+			urls:     map[string]entity.URL{"google": google, "bing": bing},
+			capacity: 1,
+			steps: []step{
+				{alias: "google", expectedURL: google, expectedStats: CacheStats{Misses: 1, Size: 1}},
+				{alias: "bing", expectedURL: bing, expectedStats: CacheStats{Misses: 2, Size: 1}},
+				{alias: "google", expectedURL: google, expectedStats: CacheStats{Misses: 3, Size: 1}},
+			},
+		},
+		{
+			name:     "update invalidates entry",
+			urls:     map[string]entity.URL{"google": google},
+			capacity: 10,
+			steps: []step{
+				{alias: "google", expectedURL: google, expectedStats: CacheStats{Misses: 1, Size: 1}},
+				{update: &updatedGoogle, alias: "google", expectedURL: updatedGoogle, expectedStats: CacheStats{Misses: 2, Size: 1}},
+			},
+		},
+		{
+			name:     "misses are not cached by default",
+			urls:     map[string]entity.URL{},
+			capacity: 10,
+			steps: []step{
+				{alias: "google", hasErr: true, expectedStats: CacheStats{Misses: 1}},
+				{alias: "google", hasErr: true, expectedStats: CacheStats{Misses: 2}},
+			},
+		},
+		{
+			name:        "negative caching remembers misses",
+			urls:        map[string]entity.URL{},
+			capacity:    10,
+			negativeTTL: time.Second,
+			steps: []step{
+				{alias: "google", hasErr: true, expectedStats: CacheStats{Misses: 1, Size: 1}},
+				{alias: "google", hasErr: true, expectedStats: CacheStats{Hits: 1, Misses: 1, Size: 1}},
+				{create: &google, alias: "google", expectedURL: google, expectedStats: CacheStats{Hits: 1, Misses: 2, Size: 1}},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			timer := mdtest.NewTimerFake(now)
+			cache := NewURLCache(testCase.capacity, time.Minute, testCase.negativeTTL, &timer)
+			urlRepo := repository.NewURLFake(copyURLs(testCase.urls))
+			cachedURLRepo := NewCachedURL(&urlRepo, cache)
+
+			for _, step := range testCase.steps {
+				timer.CurrentTime = timer.CurrentTime.Add(step.elapsed)
+				if step.update != nil {
+					mdtest.Equal(t, nil, cachedURLRepo.Update(context.Background(), *step.update))
+				}
+				if step.create != nil {
+					mdtest.Equal(t, nil, cachedURLRepo.Create(context.Background(), *step.create))
+				}
+
+				url, err := cachedURLRepo.GetByAlias(context.Background(), "", step.alias)
+				if step.hasErr {
+					mdtest.Equal(t, repository.ErrURLNotFound(step.alias), err)
+				} else {
+					mdtest.Equal(t, nil, err)
+					mdtest.Equal(t, step.expectedURL, url)
+				}
+				mdtest.Equal(t, step.expectedStats, cache.Stats())
+			}
+		})
+	}
+}
+
+func TestCachedURLBatch_CreateURLs(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	timer := mdtest.NewTimerFake(now)
+	cache := NewURLCache(10, time.Minute, time.Minute, timer)
+
+	urlRepo := repository.NewURLFake(map[string]entity.URL{})
+	cachedURLRepo := NewCachedURL(&urlRepo, cache)
+	_, err := cachedURLRepo.GetByAlias(context.Background(), "", "google")
+	mdtest.Equal(t, repository.ErrURLNotFound("google"), err)
+
+	google := entity.URL{Alias: "google", OriginalURL: "https://www.google.com"}
+	userURLRepo := repository.NewUserURLRepoFake(nil, nil, nil)
+	urlBatchRepo := repository.NewURLBatchFake(&urlRepo, &userURLRepo)
+	cachedURLBatchRepo := NewCachedURLBatch(&urlBatchRepo, cache)
+	err = cachedURLBatchRepo.CreateURLs(context.Background(), []entity.URL{google}, entity.User{})
+	mdtest.Equal(t, nil, err)
+
+	url, err := cachedURLRepo.GetByAlias(context.Background(), "", "google")
+	mdtest.Equal(t, nil, err)
+	mdtest.Equal(t, google, url)
+}
+
+func copyURLs(urls map[string]entity.URL) map[string]entity.URL {
+	copied := make(map[string]entity.URL, len(urls))
+	for alias, url := range urls {
+		copied[alias] = url
+	}
+	return copied
+}
@@ -0,0 +1,162 @@
+package memory
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/short-d/app/fw"
+	"github.com/short-d/short/app/entity"
+	"github.com/short-d/short/app/usecase/repository"
+)
+
+// CacheStats summarizes how often the entries looked up are found in the
+// cache.
+type CacheStats struct {
+	Hits   int64
+	Misses int64
+	Size   int
+}
+
+// redirectRuleKey identifies the cached redirect rules of a short link, apart
+// from the short link itself.
+type redirectRuleKey repository.URLKey
+
+// domainKey identifies the cached custom domain of a hostname.
+type domainKey string
+
+type cacheEntry struct {
+	key       interface{}
+	url       entity.URL
+	rules     []entity.RedirectRule
+	domain    entity.Domain
+	isMissing bool
+	expireAt  time.Time
+}
+
+// URLCache keeps the most recently looked up URLs, along with their redirect
+// rules and the custom domains serving them, in the memory of a single
+// instance. Entries expire after ttl, and the least recently used entry is
+// evicted once the cache is full. Aliases and hostnames which don't exist are
+// remembered for negativeTTL, which disables caching misses when it is zero.
+type URLCache struct {
+	mutex       *sync.Mutex
+	capacity    int
+	ttl         time.Duration
+	negativeTTL time.Duration
+	timer       fw.Timer
+	entries     map[interface{}]*list.Element
+	recency     *list.List
+	stats       *CacheStats
+}
+
+// Stats retrieves the number of cache hits and misses since the cache was
+// created, along with the number of entries cached.
+func (c URLCache) Stats() CacheStats {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	stats := *c.stats
+	stats.Size = c.recency.Len()
+	return stats
+}
+
+// get finds the cached entry of the given key which hasn't expired yet.
+func (c URLCache) get(key interface{}) (cacheEntry, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		c.stats.Misses++
+		return cacheEntry{}, false
+	}
+
+	entry := element.Value.(cacheEntry)
+	if !c.timer.Now().Before(entry.expireAt) {
+		c.remove(element)
+		c.stats.Misses++
+		return cacheEntry{}, false
+	}
+
+	c.recency.MoveToFront(element)
+	c.stats.Hits++
+	return entry, true
+}
+
+func (c URLCache) putURL(url entity.URL) {
+	c.put(cacheEntry{key: repository.NewURLKey(url), url: url}, c.ttl)
+}
+
+func (c URLCache) putRules(key repository.URLKey, rules []entity.RedirectRule) {
+	c.put(cacheEntry{key: redirectRuleKey(key), rules: rules}, c.ttl)
+}
+
+func (c URLCache) putDomain(domain entity.Domain) {
+	c.put(cacheEntry{key: domainKey(domain.Hostname), domain: domain}, c.ttl)
+}
+
+func (c URLCache) putMissing(key interface{}) {
+	if c.negativeTTL <= 0 {
+		return
+	}
+	c.put(cacheEntry{key: key, isMissing: true}, c.negativeTTL)
+}
+
+func (c URLCache) put(entry cacheEntry, ttl time.Duration) {
+	if c.capacity <= 0 || ttl <= 0 {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	entry.expireAt = c.timer.Now().Add(ttl)
+	element, ok := c.entries[entry.key]
+	if ok {
+		element.Value = entry
+		c.recency.MoveToFront(element)
+		return
+	}
+
+	c.entries[entry.key] = c.recency.PushFront(entry)
+	if c.recency.Len() > c.capacity {
+		c.remove(c.recency.Back())
+	}
+}
+
+// invalidate drops the cached entry of the given key so that the next look up
+// reads through to the storage.
+func (c URLCache) invalidate(key interface{}) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	element, ok := c.entries[key]
+	if ok {
+		c.remove(element)
+	}
+}
+
+func (c URLCache) remove(element *list.Element) {
+	entry := c.recency.Remove(element).(cacheEntry)
+	delete(c.entries, entry.key)
+}
+
+// NewURLCache creates URLCache holding at most capacity URLs.
+func NewURLCache(
+	capacity int,
+	ttl time.Duration,
+	negativeTTL time.Duration,
+	timer fw.Timer,
+) URLCache {
+	return URLCache{
+		mutex:       &sync.Mutex{},
+		capacity:    capacity,
+		ttl:         ttl,
+		negativeTTL: negativeTTL,
+		timer:       timer,
+		entries:     make(map[interface{}]*list.Element),
+		recency:     list.New(),
+		stats:       &CacheStats{},
+	}
+}
@@ -0,0 +1,96 @@
+package prometheus
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/short-d/short/app/usecase/service"
+)
+
+var _ service.Counter = (*Counter)(nil)
+
+type counterSeries struct {
+	labelValues []string
+	count       float64
+}
+
+// Counter counts how many times an event happens for each combination of
+// label values.
+type Counter struct {
+	labelNames []string
+	mutex      *sync.Mutex
+	series     map[string]*counterSeries
+}
+
+// Inc increases the count of the given label values by one. It panics when the
+// number of label values doesn't match the labels of the counter.
+func (c Counter) Inc(labelValues ...string) {
+	checkLabelValues(c.labelNames, labelValues)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	key := seriesKey(labelValues)
+	series, ok := c.series[key]
+	if !ok {
+		series = &counterSeries{labelValues: labelValues}
+		c.series[key] = series
+	}
+	series.count++
+}
+
+func (c Counter) collect() []sample {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	keys := make([]string, 0, len(c.series))
+	for key := range c.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	samples := make([]sample, 0, len(keys))
+	for _, key := range keys {
+		series := c.series[key]
+		samples = append(samples, sample{
+			labels: newLabels(c.labelNames, series.labelValues),
+			value:  series.count,
+		})
+	}
+	return samples
+}
+
+func newCounter(labelNames []string) Counter {
+	return Counter{
+		labelNames: labelNames,
+		mutex:      &sync.Mutex{},
+		series:     make(map[string]*counterSeries),
+	}
+}
+
+func checkLabelValues(labelNames []string, labelValues []string) {
+	if len(labelNames) != len(labelValues) {
+		panic(fmt.Sprintf(
+			"expected %d label values but got %d (labels=%v)",
+			len(labelNames),
+			len(labelValues),
+			labelNames,
+		))
+	}
+}
+
+// seriesKey identifies the series of the given label values. The separator
+// can't appear in valid UTF-8 label values.
+func seriesKey(labelValues []string) string {
+	return strings.Join(labelValues, "\xff")
+}
+
+func newLabels(labelNames []string, labelValues []string) []label {
+	labels := make([]label, 0, len(labelNames))
+	for idx, name := range labelNames {
+		labels = append(labels, label{name: name, value: labelValues[idx]})
+	}
+	return labels
+}
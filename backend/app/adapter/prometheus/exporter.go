@@ -0,0 +1,55 @@
+package prometheus
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+const bearerPrefix = "Bearer "
+
+var _ http.Handler = (*Exporter)(nil)
+
+// Exporter answers the scrapes from Prometheus presenting the configured
+// bearer token with the metrics in Registry. The metrics are not exported at
+// all when no token is configured, so that they are never served to the
+// public by accident.
+type Exporter struct {
+	registry Registry
+	token    string
+}
+
+// ServeHTTP responds with 404 when the exporter is disabled and 401 when the
+// scraper fails to present the token.
+func (e Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if e.token == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	if !e.isAuthorized(r) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	e.registry.ServeHTTP(w, r)
+}
+
+func (e Exporter) isAuthorized(r *http.Request) bool {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, bearerPrefix) {
+		return false
+	}
+
+	token := strings.TrimPrefix(auth, bearerPrefix)
+	return subtle.ConstantTimeCompare([]byte(token), []byte(e.token)) == 1
+}
+
+// NewExporter creates Exporter which only serves the metrics to the scrapers
+// presenting token.
+func NewExporter(registry Registry, token string) Exporter {
+	return Exporter{
+		registry: registry,
+		token:    token,
+	}
+}
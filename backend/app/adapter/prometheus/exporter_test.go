@@ -0,0 +1,83 @@
+// +build !integration all
+
+package prometheus
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/short-d/app/mdtest"
+)
+
+func TestExporter_ServeHTTP(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name               string
+		token              string
+		authorization      string
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			name:               "disabled without token",
+			token:              "",
+			authorization:      "Bearer ",
+			expectedStatusCode: http.StatusNotFound,
+			expectedBody:       "404 page not found\n",
+		},
+		{
+			name:               "missing authorization",
+			token:              "secret",
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedBody:       "",
+		},
+		{
+			name:               "wrong scheme",
+			token:              "secret",
+			authorization:      "Basic secret",
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedBody:       "",
+		},
+		{
+			name:               "incorrect token",
+			token:              "secret",
+			authorization:      "Bearer secrets",
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedBody:       "",
+		},
+		{
+			name:               "correct token",
+			token:              "secret",
+			authorization:      "Bearer secret",
+			expectedStatusCode: http.StatusOK,
+			expectedBody: `# HELP short_redirects_total Redirects by outcome.
+# TYPE short_redirects_total counter
+short_redirects_total{outcome="redirected"} 1
+`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			registry := NewRegistry()
+			counter := registry.NewCounter("short_redirects_total", "Redirects by outcome.", "outcome")
+			counter.Inc("redirected")
+			exporter := NewExporter(registry, testCase.token)
+
+			r := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+			if testCase.authorization != "" {
+				r.Header.Set("Authorization", testCase.authorization)
+			}
+			w := httptest.NewRecorder()
+			exporter.ServeHTTP(w, r)
+
+			mdtest.Equal(t, testCase.expectedStatusCode, w.Code)
+			mdtest.Equal(t, testCase.expectedBody, w.Body.String())
+		})
+	}
+}
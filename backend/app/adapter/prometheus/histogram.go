@@ -0,0 +1,125 @@
+package prometheus
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/short-d/short/app/usecase/service"
+)
+
+// DefaultBuckets are the upper bounds of histogram buckets suitable for
+// latencies measured in seconds.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+var _ service.Histogram = (*Histogram)(nil)
+
+type histogramSeries struct {
+	labelValues  []string
+	bucketCounts []uint64
+	count        uint64
+	sum          float64
+}
+
+// Histogram counts the observed values falling into each bucket for each
+// combination of label values.
+type Histogram struct {
+	buckets    []float64
+	labelNames []string
+	mutex      *sync.Mutex
+	series     map[string]*histogramSeries
+}
+
+// Observe records a value for the given label values. It panics when the
+// number of label values doesn't match the labels of the histogram.
+func (h Histogram) Observe(value float64, labelValues ...string) {
+	checkLabelValues(h.labelNames, labelValues)
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	key := seriesKey(labelValues)
+	series, ok := h.series[key]
+	if !ok {
+		series = &histogramSeries{
+			labelValues:  labelValues,
+			bucketCounts: make([]uint64, len(h.buckets)),
+		}
+		h.series[key] = series
+	}
+
+	idx := sort.SearchFloat64s(h.buckets, value)
+	if idx < len(h.buckets) {
+		series.bucketCounts[idx]++
+	}
+	series.count++
+	series.sum += value
+}
+
+// collect produces cumulative bucket counts followed by the sum and the count
+// of observed values for each series.
+func (h Histogram) collect() []sample {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	keys := make([]string, 0, len(h.series))
+	for key := range h.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var samples []sample
+	for _, key := range keys {
+		series := h.series[key]
+		labels := newLabels(h.labelNames, series.labelValues)
+
+		var cumulativeCount uint64
+		for idx, upperBound := range h.buckets {
+			cumulativeCount += series.bucketCounts[idx]
+			samples = append(samples, sample{
+				suffix: "_bucket",
+				labels: withLabel(labels, "le", formatValue(upperBound)),
+				value:  float64(cumulativeCount),
+			})
+		}
+		samples = append(samples,
+			sample{
+				suffix: "_bucket",
+				labels: withLabel(labels, "le", formatValue(math.Inf(1))),
+				value:  float64(series.count),
+			},
+			sample{
+				suffix: "_sum",
+				labels: labels,
+				value:  series.sum,
+			},
+			sample{
+				suffix: "_count",
+				labels: labels,
+				value:  float64(series.count),
+			},
+		)
+	}
+	return samples
+}
+
+func withLabel(labels []label, name string, value string) []label {
+	newLabels := make([]label, len(labels), len(labels)+1)
+	copy(newLabels, labels)
+	return append(newLabels, label{name: name, value: value})
+}
+
+func newHistogram(buckets []float64, labelNames []string) Histogram {
+	for idx := 1; idx < len(buckets); idx++ {
+		if buckets[idx-1] >= buckets[idx] {
+			panic(fmt.Sprintf("histogram buckets must be in increasing order (buckets=%v)", buckets))
+		}
+	}
+	return Histogram{
+		buckets:    buckets,
+		labelNames: labelNames,
+		mutex:      &sync.Mutex{},
+		series:     make(map[string]*histogramSeries),
+	}
+}
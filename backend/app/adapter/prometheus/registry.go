@@ -0,0 +1,216 @@
+package prometheus
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const contentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// The constants enumerate the types of metrics supported by Prometheus text
+// format.
+const (
+	typeCounter   = "counter"
+	typeGauge     = "gauge"
+	typeHistogram = "histogram"
+)
+
+var _ http.Handler = (*Registry)(nil)
+
+// label represents the name and value of a label attached to a sample.
+type label struct {
+	name  string
+	value string
+}
+
+// sample represents a single value of a metric, with suffix appended to the
+// name of the metric such as "_bucket" for histograms.
+type sample struct {
+	suffix string
+	labels []label
+	value  float64
+}
+
+// collector produces the samples of a metric family when metrics are
+// exported.
+type collector interface {
+	collect() []sample
+}
+
+type family struct {
+	name       string
+	help       string
+	metricType string
+	collector  collector
+}
+
+// Registry keeps track of the metrics of the system and exports them in
+// Prometheus text format.
+type Registry struct {
+	mutex    *sync.Mutex
+	families map[string]family
+}
+
+// NewCounter declares a counter partitioned by the given labels.
+func (r Registry) NewCounter(name string, help string, labelNames ...string) Counter {
+	counter := newCounter(labelNames)
+	r.register(family{
+		name:       name,
+		help:       help,
+		metricType: typeCounter,
+		collector:  counter,
+	})
+	return counter
+}
+
+// NewHistogram declares a histogram partitioned by the given labels, which
+// counts the observed values falling into each of the buckets. The buckets are
+// the inclusive upper bounds in increasing order.
+func (r Registry) NewHistogram(
+	name string,
+	help string,
+	buckets []float64,
+	labelNames ...string,
+) Histogram {
+	histogram := newHistogram(buckets, labelNames)
+	r.register(family{
+		name:       name,
+		help:       help,
+		metricType: typeHistogram,
+		collector:  histogram,
+	})
+	return histogram
+}
+
+// NewCounterFunc declares a counter whose value is read from value when
+// metrics are exported. value must never decrease.
+func (r Registry) NewCounterFunc(name string, help string, value func() float64) {
+	r.register(family{
+		name:       name,
+		help:       help,
+		metricType: typeCounter,
+		collector:  valueFunc(value),
+	})
+}
+
+// NewGaugeFunc declares a gauge whose value is read from value when metrics
+// are exported.
+func (r Registry) NewGaugeFunc(name string, help string, value func() float64) {
+	r.register(family{
+		name:       name,
+		help:       help,
+		metricType: typeGauge,
+		collector:  valueFunc(value),
+	})
+}
+
+func (r Registry) register(f family) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	_, ok := r.families[f.name]
+	if ok {
+		panic(fmt.Sprintf("metric already registered (name=%s)", f.name))
+	}
+	r.families[f.name] = f
+}
+
+// Write exports all the metrics in Prometheus text format, sorted by name.
+func (r Registry) Write(w io.Writer) error {
+	r.mutex.Lock()
+	families := make([]family, 0, len(r.families))
+	for _, f := range r.families {
+		families = append(families, f)
+	}
+	r.mutex.Unlock()
+
+	sort.Slice(families, func(i, j int) bool {
+		return families[i].name < families[j].name
+	})
+
+	buf := bufio.NewWriter(w)
+	for _, f := range families {
+		fmt.Fprintf(buf, "# HELP %s %s\n", f.name, escapeHelp(f.help))
+		fmt.Fprintf(buf, "# TYPE %s %s\n", f.name, f.metricType)
+		for _, s := range f.collector.collect() {
+			buf.WriteString(f.name)
+			buf.WriteString(s.suffix)
+			writeLabels(buf, s.labels)
+			buf.WriteByte(' ')
+			buf.WriteString(formatValue(s.value))
+			buf.WriteByte('\n')
+		}
+	}
+	return buf.Flush()
+}
+
+// ServeHTTP answers scrapes from Prometheus with all the metrics.
+func (r Registry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", contentType)
+	r.Write(w)
+}
+
+func writeLabels(buf *bufio.Writer, labels []label) {
+	if len(labels) == 0 {
+		return
+	}
+
+	buf.WriteByte('{')
+	for idx, l := range labels {
+		if idx > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(l.name)
+		buf.WriteString(`="`)
+		buf.WriteString(escapeLabelValue(l.value))
+		buf.WriteByte('"')
+	}
+	buf.WriteByte('}')
+}
+
+var helpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+func escapeHelp(help string) string {
+	return helpEscaper.Replace(help)
+}
+
+func escapeLabelValue(value string) string {
+	return labelValueEscaper.Replace(value)
+}
+
+func formatValue(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+}
+
+// valueFunc collects a single sample without labels from a function.
+type valueFunc func() float64
+
+func (v valueFunc) collect() []sample {
+	return []sample{{value: v()}}
+}
+
+// NewRegistry creates Registry without any metrics.
+func NewRegistry() Registry {
+	return Registry{
+		mutex:    &sync.Mutex{},
+		families: make(map[string]family),
+	}
+}
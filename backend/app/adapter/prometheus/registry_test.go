@@ -0,0 +1,111 @@
+// +build !integration all
+
+package prometheus
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/short-d/app/mdtest"
+)
+
+func TestRegistry_Write(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name           string
+		record         func(registry Registry)
+		expectedOutput string
+	}{
+		{
+			name: "counter without samples",
+			record: func(registry Registry) {
+				registry.NewCounter("short_redirects_total", "Redirects by outcome.", "outcome")
+			},
+			expectedOutput: `# HELP short_redirects_total Redirects by outcome.
+# TYPE short_redirects_total counter
+`,
+		},
+		{
+			name: "counter sorted by label values",
+			record: func(registry Registry) {
+				counter := registry.NewCounter("short_redirects_total", "Redirects by outcome.", "outcome")
+				counter.Inc("redirected")
+				counter.Inc("not_found")
+				counter.Inc("redirected")
+			},
+			expectedOutput: `# HELP short_redirects_total Redirects by outcome.
+# TYPE short_redirects_total counter
+short_redirects_total{outcome="not_found"} 1
+short_redirects_total{outcome="redirected"} 2
+`,
+		},
+		{
+			name: "label values and help escaped",
+			record: func(registry Registry) {
+				counter := registry.NewCounter("short_events_total", "Line 1\nLine 2 \\", "name")
+				counter.Inc("say \"hi\"\n")
+			},
+			expectedOutput: `# HELP short_events_total Line 1\nLine 2 \\
+# TYPE short_events_total counter
+short_events_total{name="say \"hi\"\n"} 1
+`,
+		},
+		{
+			name: "histogram with cumulative buckets",
+			record: func(registry Registry) {
+				histogram := registry.NewHistogram(
+					"short_latency_seconds",
+					"Latency.",
+					[]float64{0.1, 1},
+					"field",
+				)
+				histogram.Observe(0.05, "viewer")
+				histogram.Observe(0.1, "viewer")
+				histogram.Observe(0.5, "viewer")
+				histogram.Observe(2, "viewer")
+			},
+			expectedOutput: `# HELP short_latency_seconds Latency.
+# TYPE short_latency_seconds histogram
+short_latency_seconds_bucket{field="viewer",le="0.1"} 2
+short_latency_seconds_bucket{field="viewer",le="1"} 3
+short_latency_seconds_bucket{field="viewer",le="+Inf"} 4
+short_latency_seconds_sum{field="viewer"} 2.65
+short_latency_seconds_count{field="viewer"} 4
+`,
+		},
+		{
+			name: "metrics sorted by name",
+			record: func(registry Registry) {
+				registry.NewGaugeFunc("short_url_cache_size", "Cached URLs.", func() float64 {
+					return 3
+				})
+				registry.NewCounterFunc("short_url_cache_hits_total", "Cache hits.", func() float64 {
+					return 10
+				})
+			},
+			expectedOutput: `# HELP short_url_cache_hits_total Cache hits.
+# TYPE short_url_cache_hits_total counter
+short_url_cache_hits_total 10
+# HELP short_url_cache_size Cached URLs.
+# TYPE short_url_cache_size gauge
+short_url_cache_size 3
+`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			registry := NewRegistry()
+			testCase.record(registry)
+
+			buf := bytes.Buffer{}
+			err := registry.Write(&buf)
+			mdtest.Equal(t, nil, err)
+			mdtest.Equal(t, testCase.expectedOutput, buf.String())
+		})
+	}
+}
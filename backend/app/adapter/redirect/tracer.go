@@ -0,0 +1,143 @@
+package redirect
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	netURL "net/url"
+	"syscall"
+	"time"
+
+	"github.com/short-d/short/app/usecase/service"
+)
+
+var _ service.RedirectTracer = (*Tracer)(nil)
+
+// Tracer follows HTTP redirects through network. It refuses to connect to
+// private networks so that users can't probe internal services through it.
+type Tracer struct {
+	client *http.Client
+}
+
+// TraceRedirects sends HEAD requests starting from the given link and follows
+// the Location headers of redirect responses. The links visited before an
+// error are returned along with the error, including the request in flight
+// being canceled when ctx is done.
+func (t Tracer) TraceRedirects(ctx context.Context, link string, maxHops int) ([]string, error) {
+	var hops []string
+	for len(hops) < maxHops {
+		next, err := t.nextHop(ctx, link)
+		if err != nil {
+			return hops, err
+		}
+		if next == "" {
+			return hops, nil
+		}
+		hops = append(hops, next)
+		link = next
+	}
+	return hops, nil
+}
+
+func (t Tracer) nextHop(ctx context.Context, link string) (string, error) {
+	u, err := netURL.Parse(link)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, link, nil)
+	if err != nil {
+		return "", err
+	}
+
+	res, err := t.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 300 || res.StatusCode >= 400 {
+		return "", nil
+	}
+
+	location, err := res.Location()
+	if err == http.ErrNoLocation {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return location.String(), nil
+}
+
+func isPublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() {
+		return false
+	}
+	if ip.IsUnspecified() || ip.IsMulticast() || ip.IsInterfaceLocalMulticast() {
+		return false
+	}
+	return !isPrivateIP(ip)
+}
+
+// isPrivateIP checks whether the IP address belongs to private networks or
+// carrier-grade NAT.
+func isPrivateIP(ip net.IP) bool {
+	ipv4 := ip.To4()
+	if ipv4 != nil {
+		return ipv4[0] == 10 ||
+			(ipv4[0] == 172 && ipv4[1]&0xf0 == 16) ||
+			(ipv4[0] == 192 && ipv4[1] == 168) ||
+			(ipv4[0] == 100 && ipv4[1]&0xc0 == 64)
+	}
+	return ip[0]&0xfe == 0xfc
+}
+
+func newDialer(timeout time.Duration, isAllowed func(ip net.IP) bool) *net.Dialer {
+	return &net.Dialer{
+		Timeout: timeout,
+		Control: func(network string, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || !isAllowed(ip) {
+				return fmt.Errorf("address not allowed (address=%s)", address)
+			}
+			return nil
+		},
+	}
+}
+
+func newTracer(timeout time.Duration, isAllowed func(ip net.IP) bool) Tracer {
+	transport := &http.Transport{
+		DialContext:         newDialer(timeout, isAllowed).DialContext,
+		TLSHandshakeTimeout: timeout,
+	}
+	return Tracer{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   timeout,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// NewTracer creates redirect tracer which gives up each request after the
+// given timeout.
+func NewTracer(timeout time.Duration) Tracer {
+	return newTracer(timeout, isPublicIP)
+}
+
+// NewPublicDialer creates a dialer which refuses to connect to private
+// networks and gives up connecting after the given timeout.
+func NewPublicDialer(timeout time.Duration) *net.Dialer {
+	return newDialer(timeout, isPublicIP)
+}
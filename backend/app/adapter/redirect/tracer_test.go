@@ -0,0 +1,144 @@
+// +build !integration all
+
+package redirect
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/mdtest"
+)
+
+func TestTracer_TraceRedirects(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	mux.HandleFunc("/a", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/b", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/b", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, server.URL+"/c", http.StatusFound)
+	})
+	mux.HandleFunc("/c", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/loop", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/loop", http.StatusFound)
+	})
+	mux.HandleFunc("/script", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "javascript://alert(1)", http.StatusFound)
+	})
+
+	testCases := []struct {
+		name         string
+		link         string
+		maxHops      int
+		isAllowed    func(ip net.IP) bool
+		isCanceled   bool
+		expectedHops []string
+		hasErr       bool
+	}{
+		{
+			name:         "no redirect",
+			link:         server.URL + "/c",
+			maxHops:      5,
+			isAllowed:    func(ip net.IP) bool { return true },
+			expectedHops: nil,
+		},
+		{
+			name:         "redirect chain",
+			link:         server.URL + "/a",
+			maxHops:      5,
+			isAllowed:    func(ip net.IP) bool { return true },
+			expectedHops: []string{server.URL + "/b", server.URL + "/c"},
+		},
+		{
+			name:         "redirect loop",
+			link:         server.URL + "/loop",
+			maxHops:      3,
+			isAllowed:    func(ip net.IP) bool { return true },
+			expectedHops: []string{server.URL + "/loop", server.URL + "/loop", server.URL + "/loop"},
+		},
+		{
+			name:         "redirect to non http link",
+			link:         server.URL + "/script",
+			maxHops:      3,
+			isAllowed:    func(ip net.IP) bool { return true },
+			expectedHops: []string{"javascript://alert(1)"},
+		},
+		{
+			name:       "context canceled",
+			link:       server.URL + "/a",
+			maxHops:    5,
+			isAllowed:  func(ip net.IP) bool { return true },
+			isCanceled: true,
+			hasErr:     true,
+		},
+		{
+			name:      "private network",
+			link:      server.URL + "/a",
+			maxHops:   5,
+			isAllowed: isPublicIP,
+			hasErr:    true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if testCase.isCanceled {
+				cancel()
+			}
+
+			tracer := newTracer(time.Second, testCase.isAllowed)
+			hops, err := tracer.TraceRedirects(ctx, testCase.link, testCase.maxHops)
+			if testCase.hasErr {
+				mdtest.NotEqual(t, nil, err)
+				return
+			}
+			mdtest.Equal(t, nil, err)
+			mdtest.Equal(t, testCase.expectedHops, hops)
+		})
+	}
+}
+
+func TestIsPublicIP(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		ip       string
+		isPublic bool
+	}{
+		{ip: "8.8.8.8", isPublic: true},
+		{ip: "127.0.0.1", isPublic: false},
+		{ip: "10.1.2.3", isPublic: false},
+		{ip: "172.16.0.1", isPublic: false},
+		{ip: "172.32.0.1", isPublic: true},
+		{ip: "192.168.1.1", isPublic: false},
+		{ip: "169.254.169.254", isPublic: false},
+		{ip: "100.64.0.1", isPublic: false},
+		{ip: "0.0.0.0", isPublic: false},
+		{ip: "2001:4860:4860::8888", isPublic: true},
+		{ip: "::1", isPublic: false},
+		{ip: "fd00::1", isPublic: false},
+		{ip: "fe80::1", isPublic: false},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.ip, func(t *testing.T) {
+			t.Parallel()
+
+			mdtest.Equal(t, testCase.isPublic, isPublicIP(net.ParseIP(testCase.ip)))
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package routing
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/short-d/app/fw"
+)
+
+// withDeadline cancels the context of the requests served by handle once
+// timeout elapses, so that slow storage or key generation calls are abandoned
+// instead of piling up.
+func withDeadline(timeout time.Duration, handle fw.Handle) fw.Handle {
+	return func(w http.ResponseWriter, r *http.Request, params fw.Params) {
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		handle(w, r.WithContext(ctx), params)
+	}
+}
@@ -0,0 +1,31 @@
+// +build !integration all
+
+package routing
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw"
+	"github.com/short-d/app/mdtest"
+)
+
+func TestWithDeadline(t *testing.T) {
+	t.Parallel()
+
+	var deadline time.Time
+	var hasDeadline bool
+	handle := withDeadline(time.Minute, func(w http.ResponseWriter, r *http.Request, params fw.Params) {
+		deadline, hasDeadline = r.Context().Deadline()
+	})
+
+	before := time.Now()
+	r := httptest.NewRequest(http.MethodGet, "/r/alias", nil)
+	handle(httptest.NewRecorder(), r, fw.Params{})
+
+	mdtest.Equal(t, true, hasDeadline)
+	mdtest.Equal(t, false, deadline.Before(before.Add(time.Minute)))
+	mdtest.Equal(t, false, deadline.After(time.Now().Add(time.Minute)))
+}
@@ -1,51 +1,602 @@
 package routing
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
 	netURL "net/url"
+	"strings"
 
 	"github.com/short-d/app/fw"
+	"github.com/short-d/short/app/entity"
+	"github.com/short-d/short/app/usecase/analytics"
 	"github.com/short-d/short/app/usecase/auth"
+	"github.com/short-d/short/app/usecase/customdomain"
+	"github.com/short-d/short/app/usecase/linksafety"
+	"github.com/short-d/short/app/usecase/ratelimit"
+	"github.com/short-d/short/app/usecase/redirectrule"
+	"github.com/short-d/short/app/usecase/repository"
 	"github.com/short-d/short/app/usecase/service"
 	"github.com/short-d/short/app/usecase/sso"
 	"github.com/short-d/short/app/usecase/url"
 )
 
-// NewOriginalURL translates alias to the original long link.
+const (
+	exportPageSize  = 100
+	importBatchSize = 500
+	maxImportSize   = 10 << 20
+	maxUnlockSize   = 1 << 10
+	previewSuffix   = "+"
+)
+
+// The constants enumerate the reasons of failing to unlock a short link,
+// reported to the web frontend.
+const (
+	unlockErrIncorrectPassword = "incorrectPassword"
+	unlockErrTooManyAttempts   = "tooManyAttempts"
+)
+
+// The constants enumerate the outcomes of visiting a short link, counted by
+// the redirect metrics.
+const (
+	redirectOutcomeRedirected        = "redirected"
+	redirectOutcomeRateLimited       = "rate_limited"
+	redirectOutcomePreview           = "preview"
+	redirectOutcomeComingSoon        = "coming_soon"
+	redirectOutcomePasswordRequired  = "password_required"
+	redirectOutcomeIncorrectPassword = "incorrect_password"
+	redirectOutcomeNotFound          = "not_found"
+	redirectOutcomeGone              = "gone"
+	redirectOutcomeUnavailable       = "unavailable"
+)
+
+// The constants enumerate the results of signing in with an identity
+// provider, counted by the sign in metrics.
+const (
+	signInSucceeded = "success"
+	signInFailed    = "failure"
+)
+
+// importFailure represents a record which can't be imported.
+type importFailure struct {
+	Row       int    `json:"row"`
+	Alias     string `json:"alias,omitempty"`
+	ErrorCode string `json:"errorCode"`
+}
+
+// importReport summarizes the outcome of importing URLs.
+type importReport struct {
+	CreatedCount int             `json:"createdCount"`
+	Failures     []importFailure `json:"failures"`
+}
+
+// NewOriginalURL translates alias to the long link picked by its redirect
+// rules, or the original long link when no rule matches. The alias is looked
+// up under the verified custom domain matching the Host header, or under the
+// default host when none matches. The query string and
+// the path following the alias are forwarded when the short link allows it.
+// Visitors are redirected with the status code of the short link, falling back
+// to defaultRedirectStatus. Visitors of short links which are not activated
+// yet are sent to the coming soon page, while appending "+" to the alias shows
+// the preview page of the short link instead of redirecting. Short links which
+// can't be visited are answered with 404, 410 or 503. Each visit is counted by
+// its outcome.
 func NewOriginalURL(
 	logger fw.Logger,
 	tracer fw.Tracer,
+	redirectCounter service.Counter,
 	urlRetriever url.Retriever,
+	domainManager customdomain.Manager,
+	ruleResolver redirectrule.Resolver,
+	clickRecorder analytics.Recorder,
+	rateLimiter ratelimit.Limiter,
+	ipResolver ClientIPResolver,
 	timer fw.Timer,
 	webFrontendURL netURL.URL,
+	comingSoonURL netURL.URL,
+	defaultRedirectStatus int,
 ) fw.Handle {
 	return func(w http.ResponseWriter, r *http.Request, params fw.Params) {
+		ctx := r.Context()
 		trace := tracer.BeginTrace("OriginalURL")
+		outcome := redirectOutcomeRedirected
+		defer func() {
+			redirectCounter.Inc(outcome)
+		}()
+
+		alias, suffix := shortLinkPath(r)
+		ipAddress := ipResolver.ClientIP(r)
+
+		err := rateLimiter.Allow(ctx, ratelimit.ActionRedirect, ratelimit.IPSubject(ipAddress))
+		switch err.(type) {
+		case nil:
+		case ratelimit.ErrRateLimited:
+			outcome = redirectOutcomeRateLimited
+			w.WriteHeader(http.StatusTooManyRequests)
+			trace.End()
+			return
+		default:
+			// Redirects keep working when the rate limit backend is unavailable.
+			logger.Error(err)
+		}
+
+		domain, err := domainManager.ResolveHost(ctx, r.Host)
+		if err != nil {
+			outcome = serveURLError(logger, w, r, webFrontendURL, alias, url.ErrStorageFailure{Err: err})
+			trace.End()
+			return
+		}
 
-		alias := params["alias"]
+		if strings.HasSuffix(alias, previewSuffix) {
+			outcome = redirectOutcomePreview
+			servePreview(w, r, webFrontendURL, domain, strings.TrimSuffix(alias, previewSuffix))
+			trace.End()
+			return
+		}
 
 		trace1 := trace.Next("GetUrlAfter")
 		now := timer.Now()
-		u, err := urlRetriever.GetURL(alias, &now)
+		u, err := urlRetriever.GetURL(ctx, domain, alias, &now)
 		trace1.End()
 
+		switch err.(type) {
+		case nil:
+		case url.ErrURLNotActive:
+			outcome = redirectOutcomeComingSoon
+			http.Redirect(w, r, comingSoonURL.String(), http.StatusSeeOther)
+			trace.End()
+			return
+		default:
+			outcome = serveURLError(logger, w, r, webFrontendURL, alias, err)
+			trace.End()
+			return
+		}
+
+		if u.PasswordHash != nil {
+			outcome = redirectOutcomePasswordRequired
+			serveUnlock(w, r, webFrontendURL, domain, alias, "")
+			trace.End()
+			return
+		}
+
+		trace2 := trace.Next("ResolveLongLink")
+		longLink := resolveLongLink(ctx, logger, ruleResolver, ipResolver, u, r)
+		trace2.End()
+
+		visit := url.Visit{
+			PathSuffix: suffix,
+			RawQuery:   r.URL.RawQuery,
+		}
+		longLink, err = url.ExpandLongLink(longLink, u, visit)
 		if err != nil {
-			logger.Error(err)
-			serve404(w, r, webFrontendURL)
+			outcome = serveURLError(logger, w, r, webFrontendURL, alias, err)
+			trace.End()
+			return
+		}
+
+		err = urlRetriever.CountClick(ctx, u)
+		if err != nil {
+			outcome = serveURLError(logger, w, r, webFrontendURL, alias, err)
+			trace.End()
 			return
 		}
 
-		originURL := u.OriginalURL
-		http.Redirect(w, r, originURL, http.StatusSeeOther)
+		clickRecorder.RecordClick(entity.Click{
+			Domain:    domain,
+			Alias:     alias,
+			ClickedAt: now,
+			Referrer:  r.Referer(),
+			UserAgent: r.UserAgent(),
+			IPAddress: ipAddress,
+		})
+
+		http.Redirect(w, r, longLink, redirectStatus(u, defaultRedirectStatus))
 		trace.End()
 	}
 }
 
-func serve404(w http.ResponseWriter, r *http.Request, webFrontendURL netURL.URL) {
-	webFrontendURL.Path = "/404"
+// NewUnlockURL redirects to the long link of a password protected short link
+// when the password submitted through the form is correct. Incorrect passwords
+// are rate limited per visitor and alias to slow down guessing the password
+// without locking other visitors out. Visitors are
+// always redirected with 303 so that the password is not submitted again to
+// the long link. Each unlock attempt is counted by its outcome. Like
+// redirects, the short link is looked up under the verified custom domain
+// matching the Host header, so the form must be submitted to the host serving
+// the short link.
+func NewUnlockURL(
+	logger fw.Logger,
+	tracer fw.Tracer,
+	redirectCounter service.Counter,
+	urlUnlocker url.Unlocker,
+	domainManager customdomain.Manager,
+	ruleResolver redirectrule.Resolver,
+	clickRecorder analytics.Recorder,
+	rateLimiter ratelimit.Limiter,
+	ipResolver ClientIPResolver,
+	timer fw.Timer,
+	webFrontendURL netURL.URL,
+	comingSoonURL netURL.URL,
+) fw.Handle {
+	return func(w http.ResponseWriter, r *http.Request, params fw.Params) {
+		ctx := r.Context()
+		trace := tracer.BeginTrace("UnlockURL")
+		defer trace.End()
+
+		outcome := redirectOutcomeRedirected
+		defer func() {
+			redirectCounter.Inc(outcome)
+		}()
+
+		alias, _ := shortLinkPath(r)
+		domain, err := domainManager.ResolveHost(ctx, r.Host)
+		if err != nil {
+			outcome = serveURLError(logger, w, r, webFrontendURL, alias, url.ErrStorageFailure{Err: err})
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, maxUnlockSize)
+		password := r.PostFormValue("password")
+		ipAddress := ipResolver.ClientIP(r)
+		subject := ratelimit.IPAliasSubject(ipAddress, domain, alias)
+
+		err = rateLimiter.Check(ctx, ratelimit.ActionUnlockURL, subject)
+		switch err.(type) {
+		case nil:
+		case ratelimit.ErrRateLimited:
+			outcome = redirectOutcomeRateLimited
+			serveUnlock(w, r, webFrontendURL, domain, alias, unlockErrTooManyAttempts)
+			return
+		default:
+			logger.Error(err)
+		}
+
+		now := timer.Now()
+		u, err := urlUnlocker.UnlockURL(ctx, domain, alias, password, now)
+		switch err.(type) {
+		case nil:
+		case url.ErrIncorrectPassword:
+			outcome = redirectOutcomeIncorrectPassword
+			err = rateLimiter.Allow(ctx, ratelimit.ActionUnlockURL, subject)
+			if _, ok := err.(ratelimit.ErrRateLimited); err != nil && !ok {
+				logger.Error(err)
+			}
+			serveUnlock(w, r, webFrontendURL, domain, alias, unlockErrIncorrectPassword)
+			return
+		case url.ErrURLNotActive:
+			outcome = redirectOutcomeComingSoon
+			http.Redirect(w, r, comingSoonURL.String(), http.StatusSeeOther)
+			return
+		default:
+			outcome = serveURLError(logger, w, r, webFrontendURL, alias, err)
+			return
+		}
+
+		longLink := resolveLongLink(ctx, logger, ruleResolver, ipResolver, u, r)
+		longLink, err = url.ExpandLongLink(longLink, u, url.Visit{})
+		if err != nil {
+			outcome = serveURLError(logger, w, r, webFrontendURL, alias, err)
+			return
+		}
+
+		clickRecorder.RecordClick(entity.Click{
+			Domain:    domain,
+			Alias:     alias,
+			ClickedAt: now,
+			Referrer:  r.Referer(),
+			UserAgent: r.UserAgent(),
+			IPAddress: ipAddress,
+		})
+		http.Redirect(w, r, longLink, http.StatusSeeOther)
+	}
+}
+
+// resolveLongLink evaluates the redirect rules of the short link against the
+// visitor, falling back to the original long link when the rules can't be
+// retrieved.
+func resolveLongLink(
+	ctx context.Context,
+	logger fw.Logger,
+	ruleResolver redirectrule.Resolver,
+	ipResolver ClientIPResolver,
+	u entity.URL,
+	r *http.Request,
+) string {
+	visitor := redirectrule.Visitor{
+		AcceptLanguage: r.Header.Get("Accept-Language"),
+		UserAgent:      r.UserAgent(),
+		IPAddress:      ipResolver.ClientIP(r),
+	}
+
+	longLink, err := ruleResolver.ResolveLongLink(ctx, u, visitor)
+	if err != nil {
+		logger.Error(err)
+		return u.OriginalURL
+	}
+	return longLink
+}
+
+// shortLinkPath splits the requested path into the alias and the part
+// following it, such as "abc" and "/docs/intro" for "/r/abc/docs/intro",
+// keeping the escaping of the suffix intact. The alias is read from the path
+// rather than the route params, which query params of the same name overwrite.
+func shortLinkPath(r *http.Request) (string, string) {
+	path := strings.TrimPrefix(r.URL.EscapedPath(), "/r/")
+	alias, suffix := path, ""
+	index := strings.Index(path, "/")
+	if index >= 0 {
+		alias, suffix = path[:index], path[index:]
+	}
+
+	unescapedAlias, err := netURL.PathUnescape(alias)
+	if err != nil {
+		return alias, suffix
+	}
+	return unescapedAlias, suffix
+}
+
+// NewExportURLs streams all the URLs created by the signed in user in the
+// requested format, either csv or ndjson.
+func NewExportURLs(
+	logger fw.Logger,
+	tracer fw.Tracer,
+	urlRetriever url.Retriever,
+	authenticator auth.Authenticator,
+) fw.Handle {
+	return func(w http.ResponseWriter, r *http.Request, params fw.Params) {
+		ctx := r.Context()
+		trace := tracer.BeginTrace("ExportURLs")
+		defer trace.End()
+
+		user, err := authenticator.GetUser(getAuthToken(r, params))
+		if err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		format := params["format"]
+		if !isFormatSupported(format) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", contentType(format))
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="urls.%s"`, format))
+		encoder, err := newURLEncoder(format, w)
+		if err != nil {
+			logger.Error(err)
+			return
+		}
+
+		query := repository.URLQuery{SortBy: repository.URLSortByAlias}
+		for {
+			page, err := urlRetriever.GetURLPageByUser(ctx, user, query, exportPageSize)
+			if err != nil {
+				logger.Error(err)
+				return
+			}
+
+			for _, u := range page.URLs {
+				err = encoder.Encode(newURLRecord(u))
+				if err != nil {
+					logger.Error(err)
+					return
+				}
+			}
+
+			err = encoder.Flush()
+			if err != nil {
+				logger.Error(err)
+				return
+			}
+
+			if !page.HasNextPage {
+				return
+			}
+			lastURL := page.URLs[len(page.URLs)-1]
+			query.After = &repository.URLCursor{
+				Domain: lastURL.Domain,
+				Alias:  lastURL.Alias,
+			}
+		}
+	}
+}
+
+// NewImportURLs creates the URLs in the request body for the signed in user
+// and reports the records which can't be imported. The body is either in csv
+// or ndjson format, matching the export. Each record is counted against the
+// rate limit of creating URLs.
+func NewImportURLs(
+	logger fw.Logger,
+	tracer fw.Tracer,
+	urlCreator url.Creator,
+	authenticator auth.Authenticator,
+	rateLimiter ratelimit.Limiter,
+) fw.Handle {
+	return func(w http.ResponseWriter, r *http.Request, params fw.Params) {
+		ctx := r.Context()
+		trace := tracer.BeginTrace("ImportURLs")
+		defer trace.End()
+
+		user, err := authenticator.GetUser(getAuthToken(r, params))
+		if err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		body := http.MaxBytesReader(w, r.Body, maxImportSize)
+		decoder, err := newURLDecoder(params["format"], body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		report := importReport{Failures: []importFailure{}}
+		var batch []url.BulkURL
+		var batchRows []int
+		createBatch := func() error {
+			if len(batch) == 0 {
+				return nil
+			}
+			subject := ratelimit.UserSubject(user)
+			allowedCount, err := rateLimiter.AllowN(ctx, ratelimit.ActionCreateURL, subject, len(batch))
+			if err != nil {
+				return err
+			}
+
+			results, err := urlCreator.CreateURLs(ctx, batch[:allowedCount], user)
+			if err != nil {
+				return err
+			}
+			for range batch[allowedCount:] {
+				results = append(results, url.BulkResult{
+					Err: ratelimit.ErrRateLimited(ratelimit.ActionCreateURL),
+				})
+			}
+			for idx, result := range results {
+				if result.Err == nil {
+					report.CreatedCount++
+					continue
+				}
+				report.Failures = append(report.Failures, importFailure{
+					Row:       batchRows[idx],
+					Alias:     aliasOf(batch[idx]),
+					ErrorCode: importErrorCode(result.Err),
+				})
+			}
+			batch = batch[:0]
+			batchRows = batchRows[:0]
+			return nil
+		}
+
+		for row := 1; ; row++ {
+			record, err := decoder.Decode()
+			if err == io.EOF {
+				break
+			}
+			if _, ok := err.(errInvalidRecord); ok {
+				report.Failures = append(report.Failures, importFailure{
+					Row:       row,
+					ErrorCode: "invalidRecord",
+				})
+				continue
+			}
+			if err != nil {
+				logger.Error(err)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			batch = append(batch, record.bulkURL())
+			batchRows = append(batchRows, row)
+			if len(batch) < importBatchSize {
+				continue
+			}
+
+			err = createBatch()
+			if err != nil {
+				logger.Error(err)
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+		}
+
+		err = createBatch()
+		if err != nil {
+			logger.Error(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(w).Encode(report)
+		if err != nil {
+			logger.Error(err)
+		}
+	}
+}
+
+func aliasOf(bulkURL url.BulkURL) string {
+	if bulkURL.CustomAlias == nil {
+		return ""
+	}
+	return *bulkURL.CustomAlias
+}
+
+func importErrorCode(err error) string {
+	switch err.(type) {
+	case url.ErrAliasExist:
+		return "aliasAlreadyExist"
+	case url.ErrInvalidLongLink:
+		return "invalidLongLink"
+	case linksafety.ErrUnsafeLink:
+		return "unsafeLongLink"
+	case url.ErrInvalidCustomAlias:
+		return "invalidCustomAlias"
+	case url.ErrDomainNotVerified:
+		return "domainNotVerified"
+	case ratelimit.ErrRateLimited:
+		return "rateLimited"
+	default:
+		return "unknown"
+	}
+}
+
+// servePreview sends the visitor to the preview page of the web frontend.
+func servePreview(
+	w http.ResponseWriter,
+	r *http.Request,
+	webFrontendURL netURL.URL,
+	domain string,
+	alias string,
+) {
+	webFrontendURL.Path = fmt.Sprintf("/preview/%s", alias)
+	query := netURL.Values{}
+	setDomain(query, domain)
+	webFrontendURL.RawQuery = query.Encode()
+	http.Redirect(w, r, webFrontendURL.String(), http.StatusSeeOther)
+}
+
+// redirectStatus picks the HTTP status code visitors of the short link are
+// redirected with.
+func redirectStatus(u entity.URL, defaultRedirectStatus int) int {
+	if u.RedirectStatus == nil {
+		return defaultRedirectStatus
+	}
+	return *u.RedirectStatus
+}
+
+// serveUnlock sends the visitor to the unlock page of the web frontend, which
+// submits the password back to the unlock route.
+func serveUnlock(
+	w http.ResponseWriter,
+	r *http.Request,
+	webFrontendURL netURL.URL,
+	domain string,
+	alias string,
+	errCode string,
+) {
+	webFrontendURL.Path = fmt.Sprintf("/unlock/%s", alias)
+	query := netURL.Values{}
+	setDomain(query, domain)
+	if errCode != "" {
+		query.Set("error", errCode)
+	}
+	webFrontendURL.RawQuery = query.Encode()
 	http.Redirect(w, r, webFrontendURL.String(), http.StatusSeeOther)
 }
 
+// setDomain tells the web frontend which custom domain the short link is
+// served under. The default host is left out.
+func setDomain(query netURL.Values, domain string) {
+	if domain == "" {
+		return
+	}
+	query.Set("domain", domain)
+}
+
 // NewSSOSignIn redirects user to the sign in page.
 func NewSSOSignIn(
 	logger fw.Logger,
@@ -66,22 +617,36 @@ func NewSSOSignIn(
 }
 
 // NewSSOSignInCallback generates Short's authentication token given identity provider's authorization code.
+// Each sign in is counted by the name of the identity provider and whether it
+// succeeds.
 func NewSSOSignInCallback(
 	logger fw.Logger,
 	tracer fw.Tracer,
+	signInCounter service.Counter,
+	providerName string,
 	singleSignOn sso.SingleSignOn,
 	webFrontendURL netURL.URL,
 ) fw.Handle {
 	return func(w http.ResponseWriter, r *http.Request, params fw.Params) {
+		ctx := r.Context()
 		code := params["code"]
 
-		authToken, err := singleSignOn.SignIn(code)
+		authToken, err := singleSignOn.SignIn(ctx, code)
 		if err != nil {
+			signInCounter.Inc(providerName, signInFailed)
 			w.WriteHeader(http.StatusInternalServerError)
 			return
 		}
+		signInCounter.Inc(providerName, signInSucceeded)
 
 		webFrontendURL = setToken(webFrontendURL, authToken)
 		http.Redirect(w, r, webFrontendURL.String(), http.StatusSeeOther)
 	}
 }
+
+// NewMetrics exports the metrics of the system to Prometheus.
+func NewMetrics(exporter http.Handler) fw.Handle {
+	return func(w http.ResponseWriter, r *http.Request, params fw.Params) {
+		exporter.ServeHTTP(w, r)
+	}
+}
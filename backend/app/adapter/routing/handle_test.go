@@ -0,0 +1,363 @@
+// +build !integration all
+
+package routing
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw"
+	"github.com/short-d/app/mdtest"
+	"github.com/short-d/short/app/entity"
+	"github.com/short-d/short/app/usecase/auth"
+	"github.com/short-d/short/app/usecase/keygen"
+	"github.com/short-d/short/app/usecase/linksafety"
+	"github.com/short-d/short/app/usecase/password"
+	"github.com/short-d/short/app/usecase/ratelimit"
+	"github.com/short-d/short/app/usecase/repository"
+	"github.com/short-d/short/app/usecase/service"
+	"github.com/short-d/short/app/usecase/url"
+	"github.com/short-d/short/app/usecase/validator"
+)
+
+func TestShortLinkPath(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name           string
+		target         string
+		expectedAlias  string
+		expectedSuffix string
+	}{
+		{
+			name:           "alias only",
+			target:         "/r/220uFicCJj",
+			expectedAlias:  "220uFicCJj",
+			expectedSuffix: "",
+		},
+		{
+			name:           "alias with path suffix",
+			target:         "/r/220uFicCJj/docs/a%2Fb",
+			expectedAlias:  "220uFicCJj",
+			expectedSuffix: "/docs/a%2Fb",
+		},
+		{
+			name:           "alias query param",
+			target:         "/r/220uFicCJj?alias=google",
+			expectedAlias:  "220uFicCJj",
+			expectedSuffix: "",
+		},
+		{
+			name:           "escaped alias",
+			target:         "/r/caf%C3%A9",
+			expectedAlias:  "café",
+			expectedSuffix: "",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := httptest.NewRequest(http.MethodGet, testCase.target, nil)
+			alias, suffix := shortLinkPath(r)
+			mdtest.Equal(t, testCase.expectedAlias, alias)
+			mdtest.Equal(t, testCase.expectedSuffix, suffix)
+		})
+	}
+}
+
+func TestExportURLs(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	user := entity.User{Email: "alpha@example.com"}
+	otherUser := entity.User{Email: "beta@example.com"}
+
+	var manyUsers []entity.User
+	var manyURLs []entity.URL
+	var manyAliases []string
+	for idx := 0; idx <= exportPageSize; idx++ {
+		alias := fmt.Sprintf("a%03d", idx)
+		manyUsers = append(manyUsers, user)
+		manyURLs = append(manyURLs, entity.URL{
+			Alias:       alias,
+			OriginalURL: "https://www.google.com",
+		})
+		manyAliases = append(manyAliases, alias)
+	}
+
+	testCases := []struct {
+		name                string
+		isSignedIn          bool
+		format              string
+		users               []entity.User
+		urls                []entity.URL
+		expectedStatus      int
+		expectedContentType string
+		expectedAliases     []string
+	}{
+		{
+			name:           "not signed in",
+			isSignedIn:     false,
+			format:         formatCSV,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "unsupported format",
+			isSignedIn:     true,
+			format:         "xml",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "csv with urls of the user only",
+			isSignedIn: true,
+			format:     formatCSV,
+			users:      []entity.User{user, otherUser, user},
+			urls: []entity.URL{
+				{Alias: "mozilla", OriginalURL: "https://www.mozilla.org"},
+				{Alias: "github", OriginalURL: "https://www.github.com"},
+				{Alias: "google", OriginalURL: "https://www.google.com"},
+			},
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "text/csv",
+			expectedAliases:     []string{"google", "mozilla"},
+		},
+		{
+			name:                "ndjson across pages",
+			isSignedIn:          true,
+			format:              formatNDJSON,
+			users:               manyUsers,
+			urls:                manyURLs,
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/x-ndjson",
+			expectedAliases:     manyAliases,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			logger := mdtest.NewLoggerFake(mdtest.FakeLoggerArgs{})
+			tracer := mdtest.NewTracerFake()
+			publicURLRepo := repository.NewPublicURLFake(nil)
+			urlRepo := repository.NewURLFake(nil)
+			userURLRepo := repository.NewUserURLRepoFake(testCase.users, testCase.urls, &publicURLRepo)
+			retriever := url.NewRetrieverPersist(&urlRepo, &userURLRepo, &publicURLRepo)
+			authenticator := auth.NewAuthenticatorFake(now, time.Hour)
+			handle := NewExportURLs(&logger, &tracer, retriever, authenticator)
+
+			r := httptest.NewRequest(http.MethodGet, "/urls/export", nil)
+			if testCase.isSignedIn {
+				token, err := authenticator.GenerateToken(user)
+				mdtest.Equal(t, nil, err)
+				r.Header.Set("Authorization", bearerPrefix+token)
+			}
+			w := httptest.NewRecorder()
+			handle(w, r, fw.Params{"format": testCase.format})
+
+			mdtest.Equal(t, testCase.expectedStatus, w.Code)
+			if testCase.expectedStatus != http.StatusOK {
+				return
+			}
+			mdtest.Equal(t, testCase.expectedContentType, w.Header().Get("Content-Type"))
+			mdtest.Equal(t, 0, len(logger.Errors))
+
+			decoder, err := newURLDecoder(testCase.format, w.Body)
+			mdtest.Equal(t, nil, err)
+
+			var aliases []string
+			for {
+				record, err := decoder.Decode()
+				if err == io.EOF {
+					break
+				}
+				mdtest.Equal(t, nil, err)
+				aliases = append(aliases, record.Alias)
+			}
+			mdtest.Equal(t, testCase.expectedAliases, aliases)
+		})
+	}
+}
+
+func TestImportURLs(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	user := entity.User{Email: "alpha@example.com"}
+	oversizedBody := "originalURL\n\"" + strings.Repeat("a", maxImportSize) + "\"\n"
+
+	testCases := []struct {
+		name           string
+		isSignedIn     bool
+		format         string
+		body           string
+		urls           map[string]entity.URL
+		createLimit    int
+		expectedStatus int
+		expectedReport importReport
+	}{
+		{
+			name:           "not signed in",
+			isSignedIn:     false,
+			format:         formatCSV,
+			body:           "originalURL\nhttps://www.google.com\n",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "unsupported format",
+			isSignedIn:     true,
+			format:         "xml",
+			body:           "<urls></urls>",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "csv missing originalURL column",
+			isSignedIn:     true,
+			format:         formatCSV,
+			body:           "alias\ngoogle\n",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "body too large",
+			isSignedIn:     true,
+			format:         formatCSV,
+			body:           oversizedBody,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "csv with malformed rows",
+			isSignedIn: true,
+			format:     formatCSV,
+			body: "alias,originalURL,expireAt\n" +
+				"google,https://www.google.com,\n" +
+				"mozilla,https://www.mozilla.org,tomorrow\n" +
+				"taken,https://www.github.com,\n" +
+				",invalid,\n" +
+				",https://www.github.com,\n",
+			urls: map[string]entity.URL{
+				"taken": {Alias: "taken"},
+			},
+			expectedStatus: http.StatusOK,
+			expectedReport: importReport{
+				CreatedCount: 2,
+				Failures: []importFailure{
+					{Row: 2, ErrorCode: "invalidRecord"},
+					{Row: 3, Alias: "taken", ErrorCode: "aliasAlreadyExist"},
+					{Row: 4, ErrorCode: "invalidLongLink"},
+				},
+			},
+		},
+		{
+			name:       "ndjson with malformed lines",
+			isSignedIn: true,
+			format:     formatNDJSON,
+			body: "{\"alias\":\"google\",\"originalURL\":\"https://www.google.com\"}\n" +
+				"{\"alias\":\"mozilla\",\n" +
+				"{\"originalURL\":\"https://www.github.com\"}\n",
+			expectedStatus: http.StatusOK,
+			expectedReport: importReport{
+				CreatedCount: 2,
+				Failures: []importFailure{
+					{Row: 2, ErrorCode: "invalidRecord"},
+				},
+			},
+		},
+		{
+			name:       "rate limited partially",
+			isSignedIn: true,
+			format:     formatCSV,
+			body: "alias,originalURL\n" +
+				"google,https://www.google.com\n" +
+				"mozilla,https://www.mozilla.org\n" +
+				"github,https://www.github.com\n",
+			createLimit:    2,
+			expectedStatus: http.StatusOK,
+			expectedReport: importReport{
+				CreatedCount: 2,
+				Failures: []importFailure{
+					{Row: 3, Alias: "github", ErrorCode: "rateLimited"},
+				},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			logger := mdtest.NewLoggerFake(mdtest.FakeLoggerArgs{})
+			tracer := mdtest.NewTracerFake()
+			timer := mdtest.NewTimerFake(now)
+
+			urlRepo := repository.NewURLFake(testCase.urls)
+			publicURLRepo := repository.NewPublicURLFake(nil)
+			userURLRepo := repository.NewUserURLRepoFake(nil, nil, &publicURLRepo)
+			urlBatchRepo := repository.NewURLBatchFake(&urlRepo, &userURLRepo)
+			domainRepo := repository.NewDomainFake(nil)
+			keyFetcher := service.NewKeyFetcherFake([]service.Key{"0K", "0L", "0M"})
+			keyGen, err := keygen.NewKeyGenerator(3, 0, &keyFetcher, service.NewCounterFake(), timer)
+			mdtest.Equal(t, nil, err)
+			linkChecker := linksafety.NewChecker(
+				linksafety.Blocklist{},
+				nil,
+				service.NewRedirectTracerFake(nil),
+				0,
+				0,
+			)
+			creator := url.NewCreatorPersist(
+				&urlRepo,
+				&userURLRepo,
+				&publicURLRepo,
+				&urlBatchRepo,
+				&domainRepo,
+				keyGen,
+				validator.NewLongLink(),
+				validator.NewCustomAlias(),
+				linkChecker,
+				password.NewHasher(),
+				timer,
+			)
+
+			tokenBucketRepo := repository.NewTokenBucketFake()
+			rateLimiter := ratelimit.NewLimiter(
+				&tokenBucketRepo,
+				&timer,
+				map[ratelimit.Action]entity.RateLimit{
+					ratelimit.ActionCreateURL: {Requests: testCase.createLimit, Period: time.Minute},
+				},
+			)
+			authenticator := auth.NewAuthenticatorFake(now, time.Hour)
+			handle := NewImportURLs(&logger, &tracer, creator, authenticator, rateLimiter)
+
+			r := httptest.NewRequest(http.MethodPost, "/urls/import", strings.NewReader(testCase.body))
+			if testCase.isSignedIn {
+				token, err := authenticator.GenerateToken(user)
+				mdtest.Equal(t, nil, err)
+				r.Header.Set("Authorization", bearerPrefix+token)
+			}
+			w := httptest.NewRecorder()
+			handle(w, r, fw.Params{"format": testCase.format})
+
+			mdtest.Equal(t, testCase.expectedStatus, w.Code)
+			if testCase.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var report importReport
+			err = json.NewDecoder(w.Body).Decode(&report)
+			mdtest.Equal(t, nil, err)
+			mdtest.Equal(t, testCase.expectedReport, report)
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package routing
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/short-d/app/fw"
+	"github.com/short-d/short/app/usecase/health"
+)
+
+// componentHealth represents the status of a component in health reports.
+// The reasons components are down are only logged since they may reveal
+// internal details of the service.
+type componentHealth struct {
+	Status health.Status `json:"status"`
+}
+
+// healthReport represents the status of the service and each of its
+// components in health reports.
+type healthReport struct {
+	Status     health.Status              `json:"status"`
+	Components map[string]componentHealth `json:"components,omitempty"`
+}
+
+// NewHealthz answers with 200 as long as the service is able to serve
+// requests, so that the orchestrator only restarts the service when it stops
+// responding. The components the service depends on are not probed, since
+// restarting the service doesn't bring them back.
+func NewHealthz(logger fw.Logger) fw.Handle {
+	return func(w http.ResponseWriter, r *http.Request, params fw.Params) {
+		report := health.Report{Status: health.StatusUp}
+		serveHealthReport(logger, w, report, http.StatusOK)
+	}
+}
+
+// NewReadyz reports the status of each component the service depends on,
+// answering with 503 when any of them is down so that the orchestrator stops
+// routing traffic to the service until it recovers.
+func NewReadyz(logger fw.Logger, healthChecker health.Checker) fw.Handle {
+	return func(w http.ResponseWriter, r *http.Request, params fw.Params) {
+		report := healthChecker.Check(r.Context())
+
+		status := http.StatusOK
+		if report.Status != health.StatusUp {
+			status = http.StatusServiceUnavailable
+		}
+		serveHealthReport(logger, w, report, status)
+	}
+}
+
+func serveHealthReport(
+	logger fw.Logger,
+	w http.ResponseWriter,
+	report health.Report,
+	status int,
+) {
+	body := healthReport{
+		Status:     report.Status,
+		Components: make(map[string]componentHealth),
+	}
+	for _, component := range report.Components {
+		if component.Err != nil {
+			logger.Error(fmt.Errorf("component is down (component=%s): %s", component.Name, component.Err))
+		}
+		body.Components[component.Name] = componentHealth{Status: component.Status}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	err := json.NewEncoder(w).Encode(body)
+	if err != nil {
+		logger.Error(err)
+	}
+}
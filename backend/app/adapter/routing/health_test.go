@@ -0,0 +1,91 @@
+// +build !integration all
+
+package routing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw"
+	"github.com/short-d/app/mdtest"
+	"github.com/short-d/short/app/usecase/health"
+)
+
+func TestHealthz(t *testing.T) {
+	t.Parallel()
+
+	logger := mdtest.NewLoggerFake(mdtest.FakeLoggerArgs{})
+	handle := NewHealthz(&logger)
+
+	r := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	w := httptest.NewRecorder()
+	handle(w, r, fw.Params{})
+
+	mdtest.Equal(t, http.StatusOK, w.Code)
+	mdtest.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	mdtest.Equal(t, `{"status":"up"}`+"\n", w.Body.String())
+	mdtest.Equal(t, 0, len(logger.Errors))
+}
+
+func TestReadyz(t *testing.T) {
+	t.Parallel()
+
+	up := func(ctx context.Context) error {
+		return nil
+	}
+	down := func(ctx context.Context) error {
+		return errors.New("connection refused")
+	}
+
+	testCases := []struct {
+		name           string
+		probes         map[string]health.Probe
+		expectedStatus int
+		expectedBody   string
+		expectedErrs   int
+	}{
+		{
+			name: "service ready",
+			probes: map[string]health.Probe{
+				"database":     up,
+				"keyGenerator": up,
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"status":"up","components":{"database":{"status":"up"},"keyGenerator":{"status":"up"}}}` + "\n",
+		},
+		{
+			name: "service not ready with component down",
+			probes: map[string]health.Probe{
+				"database":     up,
+				"keyGenerator": down,
+			},
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   `{"status":"down","components":{"database":{"status":"up"},"keyGenerator":{"status":"down"}}}` + "\n",
+			expectedErrs:   1,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			logger := mdtest.NewLoggerFake(mdtest.FakeLoggerArgs{})
+			healthChecker := health.NewChecker(testCase.probes, time.Second)
+			handle := NewReadyz(&logger, healthChecker)
+
+			r := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+			w := httptest.NewRecorder()
+			handle(w, r, fw.Params{})
+
+			mdtest.Equal(t, testCase.expectedStatus, w.Code)
+			mdtest.Equal(t, "application/json", w.Header().Get("Content-Type"))
+			mdtest.Equal(t, testCase.expectedBody, w.Body.String())
+			mdtest.Equal(t, testCase.expectedErrs, len(logger.Errors))
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package routing
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ClientIPResolver finds the IP address of visitors. X-Forwarded-For header is
+// controlled by the client, so it is only honored for the hops appended by
+// trusted proxies.
+type ClientIPResolver struct {
+	trustedProxies []*net.IPNet
+}
+
+// ClientIP retrieves the IP address of the visitor. When the request comes
+// from a trusted proxy, X-Forwarded-For header is walked from the right and
+// the first address not belonging to a trusted proxy is the visitor.
+// Otherwise, the address of the peer connecting to the server is used.
+func (c ClientIPResolver) ClientIP(r *http.Request) string {
+	ip := remoteIP(r)
+	if !c.isTrusted(ip) {
+		return ip
+	}
+
+	var hops []string
+	for _, header := range r.Header["X-Forwarded-For"] {
+		hops = append(hops, strings.Split(header, ",")...)
+	}
+	for idx := len(hops) - 1; idx >= 0; idx-- {
+		hop := strings.TrimSpace(hops[idx])
+		if hop == "" {
+			continue
+		}
+		if !c.isTrusted(hop) {
+			return hop
+		}
+		ip = hop
+	}
+	return ip
+}
+
+func (c ClientIPResolver) isTrusted(ip string) bool {
+	parsedIP := net.ParseIP(ip)
+	if parsedIP == nil {
+		return false
+	}
+	for _, proxy := range c.trustedProxies {
+		if proxy.Contains(parsedIP) {
+			return true
+		}
+	}
+	return false
+}
+
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// ParseTrustedProxies parses the IP addresses and CIDR ranges of the proxies
+// allowed to report the address of visitors.
+func ParseTrustedProxies(proxies []string) ([]*net.IPNet, error) {
+	var networks []*net.IPNet
+	for _, proxy := range proxies {
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy (proxy=%s)", proxy)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip = ip.To4()
+				bits = 8 * net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, err
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// NewClientIPResolver creates ClientIPResolver which trusts X-Forwarded-For
+// header set by the given proxies. Without trusted proxies, the header is
+// ignored.
+func NewClientIPResolver(trustedProxies []*net.IPNet) ClientIPResolver {
+	return ClientIPResolver{trustedProxies: trustedProxies}
+}
@@ -0,0 +1,121 @@
+// +build !integration all
+
+package routing
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/short-d/app/mdtest"
+)
+
+func TestClientIPResolver_ClientIP(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name           string
+		trustedProxies []string
+		remoteAddr     string
+		forwardedFor   []string
+		expectedIP     string
+	}{
+		{
+			name:       "without forwarded for",
+			remoteAddr: "203.0.113.7:52100",
+			expectedIP: "203.0.113.7",
+		},
+		{
+			name:         "untrusted peer",
+			remoteAddr:   "203.0.113.7:52100",
+			forwardedFor: []string{"198.51.100.1"},
+			expectedIP:   "203.0.113.7",
+		},
+		{
+			name:           "trusted proxy",
+			trustedProxies: []string{"10.0.0.0/8"},
+			remoteAddr:     "10.0.0.2:52100",
+			forwardedFor:   []string{"198.51.100.1"},
+			expectedIP:     "198.51.100.1",
+		},
+		{
+			name:           "spoofed left-most hop",
+			trustedProxies: []string{"10.0.0.0/8"},
+			remoteAddr:     "10.0.0.2:52100",
+			forwardedFor:   []string{"192.0.2.99, 198.51.100.1"},
+			expectedIP:     "198.51.100.1",
+		},
+		{
+			name:           "chain of trusted proxies",
+			trustedProxies: []string{"10.0.0.0/8", "172.16.0.3"},
+			remoteAddr:     "10.0.0.2:52100",
+			forwardedFor:   []string{"192.0.2.99, 198.51.100.1", "172.16.0.3"},
+			expectedIP:     "198.51.100.1",
+		},
+		{
+			name:           "only trusted hops",
+			trustedProxies: []string{"10.0.0.0/8"},
+			remoteAddr:     "10.0.0.2:52100",
+			forwardedFor:   []string{"10.0.0.5"},
+			expectedIP:     "10.0.0.5",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			trustedProxies, err := ParseTrustedProxies(testCase.trustedProxies)
+			mdtest.Equal(t, nil, err)
+			resolver := NewClientIPResolver(trustedProxies)
+
+			r := httptest.NewRequest("GET", "/r/220uFicCJj", nil)
+			r.RemoteAddr = testCase.remoteAddr
+			for _, forwardedFor := range testCase.forwardedFor {
+				r.Header.Add("X-Forwarded-For", forwardedFor)
+			}
+
+			mdtest.Equal(t, testCase.expectedIP, resolver.ClientIP(r))
+		})
+	}
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name    string
+		proxies []string
+		hasErr  bool
+	}{
+		{
+			name:    "IP addresses and CIDR ranges",
+			proxies: []string{"10.0.0.1", "172.16.0.0/12", "::1", "fd00::/8"},
+			hasErr:  false,
+		},
+		{
+			name:    "invalid IP address",
+			proxies: []string{"proxy.example.com"},
+			hasErr:  true,
+		},
+		{
+			name:    "invalid CIDR range",
+			proxies: []string{"10.0.0.0/33"},
+			hasErr:  true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := ParseTrustedProxies(testCase.proxies)
+			if testCase.hasErr {
+				mdtest.NotEqual(t, nil, err)
+				return
+			}
+			mdtest.Equal(t, nil, err)
+		})
+	}
+}
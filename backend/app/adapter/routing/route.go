@@ -1,36 +1,68 @@
 package routing
 
 import (
+	"fmt"
+	"net/http"
 	netURL "net/url"
+	"time"
 
 	"github.com/short-d/app/fw"
 	"github.com/short-d/short/app/adapter/facebook"
 	"github.com/short-d/short/app/adapter/github"
 	"github.com/short-d/short/app/adapter/google"
 	"github.com/short-d/short/app/usecase/account"
+	"github.com/short-d/short/app/usecase/analytics"
 	"github.com/short-d/short/app/usecase/auth"
+	"github.com/short-d/short/app/usecase/customdomain"
+	"github.com/short-d/short/app/usecase/health"
+	"github.com/short-d/short/app/usecase/ratelimit"
+	"github.com/short-d/short/app/usecase/redirectrule"
+	"github.com/short-d/short/app/usecase/service"
 	"github.com/short-d/short/app/usecase/sso"
 	"github.com/short-d/short/app/usecase/url"
 )
 
 // Observability represents a set of metrics data producers which improve the observability of the
-// system, such as logger and tracer.
+// system, such as logger, tracer and metrics.
 type Observability struct {
-	Logger fw.Logger
-	Tracer fw.Tracer
+	Logger  fw.Logger
+	Tracer  fw.Tracer
+	Metrics Metrics
 }
 
-// NewShort creates HTTP routing table.
+// Metrics represents the measurements of the routing API, exported to
+// Prometheus through Exporter. Redirects are counted by outcome, while SSO
+// sign ins are counted by identity provider and result.
+type Metrics struct {
+	RedirectCounter service.Counter
+	SignInCounter   service.Counter
+	Exporter        http.Handler
+}
+
+// NewShort creates HTTP routing table. Requests interacting with storage are
+// abandoned after requestTimeout, except for exports and imports which stream
+// URLs for as long as the client stays connected.
 func NewShort(
 	observability Observability,
 	webFrontendURL string,
+	comingSoonURL string,
+	defaultRedirectStatus int,
+	requestTimeout time.Duration,
 	timer fw.Timer,
 	urlRetriever url.Retriever,
+	urlCreator url.Creator,
+	urlUnlocker url.Unlocker,
+	domainManager customdomain.Manager,
+	ruleResolver redirectrule.Resolver,
+	clickRecorder analytics.Recorder,
+	rateLimiter ratelimit.Limiter,
+	ipResolver ClientIPResolver,
 	githubAPI github.API,
 	facebookAPI facebook.API,
 	googleAPI google.API,
 	authenticator auth.Authenticator,
 	accountProvider account.Provider,
+	healthChecker health.Checker,
 ) []fw.Route {
 	githubSignIn := sso.NewSingleSignOn(
 		githubAPI.IdentityProvider,
@@ -54,8 +86,13 @@ func NewShort(
 	if err != nil {
 		panic(err)
 	}
+	comingSoonPageURL := newComingSoonURL(*frontendURL, comingSoonURL)
+	if !url.IsRedirectStatusValid(&defaultRedirectStatus) {
+		panic(fmt.Sprintf("unsupported default redirect status: %d", defaultRedirectStatus))
+	}
 	logger := observability.Logger
 	tracer := observability.Tracer
+	metrics := observability.Metrics
 	return []fw.Route{
 		{
 			Method: "GET",
@@ -71,11 +108,16 @@ func NewShort(
 		{
 			Method: "GET",
 			Path:   "/oauth/github/sign-in/callback",
-			Handle: NewSSOSignInCallback(
-				logger,
-				tracer,
-				githubSignIn,
-				*frontendURL,
+			Handle: withDeadline(
+				requestTimeout,
+				NewSSOSignInCallback(
+					logger,
+					tracer,
+					metrics.SignInCounter,
+					"github",
+					githubSignIn,
+					*frontendURL,
+				),
 			),
 		},
 		{
@@ -92,11 +134,16 @@ func NewShort(
 		{
 			Method: "GET",
 			Path:   "/oauth/facebook/sign-in/callback",
-			Handle: NewSSOSignInCallback(
-				logger,
-				tracer,
-				facebookSignIn,
-				*frontendURL,
+			Handle: withDeadline(
+				requestTimeout,
+				NewSSOSignInCallback(
+					logger,
+					tracer,
+					metrics.SignInCounter,
+					"facebook",
+					facebookSignIn,
+					*frontendURL,
+				),
 			),
 		},
 		{
@@ -113,23 +160,113 @@ func NewShort(
 		{
 			Method: "GET",
 			Path:   "/oauth/google/sign-in/callback",
-			Handle: NewSSOSignInCallback(
-				logger,
-				tracer,
-				googleSignIn,
-				*frontendURL,
+			Handle: withDeadline(
+				requestTimeout,
+				NewSSOSignInCallback(
+					logger,
+					tracer,
+					metrics.SignInCounter,
+					"google",
+					googleSignIn,
+					*frontendURL,
+				),
 			),
 		},
 		{
-			Method: "GET",
+			Method:      "GET",
+			Path:        "/r/:alias",
+			MatchPrefix: true,
+			Handle: withDeadline(
+				requestTimeout,
+				NewOriginalURL(
+					logger,
+					tracer,
+					metrics.RedirectCounter,
+					urlRetriever,
+					domainManager,
+					ruleResolver,
+					clickRecorder,
+					rateLimiter,
+					ipResolver,
+					timer,
+					*frontendURL,
+					comingSoonPageURL,
+					defaultRedirectStatus,
+				),
+			),
+		},
+		{
+			Method: "POST",
 			Path:   "/r/:alias",
-			Handle: NewOriginalURL(
+			Handle: withDeadline(
+				requestTimeout,
+				NewUnlockURL(
+					logger,
+					tracer,
+					metrics.RedirectCounter,
+					urlUnlocker,
+					domainManager,
+					ruleResolver,
+					clickRecorder,
+					rateLimiter,
+					ipResolver,
+					timer,
+					*frontendURL,
+					comingSoonPageURL,
+				),
+			),
+		},
+		{
+			Method: "GET",
+			Path:   "/urls/export",
+			Handle: NewExportURLs(
 				logger,
 				tracer,
 				urlRetriever,
-				timer,
-				*frontendURL,
+				authenticator,
+			),
+		},
+		{
+			Method: "POST",
+			Path:   "/urls/import",
+			Handle: NewImportURLs(
+				logger,
+				tracer,
+				urlCreator,
+				authenticator,
+				rateLimiter,
 			),
 		},
+		{
+			Method: "GET",
+			Path:   "/metrics",
+			Handle: NewMetrics(metrics.Exporter),
+		},
+		{
+			Method: "GET",
+			Path:   "/healthz",
+			Handle: NewHealthz(logger),
+		},
+		{
+			Method: "GET",
+			Path:   "/readyz",
+			Handle: NewReadyz(logger, healthChecker),
+		},
+	}
+}
+
+// newComingSoonURL falls back to the coming soon page of the web frontend when
+// no destination is configured for the short links which are not activated
+// yet.
+func newComingSoonURL(webFrontendURL netURL.URL, comingSoonURL string) netURL.URL {
+	if comingSoonURL == "" {
+		webFrontendURL.Path = "/coming-soon"
+		return webFrontendURL
+	}
+
+	u, err := netURL.Parse(comingSoonURL)
+	if err != nil {
+		panic(err)
 	}
+	return *u
 }
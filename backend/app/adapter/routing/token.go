@@ -1,15 +1,29 @@
 package routing
 
 import (
+	"net/http"
 	"net/url"
+	"strings"
 
 	"github.com/short-d/app/fw"
 )
 
+const bearerPrefix = "Bearer "
+
 func getToken(params fw.Params) string {
 	return params["token"]
 }
 
+// getAuthToken retrieves authentication token from Authorization header,
+// falling back to token parameter.
+func getAuthToken(r *http.Request, params fw.Params) string {
+	header := r.Header.Get("Authorization")
+	if strings.HasPrefix(header, bearerPrefix) {
+		return strings.TrimPrefix(header, bearerPrefix)
+	}
+	return getToken(params)
+}
+
 func setToken(url url.URL, token string) url.URL {
 	query := url.Query()
 	query.Set("token", token)
@@ -0,0 +1,249 @@
+package routing
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/short-d/short/app/entity"
+	"github.com/short-d/short/app/usecase/customdomain"
+	"github.com/short-d/short/app/usecase/url"
+)
+
+const (
+	formatCSV    = "csv"
+	formatNDJSON = "ndjson"
+
+	maxNDJSONLineSize = 1 << 20
+)
+
+// urlRecordColumns ends with domain so that files exported before custom
+// domains keep their layout.
+var urlRecordColumns = []string{"alias", "originalURL", "expireAt", "createdAt", "domain"}
+
+// errInvalidRecord represents a record in an imported file which can't be
+// parsed. The remaining records can still be read.
+type errInvalidRecord string
+
+func (e errInvalidRecord) Error() string {
+	return string(e)
+}
+
+// urlRecord represents a URL in an exported or imported file.
+type urlRecord struct {
+	Alias       string     `json:"alias,omitempty"`
+	OriginalURL string     `json:"originalURL"`
+	ExpireAt    *time.Time `json:"expireAt,omitempty"`
+	CreatedAt   *time.Time `json:"createdAt,omitempty"`
+	Domain      string     `json:"domain,omitempty"`
+}
+
+func newURLRecord(u entity.URL) urlRecord {
+	return urlRecord{
+		Alias:       u.Alias,
+		OriginalURL: u.OriginalURL,
+		ExpireAt:    u.ExpireAt,
+		CreatedAt:   u.CreatedAt,
+		Domain:      u.Domain,
+	}
+}
+
+func (u urlRecord) bulkURL() url.BulkURL {
+	bulkURL := url.BulkURL{
+		URL: entity.URL{
+			Domain:      customdomain.NormalizeHostname(u.Domain),
+			OriginalURL: u.OriginalURL,
+			ExpireAt:    u.ExpireAt,
+		},
+	}
+	if u.Alias != "" {
+		alias := u.Alias
+		bulkURL.CustomAlias = &alias
+	}
+	return bulkURL
+}
+
+type urlEncoder interface {
+	Encode(record urlRecord) error
+	Flush() error
+}
+
+type urlDecoder interface {
+	Decode() (urlRecord, error)
+}
+
+func isFormatSupported(format string) bool {
+	return format == formatCSV || format == formatNDJSON
+}
+
+func contentType(format string) string {
+	if format == formatNDJSON {
+		return "application/x-ndjson"
+	}
+	return "text/csv"
+}
+
+func newURLEncoder(format string, w io.Writer) (urlEncoder, error) {
+	switch format {
+	case formatCSV:
+		writer := csv.NewWriter(w)
+		err := writer.Write(urlRecordColumns)
+		return csvURLEncoder{writer: writer}, err
+	case formatNDJSON:
+		return ndjsonURLEncoder{encoder: json.NewEncoder(w)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported format (format=%s)", format)
+	}
+}
+
+func newURLDecoder(format string, r io.Reader) (urlDecoder, error) {
+	switch format {
+	case formatCSV:
+		return newCSVURLDecoder(r)
+	case formatNDJSON:
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(nil, maxNDJSONLineSize)
+		return ndjsonURLDecoder{scanner: scanner}, nil
+	default:
+		return nil, fmt.Errorf("unsupported format (format=%s)", format)
+	}
+}
+
+type csvURLEncoder struct {
+	writer *csv.Writer
+}
+
+func (c csvURLEncoder) Encode(record urlRecord) error {
+	return c.writer.Write([]string{
+		record.Alias,
+		record.OriginalURL,
+		formatRecordTime(record.ExpireAt),
+		formatRecordTime(record.CreatedAt),
+		record.Domain,
+	})
+}
+
+func (c csvURLEncoder) Flush() error {
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+type csvURLDecoder struct {
+	reader  *csv.Reader
+	columns map[string]int
+}
+
+func (c csvURLDecoder) Decode() (urlRecord, error) {
+	row, err := c.reader.Read()
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return urlRecord{}, errInvalidRecord(parseErr.Error())
+	}
+	if err != nil {
+		return urlRecord{}, err
+	}
+
+	expireAt, err := parseRecordTime(c.field(row, "expireAt"))
+	if err != nil {
+		return urlRecord{}, err
+	}
+	return urlRecord{
+		Alias:       c.field(row, "alias"),
+		OriginalURL: c.field(row, "originalURL"),
+		ExpireAt:    expireAt,
+		Domain:      c.field(row, "domain"),
+	}, nil
+}
+
+func (c csvURLDecoder) field(row []string, column string) string {
+	idx, ok := c.columns[column]
+	if !ok || idx >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[idx])
+}
+
+func newCSVURLDecoder(r io.Reader) (csvURLDecoder, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return csvURLDecoder{}, err
+	}
+
+	columns := make(map[string]int)
+	for idx, column := range header {
+		columns[strings.TrimSpace(column)] = idx
+	}
+	if _, ok := columns["originalURL"]; !ok {
+		return csvURLDecoder{}, errors.New("originalURL column is missing")
+	}
+	return csvURLDecoder{
+		reader:  reader,
+		columns: columns,
+	}, nil
+}
+
+type ndjsonURLEncoder struct {
+	encoder *json.Encoder
+}
+
+func (n ndjsonURLEncoder) Encode(record urlRecord) error {
+	return n.encoder.Encode(record)
+}
+
+func (n ndjsonURLEncoder) Flush() error {
+	return nil
+}
+
+type ndjsonURLDecoder struct {
+	scanner *bufio.Scanner
+}
+
+func (n ndjsonURLDecoder) Decode() (urlRecord, error) {
+	for n.scanner.Scan() {
+		line := strings.TrimSpace(n.scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var record urlRecord
+		err := json.Unmarshal([]byte(line), &record)
+		if err != nil {
+			return urlRecord{}, errInvalidRecord(err.Error())
+		}
+		record.CreatedAt = nil
+		return record, nil
+	}
+
+	err := n.scanner.Err()
+	if err != nil {
+		return urlRecord{}, err
+	}
+	return urlRecord{}, io.EOF
+}
+
+func formatRecordTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func parseRecordTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, errInvalidRecord(err.Error())
+	}
+	return &t, nil
+}
@@ -0,0 +1,261 @@
+// +build !integration all
+
+package routing
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/mdtest"
+)
+
+func TestURLEncoder_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	expireAt := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	createdAt := time.Date(2019, 12, 31, 23, 59, 59, 0, time.UTC)
+	records := []urlRecord{
+		{
+			Alias:       "google",
+			OriginalURL: "https://www.google.com/search?q=a,b&hl=\"en\"",
+			ExpireAt:    &expireAt,
+			CreatedAt:   &createdAt,
+			Domain:      "s.example.com",
+		},
+		{
+			OriginalURL: "https://www.mozilla.org",
+		},
+	}
+
+	// The creation time is assigned by the system when URLs are imported.
+	expectedRecords := []urlRecord{
+		{
+			Alias:       "google",
+			OriginalURL: "https://www.google.com/search?q=a,b&hl=\"en\"",
+			ExpireAt:    &expireAt,
+			Domain:      "s.example.com",
+		},
+		{
+			OriginalURL: "https://www.mozilla.org",
+		},
+	}
+
+	testCases := []struct {
+		name   string
+		format string
+	}{
+		{name: "csv", format: formatCSV},
+		{name: "ndjson", format: formatNDJSON},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			buf := bytes.Buffer{}
+			encoder, err := newURLEncoder(testCase.format, &buf)
+			mdtest.Equal(t, nil, err)
+			for _, record := range records {
+				err = encoder.Encode(record)
+				mdtest.Equal(t, nil, err)
+			}
+			err = encoder.Flush()
+			mdtest.Equal(t, nil, err)
+
+			decoder, err := newURLDecoder(testCase.format, &buf)
+			mdtest.Equal(t, nil, err)
+
+			var decodedRecords []urlRecord
+			for {
+				record, err := decoder.Decode()
+				if err == io.EOF {
+					break
+				}
+				mdtest.Equal(t, nil, err)
+				decodedRecords = append(decodedRecords, record)
+			}
+			mdtest.Equal(t, expectedRecords, decodedRecords)
+		})
+	}
+}
+
+func TestURLEncoder_CSV(t *testing.T) {
+	t.Parallel()
+
+	expireAt := time.Date(2020, 1, 2, 3, 4, 5, 0, time.FixedZone("PST", -8*60*60))
+	buf := bytes.Buffer{}
+	encoder, err := newURLEncoder(formatCSV, &buf)
+	mdtest.Equal(t, nil, err)
+
+	err = encoder.Encode(urlRecord{
+		Alias:       "google",
+		OriginalURL: "https://www.google.com",
+		ExpireAt:    &expireAt,
+	})
+	mdtest.Equal(t, nil, err)
+	err = encoder.Flush()
+	mdtest.Equal(t, nil, err)
+
+	expectedOutput := "alias,originalURL,expireAt,createdAt,domain\n" +
+		"google,https://www.google.com,2020-01-02T11:04:05Z,,\n"
+	mdtest.Equal(t, expectedOutput, buf.String())
+}
+
+func TestNewURLDecoder(t *testing.T) {
+	t.Parallel()
+
+	expireAt := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	// decodeResult represents the outcome of a single call to Decode, which
+	// either reads a record or reports an invalid one.
+	type decodeResult struct {
+		record    urlRecord
+		isInvalid bool
+	}
+
+	testCases := []struct {
+		name            string
+		format          string
+		input           string
+		hasErr          bool
+		expectedResults []decodeResult
+	}{
+		{
+			name:   "unsupported format",
+			format: "xml",
+			input:  "<urls></urls>",
+			hasErr: true,
+		},
+		{
+			name:   "csv without header",
+			format: formatCSV,
+			input:  "",
+			hasErr: true,
+		},
+		{
+			name:   "csv missing originalURL column",
+			format: formatCSV,
+			input:  "alias,domain\ngoogle,s.example.com\n",
+			hasErr: true,
+		},
+		{
+			name:   "csv with only originalURL column",
+			format: formatCSV,
+			input:  "originalURL\nhttps://www.google.com\n",
+			expectedResults: []decodeResult{
+				{record: urlRecord{OriginalURL: "https://www.google.com"}},
+			},
+		},
+		{
+			name:   "csv columns in any order",
+			format: formatCSV,
+			input: " domain , originalURL ,alias,expireAt\n" +
+				"s.example.com, https://www.google.com ,google,2020-01-02T03:04:05Z\n",
+			expectedResults: []decodeResult{
+				{
+					record: urlRecord{
+						Alias:       "google",
+						OriginalURL: "https://www.google.com",
+						ExpireAt:    &expireAt,
+						Domain:      "s.example.com",
+					},
+				},
+			},
+		},
+		{
+			name:   "csv rows missing trailing fields",
+			format: formatCSV,
+			input:  "originalURL,alias,domain\nhttps://www.google.com\n",
+			expectedResults: []decodeResult{
+				{record: urlRecord{OriginalURL: "https://www.google.com"}},
+			},
+		},
+		{
+			name:   "csv malformed rows",
+			format: formatCSV,
+			input: "alias,originalURL,expireAt\n" +
+				"google,https://www.google.com,tomorrow\n" +
+				"mozilla,\"https://www.mozilla.org\"x\n" +
+				"github,https://www.github.com,\n",
+			expectedResults: []decodeResult{
+				{isInvalid: true},
+				{isInvalid: true},
+				{
+					record: urlRecord{
+						Alias:       "github",
+						OriginalURL: "https://www.github.com",
+					},
+				},
+			},
+		},
+		{
+			name:   "ndjson skips blank lines and creation time",
+			format: formatNDJSON,
+			input: "{\"alias\":\"google\",\"originalURL\":\"https://www.google.com\",\"createdAt\":\"2019-12-31T23:59:59Z\"}\n" +
+				"\n" +
+				"  \n" +
+				"{\"originalURL\":\"https://www.mozilla.org\",\"expireAt\":\"2020-01-02T03:04:05Z\"}\n",
+			expectedResults: []decodeResult{
+				{
+					record: urlRecord{
+						Alias:       "google",
+						OriginalURL: "https://www.google.com",
+					},
+				},
+				{
+					record: urlRecord{
+						OriginalURL: "https://www.mozilla.org",
+						ExpireAt:    &expireAt,
+					},
+				},
+			},
+		},
+		{
+			name:   "ndjson malformed lines",
+			format: formatNDJSON,
+			input: "{\"alias\":\"google\",\n" +
+				"[\"https://www.mozilla.org\"]\n" +
+				"{\"originalURL\":\"https://www.github.com\",\"expireAt\":\"tomorrow\"}\n" +
+				"{\"originalURL\":\"https://www.github.com\"}\n",
+			expectedResults: []decodeResult{
+				{isInvalid: true},
+				{isInvalid: true},
+				{isInvalid: true},
+				{record: urlRecord{OriginalURL: "https://www.github.com"}},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			decoder, err := newURLDecoder(testCase.format, strings.NewReader(testCase.input))
+			if testCase.hasErr {
+				mdtest.NotEqual(t, nil, err)
+				return
+			}
+			mdtest.Equal(t, nil, err)
+
+			var results []decodeResult
+			for {
+				record, err := decoder.Decode()
+				if err == io.EOF {
+					break
+				}
+				if _, ok := err.(errInvalidRecord); ok {
+					results = append(results, decodeResult{isInvalid: true})
+					continue
+				}
+				mdtest.Equal(t, nil, err)
+				results = append(results, decodeResult{record: record})
+			}
+			mdtest.Equal(t, testCase.expectedResults, results)
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package routing
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	netURL "net/url"
+	"strings"
+
+	"github.com/short-d/app/fw"
+	"github.com/short-d/short/app/usecase/url"
+)
+
+// The constants enumerate the reasons of failing to visit a short link,
+// reported to API clients.
+const (
+	urlErrNotFound           = "urlNotFound"
+	urlErrGone               = "urlGone"
+	urlErrServiceUnavailable = "serviceUnavailable"
+)
+
+const errorPage = `<!DOCTYPE html>
+<html>
+<head><meta http-equiv="refresh" content="0; url=%s"></head>
+<body><a href="%s">%s</a></body>
+</html>
+`
+
+// urlError represents the reason of failing to visit a short link.
+type urlError struct {
+	Code  string `json:"code"`
+	Alias string `json:"alias"`
+}
+
+// serveURLError answers a failed visit to a short link with 404 when the alias
+// doesn't exist, 410 when the short link has expired or used up its clicks,
+// and 503 when the short link can't be looked up. API clients asking for JSON
+// receive the reason in the response body, while browsers are sent on to the
+// matching page of the web frontend. It returns the outcome of the visit for
+// the redirect metrics.
+func serveURLError(
+	logger fw.Logger,
+	w http.ResponseWriter,
+	r *http.Request,
+	webFrontendURL netURL.URL,
+	alias string,
+	err error,
+) string {
+	var status int
+	var code, outcome string
+	switch err.(type) {
+	case url.ErrURLNotFound, url.ErrInvalidPathSuffix:
+		logger.Info(err.Error())
+		status, code, outcome = http.StatusNotFound, urlErrNotFound, redirectOutcomeNotFound
+	case url.ErrURLExpired, url.ErrURLExhausted:
+		logger.Debug(err.Error())
+		status, code, outcome = http.StatusGone, urlErrGone, redirectOutcomeGone
+	default:
+		logger.Error(err)
+		status, code, outcome = http.StatusServiceUnavailable, urlErrServiceUnavailable, redirectOutcomeUnavailable
+	}
+
+	if isJSONAccepted(r) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(urlError{Code: code, Alias: alias})
+		return outcome
+	}
+
+	webFrontendURL.Path = fmt.Sprintf("/%d", status)
+	pageURL := html.EscapeString(webFrontendURL.String())
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, errorPage, pageURL, pageURL, pageURL)
+	return outcome
+}
+
+// isJSONAccepted checks whether the client asks for JSON rather than HTML in
+// the Accept header.
+func isJSONAccepted(r *http.Request) bool {
+	isJSON := false
+	for _, mediaRange := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType := strings.TrimSpace(strings.Split(mediaRange, ";")[0])
+		switch strings.ToLower(mediaType) {
+		case "text/html":
+			return false
+		case "application/json":
+			isJSON = true
+		}
+	}
+	return isJSON
+}
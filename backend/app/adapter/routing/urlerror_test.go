@@ -0,0 +1,97 @@
+// +build !integration all
+
+package routing
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	netURL "net/url"
+	"strings"
+	"testing"
+
+	"github.com/short-d/app/mdtest"
+	"github.com/short-d/short/app/usecase/url"
+)
+
+func TestServeURLError(t *testing.T) {
+	t.Parallel()
+
+	webFrontendURL := netURL.URL{Scheme: "https", Host: "short-d.com"}
+
+	testCases := []struct {
+		name           string
+		accept         string
+		err            error
+		expectedStatus int
+		expectedType   string
+		expectedBody   string
+	}{
+		{
+			name:           "alias not found for browser",
+			accept:         "text/html,application/xhtml+xml,*/*;q=0.8",
+			err:            url.ErrURLNotFound("220uFicCJj"),
+			expectedStatus: http.StatusNotFound,
+			expectedType:   "text/html; charset=utf-8",
+			expectedBody:   `content="0; url=https://short-d.com/404"`,
+		},
+		{
+			name:           "alias not found for API client",
+			accept:         "application/json",
+			err:            url.ErrURLNotFound("220uFicCJj"),
+			expectedStatus: http.StatusNotFound,
+			expectedType:   "application/json",
+			expectedBody:   `{"code":"urlNotFound","alias":"220uFicCJj"}`,
+		},
+		{
+			name:           "path suffix not forwarded",
+			accept:         "application/json",
+			err:            url.ErrInvalidPathSuffix("/docs"),
+			expectedStatus: http.StatusNotFound,
+			expectedType:   "application/json",
+			expectedBody:   `{"code":"urlNotFound","alias":"220uFicCJj"}`,
+		},
+		{
+			name:           "url expired",
+			accept:         "application/json; charset=utf-8",
+			err:            url.ErrURLExpired("220uFicCJj"),
+			expectedStatus: http.StatusGone,
+			expectedType:   "application/json",
+			expectedBody:   `{"code":"urlGone","alias":"220uFicCJj"}`,
+		},
+		{
+			name:           "url exhausted for browser",
+			accept:         "",
+			err:            url.ErrURLExhausted("220uFicCJj"),
+			expectedStatus: http.StatusGone,
+			expectedType:   "text/html; charset=utf-8",
+			expectedBody:   `content="0; url=https://short-d.com/410"`,
+		},
+		{
+			name:           "storage failure",
+			accept:         "application/json",
+			err:            url.ErrStorageFailure{Err: errors.New("connection refused")},
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedType:   "application/json",
+			expectedBody:   `{"code":"serviceUnavailable","alias":"220uFicCJj"}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			logger := mdtest.NewLoggerFake(mdtest.FakeLoggerArgs{})
+			r := httptest.NewRequest(http.MethodGet, "/r/220uFicCJj", nil)
+			r.Header.Set("Accept", testCase.accept)
+			w := httptest.NewRecorder()
+
+			serveURLError(&logger, w, r, webFrontendURL, "220uFicCJj", testCase.err)
+
+			mdtest.Equal(t, testCase.expectedStatus, w.Code)
+			mdtest.Equal(t, testCase.expectedType, w.Header().Get("Content-Type"))
+			mdtest.Equal(t, true, strings.Contains(w.Body.String(), testCase.expectedBody))
+		})
+	}
+}
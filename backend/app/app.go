@@ -1,9 +1,18 @@
 package app
 
 import (
+	netURL "net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/short-d/app/fw"
+	"github.com/short-d/app/modern/mdservice"
+	"github.com/short-d/short/app/adapter/kgs"
+	"github.com/short-d/short/app/entity"
 	"github.com/short-d/short/dep"
 	"github.com/short-d/short/dep/provider"
 )
@@ -24,15 +33,58 @@ type ServiceConfig struct {
 	GoogleRedirectURI    string
 	JwtSecret            string
 	WebFrontendURL       string
+	ComingSoonURL        string
+	RedirectStatus       int
 	GraphQLAPIPort       int
 	HTTPAPIPort          int
 	KeyGenBufferSize     int
+	KeyGenLowWaterMark   int
 	KgsHostname          string
 	KgsPort              int
+	KgsPlaintext         bool
+	KgsCACertFile        string
+	KgsClientCertFile    string
+	KgsClientKeyFile     string
+	KgsServerName        string
+	KgsCallTimeout       time.Duration
+	KgsMaxAttempts       int
+	KgsInitialBackoff    time.Duration
+	KgsMaxBackoff        time.Duration
+	KgsFailureThreshold  int
+	KgsOpenDuration      time.Duration
+	KeyGenSource         string
+	KeyGenLocalKeyLength int
 	AuthTokenLifetime    time.Duration
+	ClickBufferSize      int
+	ClickBatchSize       int
+	ClickFlushInterval   time.Duration
+	RateLimitBackend     string
+	CreateURLRateLimit   int
+	CreateURLRatePeriod  time.Duration
+	RedirectRateLimit    int
+	RedirectRatePeriod   time.Duration
+	UnlockURLRateLimit   int
+	UnlockURLRatePeriod  time.Duration
+	BlockedDomainsFile   string
+	BlockedPatternsFile  string
+	ShortLinkHostnames   string
+	LinkRedirectMaxHops  int
+	LinkRedirectTimeout  time.Duration
+	LinkCheckTimeout     time.Duration
+	DomainVerifyTimeout  time.Duration
+	TrustedProxies       string
+	GeoIPDatabaseFile    string
+	URLCacheEnabled      bool
+	URLCacheCapacity     int
+	URLCacheTTL          time.Duration
+	URLCacheNegativeTTL  time.Duration
+	MetricsToken         string
+	RequestTimeout       time.Duration
+	ShutdownTimeout      time.Duration
 }
 
-// Start launches the GraphQL & HTTP APIs
+// Start launches the GraphQL & HTTP APIs, and shuts them down once the process
+// is asked to terminate.
 func Start(
 	dbConfig fw.DBConfig,
 	config ServiceConfig,
@@ -49,31 +101,82 @@ func Start(
 		panic(err)
 	}
 
+	urlCache := dep.InjectURLCache(provider.URLCacheConfig{
+		IsEnabled:   config.URLCacheEnabled,
+		Capacity:    config.URLCacheCapacity,
+		TTL:         config.URLCacheTTL,
+		NegativeTTL: config.URLCacheNegativeTTL,
+	})
+
+	metrics := dep.InjectMetrics(
+		urlCache,
+		provider.MetricsToken(config.MetricsToken),
+	)
+
+	kgsRPC, err := dep.InjectKgsRPC(kgsConfig(config))
+	if err != nil {
+		panic(err)
+	}
+	defer kgsRPC.Close()
+
+	keyGenerator, err := dep.InjectKeyGenerator(
+		provider.LogPrefix(config.LogPrefix),
+		config.LogLevel,
+		db,
+		provider.KeyGenBufferSize(config.KeyGenBufferSize),
+		provider.KeyGenLowWaterMark(config.KeyGenLowWaterMark),
+		kgsRPC,
+		keyFetcherConfig(config),
+		urlCache,
+		metrics,
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	clickRecorder, err := dep.InjectClickRecorder(
+		provider.LogPrefix(config.LogPrefix),
+		config.LogLevel,
+		db,
+		provider.ClickRecorderConfig{
+			BufferSize:    config.ClickBufferSize,
+			BatchSize:     config.ClickBatchSize,
+			FlushInterval: config.ClickFlushInterval,
+		},
+	)
+	if err != nil {
+		panic(err)
+	}
+
 	graphqlAPI, err := dep.InjectGraphQLService(
 		"GraphQL API",
 		provider.LogPrefix(config.LogPrefix),
 		config.LogLevel,
 		db,
 		"/graphql",
+		provider.RequestTimeout(config.RequestTimeout),
 		provider.ReCaptchaSecret(config.RecaptchaSecret),
 		provider.JwtSecret(config.JwtSecret),
-		provider.KeyGenBufferSize(config.KeyGenBufferSize),
-		provider.KgsRPCConfig{
-			Hostname: config.KgsHostname,
-			Port:     config.KgsPort,
-		},
+		keyGenerator,
 		provider.TokenValidDuration(config.AuthTokenLifetime),
+		rateLimitConfig(config),
+		linkSafetyConfig(config),
+		provider.DomainVerifyTimeout(config.DomainVerifyTimeout),
+		defaultHostnames(config),
+		urlCache,
+		metrics,
 	)
 	if err != nil {
 		panic(err)
 	}
 	graphqlAPI.Start(config.GraphQLAPIPort)
 
-	httpAPI := dep.InjectRoutingService(
+	httpAPI, err := dep.InjectRoutingService(
 		"Routing API",
 		provider.LogPrefix(config.LogPrefix),
 		config.LogLevel,
 		db,
+		provider.MigrationRoot(config.MigrationRoot),
 		provider.GithubClientID(config.GithubClientID),
 		provider.GithubClientSecret(config.GithubClientSecret),
 		provider.FacebookClientID(config.FacebookClientID),
@@ -84,7 +187,160 @@ func Start(
 		provider.GoogleRedirectURI(config.GoogleRedirectURI),
 		provider.JwtSecret(config.JwtSecret),
 		provider.WebFrontendURL(config.WebFrontendURL),
+		provider.ComingSoonURL(config.ComingSoonURL),
+		provider.DefaultRedirectStatus(config.RedirectStatus),
+		provider.RequestTimeout(config.RequestTimeout),
 		provider.TokenValidDuration(config.AuthTokenLifetime),
+		clickRecorder,
+		keyGenerator,
+		rateLimitConfig(config),
+		linkSafetyConfig(config),
+		provider.GeoIPDatabaseFile(config.GeoIPDatabaseFile),
+		provider.DomainVerifyTimeout(config.DomainVerifyTimeout),
+		defaultHostnames(config),
+		trustedProxies(config),
+		urlCache,
+		metrics,
 	)
-	httpAPI.StartAndWait(config.HTTPAPIPort)
+	if err != nil {
+		panic(err)
+	}
+	httpAPI.Start(config.HTTPAPIPort)
+
+	waitForTermination()
+	isStopped := stop(config.ShutdownTimeout, graphqlAPI, httpAPI)
+
+	// Requests still in flight after the timeout have their clicks dropped
+	// once the recorder is closed, and keep the database open until exit.
+	clickRecorder.Close()
+	keyGenerator.Close()
+	if !isStopped {
+		return
+	}
+
+	err = db.Close()
+	if err != nil {
+		panic(err)
+	}
+}
+
+func waitForTermination() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	<-signals
+	signal.Stop(signals)
+}
+
+// stop stops accepting new requests and waits for the in-flight requests to
+// finish, giving up after timeout. It reports whether all the requests
+// finished in time.
+func stop(timeout time.Duration, services ...mdservice.Service) bool {
+	wg := sync.WaitGroup{}
+	for _, service := range services {
+		wg.Add(1)
+		go func(service mdservice.Service) {
+			defer wg.Done()
+			service.Stop()
+		}(service)
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+func kgsConfig(config ServiceConfig) kgs.Config {
+	return kgs.Config{
+		Hostname: config.KgsHostname,
+		Port:     config.KgsPort,
+		TLS: kgs.TLSConfig{
+			Plaintext:      config.KgsPlaintext,
+			CACertFile:     config.KgsCACertFile,
+			ClientCertFile: config.KgsClientCertFile,
+			ClientKeyFile:  config.KgsClientKeyFile,
+			ServerName:     config.KgsServerName,
+		},
+		CallTimeout: config.KgsCallTimeout,
+		Retry: kgs.RetryConfig{
+			MaxAttempts:    config.KgsMaxAttempts,
+			InitialBackoff: config.KgsInitialBackoff,
+			MaxBackoff:     config.KgsMaxBackoff,
+		},
+		CircuitBreaker: kgs.CircuitBreakerConfig{
+			FailureThreshold: config.KgsFailureThreshold,
+			OpenDuration:     config.KgsOpenDuration,
+		},
+	}
+}
+
+func keyFetcherConfig(config ServiceConfig) provider.KeyFetcherConfig {
+	return provider.KeyFetcherConfig{
+		Source:         config.KeyGenSource,
+		LocalKeyLength: config.KeyGenLocalKeyLength,
+	}
+}
+
+func rateLimitConfig(config ServiceConfig) provider.RateLimitConfig {
+	return provider.RateLimitConfig{
+		Backend: config.RateLimitBackend,
+		CreateURL: entity.RateLimit{
+			Requests: config.CreateURLRateLimit,
+			Period:   config.CreateURLRatePeriod,
+		},
+		Redirect: entity.RateLimit{
+			Requests: config.RedirectRateLimit,
+			Period:   config.RedirectRatePeriod,
+		},
+		UnlockURL: entity.RateLimit{
+			Requests: config.UnlockURLRateLimit,
+			Period:   config.UnlockURLRatePeriod,
+		},
+	}
+}
+
+func trustedProxies(config ServiceConfig) provider.TrustedProxies {
+	var proxies []string
+	for _, proxy := range strings.Split(config.TrustedProxies, ",") {
+		proxy = strings.TrimSpace(proxy)
+		if proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
+}
+
+func defaultHostnames(config ServiceConfig) provider.DefaultHostnames {
+	var hostnames []string
+	for _, hostname := range strings.Split(config.ShortLinkHostnames, ",") {
+		hostname = strings.TrimSpace(hostname)
+		if hostname != "" {
+			hostnames = append(hostnames, hostname)
+		}
+	}
+
+	webFrontendURL, err := netURL.Parse(config.WebFrontendURL)
+	if err == nil && webFrontendURL.Hostname() != "" {
+		hostnames = append(hostnames, webFrontendURL.Hostname())
+	}
+	return hostnames
+}
+
+func linkSafetyConfig(config ServiceConfig) provider.LinkSafetyConfig {
+	return provider.LinkSafetyConfig{
+		BlockedDomainsFile:  config.BlockedDomainsFile,
+		BlockedPatternsFile: config.BlockedPatternsFile,
+		ShortLinkHostnames:  defaultHostnames(config),
+		MaxRedirectHops:     config.LinkRedirectMaxHops,
+		RedirectTimeout:     config.LinkRedirectTimeout,
+		CheckTimeout:        config.LinkCheckTimeout,
+	}
 }
@@ -0,0 +1,37 @@
+package entity
+
+import "time"
+
+// APIKeyScope represents the operations a personal API key is allowed to
+// perform.
+type APIKeyScope string
+
+// The constants enumerate all supported API key scopes.
+const (
+	APIKeyScopeReadOnly    APIKeyScope = "read-only"
+	APIKeyScopeCreateLinks APIKeyScope = "create-links"
+	APIKeyScopeManageLinks APIKeyScope = "manage-links"
+)
+
+// APIKey represents a named credential a user creates for programmatic access.
+type APIKey struct {
+	ID        string
+	Name      string
+	UserEmail string
+	Scopes    []APIKeyScope
+	CreatedAt time.Time
+}
+
+// HasScope checks whether the key is allowed to perform operations under the
+// given scope. Every key is allowed to read.
+func (k APIKey) HasScope(scope APIKeyScope) bool {
+	if scope == APIKeyScopeReadOnly {
+		return true
+	}
+	for _, keyScope := range k.Scopes {
+		if keyScope == scope {
+			return true
+		}
+	}
+	return false
+}
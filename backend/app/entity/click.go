@@ -0,0 +1,33 @@
+package entity
+
+import "time"
+
+// Click represents a single visit to a short link.
+type Click struct {
+	Domain    string
+	Alias     string
+	ClickedAt time.Time
+	Referrer  string
+	UserAgent string
+	IPAddress string
+}
+
+// DailyClicks represents the number of clicks on a short link during a day.
+type DailyClicks struct {
+	Day   time.Time
+	Count int
+}
+
+// ReferrerClicks represents the number of clicks on a short link coming from a
+// referrer.
+type ReferrerClicks struct {
+	Referrer string
+	Count    int
+}
+
+// DeviceClicks represents the number of clicks on a short link made from a
+// class of devices.
+type DeviceClicks struct {
+	Device string
+	Count  int
+}
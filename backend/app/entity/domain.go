@@ -0,0 +1,19 @@
+package entity
+
+import "time"
+
+// Domain represents a host registered by a user to serve short links under,
+// such as go.example.com. Short links can only be created on the domain once
+// the user proves ownership of it with VerificationToken.
+type Domain struct {
+	Hostname          string
+	OwnerEmail        string
+	VerificationToken string
+	VerifiedAt        *time.Time
+	CreatedAt         *time.Time
+}
+
+// IsVerified checks whether the ownership of the domain has been proven.
+func (d Domain) IsVerified() bool {
+	return d.VerifiedAt != nil
+}
@@ -0,0 +1,67 @@
+package entity
+
+import "time"
+
+// RateLimit represents the maximum number of requests allowed during a period
+// of time. Requests can be made in burst as long as the limit is not reached.
+type RateLimit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// TokenBucket represents the remaining requests a subject is allowed to make
+// under a RateLimit. The tokens are refilled continuously over time.
+type TokenBucket struct {
+	Tokens     float64
+	RefilledAt time.Time
+}
+
+// Take refills the bucket up to now and consumes a token from it. The bucket
+// is left unchanged when it doesn't have enough tokens.
+func (t TokenBucket) Take(limit RateLimit, now time.Time) (TokenBucket, bool) {
+	tokens := t.refill(limit, now)
+	if tokens < 1 {
+		return t, false
+	}
+
+	refilledAt := t.RefilledAt
+	if now.After(refilledAt) {
+		refilledAt = now
+	}
+	return TokenBucket{
+		Tokens:     tokens - 1,
+		RefilledAt: refilledAt,
+	}, true
+}
+
+// HasToken checks whether a token can be taken from the bucket by now.
+func (t TokenBucket) HasToken(limit RateLimit, now time.Time) bool {
+	return t.refill(limit, now) >= 1
+}
+
+// IsFull checks whether the bucket is fully refilled by now.
+func (t TokenBucket) IsFull(limit RateLimit, now time.Time) bool {
+	return t.refill(limit, now) >= float64(limit.Requests)
+}
+
+func (t TokenBucket) refill(limit RateLimit, now time.Time) float64 {
+	capacity := float64(limit.Requests)
+	elapsed := now.Sub(t.RefilledAt)
+	if elapsed <= 0 {
+		return t.Tokens
+	}
+
+	tokens := t.Tokens + elapsed.Seconds()*capacity/limit.Period.Seconds()
+	if tokens > capacity {
+		return capacity
+	}
+	return tokens
+}
+
+// NewTokenBucket creates a full TokenBucket for the given RateLimit.
+func NewTokenBucket(limit RateLimit, now time.Time) TokenBucket {
+	return TokenBucket{
+		Tokens:     float64(limit.Requests),
+		RefilledAt: now,
+	}
+}
@@ -0,0 +1,24 @@
+package entity
+
+// RedirectCondition represents the property of a visit which a redirect rule
+// is matched against.
+type RedirectCondition string
+
+// The constants enumerate all supported redirect conditions.
+const (
+	RedirectConditionLanguage RedirectCondition = "language"
+	RedirectConditionDevice   RedirectCondition = "device"
+	RedirectConditionCountry  RedirectCondition = "country"
+	RedirectConditionSplit    RedirectCondition = "split"
+)
+
+// RedirectRule represents an alternative long link for the visits of a short
+// link matching the condition. Values lists the accepted languages, devices or
+// countries, while Weight is the percentage of visits a split rule sends to
+// its long link.
+type RedirectRule struct {
+	Condition RedirectCondition
+	Values    []string
+	Weight    int
+	LongLink  string
+}
@@ -2,12 +2,33 @@ package entity
 
 import "time"
 
-// URL represents a short link.
+// URL represents a short link. Aliases are unique within a domain. Domain is
+// empty for the short links served under the default host.
 type URL struct {
-	Alias       string
-	OriginalURL string
-	ExpireAt    *time.Time
-	CreatedBy   *User
-	CreatedAt   *time.Time
-	UpdatedAt   *time.Time
+	Domain         string
+	Alias          string
+	OriginalURL    string
+	ExpireAt       *time.Time
+	ActivateAt     *time.Time
+	CreatedBy      *User
+	CreatedAt      *time.Time
+	UpdatedAt      *time.Time
+	PasswordHash   *string
+	MaxClicks      *int
+	ClickCount     int
+	ForwardQuery   bool
+	ForwardPath    bool
+	UTM            *UTM
+	Title          *string
+	RedirectStatus *int
+}
+
+// UTM represents the campaign parameters appended to the long link of a short
+// link. Empty parameters are left out.
+type UTM struct {
+	Source   string
+	Medium   string
+	Campaign string
+	Term     string
+	Content  string
 }
@@ -1,6 +1,8 @@
 package account
 
 import (
+	"context"
+
 	"github.com/short-d/short/app/entity"
 	"github.com/short-d/short/app/usecase/keygen"
 	"github.com/short-d/short/app/usecase/repository"
@@ -15,15 +17,15 @@ type Linker struct {
 
 // IsAccountLinked checks whether a given external account is linked to any
 // internal users already.
-func (l Linker) IsAccountLinked(ssoUser entity.SSOUser) (bool, error) {
-	return l.accountMappingRepo.IsSSOUserExist(ssoUser)
+func (l Linker) IsAccountLinked(ctx context.Context, ssoUser entity.SSOUser) (bool, error) {
+	return l.accountMappingRepo.IsSSOUserExist(ctx, ssoUser)
 }
 
 // CreateAndLinkAccount creates an internal account when there is no internal
 // account sharing the same email as the given external account and link them
 // together afterwards.
-func (l Linker) CreateAndLinkAccount(ssoUser entity.SSOUser) error {
-	isAccountLinked, err := l.IsAccountLinked(ssoUser)
+func (l Linker) CreateAndLinkAccount(ctx context.Context, ssoUser entity.SSOUser) error {
+	isAccountLinked, err := l.IsAccountLinked(ctx, ssoUser)
 	if err != nil {
 		return err
 	}
@@ -32,50 +34,50 @@ func (l Linker) CreateAndLinkAccount(ssoUser entity.SSOUser) error {
 		return nil
 	}
 
-	user, err := l.ensureUserExist(ssoUser)
+	user, err := l.ensureUserExist(ctx, ssoUser)
 	if err != nil {
 		return err
 	}
-	return l.accountMappingRepo.CreateMapping(ssoUser, user)
+	return l.accountMappingRepo.CreateMapping(ctx, ssoUser, user)
 }
 
-func (l Linker) ensureUserExist(ssoUser entity.SSOUser) (entity.User, error) {
-	isEmailExist, err := l.userRepo.IsEmailExist(ssoUser.Email)
+func (l Linker) ensureUserExist(ctx context.Context, ssoUser entity.SSOUser) (entity.User, error) {
+	isEmailExist, err := l.userRepo.IsEmailExist(ctx, ssoUser.Email)
 	if err != nil {
 		return entity.User{}, err
 	}
-	userID, err := l.generateUnassignedUserID()
+	userID, err := l.generateUnassignedUserID(ctx)
 	if err != nil {
 		return entity.User{}, err
 	}
 
 	if isEmailExist {
-		err = l.assignUserID(ssoUser.Email, userID)
+		err = l.assignUserID(ctx, ssoUser.Email, userID)
 		return entity.User{ID: userID}, err
 	}
-	return l.createUser(userID, ssoUser.Name, ssoUser.Email)
+	return l.createUser(ctx, userID, ssoUser.Name, ssoUser.Email)
 }
 
-func (l Linker) generateUnassignedUserID() (string, error) {
-	newKey, err := l.keyGen.NewKey()
+func (l Linker) generateUnassignedUserID(ctx context.Context) (string, error) {
+	newKey, err := l.keyGen.NewKey(ctx)
 	return string(newKey), err
 }
 
-func (l Linker) createUser(id string, name string, email string) (entity.User, error) {
+func (l Linker) createUser(ctx context.Context, id string, name string, email string) (entity.User, error) {
 	user := entity.User{
 		ID:    id,
 		Name:  name,
 		Email: email,
 	}
-	err := l.userRepo.CreateUser(user)
+	err := l.userRepo.CreateUser(ctx, user)
 	if err != nil {
 		return entity.User{}, err
 	}
 	return user, nil
 }
 
-func (l Linker) assignUserID(userEmail string, userID string) error {
-	return l.userRepo.UpdateUserID(userEmail, userID)
+func (l Linker) assignUserID(ctx context.Context, userEmail string, userID string) error {
+	return l.userRepo.UpdateUserID(ctx, userEmail, userID)
 }
 
 // NewLinker creates a new account linking service.
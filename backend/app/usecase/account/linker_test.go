@@ -3,7 +3,9 @@
 package account
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/short-d/app/mdtest"
 	"github.com/short-d/short/app/entity"
@@ -68,7 +70,7 @@ func TestLinker_IsAccountLinked(t *testing.T) {
 			t.Parallel()
 
 			keyFetcher := service.NewKeyFetcherFake([]service.Key{})
-			keyGen, err := keygen.NewKeyGenerator(2, &keyFetcher)
+			keyGen, err := keygen.NewKeyGenerator(2, 0, &keyFetcher, service.NewCounterFake(), mdtest.NewTimerFake(time.Now()))
 			mdtest.Equal(t, nil, err)
 			userRepo := repository.NewUserFake(testCase.users)
 			accountMappingRepo, err :=
@@ -79,7 +81,7 @@ func TestLinker_IsAccountLinked(t *testing.T) {
 			mdtest.Equal(t, nil, err)
 
 			linker := NewLinker(keyGen, &userRepo, &accountMappingRepo)
-			isLinked, err := linker.IsAccountLinked(testCase.ssoUser)
+			isLinked, err := linker.IsAccountLinked(context.Background(), testCase.ssoUser)
 			mdtest.Equal(t, nil, err)
 			mdtest.Equal(t, testCase.expectedIsLinked, isLinked)
 		})
@@ -163,7 +165,7 @@ func TestLinker_LinkAccount(t *testing.T) {
 	for _, testCase := range testCases {
 		t.Run(testCase.name, func(t *testing.T) {
 			keyFetcher := service.NewKeyFetcherFake([]service.Key{"key", "key2"})
-			keyGen, err := keygen.NewKeyGenerator(2, &keyFetcher)
+			keyGen, err := keygen.NewKeyGenerator(2, 0, &keyFetcher, service.NewCounterFake(), mdtest.NewTimerFake(time.Now()))
 			mdtest.Equal(t, nil, err)
 			fakeUserRepo := repository.NewUserFake(testCase.users)
 			accountMappingRepo, err :=
@@ -174,7 +176,7 @@ func TestLinker_LinkAccount(t *testing.T) {
 			mdtest.Equal(t, nil, err)
 
 			linker := NewLinker(keyGen, &fakeUserRepo, &accountMappingRepo)
-			err = linker.CreateAndLinkAccount(testCase.ssoUser)
+			err = linker.CreateAndLinkAccount(context.Background(), testCase.ssoUser)
 			mdtest.Equal(t, nil, err)
 
 			gotIsRelationExist := accountMappingRepo.IsRelationExist(testCase.ssoUser, testCase.user)
@@ -1,6 +1,8 @@
 package account
 
 import (
+	"context"
+
 	"github.com/short-d/app/fw"
 	"github.com/short-d/short/app/entity"
 	"github.com/short-d/short/app/usecase/repository"
@@ -13,19 +15,19 @@ type Provider struct {
 }
 
 // IsAccountExist checks whether an user account exist.
-func (r Provider) IsAccountExist(email string) (bool, error) {
-	return r.userRepo.IsEmailExist(email)
+func (r Provider) IsAccountExist(ctx context.Context, email string) (bool, error) {
+	return r.userRepo.IsEmailExist(ctx, email)
 }
 
 // CreateAccount creates an user account.
-func (r Provider) CreateAccount(email string, name string) error {
+func (r Provider) CreateAccount(ctx context.Context, email string, name string) error {
 	now := r.timer.Now()
 	user := entity.User{
 		Email:     email,
 		Name:      name,
 		CreatedAt: &now,
 	}
-	return r.userRepo.CreateUser(user)
+	return r.userRepo.CreateUser(ctx, user)
 }
 
 // NewProvider creates user account service provider.
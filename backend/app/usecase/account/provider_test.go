@@ -3,6 +3,7 @@
 package account
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -46,7 +47,7 @@ func TestProvider_IsAccountExist(t *testing.T) {
 			fakeUserRepo := repository.NewUserFake(testCase.users)
 			fakeTimer := mdtest.NewTimerFake(now)
 			accountProvider := NewProvider(&fakeUserRepo, fakeTimer)
-			gotIsExist, err := accountProvider.IsAccountExist(testCase.userEmail)
+			gotIsExist, err := accountProvider.IsAccountExist(context.Background(), testCase.userEmail)
 			mdtest.Equal(t, nil, err)
 			mdtest.Equal(t, testCase.expectedIsExist, gotIsExist)
 		})
@@ -91,10 +92,10 @@ func TestProvider_CreateAccount(t *testing.T) {
 			fakeUserRepo := repository.NewUserFake(testCase.users)
 			fakeTimer := mdtest.NewTimerFake(now)
 			accountProvider := NewProvider(&fakeUserRepo, fakeTimer)
-			err := accountProvider.CreateAccount(testCase.email, testCase.userName)
+			err := accountProvider.CreateAccount(context.Background(), testCase.email, testCase.userName)
 			if testCase.expectedHasErr {
 				mdtest.NotEqual(t, nil, err)
-				isEmailExist, err := fakeUserRepo.IsEmailExist(testCase.email)
+				isEmailExist, err := fakeUserRepo.IsEmailExist(context.Background(), testCase.email)
 				mdtest.Equal(t, nil, err)
 				mdtest.Equal(t, true, isEmailExist)
 				return
@@ -0,0 +1,23 @@
+package analytics
+
+import "net"
+
+var (
+	ipv4Mask = net.CIDRMask(24, 32)
+	ipv6Mask = net.CIDRMask(48, 128)
+)
+
+// truncateIP anonymizes an IP address by zeroing its host part. The last octet
+// of IPv4 addresses and the last 80 bits of IPv6 addresses are removed.
+func truncateIP(ipAddress string) string {
+	ip := net.ParseIP(ipAddress)
+	if ip == nil {
+		return ""
+	}
+
+	ipv4 := ip.To4()
+	if ipv4 != nil {
+		return ipv4.Mask(ipv4Mask).String()
+	}
+	return ip.Mask(ipv6Mask).String()
+}
@@ -0,0 +1,53 @@
+// +build !integration all
+
+package analytics
+
+import (
+	"testing"
+
+	"github.com/short-d/app/mdtest"
+)
+
+func TestTruncateIP(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name       string
+		ipAddress  string
+		expectedIP string
+	}{
+		{
+			name:       "empty address",
+			ipAddress:  "",
+			expectedIP: "",
+		},
+		{
+			name:       "invalid address",
+			ipAddress:  "not-an-ip",
+			expectedIP: "",
+		},
+		{
+			name:       "IPv4 address",
+			ipAddress:  "203.0.113.195",
+			expectedIP: "203.0.113.0",
+		},
+		{
+			name:       "IPv4-mapped IPv6 address",
+			ipAddress:  "::ffff:203.0.113.195",
+			expectedIP: "203.0.113.0",
+		},
+		{
+			name:       "IPv6 address",
+			ipAddress:  "2001:db8:85a3:8d3:1319:8a2e:370:7348",
+			expectedIP: "2001:db8:85a3::",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			mdtest.Equal(t, testCase.expectedIP, truncateIP(testCase.ipAddress))
+		})
+	}
+}
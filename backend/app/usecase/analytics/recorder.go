@@ -0,0 +1,161 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/short-d/app/fw"
+	"github.com/short-d/short/app/entity"
+	"github.com/short-d/short/app/usecase/repository"
+)
+
+var _ Recorder = (*BatchRecorder)(nil)
+
+// Recorder records visits to short links.
+type Recorder interface {
+	RecordClick(click entity.Click)
+}
+
+// BatchRecorder buffers clicks in memory and persists them in batch from a
+// background goroutine so that redirects never wait for the storage.
+type BatchRecorder struct {
+	clickRepo     repository.Click
+	logger        fw.Logger
+	batchSize     int
+	flushInterval time.Duration
+	clicks        chan entity.Click
+	// closing is closed by Close instead of clicks, so that recording clicks
+	// during shutdown never sends on a closed channel.
+	closing chan struct{}
+	done    chan struct{}
+	// dropped counts the clicks dropped since the last flush interval, which
+	// are logged together so that a full buffer doesn't flood the logs.
+	dropped *int64
+}
+
+// RecordClick queues a click to be persisted in the next batch. The click is
+// dropped when the buffer is full or the recorder is closed.
+func (b BatchRecorder) RecordClick(click entity.Click) {
+	select {
+	case <-b.closing:
+		return
+	default:
+	}
+
+	click.IPAddress = truncateIP(click.IPAddress)
+
+	select {
+	case b.clicks <- click:
+	default:
+		atomic.AddInt64(b.dropped, 1)
+	}
+}
+
+// Close persists the buffered clicks and stops the background goroutine.
+func (b BatchRecorder) Close() {
+	close(b.closing)
+	<-b.done
+}
+
+func (b BatchRecorder) run() {
+	defer close(b.done)
+
+	ticker := time.NewTicker(b.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]entity.Click, 0, b.batchSize)
+	for {
+		select {
+		case click := <-b.clicks:
+			batch = b.add(batch, click)
+		case <-ticker.C:
+			batch = b.flush(batch)
+			b.logDropped()
+		case <-b.closing:
+			b.drain(batch)
+			return
+		}
+	}
+}
+
+// drain persists the clicks left in the buffer along with the batch.
+func (b BatchRecorder) drain(batch []entity.Click) {
+	for {
+		select {
+		case click := <-b.clicks:
+			batch = b.add(batch, click)
+		default:
+			b.flush(batch)
+			b.logDropped()
+			return
+		}
+	}
+}
+
+// add appends click to the batch, persisting the batch once it is full.
+func (b BatchRecorder) add(batch []entity.Click, click entity.Click) []entity.Click {
+	batch = append(batch, click)
+	if len(batch) < b.batchSize {
+		return batch
+	}
+	return b.flush(batch)
+}
+
+// flush persists the batch independently of the requests recording the clicks,
+// which may have finished by then.
+func (b BatchRecorder) flush(batch []entity.Click) []entity.Click {
+	if len(batch) == 0 {
+		return batch
+	}
+
+	err := b.clickRepo.CreateClicks(context.Background(), batch)
+	if err != nil {
+		b.logger.Error(err)
+	}
+	return make([]entity.Click, 0, b.batchSize)
+}
+
+// logDropped reports the clicks dropped since it was last called.
+func (b BatchRecorder) logDropped() {
+	dropped := atomic.SwapInt64(b.dropped, 0)
+	if dropped == 0 {
+		return
+	}
+	b.logger.Warn(fmt.Sprintf("click buffer is full, dropped clicks (count=%d)", dropped))
+}
+
+// NewBatchRecorder creates BatchRecorder and starts persisting clicks in the
+// background.
+func NewBatchRecorder(
+	clickRepo repository.Click,
+	logger fw.Logger,
+	bufferSize int,
+	batchSize int,
+	flushInterval time.Duration,
+) (BatchRecorder, error) {
+	if bufferSize < 1 {
+		return BatchRecorder{}, errors.New("buffer size can't be less than 1")
+	}
+	if batchSize < 1 {
+		return BatchRecorder{}, errors.New("batch size can't be less than 1")
+	}
+	if flushInterval <= 0 {
+		return BatchRecorder{}, errors.New("flush interval must be positive")
+	}
+
+	recorder := BatchRecorder{
+		clickRepo:     clickRepo,
+		logger:        logger,
+		batchSize:     batchSize,
+		flushInterval: flushInterval,
+		clicks:        make(chan entity.Click, bufferSize),
+		closing:       make(chan struct{}),
+		done:          make(chan struct{}),
+		dropped:       new(int64),
+	}
+	go recorder.run()
+	return recorder, nil
+}
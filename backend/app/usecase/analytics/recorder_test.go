@@ -0,0 +1,211 @@
+// +build !integration all
+
+package analytics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/mdtest"
+	"github.com/short-d/short/app/entity"
+	"github.com/short-d/short/app/usecase/repository"
+)
+
+func TestNewBatchRecorder(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		bufferSize    int
+		batchSize     int
+		flushInterval time.Duration
+		expHasErr     bool
+	}{
+		{
+			name:          "buffer size is 0",
+			bufferSize:    0,
+			batchSize:     1,
+			flushInterval: time.Second,
+			expHasErr:     true,
+		},
+		{
+			name:          "batch size is 0",
+			bufferSize:    1,
+			batchSize:     0,
+			flushInterval: time.Second,
+			expHasErr:     true,
+		},
+		{
+			name:          "flush interval is 0",
+			bufferSize:    1,
+			batchSize:     1,
+			flushInterval: 0,
+			expHasErr:     true,
+		},
+		{
+			name:          "valid config",
+			bufferSize:    1,
+			batchSize:     1,
+			flushInterval: time.Second,
+			expHasErr:     false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			clickRepo := repository.NewClickFake(nil)
+			logger := mdtest.NewLoggerFake(mdtest.FakeLoggerArgs{})
+			recorder, err := NewBatchRecorder(
+				&clickRepo,
+				&logger,
+				testCase.bufferSize,
+				testCase.batchSize,
+				testCase.flushInterval,
+			)
+			if testCase.expHasErr {
+				mdtest.NotEqual(t, nil, err)
+				return
+			}
+			mdtest.Equal(t, nil, err)
+			recorder.Close()
+		})
+	}
+}
+
+func TestBatchRecorder_RecordClick(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+
+	testCases := []struct {
+		name           string
+		batchSize      int
+		clicks         []entity.Click
+		expectedClicks []entity.Click
+	}{
+		{
+			name:           "no click",
+			batchSize:      2,
+			clicks:         []entity.Click{},
+			expectedClicks: nil,
+		},
+		{
+			name:      "partial batch flushed on close",
+			batchSize: 10,
+			clicks: []entity.Click{
+				{
+					Alias:     "220uFicCJj",
+					ClickedAt: now,
+					Referrer:  "https://www.google.com/",
+					UserAgent: "Mozilla/5.0",
+					IPAddress: "203.0.113.195",
+				},
+			},
+			expectedClicks: []entity.Click{
+				{
+					Alias:     "220uFicCJj",
+					ClickedAt: now,
+					Referrer:  "https://www.google.com/",
+					UserAgent: "Mozilla/5.0",
+					IPAddress: "203.0.113.0",
+				},
+			},
+		},
+		{
+			name:      "multiple batches",
+			batchSize: 2,
+			clicks: []entity.Click{
+				{Alias: "a", ClickedAt: now, IPAddress: "198.51.100.7"},
+				{Alias: "b", ClickedAt: now, IPAddress: "2001:db8::1"},
+				{Alias: "c", ClickedAt: now},
+			},
+			expectedClicks: []entity.Click{
+				{Alias: "a", ClickedAt: now, IPAddress: "198.51.100.0"},
+				{Alias: "b", ClickedAt: now, IPAddress: "2001:db8::"},
+				{Alias: "c", ClickedAt: now, IPAddress: ""},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			clickRepo := repository.NewClickFake(nil)
+			logger := mdtest.NewLoggerFake(mdtest.FakeLoggerArgs{})
+			recorder, err := NewBatchRecorder(
+				&clickRepo,
+				&logger,
+				10,
+				testCase.batchSize,
+				time.Hour,
+			)
+			mdtest.Equal(t, nil, err)
+
+			for _, click := range testCase.clicks {
+				recorder.RecordClick(click)
+			}
+			recorder.Close()
+
+			mdtest.Equal(t, testCase.expectedClicks, clickRepo.GetClicks())
+		})
+	}
+}
+
+// blockingClickRepo holds the first batch of clicks until released.
+type blockingClickRepo struct {
+	repository.Click
+	started chan struct{}
+	release chan struct{}
+}
+
+func (b blockingClickRepo) CreateClicks(ctx context.Context, clicks []entity.Click) error {
+	select {
+	case b.started <- struct{}{}:
+	default:
+	}
+	<-b.release
+	return nil
+}
+
+func TestBatchRecorder_RecordClickBufferFull(t *testing.T) {
+	t.Parallel()
+
+	clickRepo := blockingClickRepo{
+		started: make(chan struct{}, 1),
+		release: make(chan struct{}),
+	}
+	logger := mdtest.NewLoggerFake(mdtest.FakeLoggerArgs{})
+	recorder, err := NewBatchRecorder(clickRepo, &logger, 1, 1, time.Hour)
+	mdtest.Equal(t, nil, err)
+
+	recorder.RecordClick(entity.Click{Alias: "a"})
+	<-clickRepo.started
+	for _, alias := range []string{"b", "c", "d"} {
+		recorder.RecordClick(entity.Click{Alias: alias})
+	}
+	close(clickRepo.release)
+	recorder.Close()
+
+	mdtest.Equal(t, []string{"click buffer is full, dropped clicks (count=2)"}, logger.WarnMessages)
+}
+
+func TestBatchRecorder_RecordClickAfterClose(t *testing.T) {
+	t.Parallel()
+
+	clickRepo := repository.NewClickFake(nil)
+	logger := mdtest.NewLoggerFake(mdtest.FakeLoggerArgs{})
+	recorder, err := NewBatchRecorder(&clickRepo, &logger, 10, 2, time.Hour)
+	mdtest.Equal(t, nil, err)
+
+	recorder.RecordClick(entity.Click{Alias: "a"})
+	recorder.Close()
+	recorder.RecordClick(entity.Click{Alias: "b"})
+
+	mdtest.Equal(t, []entity.Click{{Alias: "a"}}, clickRepo.GetClicks())
+}
@@ -0,0 +1,184 @@
+package analytics
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/short-d/short/app/entity"
+	"github.com/short-d/short/app/usecase/repository"
+	"github.com/short-d/short/app/usecase/useragent"
+)
+
+const (
+	oneDay          = 24 * time.Hour
+	maxDays         = 366
+	maxReferrersLen = 100
+)
+
+var _ Retriever = (*RetrieverPersist)(nil)
+
+// ErrNotURLOwner represents the error of accessing analytics of a short link
+// created by another user.
+type ErrNotURLOwner string
+
+func (e ErrNotURLOwner) Error() string {
+	return fmt.Sprintf("user is not the owner of url (alias=%s)", string(e))
+}
+
+// ErrInvalidTimeRange represents incorrect time range error.
+type ErrInvalidTimeRange string
+
+func (e ErrInvalidTimeRange) Error() string {
+	return string(e)
+}
+
+// ErrInvalidLimit represents incorrect result size limit error.
+type ErrInvalidLimit string
+
+func (e ErrInvalidLimit) Error() string {
+	return string(e)
+}
+
+// Retriever retrieves click statistics of short links for their owners.
+type Retriever interface {
+	GetClickCount(ctx context.Context, domain string, alias string, user entity.User) (int, error)
+	GetClicksByDay(ctx context.Context, domain string, alias string, from time.Time, to time.Time, user entity.User) ([]entity.DailyClicks, error)
+	GetTopReferrers(ctx context.Context, domain string, alias string, limit int, user entity.User) ([]entity.ReferrerClicks, error)
+	GetDeviceBreakdown(ctx context.Context, domain string, alias string, user entity.User) ([]entity.DeviceClicks, error)
+}
+
+// RetrieverPersist retrieves click statistics from persistent storage, such as
+// database.
+type RetrieverPersist struct {
+	clickRepo           repository.Click
+	userURLRelationRepo repository.UserURLRelation
+}
+
+// GetClickCount retrieves the total number of clicks on a short link.
+func (r RetrieverPersist) GetClickCount(ctx context.Context, domain string, alias string, user entity.User) (int, error) {
+	err := r.checkOwner(ctx, domain, alias, user)
+	if err != nil {
+		return 0, err
+	}
+	return r.clickRepo.CountClicks(ctx, domain, alias)
+}
+
+// GetClicksByDay retrieves the number of clicks on a short link for every UTC
+// day between from and to, inclusively. Days without clicks are reported with
+// zero count.
+func (r RetrieverPersist) GetClicksByDay(
+	ctx context.Context,
+	domain string,
+	alias string,
+	from time.Time,
+	to time.Time,
+	user entity.User,
+) ([]entity.DailyClicks, error) {
+	firstDay := from.UTC().Truncate(oneDay)
+	lastDay := to.UTC().Truncate(oneDay)
+	if lastDay.Before(firstDay) {
+		return nil, ErrInvalidTimeRange("from can't be after to")
+	}
+
+	numDays := int(lastDay.Sub(firstDay)/oneDay) + 1
+	if numDays > maxDays {
+		return nil, ErrInvalidTimeRange(fmt.Sprintf("time range can't exceed %d days", maxDays))
+	}
+
+	err := r.checkOwner(ctx, domain, alias, user)
+	if err != nil {
+		return nil, err
+	}
+
+	counted, err := r.clickRepo.CountClicksByDay(ctx, domain, alias, firstDay, lastDay.Add(oneDay))
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[time.Time]int)
+	for _, daily := range counted {
+		counts[daily.Day.UTC()] = daily.Count
+	}
+
+	dailyClicks := make([]entity.DailyClicks, 0, numDays)
+	for day := firstDay; !day.After(lastDay); day = day.Add(oneDay) {
+		dailyClicks = append(dailyClicks, entity.DailyClicks{
+			Day:   day,
+			Count: counts[day],
+		})
+	}
+	return dailyClicks, nil
+}
+
+// GetTopReferrers retrieves the referrers bringing the most clicks to a short
+// link. Direct visits are reported with empty referrer.
+func (r RetrieverPersist) GetTopReferrers(ctx context.Context, domain string, alias string, limit int, user entity.User) ([]entity.ReferrerClicks, error) {
+	if limit < 1 || limit > maxReferrersLen {
+		return nil, ErrInvalidLimit(fmt.Sprintf("limit must be between 1 and %d", maxReferrersLen))
+	}
+
+	err := r.checkOwner(ctx, domain, alias, user)
+	if err != nil {
+		return nil, err
+	}
+	return r.clickRepo.CountClicksByReferrer(ctx, domain, alias, limit)
+}
+
+// GetDeviceBreakdown retrieves the number of clicks on a short link for each
+// class of devices, ordered from the most to the least common.
+func (r RetrieverPersist) GetDeviceBreakdown(ctx context.Context, domain string, alias string, user entity.User) ([]entity.DeviceClicks, error) {
+	err := r.checkOwner(ctx, domain, alias, user)
+	if err != nil {
+		return nil, err
+	}
+
+	userAgentCounts, err := r.clickRepo.CountClicksByUserAgent(ctx, domain, alias)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[useragent.Device]int)
+	for userAgent, count := range userAgentCounts {
+		device := useragent.ParseDevice(userAgent)
+		counts[device] += count
+	}
+
+	deviceClicks := make([]entity.DeviceClicks, 0, len(counts))
+	for device, count := range counts {
+		deviceClicks = append(deviceClicks, entity.DeviceClicks{
+			Device: string(device),
+			Count:  count,
+		})
+	}
+	sort.Slice(deviceClicks, func(i, j int) bool {
+		if deviceClicks[i].Count != deviceClicks[j].Count {
+			return deviceClicks[i].Count > deviceClicks[j].Count
+		}
+		return deviceClicks[i].Device < deviceClicks[j].Device
+	})
+	return deviceClicks, nil
+}
+
+func (r RetrieverPersist) checkOwner(ctx context.Context, domain string, alias string, user entity.User) error {
+	isOwner, err := r.userURLRelationRepo.IsAliasOwner(ctx, user, domain, alias)
+	if err != nil {
+		return err
+	}
+	if !isOwner {
+		return ErrNotURLOwner(alias)
+	}
+	return nil
+}
+
+// NewRetrieverPersist creates RetrieverPersist
+func NewRetrieverPersist(
+	clickRepo repository.Click,
+	userURLRelationRepo repository.UserURLRelation,
+) RetrieverPersist {
+	return RetrieverPersist{
+		clickRepo:           clickRepo,
+		userURLRelationRepo: userURLRelationRepo,
+	}
+}
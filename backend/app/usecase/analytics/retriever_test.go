@@ -0,0 +1,307 @@
+// +build !integration all
+
+package analytics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/mdtest"
+	"github.com/short-d/short/app/entity"
+	"github.com/short-d/short/app/usecase/repository"
+)
+
+const (
+	desktopUserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/79.0.3945.130 Safari/537.36"
+	mobileUserAgent  = "Mozilla/5.0 (iPhone; CPU iPhone OS 13_3 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/13.0.4 Mobile/15E148 Safari/604.1"
+)
+
+var (
+	owner = entity.User{
+		ID:    "alpha",
+		Email: "alpha@example.com",
+	}
+	stranger = entity.User{
+		ID:    "beta",
+		Email: "beta@example.com",
+	}
+)
+
+func TestRetrieverPersist_GetClickCount(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+
+	testCases := []struct {
+		name          string
+		clicks        []entity.Click
+		user          entity.User
+		alias         string
+		expHasErr     bool
+		expectedCount int
+	}{
+		{
+			name: "not owner",
+			clicks: []entity.Click{
+				{Alias: "220uFicCJj", ClickedAt: now},
+			},
+			user:      stranger,
+			alias:     "220uFicCJj",
+			expHasErr: true,
+		},
+		{
+			name:          "no click",
+			clicks:        []entity.Click{},
+			user:          owner,
+			alias:         "220uFicCJj",
+			expHasErr:     false,
+			expectedCount: 0,
+		},
+		{
+			name: "count clicks of the alias",
+			clicks: []entity.Click{
+				{Alias: "220uFicCJj", ClickedAt: now},
+				{Alias: "yDOBcj5HIPbUAsw", ClickedAt: now},
+				{Alias: "220uFicCJj", ClickedAt: now},
+			},
+			user:          owner,
+			alias:         "220uFicCJj",
+			expHasErr:     false,
+			expectedCount: 2,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			retriever := newRetrieverFake(testCase.clicks, testCase.alias)
+			count, err := retriever.GetClickCount(context.Background(), "", testCase.alias, testCase.user)
+			if testCase.expHasErr {
+				mdtest.NotEqual(t, nil, err)
+				return
+			}
+			mdtest.Equal(t, nil, err)
+			mdtest.Equal(t, testCase.expectedCount, count)
+		})
+	}
+}
+
+func TestRetrieverPersist_GetClicksByDay(t *testing.T) {
+	t.Parallel()
+
+	day1 := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	day2 := day1.Add(oneDay)
+	day3 := day2.Add(oneDay)
+
+	testCases := []struct {
+		name           string
+		clicks         []entity.Click
+		user           entity.User
+		from           time.Time
+		to             time.Time
+		expHasErr      bool
+		expectedClicks []entity.DailyClicks
+	}{
+		{
+			name:      "from after to",
+			clicks:    []entity.Click{},
+			user:      owner,
+			from:      day2,
+			to:        day1,
+			expHasErr: true,
+		},
+		{
+			name:      "time range too long",
+			clicks:    []entity.Click{},
+			user:      owner,
+			from:      day1,
+			to:        day1.Add(400 * oneDay),
+			expHasErr: true,
+		},
+		{
+			name:      "not owner",
+			clicks:    []entity.Click{},
+			user:      stranger,
+			from:      day1,
+			to:        day3,
+			expHasErr: true,
+		},
+		{
+			name: "fill days without clicks",
+			clicks: []entity.Click{
+				{Alias: "220uFicCJj", ClickedAt: day1.Add(time.Hour)},
+				{Alias: "220uFicCJj", ClickedAt: day1.Add(23 * time.Hour)},
+				{Alias: "yDOBcj5HIPbUAsw", ClickedAt: day2},
+				{Alias: "220uFicCJj", ClickedAt: day3.Add(time.Minute)},
+				{Alias: "220uFicCJj", ClickedAt: day3.Add(oneDay)},
+			},
+			user:      owner,
+			from:      day1.Add(12 * time.Hour),
+			to:        day3,
+			expHasErr: false,
+			expectedClicks: []entity.DailyClicks{
+				{Day: day1, Count: 2},
+				{Day: day2, Count: 0},
+				{Day: day3, Count: 1},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			retriever := newRetrieverFake(testCase.clicks, "220uFicCJj")
+			dailyClicks, err := retriever.GetClicksByDay(
+				context.Background(),
+				"",
+				"220uFicCJj",
+				testCase.from,
+				testCase.to,
+				testCase.user,
+			)
+			if testCase.expHasErr {
+				mdtest.NotEqual(t, nil, err)
+				return
+			}
+			mdtest.Equal(t, nil, err)
+			mdtest.Equal(t, testCase.expectedClicks, dailyClicks)
+		})
+	}
+}
+
+func TestRetrieverPersist_GetTopReferrers(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+
+	testCases := []struct {
+		name              string
+		clicks            []entity.Click
+		user              entity.User
+		limit             int
+		expHasErr         bool
+		expectedReferrers []entity.ReferrerClicks
+	}{
+		{
+			name:      "limit too small",
+			clicks:    []entity.Click{},
+			user:      owner,
+			limit:     0,
+			expHasErr: true,
+		},
+		{
+			name:      "not owner",
+			clicks:    []entity.Click{},
+			user:      stranger,
+			limit:     1,
+			expHasErr: true,
+		},
+		{
+			name: "most common referrers first",
+			clicks: []entity.Click{
+				{Alias: "220uFicCJj", ClickedAt: now, Referrer: "https://t.co/"},
+				{Alias: "220uFicCJj", ClickedAt: now, Referrer: "https://www.google.com/"},
+				{Alias: "220uFicCJj", ClickedAt: now, Referrer: "https://www.google.com/"},
+				{Alias: "220uFicCJj", ClickedAt: now, Referrer: ""},
+			},
+			user:      owner,
+			limit:     2,
+			expHasErr: false,
+			expectedReferrers: []entity.ReferrerClicks{
+				{Referrer: "https://www.google.com/", Count: 2},
+				{Referrer: "", Count: 1},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			retriever := newRetrieverFake(testCase.clicks, "220uFicCJj")
+			referrers, err := retriever.GetTopReferrers(context.Background(), "", "220uFicCJj", testCase.limit, testCase.user)
+			if testCase.expHasErr {
+				mdtest.NotEqual(t, nil, err)
+				return
+			}
+			mdtest.Equal(t, nil, err)
+			mdtest.Equal(t, testCase.expectedReferrers, referrers)
+		})
+	}
+}
+
+func TestRetrieverPersist_GetDeviceBreakdown(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+
+	testCases := []struct {
+		name            string
+		clicks          []entity.Click
+		user            entity.User
+		expHasErr       bool
+		expectedDevices []entity.DeviceClicks
+	}{
+		{
+			name:      "not owner",
+			clicks:    []entity.Click{},
+			user:      stranger,
+			expHasErr: true,
+		},
+		{
+			name:            "no click",
+			clicks:          []entity.Click{},
+			user:            owner,
+			expHasErr:       false,
+			expectedDevices: []entity.DeviceClicks{},
+		},
+		{
+			name: "group user agents by device",
+			clicks: []entity.Click{
+				{Alias: "220uFicCJj", ClickedAt: now, UserAgent: mobileUserAgent},
+				{Alias: "220uFicCJj", ClickedAt: now, UserAgent: desktopUserAgent},
+				{Alias: "220uFicCJj", ClickedAt: now, UserAgent: mobileUserAgent},
+				{Alias: "220uFicCJj", ClickedAt: now, UserAgent: "curl/7.64.1"},
+			},
+			user:      owner,
+			expHasErr: false,
+			expectedDevices: []entity.DeviceClicks{
+				{Device: "mobile", Count: 2},
+				{Device: "bot", Count: 1},
+				{Device: "desktop", Count: 1},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			retriever := newRetrieverFake(testCase.clicks, "220uFicCJj")
+			devices, err := retriever.GetDeviceBreakdown(context.Background(), "", "220uFicCJj", testCase.user)
+			if testCase.expHasErr {
+				mdtest.NotEqual(t, nil, err)
+				return
+			}
+			mdtest.Equal(t, nil, err)
+			mdtest.Equal(t, testCase.expectedDevices, devices)
+		})
+	}
+}
+
+func newRetrieverFake(clicks []entity.Click, ownedAlias string) RetrieverPersist {
+	clickRepo := repository.NewClickFake(clicks)
+	userURLRelationRepo := repository.NewUserURLRepoFake(
+		[]entity.User{owner},
+		[]entity.URL{{Alias: ownedAlias}},
+		nil,
+	)
+	return NewRetrieverPersist(&clickRepo, &userURLRelationRepo)
+}
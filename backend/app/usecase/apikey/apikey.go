@@ -0,0 +1,185 @@
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/short-d/app/fw"
+	"github.com/short-d/short/app/entity"
+	"github.com/short-d/short/app/usecase/repository"
+)
+
+const (
+	keyPrefix     = "short_"
+	secretSize    = 32
+	idSize        = 8
+	maxNameLength = 50
+)
+
+// ErrInvalidName represents the error of naming an API key with an empty or
+// overly long name.
+type ErrInvalidName string
+
+func (e ErrInvalidName) Error() string {
+	return fmt.Sprintf("api key name must have 1 to %d characters (name=%s)", maxNameLength, string(e))
+}
+
+// ErrAPIKeyNotFound represents the error of accessing an API key which does not
+// exist or belongs to another user.
+type ErrAPIKeyNotFound string
+
+func (e ErrAPIKeyNotFound) Error() string {
+	return fmt.Sprintf("api key not found (id=%s)", string(e))
+}
+
+// ErrInvalidAPIKey represents the error of authenticating with an API key
+// which is malformed or revoked.
+type ErrInvalidAPIKey string
+
+func (e ErrInvalidAPIKey) Error() string {
+	return string(e)
+}
+
+// ErrInsufficientScope represents the error of performing an operation not
+// allowed by the scopes of an API key.
+type ErrInsufficientScope entity.APIKeyScope
+
+func (e ErrInsufficientScope) Error() string {
+	return fmt.Sprintf("api key lacks scope (scope=%s)", string(e))
+}
+
+// Manager creates, lists, revokes and authenticates personal API keys. Only the
+// hashes of the keys are persisted, so a key can't be recovered after it is
+// created.
+type Manager struct {
+	apiKeyRepo repository.APIKey
+	timer      fw.Timer
+}
+
+// CreateKey issues a new API key for the given user. The returned secret is
+// the only copy of the key.
+func (m Manager) CreateKey(
+	ctx context.Context,
+	user entity.User,
+	name string,
+	scopes []entity.APIKeyScope,
+) (entity.APIKey, string, error) {
+	if len(name) < 1 || len(name) > maxNameLength {
+		return entity.APIKey{}, "", ErrInvalidName(name)
+	}
+
+	id, err := randomString(idSize, hex.EncodeToString)
+	if err != nil {
+		return entity.APIKey{}, "", err
+	}
+
+	secret, err := randomString(secretSize, base64.RawURLEncoding.EncodeToString)
+	if err != nil {
+		return entity.APIKey{}, "", err
+	}
+	key := keyPrefix + secret
+
+	apiKey := entity.APIKey{
+		ID:        id,
+		Name:      name,
+		UserEmail: user.Email,
+		Scopes:    uniqueScopes(scopes),
+		CreatedAt: m.timer.Now().UTC(),
+	}
+
+	err = m.apiKeyRepo.Create(ctx, apiKey, hashKey(key))
+	if err != nil {
+		return entity.APIKey{}, "", err
+	}
+	return apiKey, key, nil
+}
+
+// ListKeys retrieves the API keys created by the given user.
+func (m Manager) ListKeys(ctx context.Context, user entity.User) ([]entity.APIKey, error) {
+	return m.apiKeyRepo.FindByUser(ctx, user)
+}
+
+// RevokeKey permanently disables an API key created by the given user.
+func (m Manager) RevokeKey(ctx context.Context, id string, user entity.User) error {
+	apiKeys, err := m.apiKeyRepo.FindByUser(ctx, user)
+	if err != nil {
+		return err
+	}
+
+	for _, apiKey := range apiKeys {
+		if apiKey.ID == id {
+			return m.apiKeyRepo.Delete(ctx, id)
+		}
+	}
+	return ErrAPIKeyNotFound(id)
+}
+
+// GetUser finds the owner of an API key, ensuring the key is allowed to
+// perform operations under the given scope.
+func (m Manager) GetUser(ctx context.Context, key string, scope entity.APIKeyScope) (entity.User, error) {
+	apiKey, err := m.GetKey(ctx, key, scope)
+	if err != nil {
+		return entity.User{}, err
+	}
+	return entity.User{
+		Email: apiKey.UserEmail,
+	}, nil
+}
+
+// GetKey finds the attributes of an API key, ensuring the key is allowed to
+// perform operations under the given scope.
+func (m Manager) GetKey(ctx context.Context, key string, scope entity.APIKeyScope) (entity.APIKey, error) {
+	if !strings.HasPrefix(key, keyPrefix) {
+		return entity.APIKey{}, ErrInvalidAPIKey("api key is malformed")
+	}
+
+	apiKey, err := m.apiKeyRepo.FindByHash(ctx, hashKey(key))
+	if err != nil {
+		return entity.APIKey{}, ErrInvalidAPIKey("api key is revoked or never issued")
+	}
+
+	if !apiKey.HasScope(scope) {
+		return entity.APIKey{}, ErrInsufficientScope(scope)
+	}
+	return apiKey, nil
+}
+
+func hashKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
+
+func randomString(size int, encode func([]byte) string) (string, error) {
+	buf := make([]byte, size)
+	_, err := rand.Read(buf)
+	if err != nil {
+		return "", err
+	}
+	return encode(buf), nil
+}
+
+func uniqueScopes(scopes []entity.APIKeyScope) []entity.APIKeyScope {
+	seen := make(map[entity.APIKeyScope]bool)
+	unique := []entity.APIKeyScope{}
+	for _, scope := range scopes {
+		if seen[scope] {
+			continue
+		}
+		seen[scope] = true
+		unique = append(unique, scope)
+	}
+	return unique
+}
+
+// NewManager creates API key manager
+func NewManager(apiKeyRepo repository.APIKey, timer fw.Timer) Manager {
+	return Manager{
+		apiKeyRepo: apiKeyRepo,
+		timer:      timer,
+	}
+}
@@ -0,0 +1,237 @@
+// +build !integration all
+
+package apikey
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/mdtest"
+	"github.com/short-d/short/app/entity"
+	"github.com/short-d/short/app/usecase/repository"
+)
+
+func TestManager_CreateKey(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	user := entity.User{Email: "alpha@example.com"}
+
+	testCases := []struct {
+		name           string
+		keyName        string
+		scopes         []entity.APIKeyScope
+		expectedScopes []entity.APIKeyScope
+		expectedErr    error
+	}{
+		{
+			name:        "empty name",
+			keyName:     "",
+			expectedErr: ErrInvalidName(""),
+		},
+		{
+			name:        "name too long",
+			keyName:     strings.Repeat("a", 51),
+			expectedErr: ErrInvalidName(strings.Repeat("a", 51)),
+		},
+		{
+			name:           "read only key",
+			keyName:        "ci",
+			scopes:         []entity.APIKeyScope{},
+			expectedScopes: []entity.APIKeyScope{},
+		},
+		{
+			name:    "duplicated scopes",
+			keyName: "deploy script",
+			scopes: []entity.APIKeyScope{
+				entity.APIKeyScopeCreateLinks,
+				entity.APIKeyScopeCreateLinks,
+				entity.APIKeyScopeManageLinks,
+			},
+			expectedScopes: []entity.APIKeyScope{
+				entity.APIKeyScopeCreateLinks,
+				entity.APIKeyScopeManageLinks,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			apiKeyRepo := repository.NewAPIKeyFake()
+			manager := NewManager(&apiKeyRepo, mdtest.NewTimerFake(now))
+
+			apiKey, key, err := manager.CreateKey(context.Background(), user, testCase.keyName, testCase.scopes)
+			mdtest.Equal(t, testCase.expectedErr, err)
+			if testCase.expectedErr != nil {
+				return
+			}
+
+			mdtest.Equal(t, testCase.keyName, apiKey.Name)
+			mdtest.Equal(t, user.Email, apiKey.UserEmail)
+			mdtest.Equal(t, testCase.expectedScopes, apiKey.Scopes)
+			mdtest.Equal(t, now, apiKey.CreatedAt)
+			mdtest.Equal(t, true, strings.HasPrefix(key, keyPrefix))
+
+			apiKeys, err := manager.ListKeys(context.Background(), user)
+			mdtest.Equal(t, nil, err)
+			mdtest.Equal(t, []entity.APIKey{apiKey}, apiKeys)
+
+			keyUser, err := manager.GetUser(context.Background(), key, entity.APIKeyScopeReadOnly)
+			mdtest.Equal(t, nil, err)
+			mdtest.Equal(t, user, keyUser)
+		})
+	}
+}
+
+func TestManager_RevokeKey(t *testing.T) {
+	t.Parallel()
+
+	owner := entity.User{Email: "alpha@example.com"}
+	otherUser := entity.User{Email: "beta@example.com"}
+
+	testCases := []struct {
+		name        string
+		id          func(apiKey entity.APIKey) string
+		user        entity.User
+		expectedErr func(apiKey entity.APIKey) error
+	}{
+		{
+			name: "key not found",
+			id: func(apiKey entity.APIKey) string {
+				return "unknown"
+			},
+			user: owner,
+			expectedErr: func(apiKey entity.APIKey) error {
+				return ErrAPIKeyNotFound("unknown")
+			},
+		},
+		{
+			name: "key created by other user",
+			id: func(apiKey entity.APIKey) string {
+				return apiKey.ID
+			},
+			user: otherUser,
+			expectedErr: func(apiKey entity.APIKey) error {
+				return ErrAPIKeyNotFound(apiKey.ID)
+			},
+		},
+		{
+			name: "revoke key successfully",
+			id: func(apiKey entity.APIKey) string {
+				return apiKey.ID
+			},
+			user: owner,
+			expectedErr: func(apiKey entity.APIKey) error {
+				return nil
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			apiKeyRepo := repository.NewAPIKeyFake()
+			manager := NewManager(&apiKeyRepo, mdtest.NewTimerFake(time.Now()))
+
+			apiKey, key, err := manager.CreateKey(context.Background(), owner, "ci", nil)
+			mdtest.Equal(t, nil, err)
+
+			expectedErr := testCase.expectedErr(apiKey)
+			err = manager.RevokeKey(context.Background(), testCase.id(apiKey), testCase.user)
+			mdtest.Equal(t, expectedErr, err)
+
+			_, err = manager.GetUser(context.Background(), key, entity.APIKeyScopeReadOnly)
+			mdtest.Equal(t, expectedErr != nil, err == nil)
+		})
+	}
+}
+
+func TestManager_GetUser(t *testing.T) {
+	t.Parallel()
+
+	user := entity.User{Email: "alpha@example.com"}
+
+	testCases := []struct {
+		name         string
+		scopes       []entity.APIKeyScope
+		key          func(key string) string
+		scope        entity.APIKeyScope
+		expectedUser entity.User
+		expectedErr  error
+	}{
+		{
+			name: "malformed key",
+			key: func(key string) string {
+				return "malformed"
+			},
+			scope:       entity.APIKeyScopeReadOnly,
+			expectedErr: ErrInvalidAPIKey("api key is malformed"),
+		},
+		{
+			name: "unknown key",
+			key: func(key string) string {
+				return key + "a"
+			},
+			scope:       entity.APIKeyScopeReadOnly,
+			expectedErr: ErrInvalidAPIKey("api key is revoked or never issued"),
+		},
+		{
+			name: "read only key reads",
+			key: func(key string) string {
+				return key
+			},
+			scope:        entity.APIKeyScopeReadOnly,
+			expectedUser: user,
+		},
+		{
+			name: "read only key creates links",
+			key: func(key string) string {
+				return key
+			},
+			scope:       entity.APIKeyScopeCreateLinks,
+			expectedErr: ErrInsufficientScope(entity.APIKeyScopeCreateLinks),
+		},
+		{
+			name:   "key creates links",
+			scopes: []entity.APIKeyScope{entity.APIKeyScopeCreateLinks},
+			key: func(key string) string {
+				return key
+			},
+			scope:        entity.APIKeyScopeCreateLinks,
+			expectedUser: user,
+		},
+		{
+			name:   "key manages links without scope",
+			scopes: []entity.APIKeyScope{entity.APIKeyScopeCreateLinks},
+			key: func(key string) string {
+				return key
+			},
+			scope:       entity.APIKeyScopeManageLinks,
+			expectedErr: ErrInsufficientScope(entity.APIKeyScopeManageLinks),
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			apiKeyRepo := repository.NewAPIKeyFake()
+			manager := NewManager(&apiKeyRepo, mdtest.NewTimerFake(time.Now()))
+
+			_, key, err := manager.CreateKey(context.Background(), user, "ci", testCase.scopes)
+			mdtest.Equal(t, nil, err)
+
+			keyUser, err := manager.GetUser(context.Background(), testCase.key(key), testCase.scope)
+			mdtest.Equal(t, testCase.expectedErr, err)
+			mdtest.Equal(t, testCase.expectedUser, keyUser)
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package changelog
 
 import (
+	"context"
 	"time"
 
 	"github.com/short-d/app/fw"
@@ -13,9 +14,9 @@ var _ ChangeLog = (*Persist)(nil)
 
 // ChangeLog retrieves change log and create changes.
 type ChangeLog interface {
-	CreateChange(title string, summaryMarkdown *string) (entity.Change, error)
-	GetChangeLog() ([]entity.Change, error)
-	GetLastViewedAt() *time.Time
+	CreateChange(ctx context.Context, title string, summaryMarkdown *string) (entity.Change, error)
+	GetChangeLog(ctx context.Context) ([]entity.Change, error)
+	GetLastViewedAt(ctx context.Context) *time.Time
 }
 
 // Persist retrieves change log from and saves changes to persistent data store.
@@ -26,9 +27,9 @@ type Persist struct {
 }
 
 // CreateChange creates a new change in the data store.
-func (p Persist) CreateChange(title string, summaryMarkdown *string) (entity.Change, error) {
+func (p Persist) CreateChange(ctx context.Context, title string, summaryMarkdown *string) (entity.Change, error) {
 	now := p.timer.Now()
-	key, err := p.keyGen.NewKey()
+	key, err := p.keyGen.NewKey(ctx)
 	if err != nil {
 		return entity.Change{}, err
 	}
@@ -38,17 +39,17 @@ func (p Persist) CreateChange(title string, summaryMarkdown *string) (entity.Cha
 		SummaryMarkdown: summaryMarkdown,
 		ReleasedAt:      now,
 	}
-	return p.changeLogRepo.CreateChange(newChange)
+	return p.changeLogRepo.CreateChange(ctx, newChange)
 }
 
 // GetChangeLog retrieves full ChangeLog from persistent data store.
-func (p Persist) GetChangeLog() ([]entity.Change, error) {
-	return p.changeLogRepo.GetChangeLog()
+func (p Persist) GetChangeLog(ctx context.Context) ([]entity.Change, error) {
+	return p.changeLogRepo.GetChangeLog(ctx)
 }
 
 // GetLastViewedAt retrieves the last time the user viewed the change log
 // TODO(issue#613): fetch the last time the user viewed the change log from persistent storage.
-func (p Persist) GetLastViewedAt() *time.Time {
+func (p Persist) GetLastViewedAt(ctx context.Context) *time.Time {
 	now := p.timer.Now()
 	return &now
 }
@@ -3,6 +3,7 @@
 package changelog
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -137,7 +138,7 @@ func TestPersist_CreateChange(t *testing.T) {
 
 			changeLogRepo := repository.NewChangeLogFake(testCase.changeLog)
 			keyFetcher := service.NewKeyFetcherFake(testCase.availableKeys)
-			keyGen, err := keygen.NewKeyGenerator(2, &keyFetcher)
+			keyGen, err := keygen.NewKeyGenerator(2, 0, &keyFetcher, service.NewCounterFake(), mdtest.NewTimerFake(time.Now()))
 			mdtest.Equal(t, nil, err)
 
 			fakeTimer := mdtest.NewTimerFake(now)
@@ -147,7 +148,7 @@ func TestPersist_CreateChange(t *testing.T) {
 				&changeLogRepo,
 			)
 
-			newChange, err := persist.CreateChange(testCase.change.Title, testCase.change.SummaryMarkdown)
+			newChange, err := persist.CreateChange(context.Background(), testCase.change.Title, testCase.change.SummaryMarkdown)
 			if testCase.hasErr {
 				mdtest.NotEqual(t, nil, err)
 				return
@@ -156,7 +157,7 @@ func TestPersist_CreateChange(t *testing.T) {
 
 			mdtest.Equal(t, testCase.expectedChange, newChange)
 
-			changeLog, err := persist.GetChangeLog()
+			changeLog, err := persist.GetChangeLog(context.Background())
 			mdtest.Equal(t, nil, err)
 
 			mdtest.Equal(t, testCase.expectedChangeLogSize, len(changeLog))
@@ -204,7 +205,7 @@ func TestPersist_GetChangeLog(t *testing.T) {
 
 			changeLogRepo := repository.NewChangeLogFake(testCase.changeLog)
 			keyFetcher := service.NewKeyFetcherFake(testCase.availableKeys)
-			keyGen, err := keygen.NewKeyGenerator(2, &keyFetcher)
+			keyGen, err := keygen.NewKeyGenerator(2, 0, &keyFetcher, service.NewCounterFake(), mdtest.NewTimerFake(time.Now()))
 			mdtest.Equal(t, nil, err)
 
 			fakeTimer := mdtest.NewTimerFake(now)
@@ -214,7 +215,7 @@ func TestPersist_GetChangeLog(t *testing.T) {
 				&changeLogRepo,
 			)
 
-			changeLog, err := persist.GetChangeLog()
+			changeLog, err := persist.GetChangeLog(context.Background())
 			mdtest.Equal(t, nil, err)
 
 			mdtest.SameElements(t, testCase.changeLog, changeLog)
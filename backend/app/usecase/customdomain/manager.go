@@ -0,0 +1,330 @@
+package customdomain
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/short-d/app/fw"
+	"github.com/short-d/short/app/entity"
+	"github.com/short-d/short/app/usecase/repository"
+	"github.com/short-d/short/app/usecase/service"
+)
+
+const (
+	tokenSize         = 16
+	maxHostnameLength = 253
+
+	// claimTTL is how long a domain registration stays reserved for its
+	// user before being verified, so that hostnames can't be squatted by
+	// registering them without proving the ownership.
+	claimTTL = 72 * time.Hour
+
+	// ChallengeRecordPrefix is prepended to the hostname to name the TXT
+	// record publishing the DNS challenge, such as
+	// _short-challenge.go.example.com.
+	ChallengeRecordPrefix = "_short-challenge."
+	// ChallengeRecordValuePrefix is prepended to the verification token in
+	// the TXT record publishing the DNS challenge.
+	ChallengeRecordValuePrefix = "short-domain-verification="
+	// ChallengeFilePath is the path of the file publishing the verification
+	// token for the HTTP challenge.
+	ChallengeFilePath = "/.well-known/short-domain-verification"
+)
+
+var hostnamePattern = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// Challenge represents the way of proving the ownership of a domain.
+type Challenge string
+
+// Challenge types
+const (
+	// ChallengeDNS proves the ownership by publishing the verification token
+	// in a TXT record.
+	ChallengeDNS Challenge = "DNS"
+	// ChallengeHTTP proves the ownership by serving the verification token
+	// from a file on the domain.
+	ChallengeHTTP Challenge = "HTTP"
+)
+
+// ErrInvalidHostname represents the error of registering a malformed hostname.
+type ErrInvalidHostname string
+
+func (e ErrInvalidHostname) Error() string {
+	return fmt.Sprintf("invalid hostname (hostname=%s)", string(e))
+}
+
+// ErrDomainExist represents the error of registering a domain which is
+// already registered by another user.
+type ErrDomainExist string
+
+func (e ErrDomainExist) Error() string {
+	return fmt.Sprintf("domain already registered (hostname=%s)", string(e))
+}
+
+// ErrDomainNotFound represents the error of accessing a domain which is not
+// registered or belongs to another user.
+type ErrDomainNotFound string
+
+func (e ErrDomainNotFound) Error() string {
+	return fmt.Sprintf("domain not found (hostname=%s)", string(e))
+}
+
+// ErrUnknownChallenge represents the error of verifying a domain with an
+// unsupported challenge.
+type ErrUnknownChallenge Challenge
+
+func (e ErrUnknownChallenge) Error() string {
+	return fmt.Sprintf("unknown challenge (challenge=%s)", string(e))
+}
+
+// ErrChallengeFailed represents the error of verifying a domain which doesn't
+// publish its verification token.
+type ErrChallengeFailed string
+
+func (e ErrChallengeFailed) Error() string {
+	return fmt.Sprintf("verification token not found (hostname=%s)", string(e))
+}
+
+// Manager registers and verifies the custom domains which users serve short
+// links under, and resolves the hosts of incoming requests to them.
+type Manager struct {
+	domainRepo       repository.Domain
+	verifier         service.DomainVerifier
+	timer            fw.Timer
+	defaultHostnames map[string]bool
+}
+
+// RegisterDomain records the intent of the given user to serve short links
+// under the hostname, issuing the token to prove the ownership with.
+// Registering a domain again returns the existing registration. Registrations
+// of other users which are not verified within claimTTL are taken over.
+func (m Manager) RegisterDomain(ctx context.Context, user entity.User, hostname string) (entity.Domain, error) {
+	hostname = NormalizeHostname(hostname)
+	if !isHostnameValid(hostname) {
+		return entity.Domain{}, ErrInvalidHostname(hostname)
+	}
+
+	now := m.timer.Now().UTC()
+	domain, err := m.domainRepo.GetByHostname(ctx, hostname)
+	switch err.(type) {
+	case nil:
+		if domain.OwnerEmail == user.Email {
+			return domain, nil
+		}
+		if domain.IsVerified() || !isClaimExpired(domain, now) {
+			return entity.Domain{}, ErrDomainExist(hostname)
+		}
+		return m.takeOverDomain(ctx, user, hostname, now)
+	case repository.ErrDomainNotFound:
+	default:
+		return entity.Domain{}, err
+	}
+
+	domain, err = newDomain(user, hostname, now)
+	if err != nil {
+		return entity.Domain{}, err
+	}
+
+	err = m.domainRepo.Create(ctx, domain)
+	if err != nil {
+		return entity.Domain{}, err
+	}
+	return domain, nil
+}
+
+// takeOverDomain replaces the expired registration of another user with a
+// new one for the given user. It fails with ErrDomainExist when the
+// registration is verified or renewed in the meantime.
+func (m Manager) takeOverDomain(ctx context.Context, user entity.User, hostname string, now time.Time) (entity.Domain, error) {
+	domain, err := newDomain(user, hostname, now)
+	if err != nil {
+		return entity.Domain{}, err
+	}
+
+	err = m.domainRepo.ReplaceUnverified(ctx, domain, now.Add(-claimTTL))
+	switch err.(type) {
+	case nil:
+		return domain, nil
+	case repository.ErrDomainNotFound:
+		return entity.Domain{}, ErrDomainExist(hostname)
+	default:
+		return entity.Domain{}, err
+	}
+}
+
+// VerifyDomain checks whether the domain registered by the given user
+// publishes its verification token through the challenge, marking the domain
+// verified on success. It fails with ErrChallengeFailed when the token is
+// missing.
+func (m Manager) VerifyDomain(
+	ctx context.Context,
+	user entity.User,
+	hostname string,
+	challenge Challenge,
+) (entity.Domain, error) {
+	hostname = NormalizeHostname(hostname)
+	domain, err := m.getOwnDomain(ctx, user, hostname)
+	if err != nil {
+		return entity.Domain{}, err
+	}
+
+	if domain.IsVerified() {
+		return domain, nil
+	}
+
+	isPublished, err := m.isTokenPublished(ctx, domain, challenge)
+	if err != nil {
+		return entity.Domain{}, err
+	}
+	if !isPublished {
+		return entity.Domain{}, ErrChallengeFailed(hostname)
+	}
+
+	now := m.timer.Now().UTC()
+	err = m.domainRepo.MarkVerified(ctx, hostname, now)
+	if err != nil {
+		return entity.Domain{}, err
+	}
+	domain.VerifiedAt = &now
+	return domain, nil
+}
+
+// ListDomains retrieves the domains registered by the given user.
+func (m Manager) ListDomains(ctx context.Context, user entity.User) ([]entity.Domain, error) {
+	return m.domainRepo.FindByOwner(ctx, user)
+}
+
+// ResolveHost finds the verified domain serving the requests sent to the
+// given host, which may include a port. An empty string is returned for the
+// default host and the domains not verified yet. The default hostnames are
+// resolved without querying storage.
+func (m Manager) ResolveHost(ctx context.Context, host string) (string, error) {
+	hostname, _, err := net.SplitHostPort(host)
+	if err != nil {
+		hostname = host
+	}
+	hostname = NormalizeHostname(hostname)
+	if m.defaultHostnames[hostname] {
+		return "", nil
+	}
+
+	domain, err := m.domainRepo.GetByHostname(ctx, hostname)
+	switch err.(type) {
+	case nil:
+	case repository.ErrDomainNotFound:
+		return "", nil
+	default:
+		return "", err
+	}
+
+	if !domain.IsVerified() {
+		return "", nil
+	}
+	return domain.Hostname, nil
+}
+
+func (m Manager) getOwnDomain(ctx context.Context, user entity.User, hostname string) (entity.Domain, error) {
+	domain, err := m.domainRepo.GetByHostname(ctx, hostname)
+	switch err.(type) {
+	case nil:
+	case repository.ErrDomainNotFound:
+		return entity.Domain{}, ErrDomainNotFound(hostname)
+	default:
+		return entity.Domain{}, err
+	}
+
+	if domain.OwnerEmail != user.Email {
+		return entity.Domain{}, ErrDomainNotFound(hostname)
+	}
+	return domain, nil
+}
+
+func (m Manager) isTokenPublished(ctx context.Context, domain entity.Domain, challenge Challenge) (bool, error) {
+	switch challenge {
+	case ChallengeDNS:
+		records, err := m.verifier.LookupTXT(ctx, ChallengeRecordPrefix+domain.Hostname)
+		if err != nil {
+			return false, err
+		}
+
+		expectedRecord := ChallengeRecordValuePrefix + domain.VerificationToken
+		for _, record := range records {
+			if strings.TrimSpace(record) == expectedRecord {
+				return true, nil
+			}
+		}
+		return false, nil
+	case ChallengeHTTP:
+		link := fmt.Sprintf("http://%s%s", domain.Hostname, ChallengeFilePath)
+		content, err := m.verifier.FetchFile(ctx, link)
+		if err != nil {
+			return false, err
+		}
+		return strings.TrimSpace(content) == domain.VerificationToken, nil
+	default:
+		return false, ErrUnknownChallenge(challenge)
+	}
+}
+
+// NormalizeHostname converts the hostname to the form domains are stored in,
+// which is lower case without the trailing dot.
+func NormalizeHostname(hostname string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(hostname)), ".")
+}
+
+func isClaimExpired(domain entity.Domain, now time.Time) bool {
+	return domain.CreatedAt != nil && !domain.CreatedAt.After(now.Add(-claimTTL))
+}
+
+func newDomain(user entity.User, hostname string, now time.Time) (entity.Domain, error) {
+	token, err := newToken()
+	if err != nil {
+		return entity.Domain{}, err
+	}
+
+	return entity.Domain{
+		Hostname:          hostname,
+		OwnerEmail:        user.Email,
+		VerificationToken: token,
+		CreatedAt:         &now,
+	}, nil
+}
+
+func isHostnameValid(hostname string) bool {
+	return len(hostname) <= maxHostnameLength && hostnamePattern.MatchString(hostname)
+}
+
+func newToken() (string, error) {
+	buf := make([]byte, tokenSize)
+	_, err := rand.Read(buf)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// NewManager creates custom domain manager, treating defaultHostnames as the
+// default host.
+func NewManager(
+	domainRepo repository.Domain,
+	verifier service.DomainVerifier,
+	timer fw.Timer,
+	defaultHostnames []string,
+) Manager {
+	hostnames := make(map[string]bool)
+	for _, hostname := range defaultHostnames {
+		hostnames[NormalizeHostname(hostname)] = true
+	}
+	return Manager{
+		domainRepo:       domainRepo,
+		verifier:         verifier,
+		timer:            timer,
+		defaultHostnames: hostnames,
+	}
+}
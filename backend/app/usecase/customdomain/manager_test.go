@@ -0,0 +1,331 @@
+// +build !integration all
+
+package customdomain
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/mdtest"
+	"github.com/short-d/short/app/entity"
+	"github.com/short-d/short/app/usecase/repository"
+	"github.com/short-d/short/app/usecase/service"
+)
+
+func TestManager_RegisterDomain(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	recentlyCreatedAt := now.Add(-time.Hour)
+	expiredCreatedAt := now.Add(-claimTTL)
+	user := entity.User{Email: "alpha@example.com"}
+
+	testCases := []struct {
+		name             string
+		domains          []entity.Domain
+		hostname         string
+		expectedHostname string
+		expectedToken    string
+		expectedErr      error
+	}{
+		{
+			name:             "new domain",
+			hostname:         "Go.Example.com.",
+			expectedHostname: "go.example.com",
+		},
+		{
+			name:        "single label",
+			hostname:    "localhost",
+			expectedErr: ErrInvalidHostname("localhost"),
+		},
+		{
+			name:        "invalid character",
+			hostname:    "go_links.example.com",
+			expectedErr: ErrInvalidHostname("go_links.example.com"),
+		},
+		{
+			name:        "port included",
+			hostname:    "go.example.com:8080",
+			expectedErr: ErrInvalidHostname("go.example.com:8080"),
+		},
+		{
+			name: "registered by the user",
+			domains: []entity.Domain{
+				{
+					Hostname:          "go.example.com",
+					OwnerEmail:        "alpha@example.com",
+					VerificationToken: "token",
+				},
+			},
+			hostname:         "go.example.com",
+			expectedHostname: "go.example.com",
+			expectedToken:    "token",
+		},
+		{
+			name: "registered by another user",
+			domains: []entity.Domain{
+				{
+					Hostname:          "go.example.com",
+					OwnerEmail:        "beta@example.com",
+					VerificationToken: "token",
+					CreatedAt:         &recentlyCreatedAt,
+				},
+			},
+			hostname:    "go.example.com",
+			expectedErr: ErrDomainExist("go.example.com"),
+		},
+		{
+			name: "verified by another user",
+			domains: []entity.Domain{
+				{
+					Hostname:          "go.example.com",
+					OwnerEmail:        "beta@example.com",
+					VerificationToken: "token",
+					CreatedAt:         &expiredCreatedAt,
+					VerifiedAt:        &recentlyCreatedAt,
+				},
+			},
+			hostname:    "go.example.com",
+			expectedErr: ErrDomainExist("go.example.com"),
+		},
+		{
+			name: "registration of another user expired",
+			domains: []entity.Domain{
+				{
+					Hostname:          "go.example.com",
+					OwnerEmail:        "beta@example.com",
+					VerificationToken: "token",
+					CreatedAt:         &expiredCreatedAt,
+				},
+			},
+			hostname:         "go.example.com",
+			expectedHostname: "go.example.com",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			domainRepo := repository.NewDomainFake(testCase.domains)
+			verifier := service.NewDomainVerifierFake(nil, nil)
+			manager := NewManager(&domainRepo, verifier, mdtest.NewTimerFake(now), nil)
+
+			domain, err := manager.RegisterDomain(context.Background(), user, testCase.hostname)
+			mdtest.Equal(t, testCase.expectedErr, err)
+			if testCase.expectedErr != nil {
+				return
+			}
+
+			mdtest.Equal(t, testCase.expectedHostname, domain.Hostname)
+			mdtest.Equal(t, user.Email, domain.OwnerEmail)
+			mdtest.Equal(t, false, domain.IsVerified())
+			if testCase.expectedToken != "" {
+				mdtest.Equal(t, testCase.expectedToken, domain.VerificationToken)
+				return
+			}
+			mdtest.Equal(t, tokenSize*2, len(domain.VerificationToken))
+			mdtest.Equal(t, now, *domain.CreatedAt)
+
+			domains, err := manager.ListDomains(context.Background(), user)
+			mdtest.Equal(t, nil, err)
+			mdtest.Equal(t, []entity.Domain{domain}, domains)
+		})
+	}
+}
+
+func TestManager_VerifyDomain(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	verifiedAt := time.Date(2019, 12, 1, 0, 0, 0, 0, time.UTC)
+	user := entity.User{Email: "alpha@example.com"}
+
+	testCases := []struct {
+		name               string
+		domains            []entity.Domain
+		txtRecords         map[string][]string
+		files              map[string]string
+		hostname           string
+		challenge          Challenge
+		expectedVerifiedAt time.Time
+		expectedErr        error
+	}{
+		{
+			name: "dns challenge passed",
+			domains: []entity.Domain{
+				{Hostname: "go.example.com", OwnerEmail: "alpha@example.com", VerificationToken: "token"},
+			},
+			txtRecords: map[string][]string{
+				"_short-challenge.go.example.com": {"v=spf1 -all", "short-domain-verification=token"},
+			},
+			hostname:           "GO.example.com",
+			challenge:          ChallengeDNS,
+			expectedVerifiedAt: now,
+		},
+		{
+			name: "dns challenge failed",
+			domains: []entity.Domain{
+				{Hostname: "go.example.com", OwnerEmail: "alpha@example.com", VerificationToken: "token"},
+			},
+			txtRecords: map[string][]string{
+				"_short-challenge.go.example.com": {"short-domain-verification=other"},
+			},
+			hostname:    "go.example.com",
+			challenge:   ChallengeDNS,
+			expectedErr: ErrChallengeFailed("go.example.com"),
+		},
+		{
+			name: "http challenge passed",
+			domains: []entity.Domain{
+				{Hostname: "go.example.com", OwnerEmail: "alpha@example.com", VerificationToken: "token"},
+			},
+			files: map[string]string{
+				"http://go.example.com/.well-known/short-domain-verification": "token\n",
+			},
+			hostname:           "go.example.com",
+			challenge:          ChallengeHTTP,
+			expectedVerifiedAt: now,
+		},
+		{
+			name: "http challenge failed",
+			domains: []entity.Domain{
+				{Hostname: "go.example.com", OwnerEmail: "alpha@example.com", VerificationToken: "token"},
+			},
+			hostname:    "go.example.com",
+			challenge:   ChallengeHTTP,
+			expectedErr: ErrChallengeFailed("go.example.com"),
+		},
+		{
+			name: "unknown challenge",
+			domains: []entity.Domain{
+				{Hostname: "go.example.com", OwnerEmail: "alpha@example.com", VerificationToken: "token"},
+			},
+			hostname:    "go.example.com",
+			challenge:   Challenge("EMAIL"),
+			expectedErr: ErrUnknownChallenge("EMAIL"),
+		},
+		{
+			name: "already verified",
+			domains: []entity.Domain{
+				{
+					Hostname:          "go.example.com",
+					OwnerEmail:        "alpha@example.com",
+					VerificationToken: "token",
+					VerifiedAt:        &verifiedAt,
+				},
+			},
+			hostname:           "go.example.com",
+			challenge:          ChallengeDNS,
+			expectedVerifiedAt: verifiedAt,
+		},
+		{
+			name: "registered by another user",
+			domains: []entity.Domain{
+				{Hostname: "go.example.com", OwnerEmail: "beta@example.com", VerificationToken: "token"},
+			},
+			txtRecords: map[string][]string{
+				"_short-challenge.go.example.com": {"short-domain-verification=token"},
+			},
+			hostname:    "go.example.com",
+			challenge:   ChallengeDNS,
+			expectedErr: ErrDomainNotFound("go.example.com"),
+		},
+		{
+			name:        "not registered",
+			hostname:    "go.example.com",
+			challenge:   ChallengeDNS,
+			expectedErr: ErrDomainNotFound("go.example.com"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			domainRepo := repository.NewDomainFake(testCase.domains)
+			verifier := service.NewDomainVerifierFake(testCase.txtRecords, testCase.files)
+			manager := NewManager(&domainRepo, verifier, mdtest.NewTimerFake(now), nil)
+
+			domain, err := manager.VerifyDomain(context.Background(), user, testCase.hostname, testCase.challenge)
+			mdtest.Equal(t, testCase.expectedErr, err)
+			if testCase.expectedErr != nil {
+				return
+			}
+			mdtest.Equal(t, testCase.expectedVerifiedAt, *domain.VerifiedAt)
+
+			host, err := manager.ResolveHost(context.Background(), domain.Hostname)
+			mdtest.Equal(t, nil, err)
+			mdtest.Equal(t, domain.Hostname, host)
+		})
+	}
+}
+
+func TestManager_ResolveHost(t *testing.T) {
+	t.Parallel()
+
+	verifiedAt := time.Date(2019, 12, 1, 0, 0, 0, 0, time.UTC)
+	domains := []entity.Domain{
+		{
+			Hostname:   "go.example.com",
+			OwnerEmail: "alpha@example.com",
+			VerifiedAt: &verifiedAt,
+		},
+		{
+			Hostname:   "links.example.com",
+			OwnerEmail: "beta@example.com",
+		},
+	}
+
+	testCases := []struct {
+		name             string
+		defaultHostnames []string
+		host             string
+		expectedHost     string
+	}{
+		{
+			name:         "verified domain",
+			host:         "go.example.com",
+			expectedHost: "go.example.com",
+		},
+		{
+			name:         "verified domain with port",
+			host:         "Go.Example.com:8080",
+			expectedHost: "go.example.com",
+		},
+		{
+			name:         "unverified domain",
+			host:         "links.example.com",
+			expectedHost: "",
+		},
+		{
+			name:         "default host",
+			host:         "localhost:8080",
+			expectedHost: "",
+		},
+		{
+			name:             "default hostname registered as domain",
+			defaultHostnames: []string{"Go.Example.com"},
+			host:             "go.example.com:443",
+			expectedHost:     "",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			domainRepo := repository.NewDomainFake(domains)
+			verifier := service.NewDomainVerifierFake(nil, nil)
+			manager := NewManager(&domainRepo, verifier, mdtest.NewTimerFake(verifiedAt), testCase.defaultHostnames)
+
+			host, err := manager.ResolveHost(context.Background(), testCase.host)
+			mdtest.Equal(t, nil, err)
+			mdtest.Equal(t, testCase.expectedHost, host)
+		})
+	}
+}
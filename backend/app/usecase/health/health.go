@@ -0,0 +1,110 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Status represents whether a component of the service works.
+type Status string
+
+// The constants enumerate the statuses of components.
+const (
+	StatusUp   Status = "up"
+	StatusDown Status = "down"
+)
+
+// Probe checks whether a component the service depends on works, failing with
+// the reason when it doesn't. Probes should give up once ctx is done.
+type Probe func(ctx context.Context) error
+
+// ErrProbeTimeout represents a probe which doesn't finish in time.
+type ErrProbeTimeout time.Duration
+
+func (e ErrProbeTimeout) Error() string {
+	return fmt.Sprintf("probe timed out after %s", time.Duration(e))
+}
+
+// Component represents the status of a component and the reason it is down.
+type Component struct {
+	Name   string
+	Status Status
+	Err    error
+}
+
+// Report represents the status of the service, which is up only when all of
+// its components are up.
+type Report struct {
+	Status     Status
+	Components []Component
+}
+
+// Checker probes the components the service depends on.
+type Checker struct {
+	probes  map[string]Probe
+	timeout time.Duration
+}
+
+// Check probes all the components concurrently, reporting the components
+// whose probes don't finish within the timeout or before ctx is done as down.
+// Components are sorted by name.
+func (c Checker) Check(ctx context.Context) Report {
+	results := make(chan Component, len(c.probes))
+	for name, probe := range c.probes {
+		go func(name string, probe Probe) {
+			results <- runProbe(ctx, name, probe, c.timeout)
+		}(name, probe)
+	}
+
+	report := Report{Status: StatusUp}
+	for range c.probes {
+		component := <-results
+		if component.Status == StatusDown {
+			report.Status = StatusDown
+		}
+		report.Components = append(report.Components, component)
+	}
+
+	sort.Slice(report.Components, func(i, j int) bool {
+		return report.Components[i].Name < report.Components[j].Name
+	})
+	return report
+}
+
+func runProbe(
+	ctx context.Context,
+	name string,
+	probe Probe,
+	timeout time.Duration,
+) Component {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- probe(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-errs:
+	case <-ctx.Done():
+		err = ErrProbeTimeout(timeout)
+	}
+
+	if err != nil {
+		return Component{Name: name, Status: StatusDown, Err: err}
+	}
+	return Component{Name: name, Status: StatusUp}
+}
+
+// NewChecker creates Checker which probes each component in probes, giving up
+// on probes which take longer than timeout.
+func NewChecker(probes map[string]Probe, timeout time.Duration) Checker {
+	return Checker{
+		probes:  probes,
+		timeout: timeout,
+	}
+}
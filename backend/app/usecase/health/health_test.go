@@ -0,0 +1,96 @@
+// +build !integration all
+
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/mdtest"
+)
+
+func TestChecker_Check(t *testing.T) {
+	t.Parallel()
+
+	errConnection := errors.New("connection refused")
+	up := func(ctx context.Context) error {
+		return nil
+	}
+	down := func(ctx context.Context) error {
+		return errConnection
+	}
+	slow := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	testCases := []struct {
+		name           string
+		probes         map[string]Probe
+		expectedReport Report
+	}{
+		{
+			name:   "no components",
+			probes: map[string]Probe{},
+			expectedReport: Report{
+				Status: StatusUp,
+			},
+		},
+		{
+			name: "all components up",
+			probes: map[string]Probe{
+				"keyGenerator": up,
+				"database":     up,
+			},
+			expectedReport: Report{
+				Status: StatusUp,
+				Components: []Component{
+					{Name: "database", Status: StatusUp},
+					{Name: "keyGenerator", Status: StatusUp},
+				},
+			},
+		},
+		{
+			name: "component down",
+			probes: map[string]Probe{
+				"database":     down,
+				"keyGenerator": up,
+			},
+			expectedReport: Report{
+				Status: StatusDown,
+				Components: []Component{
+					{Name: "database", Status: StatusDown, Err: errConnection},
+					{Name: "keyGenerator", Status: StatusUp},
+				},
+			},
+		},
+		{
+			name: "component times out",
+			probes: map[string]Probe{
+				"database": slow,
+			},
+			expectedReport: Report{
+				Status: StatusDown,
+				Components: []Component{
+					{
+						Name:   "database",
+						Status: StatusDown,
+						Err:    ErrProbeTimeout(10 * time.Millisecond),
+					},
+				},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			checker := NewChecker(testCase.probes, 10*time.Millisecond)
+			mdtest.Equal(t, testCase.expectedReport, checker.Check(context.Background()))
+		})
+	}
+}
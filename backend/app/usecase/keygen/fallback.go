@@ -0,0 +1,43 @@
+package keygen
+
+import (
+	"context"
+
+	"github.com/short-d/app/fw"
+	"github.com/short-d/short/app/usecase/service"
+)
+
+var _ service.KeyFetcher = (*FallbackKeyFetcher)(nil)
+
+// FallbackKeyFetcher fetches keys from primary, falling back to fallback
+// whenever primary fails, such as when key generation service is
+// unreachable.
+type FallbackKeyFetcher struct {
+	primary  service.KeyFetcher
+	fallback service.KeyFetcher
+	logger   fw.Logger
+}
+
+// FetchKeys fetches keys from primary, or from fallback when primary fails.
+func (f FallbackKeyFetcher) FetchKeys(ctx context.Context, maxCount int) ([]service.Key, error) {
+	keys, err := f.primary.FetchKeys(ctx, maxCount)
+	if err == nil {
+		return keys, nil
+	}
+
+	f.logger.Error(err)
+	return f.fallback.FetchKeys(ctx, maxCount)
+}
+
+// NewFallbackKeyFetcher creates FallbackKeyFetcher.
+func NewFallbackKeyFetcher(
+	primary service.KeyFetcher,
+	fallback service.KeyFetcher,
+	logger fw.Logger,
+) FallbackKeyFetcher {
+	return FallbackKeyFetcher{
+		primary:  primary,
+		fallback: fallback,
+		logger:   logger,
+	}
+}
@@ -0,0 +1,62 @@
+// +build !integration all
+
+package keygen
+
+import (
+	"context"
+	"testing"
+
+	"github.com/short-d/app/mdtest"
+	"github.com/short-d/short/app/usecase/service"
+)
+
+func TestFallbackKeyFetcher_FetchKeys(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name         string
+		primaryKeys  []service.Key
+		fallbackKeys []service.Key
+		hasErr       bool
+		expectedKeys []service.Key
+	}{
+		{
+			name:         "primary available",
+			primaryKeys:  []service.Key{"0K", "0L"},
+			fallbackKeys: []service.Key{"1A", "1B"},
+			expectedKeys: []service.Key{"0K", "0L"},
+		},
+		{
+			name:         "primary unavailable",
+			primaryKeys:  []service.Key{},
+			fallbackKeys: []service.Key{"1A", "1B"},
+			expectedKeys: []service.Key{"1A", "1B"},
+		},
+		{
+			name:         "both unavailable",
+			primaryKeys:  []service.Key{},
+			fallbackKeys: []service.Key{},
+			hasErr:       true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			primary := service.NewKeyFetcherFake(testCase.primaryKeys)
+			fallback := service.NewKeyFetcherFake(testCase.fallbackKeys)
+			logger := mdtest.NewLoggerFake(mdtest.FakeLoggerArgs{})
+			keyFetcher := NewFallbackKeyFetcher(&primary, &fallback, &logger)
+
+			keys, err := keyFetcher.FetchKeys(context.Background(), 2)
+			if testCase.hasErr {
+				mdtest.NotEqual(t, nil, err)
+				return
+			}
+			mdtest.Equal(t, nil, err)
+			mdtest.Equal(t, testCase.expectedKeys, keys)
+		})
+	}
+}
@@ -1,62 +1,258 @@
 package keygen
 
 import (
+	"context"
 	"errors"
+	"sync"
+	"time"
 
+	"github.com/short-d/app/fw"
 	"github.com/short-d/short/app/usecase/service"
 )
 
-type bufferEntry struct {
-	key service.Key
-	err error
+// The constants enumerate the results of refilling the buffer, counted by the
+// refill counter.
+const (
+	refillSucceeded = "success"
+	refillFailed    = "failure"
+)
+
+// refillProbeInterval is the minimum time between fetching keys for health
+// checks, so that frequent health checks don't flood a failing key generation
+// service.
+const refillProbeInterval = 10 * time.Second
+
+var (
+	errNoAvailableKey = errors.New("no available key")
+	errClosed         = errors.New("key generator is closed")
+)
+
+// Stats represents how many keys are buffered out of the capacity of the
+// buffer, and how many callers are waiting for the buffer to be refilled.
+type Stats struct {
+	Depth    int
+	Capacity int
+	Waiters  int
+}
+
+// buffer holds the fetched keys shared by all the copies of KeyGenerator.
+type buffer struct {
+	mutex *sync.Mutex
+	keys  []service.Key
+	// isRefilling ensures that at most one refill is in flight, so that
+	// concurrent callers never fetch more keys than the buffer holds.
+	isRefilling bool
+	// refilled is closed when the refill in flight finishes, waking up all
+	// the callers waiting for it.
+	refilled chan struct{}
+	// refillErr is the error of the last refill, which is handed to every
+	// caller waiting for it.
+	refillErr error
+	// fetchedAt is the time keys are last fetched for refilling the buffer or
+	// probing the key fetcher.
+	fetchedAt time.Time
+	waiters   int
+	// ctx bounds the refills, which are canceled once the generator is
+	// closed.
+	ctx      context.Context
+	cancel   context.CancelFunc
+	isClosed bool
 }
 
 // KeyGenerator fetches unique keys in batch from key generation service
-// and buffer them in memory for fast response.
+// and buffer them in memory for fast response. The buffer is refilled in the
+// background once it drops below the low water mark, so that keys are usually
+// available before they are requested. Each refill of the buffer is counted
+// by its result.
 type KeyGenerator struct {
-	bufferSize int
-	buffer     chan bufferEntry
-	keyFetcher service.KeyFetcher
+	bufferSize    int
+	lowWaterMark  int
+	keyFetcher    service.KeyFetcher
+	refillCounter service.Counter
+	timer         fw.Timer
+	buffer        *buffer
 }
 
-// NewKey produces a unique key
-func (r KeyGenerator) NewKey() (service.Key, error) {
-	if len(r.buffer) == 0 {
-		go func() {
-			r.fetchKeys()
-		}()
+// NewKey produces a unique key, waiting for the buffer to be refilled when it
+// is empty. It fails with the error of the refill when the refill fails, and
+// gives up when ctx is done before the buffer is refilled.
+func (r KeyGenerator) NewKey(ctx context.Context) (service.Key, error) {
+	r.buffer.mutex.Lock()
+	for len(r.buffer.keys) == 0 {
+		if r.buffer.isClosed {
+			r.buffer.mutex.Unlock()
+			return "", errClosed
+		}
+		r.startRefill()
+		refilled := r.buffer.refilled
+		r.buffer.waiters++
+		r.buffer.mutex.Unlock()
+
+		select {
+		case <-refilled:
+		case <-ctx.Done():
+			r.buffer.mutex.Lock()
+			r.buffer.waiters--
+			r.buffer.mutex.Unlock()
+			return "", ctx.Err()
+		}
+
+		r.buffer.mutex.Lock()
+		r.buffer.waiters--
+		if len(r.buffer.keys) == 0 && r.buffer.refillErr != nil {
+			err := r.buffer.refillErr
+			r.buffer.mutex.Unlock()
+			return "", err
+		}
 	}
 
-	entry := <-r.buffer
-	return entry.key, entry.err
+	key := r.buffer.keys[0]
+	r.buffer.keys = r.buffer.keys[1:]
+	if len(r.buffer.keys) < r.lowWaterMark {
+		r.startRefill()
+	}
+	r.buffer.mutex.Unlock()
+	return key, nil
 }
 
-func (r KeyGenerator) fetchKeys() {
-	keys, err := r.keyFetcher.FetchKeys(r.bufferSize)
-	if err != nil {
-		r.buffer <- bufferEntry{
-			key: "",
-			err: err,
+// NewKeys produces count unique keys. The keys are fetched from key generation
+// service in batch, bypassing the buffer.
+func (r KeyGenerator) NewKeys(ctx context.Context, count int) ([]service.Key, error) {
+	keys := make([]service.Key, 0, count)
+	for len(keys) < count {
+		fetchedKeys, err := r.keyFetcher.FetchKeys(ctx, count-len(keys))
+		if err != nil {
+			return nil, err
 		}
+		if len(fetchedKeys) == 0 {
+			return nil, errNoAvailableKey
+		}
+		keys = append(keys, fetchedKeys...)
+	}
+	return keys, nil
+}
+
+// CheckRefill reports whether the buffer can be refilled. After a failed
+// refill, it fetches a key again so that the generator is reported healthy
+// as soon as the key generation service recovers, even without new keys being
+// requested. Keys are fetched at most once every refillProbeInterval, and the
+// error of the last attempt is reported in between.
+func (r KeyGenerator) CheckRefill(ctx context.Context) error {
+	r.buffer.mutex.Lock()
+	err := r.buffer.refillErr
+	now := r.timer.Now()
+	if err == nil || now.Sub(r.buffer.fetchedAt) < refillProbeInterval {
+		r.buffer.mutex.Unlock()
+		return err
+	}
+	r.buffer.fetchedAt = now
+	r.buffer.mutex.Unlock()
+
+	keys, err := r.keyFetcher.FetchKeys(ctx, 1)
+
+	r.buffer.mutex.Lock()
+	defer r.buffer.mutex.Unlock()
+	r.buffer.refillErr = err
+	if err != nil {
+		return err
+	}
+	r.buffer.keys = append(r.buffer.keys, keys...)
+	return nil
+}
+
+// Close cancels the refill in flight and waits for it to finish. The buffer is
+// no longer refilled afterwards, so new keys can only be produced until it
+// runs out.
+func (r KeyGenerator) Close() {
+	r.buffer.mutex.Lock()
+	r.buffer.isClosed = true
+	isRefilling := r.buffer.isRefilling
+	refilled := r.buffer.refilled
+	r.buffer.mutex.Unlock()
+
+	r.buffer.cancel()
+	if isRefilling {
+		<-refilled
+	}
+}
+
+// Stats reports the depth of the buffer.
+func (r KeyGenerator) Stats() Stats {
+	r.buffer.mutex.Lock()
+	defer r.buffer.mutex.Unlock()
+
+	return Stats{
+		Depth:    len(r.buffer.keys),
+		Capacity: r.bufferSize,
+		Waiters:  r.buffer.waiters,
+	}
+}
+
+// startRefill refills the buffer in the background unless a refill is already
+// in flight or the generator is closed. The caller must hold the mutex of the
+// buffer.
+func (r KeyGenerator) startRefill() {
+	if r.buffer.isRefilling || r.buffer.isClosed {
 		return
 	}
+	r.buffer.isRefilling = true
+	go r.refill(r.bufferSize - len(r.buffer.keys))
+}
 
-	for _, key := range keys {
-		r.buffer <- bufferEntry{
-			key: key,
-			err: nil,
-		}
+// refill fetches keys for the buffer shared by all the requests for new keys,
+// so it isn't bound to the context of the request triggering the refill.
+func (r KeyGenerator) refill(count int) {
+	keys, err := r.keyFetcher.FetchKeys(r.buffer.ctx, count)
+	if err == nil && len(keys) == 0 {
+		err = errNoAvailableKey
+	}
+
+	if err != nil {
+		r.refillCounter.Inc(refillFailed)
+	} else {
+		r.refillCounter.Inc(refillSucceeded)
 	}
+
+	r.buffer.mutex.Lock()
+	defer r.buffer.mutex.Unlock()
+
+	r.buffer.keys = append(r.buffer.keys, keys...)
+	r.buffer.refillErr = err
+	r.buffer.fetchedAt = r.timer.Now()
+	r.buffer.isRefilling = false
+	close(r.buffer.refilled)
+	r.buffer.refilled = make(chan struct{})
 }
 
-// NewKeyGenerator creates KeyGenerator
-func NewKeyGenerator(bufferSize int, keyFetcher service.KeyFetcher) (KeyGenerator, error) {
+// NewKeyGenerator creates KeyGenerator which buffers up to bufferSize keys,
+// refilling the buffer once it holds fewer than lowWaterMark keys. A zero
+// lowWaterMark only refills the buffer once a key is requested while it is
+// empty.
+func NewKeyGenerator(
+	bufferSize int,
+	lowWaterMark int,
+	keyFetcher service.KeyFetcher,
+	refillCounter service.Counter,
+	timer fw.Timer,
+) (KeyGenerator, error) {
 	if bufferSize < 1 {
 		return KeyGenerator{}, errors.New("buffer size can't be less than 1")
 	}
+	if lowWaterMark < 0 || lowWaterMark > bufferSize {
+		return KeyGenerator{}, errors.New("low water mark must be between 0 and buffer size")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
 	return KeyGenerator{
-		bufferSize: bufferSize,
-		buffer:     make(chan bufferEntry, bufferSize),
-		keyFetcher: keyFetcher,
+		bufferSize:    bufferSize,
+		lowWaterMark:  lowWaterMark,
+		keyFetcher:    keyFetcher,
+		refillCounter: refillCounter,
+		timer:         timer,
+		buffer: &buffer{
+			mutex:    &sync.Mutex{},
+			refilled: make(chan struct{}),
+			ctx:      ctx,
+			cancel:   cancel,
+		},
 	}, nil
 }
@@ -0,0 +1,81 @@
+// +build !integration all
+
+package keygen
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/mdtest"
+	"github.com/short-d/short/app/usecase/service"
+)
+
+// slowKeyFetcher allocates unique keys after latency, counting the calls and
+// the keys allocated.
+type slowKeyFetcher struct {
+	latency   time.Duration
+	calls     *int64
+	allocated *int64
+}
+
+func (s slowKeyFetcher) FetchKeys(ctx context.Context, maxCount int) ([]service.Key, error) {
+	atomic.AddInt64(s.calls, 1)
+	time.Sleep(s.latency)
+
+	keys := make([]service.Key, 0, maxCount)
+	for idx := 0; idx < maxCount; idx++ {
+		key := atomic.AddInt64(s.allocated, 1)
+		keys = append(keys, service.Key(fmt.Sprint(key)))
+	}
+	return keys, nil
+}
+
+func BenchmarkKeyGenerator_NewKey(b *testing.B) {
+	benchmarks := []struct {
+		name         string
+		lowWaterMark int
+	}{
+		{name: "refill on demand", lowWaterMark: 0},
+		{name: "prefetch at half buffer", lowWaterMark: 50},
+	}
+
+	for _, benchmark := range benchmarks {
+		benchmark := benchmark
+		b.Run(benchmark.name, func(b *testing.B) {
+			keyFetcher := slowKeyFetcher{
+				latency:   time.Millisecond,
+				calls:     new(int64),
+				allocated: new(int64),
+			}
+			keyGen, err := NewKeyGenerator(100, benchmark.lowWaterMark, keyFetcher, service.NewCounterFake(), mdtest.NewTimerFake(time.Now()))
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			waited := new(int64)
+			b.SetParallelism(16)
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					start := time.Now()
+					_, err := keyGen.NewKey(context.Background())
+					atomic.AddInt64(waited, int64(time.Since(start)))
+					if err != nil {
+						b.Error(err)
+					}
+
+					// Simulates persisting the short link with the key.
+					time.Sleep(time.Millisecond)
+				}
+			})
+			b.StopTimer()
+
+			b.ReportMetric(float64(atomic.LoadInt64(waited))/float64(b.N), "wait-ns/op")
+			b.ReportMetric(float64(atomic.LoadInt64(keyFetcher.calls))/float64(b.N), "fetches/op")
+			b.ReportMetric(float64(atomic.LoadInt64(keyFetcher.allocated))/float64(b.N), "allocated-keys/op")
+		})
+	}
+}
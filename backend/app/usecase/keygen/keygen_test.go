@@ -3,7 +3,10 @@
 package keygen
 
 import (
+	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/short-d/app/mdtest"
 	"github.com/short-d/short/app/usecase/service"
@@ -12,9 +15,59 @@ import (
 func TestNewRemote(t *testing.T) {
 	t.Parallel()
 
-	keyFetcher := service.NewKeyFetcherFake([]service.Key{})
-	_, err := NewKeyGenerator(0, &keyFetcher)
-	mdtest.NotEqual(t, nil, err)
+	testCases := []struct {
+		name         string
+		bufferSize   int
+		lowWaterMark int
+		hasErr       bool
+	}{
+		{
+			name:         "buffer size is 0",
+			bufferSize:   0,
+			lowWaterMark: 0,
+			hasErr:       true,
+		},
+		{
+			name:         "low water mark is negative",
+			bufferSize:   2,
+			lowWaterMark: -1,
+			hasErr:       true,
+		},
+		{
+			name:         "low water mark exceeds buffer size",
+			bufferSize:   2,
+			lowWaterMark: 3,
+			hasErr:       true,
+		},
+		{
+			name:         "low water mark equals buffer size",
+			bufferSize:   2,
+			lowWaterMark: 2,
+			hasErr:       false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			keyFetcher := service.NewKeyFetcherFake([]service.Key{})
+			refillCounter := service.NewCounterFake()
+			_, err := NewKeyGenerator(
+				testCase.bufferSize,
+				testCase.lowWaterMark,
+				&keyFetcher,
+				refillCounter,
+				mdtest.NewTimerFake(time.Now()),
+			)
+			if testCase.hasErr {
+				mdtest.NotEqual(t, nil, err)
+				return
+			}
+			mdtest.Equal(t, nil, err)
+		})
+	}
 }
 
 func TestRemote_NewKey(t *testing.T) {
@@ -27,6 +80,8 @@ func TestRemote_NewKey(t *testing.T) {
 		expectedGetKeyOps int
 		expectedHasErrs   []bool
 		expectedKeys      []service.Key
+		expectedRefills   int
+		expectedFailures  int
 	}{
 		{
 			name: "buffer size is 2",
@@ -44,6 +99,8 @@ func TestRemote_NewKey(t *testing.T) {
 				service.Key("0K"),
 				service.Key("0L"),
 			},
+			expectedRefills:  1,
+			expectedFailures: 0,
 		},
 		{
 			name:              "no key available at beginning",
@@ -56,6 +113,8 @@ func TestRemote_NewKey(t *testing.T) {
 			expectedKeys: []service.Key{
 				service.Key(""),
 			},
+			expectedRefills:  0,
+			expectedFailures: 1,
 		},
 		{
 			name: "run out of key",
@@ -75,6 +134,8 @@ func TestRemote_NewKey(t *testing.T) {
 				service.Key("0L"),
 				service.Key(""),
 			},
+			expectedRefills:  1,
+			expectedFailures: 1,
 		},
 	}
 
@@ -84,11 +145,12 @@ func TestRemote_NewKey(t *testing.T) {
 			t.Parallel()
 
 			keyFetcher := service.NewKeyFetcherFake(testCase.availableKeys)
-			remote, err := NewKeyGenerator(testCase.bufferSize, &keyFetcher)
+			refillCounter := service.NewCounterFake()
+			remote, err := NewKeyGenerator(testCase.bufferSize, 0, &keyFetcher, refillCounter, mdtest.NewTimerFake(time.Now()))
 			mdtest.Equal(t, nil, err)
 
 			for idx := 0; idx < testCase.expectedGetKeyOps; idx++ {
-				key, err := remote.NewKey()
+				key, err := remote.NewKey(context.Background())
 
 				if testCase.expectedHasErrs[idx] {
 					mdtest.NotEqual(t, nil, err)
@@ -96,6 +158,290 @@ func TestRemote_NewKey(t *testing.T) {
 				}
 				mdtest.Equal(t, testCase.expectedKeys[idx], key)
 			}
+			mdtest.Equal(t, testCase.expectedRefills, refillCounter.Count("success"))
+			mdtest.Equal(t, testCase.expectedFailures, refillCounter.Count("failure"))
 		})
 	}
 }
+
+func TestRemote_NewKeys(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		availableKeys []service.Key
+		count         int
+		hasErr        bool
+		expectedKeys  []service.Key
+	}{
+		{
+			name:          "no key requested",
+			availableKeys: []service.Key{},
+			count:         0,
+			hasErr:        false,
+			expectedKeys:  []service.Key{},
+		},
+		{
+			name: "enough keys available",
+			availableKeys: []service.Key{
+				service.Key("0K"),
+				service.Key("0L"),
+				service.Key("0M"),
+			},
+			count:  2,
+			hasErr: false,
+			expectedKeys: []service.Key{
+				service.Key("0K"),
+				service.Key("0L"),
+			},
+		},
+		{
+			name: "run out of key",
+			availableKeys: []service.Key{
+				service.Key("0K"),
+			},
+			count:  2,
+			hasErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			keyFetcher := service.NewKeyFetcherFake(testCase.availableKeys)
+			refillCounter := service.NewCounterFake()
+			remote, err := NewKeyGenerator(1, 0, &keyFetcher, refillCounter, mdtest.NewTimerFake(time.Now()))
+			mdtest.Equal(t, nil, err)
+
+			keys, err := remote.NewKeys(context.Background(), testCase.count)
+			if testCase.hasErr {
+				mdtest.NotEqual(t, nil, err)
+				return
+			}
+			mdtest.Equal(t, nil, err)
+			mdtest.Equal(t, testCase.expectedKeys, keys)
+		})
+	}
+}
+
+func TestKeyGenerator_CheckRefill(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		availableKeys []service.Key
+		newKeyOps     int
+		hasErr        bool
+	}{
+		{
+			name:          "buffer never refilled",
+			availableKeys: []service.Key{},
+			newKeyOps:     0,
+			hasErr:        false,
+		},
+		{
+			name: "buffer refilled",
+			availableKeys: []service.Key{
+				service.Key("0K"),
+				service.Key("0L"),
+			},
+			newKeyOps: 1,
+			hasErr:    false,
+		},
+		{
+			name: "key fetcher still unavailable after failed refill",
+			availableKeys: []service.Key{
+				service.Key("0K"),
+			},
+			newKeyOps: 2,
+			hasErr:    true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			keyFetcher := service.NewKeyFetcherFake(testCase.availableKeys)
+			keyGen, err := NewKeyGenerator(2, 0, &keyFetcher, service.NewCounterFake(), mdtest.NewTimerFake(time.Now()))
+			mdtest.Equal(t, nil, err)
+
+			for idx := 0; idx < testCase.newKeyOps; idx++ {
+				keyGen.NewKey(context.Background())
+			}
+
+			err = keyGen.CheckRefill(context.Background())
+			if testCase.hasErr {
+				mdtest.NotEqual(t, nil, err)
+				return
+			}
+			mdtest.Equal(t, nil, err)
+		})
+	}
+}
+
+// failingKeyFetcher counts the attempts to fetch keys, failing all of them.
+type failingKeyFetcher struct {
+	fetches *int
+}
+
+func (f failingKeyFetcher) FetchKeys(ctx context.Context, maxCount int) ([]service.Key, error) {
+	*f.fetches++
+	return nil, errors.New("connection refused")
+}
+
+func TestKeyGenerator_CheckRefillThrottled(t *testing.T) {
+	t.Parallel()
+
+	keyFetcher := failingKeyFetcher{fetches: new(int)}
+	timer := mdtest.NewTimerFake(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC))
+	keyGen, err := NewKeyGenerator(2, 0, keyFetcher, service.NewCounterFake(), &timer)
+	mdtest.Equal(t, nil, err)
+
+	_, err = keyGen.NewKey(context.Background())
+	mdtest.NotEqual(t, nil, err)
+	mdtest.Equal(t, 1, *keyFetcher.fetches)
+
+	for idx := 0; idx < 3; idx++ {
+		err = keyGen.CheckRefill(context.Background())
+		mdtest.NotEqual(t, nil, err)
+	}
+	mdtest.Equal(t, 1, *keyFetcher.fetches)
+
+	timer.CurrentTime = timer.CurrentTime.Add(refillProbeInterval)
+	err = keyGen.CheckRefill(context.Background())
+	mdtest.NotEqual(t, nil, err)
+	mdtest.Equal(t, 2, *keyFetcher.fetches)
+}
+
+// blockingKeyFetcher never returns keys until released.
+type blockingKeyFetcher struct {
+	release chan struct{}
+}
+
+func (b blockingKeyFetcher) FetchKeys(ctx context.Context, maxCount int) ([]service.Key, error) {
+	<-b.release
+	return nil, errors.New("no available key")
+}
+
+func TestKeyGenerator_NewKeyContextDone(t *testing.T) {
+	t.Parallel()
+
+	keyFetcher := blockingKeyFetcher{release: make(chan struct{})}
+	defer close(keyFetcher.release)
+
+	keyGen, err := NewKeyGenerator(2, 0, keyFetcher, service.NewCounterFake(), mdtest.NewTimerFake(time.Now()))
+	mdtest.Equal(t, nil, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err = keyGen.NewKey(ctx)
+	mdtest.Equal(t, context.DeadlineExceeded, err)
+}
+
+func TestKeyGenerator_NewKeyRefillFailed(t *testing.T) {
+	t.Parallel()
+
+	keyFetcher := blockingKeyFetcher{release: make(chan struct{})}
+	refillCounter := service.NewCounterFake()
+	keyGen, err := NewKeyGenerator(2, 0, keyFetcher, refillCounter, mdtest.NewTimerFake(time.Now()))
+	mdtest.Equal(t, nil, err)
+
+	waiters := 3
+	errs := make(chan error, waiters)
+	for idx := 0; idx < waiters; idx++ {
+		go func() {
+			_, err := keyGen.NewKey(context.Background())
+			errs <- err
+		}()
+	}
+	waitFor(t, func() bool {
+		return keyGen.Stats().Waiters == waiters
+	})
+	close(keyFetcher.release)
+
+	for idx := 0; idx < waiters; idx++ {
+		mdtest.NotEqual(t, nil, <-errs)
+	}
+	mdtest.Equal(t, 1, refillCounter.Count("failure"))
+	mdtest.Equal(t, Stats{Depth: 0, Capacity: 2, Waiters: 0}, keyGen.Stats())
+}
+
+func TestKeyGenerator_NewKeyBelowLowWaterMark(t *testing.T) {
+	t.Parallel()
+
+	keyFetcher := service.NewKeyFetcherFake([]service.Key{
+		service.Key("0K"),
+		service.Key("0L"),
+		service.Key("0M"),
+		service.Key("0N"),
+		service.Key("0O"),
+		service.Key("0P"),
+		service.Key("0Q"),
+	})
+	refillCounter := service.NewCounterFake()
+	keyGen, err := NewKeyGenerator(4, 2, &keyFetcher, refillCounter, mdtest.NewTimerFake(time.Now()))
+	mdtest.Equal(t, nil, err)
+
+	expectedKeys := []service.Key{
+		service.Key("0K"),
+		service.Key("0L"),
+		service.Key("0M"),
+	}
+	for _, expectedKey := range expectedKeys {
+		key, err := keyGen.NewKey(context.Background())
+		mdtest.Equal(t, nil, err)
+		mdtest.Equal(t, expectedKey, key)
+	}
+
+	waitFor(t, func() bool {
+		return keyGen.Stats().Depth == 4
+	})
+	mdtest.Equal(t, 2, refillCounter.Count("success"))
+	mdtest.Equal(t, Stats{Depth: 4, Capacity: 4, Waiters: 0}, keyGen.Stats())
+}
+
+// waitFor polls condition until it holds, failing the test after a second.
+// cancelableKeyFetcher never returns keys until ctx is done.
+type cancelableKeyFetcher struct{}
+
+func (c cancelableKeyFetcher) FetchKeys(ctx context.Context, maxCount int) ([]service.Key, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestKeyGenerator_Close(t *testing.T) {
+	t.Parallel()
+
+	keyGen, err := NewKeyGenerator(2, 0, cancelableKeyFetcher{}, service.NewCounterFake(), mdtest.NewTimerFake(time.Now()))
+	mdtest.Equal(t, nil, err)
+
+	errs := make(chan error, 1)
+	go func() {
+		_, err := keyGen.NewKey(context.Background())
+		errs <- err
+	}()
+	waitFor(t, func() bool {
+		return keyGen.Stats().Waiters == 1
+	})
+
+	keyGen.Close()
+	mdtest.Equal(t, context.Canceled, <-errs)
+
+	_, err = keyGen.NewKey(context.Background())
+	mdtest.Equal(t, errClosed, err)
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	deadline := time.Now().Add(time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition never held")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,110 @@
+package keygen
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/short-d/short/app/usecase/repository"
+	"github.com/short-d/short/app/usecase/service"
+)
+
+const base62Alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// maxUnbiasedByte is the largest multiple of the size of base62Alphabet which
+// fits into a byte. Random bytes beyond it are discarded so that every
+// character is equally likely.
+const maxUnbiasedByte = 256 / len(base62Alphabet) * len(base62Alphabet)
+
+const maxGenerateAttempts = 10
+
+// ErrKeyCollision represents the failure of generating a key which is not
+// taken by any existing alias.
+type ErrKeyCollision int
+
+func (e ErrKeyCollision) Error() string {
+	return fmt.Sprintf("generated keys collided with existing aliases (attempts=%d)", int(e))
+}
+
+var _ service.KeyFetcher = (*LocalKeyFetcher)(nil)
+
+// LocalKeyFetcher generates random base62 keys in process, skipping the keys
+// already taken as aliases. Unlike key generation service, keys are not
+// reserved, so instances generating keys concurrently rely on the uniqueness
+// of aliases in storage to reject duplicates.
+type LocalKeyFetcher struct {
+	keyLength int
+	urlRepo   repository.URL
+	random    io.Reader
+}
+
+// FetchKeys generates maxCount keys which are not taken as aliases.
+func (l LocalKeyFetcher) FetchKeys(ctx context.Context, maxCount int) ([]service.Key, error) {
+	keys := make([]service.Key, 0, maxCount)
+	generated := make(map[service.Key]bool)
+	for len(keys) < maxCount {
+		key, err := l.generateKey(ctx, generated)
+		if err != nil {
+			return nil, err
+		}
+		generated[key] = true
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func (l LocalKeyFetcher) generateKey(ctx context.Context, generated map[service.Key]bool) (service.Key, error) {
+	for attempt := 0; attempt < maxGenerateAttempts; attempt++ {
+		key, err := l.randomKey()
+		if err != nil {
+			return "", err
+		}
+		if generated[key] {
+			continue
+		}
+
+		// Keys are fetched before the domain of the short link is known, so
+		// they are only checked against the default domain.
+		isExist, err := l.urlRepo.IsAliasExist(ctx, "", string(key))
+		if err != nil {
+			return "", err
+		}
+		if !isExist {
+			return key, nil
+		}
+	}
+	return "", ErrKeyCollision(maxGenerateAttempts)
+}
+
+func (l LocalKeyFetcher) randomKey() (service.Key, error) {
+	key := make([]byte, 0, l.keyLength)
+	buf := make([]byte, l.keyLength)
+	for len(key) < l.keyLength {
+		_, err := io.ReadFull(l.random, buf)
+		if err != nil {
+			return "", err
+		}
+		for _, b := range buf {
+			if int(b) >= maxUnbiasedByte || len(key) == l.keyLength {
+				continue
+			}
+			key = append(key, base62Alphabet[int(b)%len(base62Alphabet)])
+		}
+	}
+	return service.Key(key), nil
+}
+
+// NewLocalKeyFetcher creates LocalKeyFetcher which generates keys of
+// keyLength characters.
+func NewLocalKeyFetcher(keyLength int, urlRepo repository.URL) (LocalKeyFetcher, error) {
+	if keyLength < 1 {
+		return LocalKeyFetcher{}, errors.New("key length can't be less than 1")
+	}
+	return LocalKeyFetcher{
+		keyLength: keyLength,
+		urlRepo:   urlRepo,
+		random:    rand.Reader,
+	}, nil
+}
@@ -0,0 +1,128 @@
+// +build !integration all
+
+package keygen
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/short-d/app/mdtest"
+	"github.com/short-d/short/app/entity"
+	"github.com/short-d/short/app/usecase/repository"
+	"github.com/short-d/short/app/usecase/service"
+)
+
+func TestNewLocalKeyFetcher(t *testing.T) {
+	t.Parallel()
+
+	urlRepo := repository.NewURLFake(map[string]entity.URL{})
+	_, err := NewLocalKeyFetcher(0, &urlRepo)
+	mdtest.NotEqual(t, nil, err)
+}
+
+func TestLocalKeyFetcher_FetchKeys(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name         string
+		urls         map[string]entity.URL
+		randomBytes  []byte
+		maxCount     int
+		hasErr       bool
+		expectedKeys []service.Key
+	}{
+		{
+			name:         "no key requested",
+			urls:         map[string]entity.URL{},
+			randomBytes:  []byte{},
+			maxCount:     0,
+			expectedKeys: []service.Key{},
+		},
+		{
+			name:        "keys generated",
+			urls:        map[string]entity.URL{},
+			randomBytes: []byte{0, 1, 36, 61},
+			maxCount:    2,
+			expectedKeys: []service.Key{
+				service.Key("01"),
+				service.Key("az"),
+			},
+		},
+		{
+			name:        "bytes beyond alphabet wrapped",
+			urls:        map[string]entity.URL{},
+			randomBytes: []byte{62, 125},
+			maxCount:    1,
+			expectedKeys: []service.Key{
+				service.Key("01"),
+			},
+		},
+		{
+			name:        "biased bytes discarded",
+			urls:        map[string]entity.URL{},
+			randomBytes: []byte{255, 10, 11, 12},
+			maxCount:    1,
+			expectedKeys: []service.Key{
+				service.Key("AB"),
+			},
+		},
+		{
+			name: "taken alias skipped",
+			urls: map[string]entity.URL{
+				"01": {Alias: "01"},
+			},
+			randomBytes: []byte{0, 1, 2, 3},
+			maxCount:    1,
+			expectedKeys: []service.Key{
+				service.Key("23"),
+			},
+		},
+		{
+			name:        "duplicate in batch skipped",
+			urls:        map[string]entity.URL{},
+			randomBytes: []byte{0, 1, 0, 1, 2, 3},
+			maxCount:    2,
+			expectedKeys: []service.Key{
+				service.Key("01"),
+				service.Key("23"),
+			},
+		},
+		{
+			name: "every attempt collides",
+			urls: map[string]entity.URL{
+				"00": {Alias: "00"},
+			},
+			randomBytes: make([]byte, 2*maxGenerateAttempts),
+			maxCount:    1,
+			hasErr:      true,
+		},
+		{
+			name:        "random source exhausted",
+			urls:        map[string]entity.URL{},
+			randomBytes: []byte{0},
+			maxCount:    1,
+			hasErr:      true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			urlRepo := repository.NewURLFake(testCase.urls)
+			keyFetcher, err := NewLocalKeyFetcher(2, &urlRepo)
+			mdtest.Equal(t, nil, err)
+			keyFetcher.random = bytes.NewReader(testCase.randomBytes)
+
+			keys, err := keyFetcher.FetchKeys(context.Background(), testCase.maxCount)
+			if testCase.hasErr {
+				mdtest.NotEqual(t, nil, err)
+				return
+			}
+			mdtest.Equal(t, nil, err)
+			mdtest.Equal(t, testCase.expectedKeys, keys)
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package linksafety
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	netURL "net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/short-d/short/app/usecase/service"
+)
+
+// The constants enumerate the reasons a link is considered unsafe.
+const (
+	ReasonMalformed       = "malformed"
+	ReasonDangerousScheme = "dangerousScheme"
+	ReasonBlockedDomain   = "blockedDomain"
+	ReasonBlockedPattern  = "blockedPattern"
+	ReasonShortLinkLoop   = "shortLinkLoop"
+)
+
+const maxConcurrentTraces = 10
+
+var dangerousSchemes = map[string]bool{
+	"javascript": true,
+	"vbscript":   true,
+	"data":       true,
+	"file":       true,
+	"blob":       true,
+}
+
+// ErrUnsafeLink represents the error of shortening a link which may harm the
+// visitors. It holds the reason the link is rejected.
+type ErrUnsafeLink string
+
+func (e ErrUnsafeLink) Error() string {
+	return fmt.Sprintf("link is unsafe (reason=%s)", string(e))
+}
+
+// Blocklist represents the destinations which are not allowed to be
+// shortened. Domains block their subdomains as well, while patterns are
+// matched against the whole link.
+type Blocklist struct {
+	Domains  []string
+	Patterns []*regexp.Regexp
+}
+
+// Checker screens the destinations of long links before they are shortened.
+type Checker struct {
+	blocklist          Blocklist
+	shortLinkHostnames map[string]bool
+	redirectTracer     service.RedirectTracer
+	maxRedirectHops    int
+	traceTimeout       time.Duration
+}
+
+// Check ensures the long link and every link it redirects to use safe
+// schemes, are not blocklisted and don't point back to a short link. Failing
+// to follow the redirects doesn't make a link unsafe.
+func (c Checker) Check(ctx context.Context, longLink string) error {
+	return c.CheckAll(ctx, []string{longLink})[0]
+}
+
+// CheckAll checks each of the long links as Check does, following their
+// redirects concurrently. The redirects are no longer followed once the trace
+// timeout elapses or ctx is done, so the time spent on a batch is bounded
+// regardless of its size. The error of each link is returned at the same
+// index.
+func (c Checker) CheckAll(ctx context.Context, longLinks []string) []error {
+	errs := make([]error, len(longLinks))
+	if c.traceTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.traceTimeout)
+		defer cancel()
+	}
+
+	var wg sync.WaitGroup
+	tokens := make(chan struct{}, maxConcurrentTraces)
+	for idx, longLink := range longLinks {
+		errs[idx] = c.checkLink(longLink)
+		if errs[idx] != nil || c.maxRedirectHops < 1 {
+			continue
+		}
+
+		wg.Add(1)
+		go func(idx int, longLink string) {
+			defer wg.Done()
+
+			select {
+			case tokens <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			defer func() { <-tokens }()
+
+			errs[idx] = c.checkRedirects(ctx, longLink)
+		}(idx, longLink)
+	}
+	wg.Wait()
+	return errs
+}
+
+func (c Checker) checkRedirects(ctx context.Context, longLink string) error {
+	hops, _ := c.redirectTracer.TraceRedirects(ctx, longLink, c.maxRedirectHops)
+	for _, hop := range hops {
+		err := c.checkLink(hop)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c Checker) checkLink(link string) error {
+	u, err := netURL.Parse(link)
+	if err != nil {
+		return ErrUnsafeLink(ReasonMalformed)
+	}
+
+	if dangerousSchemes[strings.ToLower(u.Scheme)] {
+		return ErrUnsafeLink(ReasonDangerousScheme)
+	}
+
+	hostname := normalizeHostname(u.Hostname())
+	if c.shortLinkHostnames[hostname] {
+		return ErrUnsafeLink(ReasonShortLinkLoop)
+	}
+
+	for _, domain := range c.blocklist.Domains {
+		if hostname == domain || strings.HasSuffix(hostname, "."+domain) {
+			return ErrUnsafeLink(ReasonBlockedDomain)
+		}
+	}
+
+	for _, pattern := range c.blocklist.Patterns {
+		if pattern.MatchString(link) {
+			return ErrUnsafeLink(ReasonBlockedPattern)
+		}
+	}
+	return nil
+}
+
+func normalizeHostname(hostname string) string {
+	return strings.TrimSuffix(strings.ToLower(hostname), ".")
+}
+
+// ParseDomains reads a domain blocklist with one domain per line. Blank lines
+// and lines starting with # are ignored.
+func ParseDomains(reader io.Reader) ([]string, error) {
+	lines, err := readRules(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	domains := make([]string, 0, len(lines))
+	for _, line := range lines {
+		domains = append(domains, normalizeHostname(line))
+	}
+	return domains, nil
+}
+
+// ParsePatterns reads a regular expression blocklist with one expression per
+// line. Blank lines and lines starting with # are ignored.
+func ParsePatterns(reader io.Reader) ([]*regexp.Regexp, error) {
+	lines, err := readRules(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	patterns := make([]*regexp.Regexp, 0, len(lines))
+	for _, line := range lines {
+		pattern, err := regexp.Compile(line)
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, nil
+}
+
+func readRules(reader io.Reader) ([]string, error) {
+	var rules []string
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		rule := strings.TrimSpace(scanner.Text())
+		if rule == "" || strings.HasPrefix(rule, "#") {
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	return rules, scanner.Err()
+}
+
+// NewChecker creates link safety checker. The short link hostnames are the
+// hostnames serving short links, which long links must not redirect to.
+// Redirects are not followed when maxRedirectHops is not positive, and are
+// followed without a time limit when traceTimeout is not positive.
+func NewChecker(
+	blocklist Blocklist,
+	shortLinkHostnames []string,
+	redirectTracer service.RedirectTracer,
+	maxRedirectHops int,
+	traceTimeout time.Duration,
+) Checker {
+	hostnames := make(map[string]bool)
+	for _, hostname := range shortLinkHostnames {
+		hostnames[normalizeHostname(hostname)] = true
+	}
+	return Checker{
+		blocklist:          blocklist,
+		shortLinkHostnames: hostnames,
+		redirectTracer:     redirectTracer,
+		maxRedirectHops:    maxRedirectHops,
+		traceTimeout:       traceTimeout,
+	}
+}
@@ -0,0 +1,266 @@
+// +build !integration all
+
+package linksafety
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/mdtest"
+	"github.com/short-d/short/app/usecase/service"
+)
+
+func TestChecker_Check(t *testing.T) {
+	t.Parallel()
+
+	blocklist := Blocklist{
+		Domains:  []string{"malware.example"},
+		Patterns: []*regexp.Regexp{regexp.MustCompile(`\.exe$`)},
+	}
+	shortLinkHostnames := []string{"s.short-d.com"}
+
+	testCases := []struct {
+		name            string
+		longLink        string
+		redirects       map[string]string
+		maxRedirectHops int
+		expectedErr     error
+	}{
+		{
+			name:        "safe link",
+			longLink:    "https://www.google.com/search?q=short",
+			expectedErr: nil,
+		},
+		{
+			name:        "javascript scheme",
+			longLink:    "javascript://alert(1)",
+			expectedErr: ErrUnsafeLink(ReasonDangerousScheme),
+		},
+		{
+			name:        "data scheme in upper case",
+			longLink:    "DATA://text/html;base64,PHNjcmlwdD4=",
+			expectedErr: ErrUnsafeLink(ReasonDangerousScheme),
+		},
+		{
+			name:        "blocked domain",
+			longLink:    "http://malware.example/download",
+			expectedErr: ErrUnsafeLink(ReasonBlockedDomain),
+		},
+		{
+			name:        "subdomain of blocked domain",
+			longLink:    "http://cdn.MALWARE.example./download",
+			expectedErr: ErrUnsafeLink(ReasonBlockedDomain),
+		},
+		{
+			name:        "domain sharing suffix with blocked domain",
+			longLink:    "http://notmalware.example/download",
+			expectedErr: nil,
+		},
+		{
+			name:        "blocked pattern",
+			longLink:    "https://www.example.com/setup.exe",
+			expectedErr: ErrUnsafeLink(ReasonBlockedPattern),
+		},
+		{
+			name:        "short link",
+			longLink:    "https://s.short-d.com/r/220uFicCJj",
+			expectedErr: ErrUnsafeLink(ReasonShortLinkLoop),
+		},
+		{
+			name:     "redirect chain to short link",
+			longLink: "https://bit.example/a",
+			redirects: map[string]string{
+				"https://bit.example/a":  "https://tiny.example/b",
+				"https://tiny.example/b": "https://s.short-d.com/r/220uFicCJj",
+			},
+			maxRedirectHops: 5,
+			expectedErr:     ErrUnsafeLink(ReasonShortLinkLoop),
+		},
+		{
+			name:     "redirect to blocked domain",
+			longLink: "https://bit.example/a",
+			redirects: map[string]string{
+				"https://bit.example/a": "http://malware.example/download",
+			},
+			maxRedirectHops: 5,
+			expectedErr:     ErrUnsafeLink(ReasonBlockedDomain),
+		},
+		{
+			name:     "redirect chain longer than max hops",
+			longLink: "https://bit.example/a",
+			redirects: map[string]string{
+				"https://bit.example/a":  "https://tiny.example/b",
+				"https://tiny.example/b": "https://s.short-d.com/r/220uFicCJj",
+			},
+			maxRedirectHops: 1,
+			expectedErr:     nil,
+		},
+		{
+			name:     "redirects not followed",
+			longLink: "https://bit.example/a",
+			redirects: map[string]string{
+				"https://bit.example/a": "https://s.short-d.com/r/220uFicCJj",
+			},
+			maxRedirectHops: 0,
+			expectedErr:     nil,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			redirectTracer := service.NewRedirectTracerFake(testCase.redirects)
+			checker := NewChecker(
+				blocklist,
+				shortLinkHostnames,
+				redirectTracer,
+				testCase.maxRedirectHops,
+				time.Second,
+			)
+
+			err := checker.Check(context.Background(), testCase.longLink)
+			mdtest.Equal(t, testCase.expectedErr, err)
+		})
+	}
+}
+
+func TestChecker_CheckAll(t *testing.T) {
+	t.Parallel()
+
+	blocklist := Blocklist{
+		Domains: []string{"malware.example"},
+	}
+	shortLinkHostnames := []string{"s.short-d.com"}
+	redirects := map[string]string{
+		"https://bit.example/a": "http://malware.example/download",
+	}
+
+	testCases := []struct {
+		name         string
+		longLinks    []string
+		isCancelled  bool
+		expectedErrs []error
+	}{
+		{
+			name:         "no links",
+			longLinks:    []string{},
+			expectedErrs: []error{},
+		},
+		{
+			name: "errors kept in order",
+			longLinks: []string{
+				"https://www.google.com",
+				"https://bit.example/a",
+				"javascript://alert(1)",
+				"https://s.short-d.com/r/220uFicCJj",
+			},
+			expectedErrs: []error{
+				nil,
+				ErrUnsafeLink(ReasonBlockedDomain),
+				ErrUnsafeLink(ReasonDangerousScheme),
+				ErrUnsafeLink(ReasonShortLinkLoop),
+			},
+		},
+		{
+			name: "redirects not followed after timeout",
+			longLinks: []string{
+				"https://bit.example/a",
+				"http://malware.example/download",
+			},
+			isCancelled: true,
+			expectedErrs: []error{
+				nil,
+				ErrUnsafeLink(ReasonBlockedDomain),
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			redirectTracer := service.NewRedirectTracerFake(redirects)
+			checker := NewChecker(
+				blocklist,
+				shortLinkHostnames,
+				redirectTracer,
+				5,
+				time.Second,
+			)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if testCase.isCancelled {
+				cancel()
+			}
+
+			errs := checker.CheckAll(ctx, testCase.longLinks)
+			mdtest.Equal(t, testCase.expectedErrs, errs)
+		})
+	}
+}
+
+func TestParseDomains(t *testing.T) {
+	t.Parallel()
+
+	blocklist := `
+# phishing
+Phishing.example.
+
+malware.example
+`
+	domains, err := ParseDomains(strings.NewReader(blocklist))
+	mdtest.Equal(t, nil, err)
+	mdtest.Equal(t, []string{"phishing.example", "malware.example"}, domains)
+}
+
+func TestParsePatterns(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name             string
+		blocklist        string
+		expectedPatterns []string
+		hasErr           bool
+	}{
+		{
+			name: "valid patterns",
+			blocklist: `
+# executables
+\.exe$
+^http://`,
+			expectedPatterns: []string{`\.exe$`, `^http://`},
+		},
+		{
+			name:      "invalid pattern",
+			blocklist: `(`,
+			hasErr:    true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			patterns, err := ParsePatterns(strings.NewReader(testCase.blocklist))
+			if testCase.hasErr {
+				mdtest.NotEqual(t, nil, err)
+				return
+			}
+			mdtest.Equal(t, nil, err)
+
+			var expressions []string
+			for _, pattern := range patterns {
+				expressions = append(expressions, pattern.String())
+			}
+			mdtest.Equal(t, testCase.expectedPatterns, expressions)
+		})
+	}
+}
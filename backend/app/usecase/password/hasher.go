@@ -0,0 +1,101 @@
+package password
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	algorithm         = "pbkdf2-sha256"
+	defaultIterations = 100000
+	saltSize          = 16
+	keySize           = sha256.Size
+)
+
+// Hasher derives salted hashes from passwords with PBKDF2 so that the
+// passwords themselves are never persisted. A hash is encoded as
+// algorithm$iterations$salt$key.
+type Hasher struct {
+	iterations int
+}
+
+// Hash derives a hash from the password with a new random salt.
+func (h Hasher) Hash(password string) (string, error) {
+	salt := make([]byte, saltSize)
+	_, err := rand.Read(salt)
+	if err != nil {
+		return "", err
+	}
+
+	key := pbkdf2([]byte(password), salt, h.iterations)
+	return fmt.Sprintf(
+		"%s$%d$%s$%s",
+		algorithm,
+		h.iterations,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// IsMatch checks whether the password derives the given hash. Malformed
+// hashes never match.
+func (h Hasher) IsMatch(password string, hash string) bool {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 4 || parts[0] != algorithm {
+		return false
+	}
+
+	iterations, err := strconv.Atoi(parts[1])
+	if err != nil || iterations < 1 {
+		return false
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return false
+	}
+
+	expectedKey, err := base64.RawStdEncoding.DecodeString(parts[3])
+	if err != nil {
+		return false
+	}
+
+	key := pbkdf2([]byte(password), salt, iterations)
+	return subtle.ConstantTimeCompare(key, expectedKey) == 1
+}
+
+// pbkdf2 derives a key as specified in RFC 8018 with HMAC-SHA256 as the
+// pseudorandom function. The key is exactly one block long.
+func pbkdf2(password []byte, salt []byte, iterations int) []byte {
+	prf := hmac.New(sha256.New, password)
+
+	blockIndex := make([]byte, 4)
+	binary.BigEndian.PutUint32(blockIndex, 1)
+	prf.Write(salt)
+	prf.Write(blockIndex)
+	u := prf.Sum(nil)
+
+	key := make([]byte, keySize)
+	copy(key, u)
+	for i := 1; i < iterations; i++ {
+		prf.Reset()
+		prf.Write(u)
+		u = prf.Sum(u[:0])
+		for j := range key {
+			key[j] ^= u[j]
+		}
+	}
+	return key
+}
+
+// NewHasher creates password hasher.
+func NewHasher() Hasher {
+	return Hasher{iterations: defaultIterations}
+}
@@ -0,0 +1,77 @@
+// +build !integration all
+
+package password
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/short-d/app/mdtest"
+)
+
+func TestPBKDF2(t *testing.T) {
+	t.Parallel()
+
+	// Test vector from RFC 7914, section 11.
+	key := pbkdf2([]byte("passwd"), []byte("salt"), 1)
+	mdtest.Equal(t, "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc", hex.EncodeToString(key))
+}
+
+func TestHasher_IsMatch(t *testing.T) {
+	t.Parallel()
+
+	hasher := Hasher{iterations: 10}
+	hash, err := hasher.Hash("open sesame")
+	mdtest.Equal(t, nil, err)
+
+	otherHash, err := hasher.Hash("open sesame")
+	mdtest.Equal(t, nil, err)
+	mdtest.NotEqual(t, hash, otherHash)
+
+	testCases := []struct {
+		name     string
+		password string
+		hash     string
+		expected bool
+	}{
+		{
+			name:     "correct password",
+			password: "open sesame",
+			hash:     hash,
+			expected: true,
+		},
+		{
+			name:     "incorrect password",
+			password: "open barley",
+			hash:     hash,
+			expected: false,
+		},
+		{
+			name:     "empty password",
+			password: "",
+			hash:     hash,
+			expected: false,
+		},
+		{
+			name:     "unknown algorithm",
+			password: "open sesame",
+			hash:     "md5$10$c2FsdA$a2V5",
+			expected: false,
+		},
+		{
+			name:     "malformed hash",
+			password: "open sesame",
+			hash:     "pbkdf2-sha256$ten",
+			expected: false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			mdtest.Equal(t, testCase.expected, hasher.IsMatch(testCase.password, testCase.hash))
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/short-d/app/fw"
+	"github.com/short-d/short/app/entity"
+	"github.com/short-d/short/app/usecase/repository"
+)
+
+// Action represents a rate limited operation.
+type Action string
+
+// The constants enumerate all rate limited actions.
+const (
+	ActionCreateURL Action = "create-url"
+	ActionRedirect  Action = "redirect"
+	ActionUnlockURL Action = "unlock-url"
+)
+
+// Subject represents the party whose requests are counted against a rate
+// limit, such as a user, an API key or an IP address.
+type Subject string
+
+// UserSubject identifies requests made by a signed in user.
+func UserSubject(user entity.User) Subject {
+	return Subject(fmt.Sprintf("user:%s", user.Email))
+}
+
+// APIKeySubject identifies requests made with a personal API key.
+func APIKeySubject(apiKey entity.APIKey) Subject {
+	return Subject(fmt.Sprintf("api-key:%s", apiKey.ID))
+}
+
+// IPSubject identifies requests made from an IP address.
+func IPSubject(ipAddress string) Subject {
+	return Subject(fmt.Sprintf("ip:%s", ipAddress))
+}
+
+// IPAliasSubject identifies requests made from an IP address against a short
+// link. Domain is empty for the short links served under the default host.
+func IPAliasSubject(ipAddress string, domain string, alias string) Subject {
+	if domain == "" {
+		return Subject(fmt.Sprintf("ip:%s:alias:%s", ipAddress, alias))
+	}
+	return Subject(fmt.Sprintf("ip:%s:alias:%s/%s", ipAddress, domain, alias))
+}
+
+// ErrRateLimited represents the error of performing an action more often than
+// allowed.
+type ErrRateLimited Action
+
+func (e ErrRateLimited) Error() string {
+	return fmt.Sprintf("rate limit exceeded (action=%s)", string(e))
+}
+
+// Limiter restricts how often each subject can perform an action using token
+// buckets.
+type Limiter struct {
+	tokenBucketRepo repository.TokenBucket
+	timer           fw.Timer
+	limits          map[Action]entity.RateLimit
+}
+
+// Allow consumes a token from the bucket of the subject for the given action,
+// returning ErrRateLimited when the bucket is empty. Actions without a
+// positive limit are not rate limited.
+func (l Limiter) Allow(ctx context.Context, action Action, subject Subject) error {
+	limit, ok := l.limits[action]
+	if !ok || limit.Requests < 1 || limit.Period <= 0 {
+		return nil
+	}
+
+	key := fmt.Sprintf("%s:%s", action, subject)
+	isTaken, err := l.tokenBucketRepo.TakeToken(ctx, key, limit, l.timer.Now())
+	if err != nil {
+		return err
+	}
+	if !isTaken {
+		return ErrRateLimited(action)
+	}
+	return nil
+}
+
+// Check returns ErrRateLimited when the bucket of the subject for the given
+// action is empty, without consuming a token. It lets actions which are only
+// charged when they fail be rejected before being attempted.
+func (l Limiter) Check(ctx context.Context, action Action, subject Subject) error {
+	limit, ok := l.limits[action]
+	if !ok || limit.Requests < 1 || limit.Period <= 0 {
+		return nil
+	}
+
+	key := fmt.Sprintf("%s:%s", action, subject)
+	hasToken, err := l.tokenBucketRepo.HasToken(ctx, key, limit, l.timer.Now())
+	if err != nil {
+		return err
+	}
+	if !hasToken {
+		return ErrRateLimited(action)
+	}
+	return nil
+}
+
+// AllowN consumes up to n tokens from the bucket of the subject for the given
+// action, one for each item of a batch, and returns how many of them are
+// allowed. It stops at the first empty bucket, so the items past the returned
+// count must be rejected. Actions without a positive limit are not rate
+// limited.
+func (l Limiter) AllowN(ctx context.Context, action Action, subject Subject, n int) (int, error) {
+	for idx := 0; idx < n; idx++ {
+		err := l.Allow(ctx, action, subject)
+		if _, ok := err.(ErrRateLimited); ok {
+			return idx, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+	return n, nil
+}
+
+// NewLimiter creates Limiter with the rate limit of each action.
+func NewLimiter(
+	tokenBucketRepo repository.TokenBucket,
+	timer fw.Timer,
+	limits map[Action]entity.RateLimit,
+) Limiter {
+	return Limiter{
+		tokenBucketRepo: tokenBucketRepo,
+		timer:           timer,
+		limits:          limits,
+	}
+}
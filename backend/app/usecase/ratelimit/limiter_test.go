@@ -0,0 +1,305 @@
+// +build !integration all
+
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/mdtest"
+	"github.com/short-d/short/app/entity"
+	"github.com/short-d/short/app/usecase/repository"
+)
+
+func TestLimiter_Allow(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	alpha := UserSubject(entity.User{Email: "alpha@example.com"})
+	beta := UserSubject(entity.User{Email: "beta@example.com"})
+
+	type request struct {
+		action      Action
+		subject     Subject
+		elapsed     time.Duration
+		expectedErr error
+	}
+
+	testCases := []struct {
+		name     string
+		limits   map[Action]entity.RateLimit
+		requests []request
+	}{
+		{
+			name:   "action without limit",
+			limits: map[Action]entity.RateLimit{},
+			requests: []request{
+				{action: ActionCreateURL, subject: alpha},
+				{action: ActionCreateURL, subject: alpha},
+			},
+		},
+		{
+			name: "limit disabled",
+			limits: map[Action]entity.RateLimit{
+				ActionCreateURL: {Requests: 0, Period: time.Minute},
+			},
+			requests: []request{
+				{action: ActionCreateURL, subject: alpha},
+				{action: ActionCreateURL, subject: alpha},
+			},
+		},
+		{
+			name: "burst exceeds limit",
+			limits: map[Action]entity.RateLimit{
+				ActionCreateURL: {Requests: 2, Period: time.Minute},
+			},
+			requests: []request{
+				{action: ActionCreateURL, subject: alpha},
+				{action: ActionCreateURL, subject: alpha},
+				{
+					action:      ActionCreateURL,
+					subject:     alpha,
+					expectedErr: ErrRateLimited(ActionCreateURL),
+				},
+			},
+		},
+		{
+			name: "subjects have separate buckets",
+			limits: map[Action]entity.RateLimit{
+				ActionCreateURL: {Requests: 1, Period: time.Minute},
+			},
+			requests: []request{
+				{action: ActionCreateURL, subject: alpha},
+				{action: ActionCreateURL, subject: beta},
+				{
+					action:      ActionCreateURL,
+					subject:     alpha,
+					expectedErr: ErrRateLimited(ActionCreateURL),
+				},
+			},
+		},
+		{
+			name: "actions have separate buckets",
+			limits: map[Action]entity.RateLimit{
+				ActionCreateURL: {Requests: 1, Period: time.Minute},
+				ActionRedirect:  {Requests: 1, Period: time.Minute},
+			},
+			requests: []request{
+				{action: ActionCreateURL, subject: alpha},
+				{action: ActionRedirect, subject: alpha},
+			},
+		},
+		{
+			name: "tokens refill over time",
+			limits: map[Action]entity.RateLimit{
+				ActionRedirect: {Requests: 2, Period: time.Minute},
+			},
+			requests: []request{
+				{action: ActionRedirect, subject: alpha},
+				{action: ActionRedirect, subject: alpha},
+				{
+					action:      ActionRedirect,
+					subject:     alpha,
+					elapsed:     20 * time.Second,
+					expectedErr: ErrRateLimited(ActionRedirect),
+				},
+				{action: ActionRedirect, subject: alpha, elapsed: 10 * time.Second},
+				{
+					action:      ActionRedirect,
+					subject:     alpha,
+					expectedErr: ErrRateLimited(ActionRedirect),
+				},
+			},
+		},
+		{
+			name: "tokens never exceed limit",
+			limits: map[Action]entity.RateLimit{
+				ActionRedirect: {Requests: 1, Period: time.Minute},
+			},
+			requests: []request{
+				{action: ActionRedirect, subject: alpha},
+				{action: ActionRedirect, subject: alpha, elapsed: time.Hour},
+				{
+					action:      ActionRedirect,
+					subject:     alpha,
+					expectedErr: ErrRateLimited(ActionRedirect),
+				},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			tokenBucketRepo := repository.NewTokenBucketFake()
+			timer := mdtest.NewTimerFake(now)
+			limiter := NewLimiter(&tokenBucketRepo, &timer, testCase.limits)
+
+			for _, req := range testCase.requests {
+				timer.CurrentTime = timer.CurrentTime.Add(req.elapsed)
+				err := limiter.Allow(context.Background(), req.action, req.subject)
+				mdtest.Equal(t, req.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestLimiter_AllowN(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	alpha := UserSubject(entity.User{Email: "alpha@example.com"})
+
+	testCases := []struct {
+		name            string
+		limits          map[Action]entity.RateLimit
+		taken           int
+		n               int
+		expectedAllowed int
+	}{
+		{
+			name:            "action without limit",
+			limits:          map[Action]entity.RateLimit{},
+			n:               1000,
+			expectedAllowed: 1000,
+		},
+		{
+			name: "batch within limit",
+			limits: map[Action]entity.RateLimit{
+				ActionCreateURL: {Requests: 5, Period: time.Minute},
+			},
+			n:               3,
+			expectedAllowed: 3,
+		},
+		{
+			name: "batch exceeds limit",
+			limits: map[Action]entity.RateLimit{
+				ActionCreateURL: {Requests: 5, Period: time.Minute},
+			},
+			n:               1000,
+			expectedAllowed: 5,
+		},
+		{
+			name: "bucket partially used",
+			limits: map[Action]entity.RateLimit{
+				ActionCreateURL: {Requests: 5, Period: time.Minute},
+			},
+			taken:           4,
+			n:               3,
+			expectedAllowed: 1,
+		},
+		{
+			name: "bucket empty",
+			limits: map[Action]entity.RateLimit{
+				ActionCreateURL: {Requests: 5, Period: time.Minute},
+			},
+			taken:           5,
+			n:               3,
+			expectedAllowed: 0,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			tokenBucketRepo := repository.NewTokenBucketFake()
+			timer := mdtest.NewTimerFake(now)
+			limiter := NewLimiter(&tokenBucketRepo, &timer, testCase.limits)
+
+			for idx := 0; idx < testCase.taken; idx++ {
+				err := limiter.Allow(context.Background(), ActionCreateURL, alpha)
+				mdtest.Equal(t, nil, err)
+			}
+
+			allowed, err := limiter.AllowN(context.Background(), ActionCreateURL, alpha, testCase.n)
+			mdtest.Equal(t, nil, err)
+			mdtest.Equal(t, testCase.expectedAllowed, allowed)
+		})
+	}
+}
+
+func TestLimiter_Check(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	alpha := IPAliasSubject("203.0.113.7", "", "220uFicCJj")
+	beta := IPAliasSubject("198.51.100.1", "", "220uFicCJj")
+
+	testCases := []struct {
+		name        string
+		limits      map[Action]entity.RateLimit
+		taken       int
+		subject     Subject
+		expectedErr error
+	}{
+		{
+			name:        "action without limit",
+			limits:      map[Action]entity.RateLimit{},
+			taken:       5,
+			subject:     alpha,
+			expectedErr: nil,
+		},
+		{
+			name: "new bucket",
+			limits: map[Action]entity.RateLimit{
+				ActionUnlockURL: {Requests: 2, Period: time.Minute},
+			},
+			taken:       0,
+			subject:     alpha,
+			expectedErr: nil,
+		},
+		{
+			name: "bucket partially used",
+			limits: map[Action]entity.RateLimit{
+				ActionUnlockURL: {Requests: 2, Period: time.Minute},
+			},
+			taken:       1,
+			subject:     alpha,
+			expectedErr: nil,
+		},
+		{
+			name: "bucket empty",
+			limits: map[Action]entity.RateLimit{
+				ActionUnlockURL: {Requests: 2, Period: time.Minute},
+			},
+			taken:       2,
+			subject:     alpha,
+			expectedErr: ErrRateLimited(ActionUnlockURL),
+		},
+		{
+			name: "bucket of another visitor empty",
+			limits: map[Action]entity.RateLimit{
+				ActionUnlockURL: {Requests: 2, Period: time.Minute},
+			},
+			taken:       2,
+			subject:     beta,
+			expectedErr: nil,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			tokenBucketRepo := repository.NewTokenBucketFake()
+			timer := mdtest.NewTimerFake(now)
+			limiter := NewLimiter(&tokenBucketRepo, &timer, testCase.limits)
+
+			for idx := 0; idx < testCase.taken; idx++ {
+				err := limiter.Allow(context.Background(), ActionUnlockURL, alpha)
+				mdtest.Equal(t, nil, err)
+			}
+
+			for idx := 0; idx < 2; idx++ {
+				err := limiter.Check(context.Background(), ActionUnlockURL, testCase.subject)
+				mdtest.Equal(t, testCase.expectedErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package redirectrule
+
+import (
+	"context"
+
+	"github.com/short-d/short/app/entity"
+	"github.com/short-d/short/app/usecase/repository"
+	"github.com/short-d/short/app/usecase/service"
+	"github.com/short-d/short/app/usecase/useragent"
+)
+
+// Visitor represents the request headers and the IP address of a visitor of a
+// short link.
+type Visitor struct {
+	AcceptLanguage string
+	UserAgent      string
+	IPAddress      string
+}
+
+// Resolver picks the long link a visitor of a short link is redirected to.
+type Resolver struct {
+	ruleRepo   repository.RedirectRule
+	geoLocator service.GeoLocator
+}
+
+// ResolveLongLink evaluates the redirect rules of the short link in order,
+// returning the long link of the first matching rule, or the original long
+// link when no rule matches. Visitors who can't be located don't match any
+// country rule.
+func (r Resolver) ResolveLongLink(ctx context.Context, url entity.URL, visitor Visitor) (string, error) {
+	rules, err := r.ruleRepo.FindByAlias(ctx, url.Domain, url.Alias)
+	if err != nil {
+		return "", err
+	}
+
+	if len(rules) == 0 {
+		return url.OriginalURL, nil
+	}
+
+	visit := Visit{
+		Language: ParseLanguage(visitor.AcceptLanguage),
+		Device:   useragent.ParseDevice(visitor.UserAgent),
+		Bucket:   SplitBucket(url.Alias, visitor.IPAddress, visitor.UserAgent),
+	}
+	if hasCondition(rules, entity.RedirectConditionCountry) {
+		visit.Country, _ = r.geoLocator.GetCountry(visitor.IPAddress)
+	}
+
+	rule, ok := Match(rules, visit)
+	if !ok {
+		return url.OriginalURL, nil
+	}
+	return rule.LongLink, nil
+}
+
+func hasCondition(rules []entity.RedirectRule, condition entity.RedirectCondition) bool {
+	for _, rule := range rules {
+		if rule.Condition == condition {
+			return true
+		}
+	}
+	return false
+}
+
+// NewResolver creates redirect rule Resolver.
+func NewResolver(ruleRepo repository.RedirectRule, geoLocator service.GeoLocator) Resolver {
+	return Resolver{
+		ruleRepo:   ruleRepo,
+		geoLocator: geoLocator,
+	}
+}
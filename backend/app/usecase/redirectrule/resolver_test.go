@@ -0,0 +1,148 @@
+// +build !integration all
+
+package redirectrule
+
+import (
+	"context"
+	"testing"
+
+	"github.com/short-d/app/mdtest"
+	"github.com/short-d/short/app/entity"
+	"github.com/short-d/short/app/usecase/repository"
+	"github.com/short-d/short/app/usecase/service"
+)
+
+func TestResolver_ResolveLongLink(t *testing.T) {
+	t.Parallel()
+
+	url := entity.URL{
+		Alias:       "220uFicCJj",
+		OriginalURL: "https://www.example.com",
+	}
+	iPhone := "Mozilla/5.0 (iPhone; CPU iPhone OS 13_3 like Mac OS X) Mobile/15E148"
+
+	testCases := []struct {
+		name             string
+		rules            map[string][]entity.RedirectRule
+		countries        map[string]string
+		visitor          Visitor
+		expectedLongLink string
+	}{
+		{
+			name:  "no rules",
+			rules: nil,
+			visitor: Visitor{
+				AcceptLanguage: "fr",
+				UserAgent:      iPhone,
+				IPAddress:      "203.0.113.7",
+			},
+			expectedLongLink: "https://www.example.com",
+		},
+		{
+			name: "no rule matches",
+			rules: map[string][]entity.RedirectRule{
+				"220uFicCJj": {
+					{
+						Condition: entity.RedirectConditionLanguage,
+						Values:    []string{"de"},
+						LongLink:  "https://www.example.de",
+					},
+				},
+			},
+			visitor: Visitor{
+				AcceptLanguage: "fr",
+				UserAgent:      iPhone,
+				IPAddress:      "203.0.113.7",
+			},
+			expectedLongLink: "https://www.example.com",
+		},
+		{
+			name: "device rule matches",
+			rules: map[string][]entity.RedirectRule{
+				"220uFicCJj": {
+					{
+						Condition: entity.RedirectConditionLanguage,
+						Values:    []string{"de"},
+						LongLink:  "https://www.example.de",
+					},
+					{
+						Condition: entity.RedirectConditionDevice,
+						Values:    []string{"mobile"},
+						LongLink:  "https://m.example.com",
+					},
+				},
+			},
+			visitor: Visitor{
+				AcceptLanguage: "fr",
+				UserAgent:      iPhone,
+				IPAddress:      "203.0.113.7",
+			},
+			expectedLongLink: "https://m.example.com",
+		},
+		{
+			name: "country rule matches",
+			rules: map[string][]entity.RedirectRule{
+				"220uFicCJj": {
+					{
+						Condition: entity.RedirectConditionCountry,
+						Values:    []string{"CA"},
+						LongLink:  "https://www.example.ca",
+					},
+				},
+			},
+			countries: map[string]string{"203.0.113.7": "CA"},
+			visitor: Visitor{
+				IPAddress: "203.0.113.7",
+			},
+			expectedLongLink: "https://www.example.ca",
+		},
+		{
+			name: "visitor can't be located",
+			rules: map[string][]entity.RedirectRule{
+				"220uFicCJj": {
+					{
+						Condition: entity.RedirectConditionCountry,
+						Values:    []string{"CA"},
+						LongLink:  "https://www.example.ca",
+					},
+				},
+			},
+			countries: map[string]string{},
+			visitor: Visitor{
+				IPAddress: "203.0.113.7",
+			},
+			expectedLongLink: "https://www.example.com",
+		},
+		{
+			name: "split takes all visitors",
+			rules: map[string][]entity.RedirectRule{
+				"220uFicCJj": {
+					{
+						Condition: entity.RedirectConditionSplit,
+						Weight:    100,
+						LongLink:  "https://www.example.com/b",
+					},
+				},
+			},
+			visitor: Visitor{
+				IPAddress: "203.0.113.7",
+			},
+			expectedLongLink: "https://www.example.com/b",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			ruleRepo := repository.NewRedirectRuleFake(testCase.rules)
+			geoLocator := service.NewGeoLocatorFake(testCase.countries)
+			resolver := NewResolver(&ruleRepo, geoLocator)
+
+			longLink, err := resolver.ResolveLongLink(context.Background(), url, testCase.visitor)
+			mdtest.Equal(t, nil, err)
+			mdtest.Equal(t, testCase.expectedLongLink, longLink)
+		})
+	}
+}
@@ -0,0 +1,231 @@
+package redirectrule
+
+import (
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/short-d/short/app/entity"
+	"github.com/short-d/short/app/usecase/useragent"
+)
+
+const (
+	maxRules     = 20
+	numBuckets   = 100
+	maxLanguages = 20
+)
+
+var (
+	languagePattern = regexp.MustCompile(`^[a-zA-Z]{2,3}$`)
+	countryPattern  = regexp.MustCompile(`^[a-zA-Z]{2}$`)
+)
+
+var devices = map[string]bool{
+	string(useragent.DeviceDesktop): true,
+	string(useragent.DeviceMobile):  true,
+	string(useragent.DeviceTablet):  true,
+	string(useragent.DeviceBot):     true,
+	string(useragent.DeviceUnknown): true,
+}
+
+// ErrInvalidRule represents the error of a malformed redirect rule. It holds
+// the position of the rule and the reason it is rejected.
+type ErrInvalidRule struct {
+	Position int
+	Reason   string
+}
+
+func (e ErrInvalidRule) Error() string {
+	return fmt.Sprintf("invalid redirect rule (position=%d, reason=%s)", e.Position, e.Reason)
+}
+
+// Visit represents the properties of a visit to a short link which redirect
+// rules are matched against. Bucket places the visitor in one of 100 equally
+// sized groups shared by all split rules of the short link.
+type Visit struct {
+	Language string
+	Device   useragent.Device
+	Country  string
+	Bucket   int
+}
+
+// Validate ensures the redirect rules can be evaluated. Languages are primary
+// language subtags, such as "en", countries are ISO 3166-1 alpha-2 codes, and
+// the weights of split rules add up to at most 100 percent.
+func Validate(rules []entity.RedirectRule) error {
+	if len(rules) > maxRules {
+		return ErrInvalidRule{Position: maxRules, Reason: "too many rules"}
+	}
+
+	totalWeight := 0
+	for position, rule := range rules {
+		reason := validateRule(rule)
+		if reason != "" {
+			return ErrInvalidRule{Position: position, Reason: reason}
+		}
+
+		totalWeight += rule.Weight
+		if totalWeight > numBuckets {
+			return ErrInvalidRule{Position: position, Reason: "split weights exceed 100"}
+		}
+	}
+	return nil
+}
+
+func validateRule(rule entity.RedirectRule) string {
+	if rule.LongLink == "" {
+		return "empty long link"
+	}
+
+	if rule.Condition == entity.RedirectConditionSplit {
+		if len(rule.Values) > 0 {
+			return "split rule with values"
+		}
+		if rule.Weight < 1 || rule.Weight > numBuckets {
+			return "weight out of range"
+		}
+		return ""
+	}
+
+	switch rule.Condition {
+	case entity.RedirectConditionLanguage,
+		entity.RedirectConditionDevice,
+		entity.RedirectConditionCountry:
+	default:
+		return "unknown condition"
+	}
+
+	if rule.Weight != 0 {
+		return "weight on conditional rule"
+	}
+	if len(rule.Values) == 0 {
+		return "no values"
+	}
+
+	for _, value := range rule.Values {
+		if !isValueValid(rule.Condition, value) {
+			return fmt.Sprintf("invalid %s %q", rule.Condition, value)
+		}
+	}
+	return ""
+}
+
+func isValueValid(condition entity.RedirectCondition, value string) bool {
+	switch condition {
+	case entity.RedirectConditionLanguage:
+		return languagePattern.MatchString(value)
+	case entity.RedirectConditionDevice:
+		return devices[strings.ToLower(value)]
+	case entity.RedirectConditionCountry:
+		return countryPattern.MatchString(value)
+	default:
+		return false
+	}
+}
+
+// Match finds the first redirect rule the visit satisfies. Split rules take
+// consecutive ranges of buckets in the order they are listed.
+func Match(rules []entity.RedirectRule, visit Visit) (entity.RedirectRule, bool) {
+	splitStart := 0
+	for _, rule := range rules {
+		switch rule.Condition {
+		case entity.RedirectConditionLanguage:
+			if containsFold(rule.Values, visit.Language) {
+				return rule, true
+			}
+		case entity.RedirectConditionDevice:
+			if containsFold(rule.Values, string(visit.Device)) {
+				return rule, true
+			}
+		case entity.RedirectConditionCountry:
+			if containsFold(rule.Values, visit.Country) {
+				return rule, true
+			}
+		case entity.RedirectConditionSplit:
+			splitEnd := splitStart + rule.Weight
+			if visit.Bucket >= splitStart && visit.Bucket < splitEnd {
+				return rule, true
+			}
+			splitStart = splitEnd
+		}
+	}
+	return entity.RedirectRule{}, false
+}
+
+func containsFold(values []string, target string) bool {
+	if target == "" {
+		return false
+	}
+	for _, value := range values {
+		if strings.EqualFold(value, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseLanguage finds the primary subtag of the most preferred language in
+// an Accept-Language header, such as "en" for "en-US,fr;q=0.8". It returns an
+// empty string when no language is acceptable.
+func ParseLanguage(acceptLanguage string) string {
+	type preference struct {
+		language string
+		quality  float64
+	}
+
+	var preferences []preference
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		if len(preferences) == maxLanguages {
+			break
+		}
+
+		fields := strings.Split(part, ";")
+		tag := strings.TrimSpace(fields[0])
+		language := strings.ToLower(strings.Split(tag, "-")[0])
+		if !languagePattern.MatchString(language) {
+			continue
+		}
+
+		quality := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if !strings.HasPrefix(param, "q=") {
+				continue
+			}
+			value, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64)
+			if err != nil {
+				value = 0
+			}
+			quality = value
+		}
+
+		if quality <= 0 {
+			continue
+		}
+		preferences = append(preferences, preference{language: language, quality: quality})
+	}
+
+	if len(preferences) == 0 {
+		return ""
+	}
+
+	sort.SliceStable(preferences, func(i, j int) bool {
+		return preferences[i].quality > preferences[j].quality
+	})
+	return preferences[0].language
+}
+
+// SplitBucket places a visitor of a short link into a bucket between 0 and 99
+// so that the same visitor keeps landing on the same side of an A/B test.
+func SplitBucket(alias string, ipAddress string, userAgent string) int {
+	hash := fnv.New32a()
+	hash.Write([]byte(alias))
+	hash.Write([]byte{0})
+	hash.Write([]byte(ipAddress))
+	hash.Write([]byte{0})
+	hash.Write([]byte(userAgent))
+	return int(hash.Sum32() % numBuckets)
+}
@@ -0,0 +1,311 @@
+// +build !integration all
+
+package redirectrule
+
+import (
+	"testing"
+
+	"github.com/short-d/app/mdtest"
+	"github.com/short-d/short/app/entity"
+	"github.com/short-d/short/app/usecase/useragent"
+)
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name        string
+		rules       []entity.RedirectRule
+		expectedErr error
+	}{
+		{
+			name:        "no rules",
+			rules:       []entity.RedirectRule{},
+			expectedErr: nil,
+		},
+		{
+			name: "valid rules",
+			rules: []entity.RedirectRule{
+				{
+					Condition: entity.RedirectConditionLanguage,
+					Values:    []string{"fr", "DE"},
+					LongLink:  "https://www.example.com/eu",
+				},
+				{
+					Condition: entity.RedirectConditionDevice,
+					Values:    []string{"mobile", "tablet"},
+					LongLink:  "https://m.example.com",
+				},
+				{
+					Condition: entity.RedirectConditionCountry,
+					Values:    []string{"CA"},
+					LongLink:  "https://www.example.ca",
+				},
+				{
+					Condition: entity.RedirectConditionSplit,
+					Weight:    60,
+					LongLink:  "https://www.example.com/a",
+				},
+				{
+					Condition: entity.RedirectConditionSplit,
+					Weight:    40,
+					LongLink:  "https://www.example.com/b",
+				},
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "unknown condition",
+			rules: []entity.RedirectRule{
+				{
+					Condition: "weather",
+					Values:    []string{"rain"},
+					LongLink:  "https://www.example.com",
+				},
+			},
+			expectedErr: ErrInvalidRule{Position: 0, Reason: "unknown condition"},
+		},
+		{
+			name: "empty long link",
+			rules: []entity.RedirectRule{
+				{
+					Condition: entity.RedirectConditionDevice,
+					Values:    []string{"mobile"},
+				},
+			},
+			expectedErr: ErrInvalidRule{Position: 0, Reason: "empty long link"},
+		},
+		{
+			name: "no values",
+			rules: []entity.RedirectRule{
+				{
+					Condition: entity.RedirectConditionCountry,
+					Values:    []string{},
+					LongLink:  "https://www.example.com",
+				},
+			},
+			expectedErr: ErrInvalidRule{Position: 0, Reason: "no values"},
+		},
+		{
+			name: "invalid country",
+			rules: []entity.RedirectRule{
+				{
+					Condition: entity.RedirectConditionCountry,
+					Values:    []string{"Canada"},
+					LongLink:  "https://www.example.com",
+				},
+			},
+			expectedErr: ErrInvalidRule{Position: 0, Reason: `invalid country "Canada"`},
+		},
+		{
+			name: "invalid device",
+			rules: []entity.RedirectRule{
+				{
+					Condition: entity.RedirectConditionDevice,
+					Values:    []string{"watch"},
+					LongLink:  "https://www.example.com",
+				},
+			},
+			expectedErr: ErrInvalidRule{Position: 0, Reason: `invalid device "watch"`},
+		},
+		{
+			name: "weight on conditional rule",
+			rules: []entity.RedirectRule{
+				{
+					Condition: entity.RedirectConditionLanguage,
+					Values:    []string{"en"},
+					Weight:    10,
+					LongLink:  "https://www.example.com",
+				},
+			},
+			expectedErr: ErrInvalidRule{Position: 0, Reason: "weight on conditional rule"},
+		},
+		{
+			name: "split weight out of range",
+			rules: []entity.RedirectRule{
+				{
+					Condition: entity.RedirectConditionSplit,
+					Weight:    0,
+					LongLink:  "https://www.example.com",
+				},
+			},
+			expectedErr: ErrInvalidRule{Position: 0, Reason: "weight out of range"},
+		},
+		{
+			name: "split weights exceed 100",
+			rules: []entity.RedirectRule{
+				{
+					Condition: entity.RedirectConditionSplit,
+					Weight:    70,
+					LongLink:  "https://www.example.com/a",
+				},
+				{
+					Condition: entity.RedirectConditionSplit,
+					Weight:    40,
+					LongLink:  "https://www.example.com/b",
+				},
+			},
+			expectedErr: ErrInvalidRule{Position: 1, Reason: "split weights exceed 100"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			mdtest.Equal(t, testCase.expectedErr, Validate(testCase.rules))
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	t.Parallel()
+
+	rules := []entity.RedirectRule{
+		{
+			Condition: entity.RedirectConditionCountry,
+			Values:    []string{"ca"},
+			LongLink:  "https://www.example.ca",
+		},
+		{
+			Condition: entity.RedirectConditionLanguage,
+			Values:    []string{"fr"},
+			LongLink:  "https://www.example.fr",
+		},
+		{
+			Condition: entity.RedirectConditionDevice,
+			Values:    []string{"mobile"},
+			LongLink:  "https://m.example.com",
+		},
+		{
+			Condition: entity.RedirectConditionSplit,
+			Weight:    30,
+			LongLink:  "https://www.example.com/a",
+		},
+		{
+			Condition: entity.RedirectConditionSplit,
+			Weight:    20,
+			LongLink:  "https://www.example.com/b",
+		},
+	}
+
+	testCases := []struct {
+		name             string
+		visit            Visit
+		expectedIsMatch  bool
+		expectedLongLink string
+	}{
+		{
+			name: "country matches before language",
+			visit: Visit{
+				Language: "fr",
+				Country:  "CA",
+				Bucket:   99,
+			},
+			expectedIsMatch:  true,
+			expectedLongLink: "https://www.example.ca",
+		},
+		{
+			name: "language matches",
+			visit: Visit{
+				Language: "fr",
+				Device:   useragent.DeviceMobile,
+				Bucket:   99,
+			},
+			expectedIsMatch:  true,
+			expectedLongLink: "https://www.example.fr",
+		},
+		{
+			name: "device matches",
+			visit: Visit{
+				Language: "en",
+				Device:   useragent.DeviceMobile,
+				Bucket:   99,
+			},
+			expectedIsMatch:  true,
+			expectedLongLink: "https://m.example.com",
+		},
+		{
+			name:             "first split bucket",
+			visit:            Visit{Bucket: 29},
+			expectedIsMatch:  true,
+			expectedLongLink: "https://www.example.com/a",
+		},
+		{
+			name:             "second split bucket",
+			visit:            Visit{Bucket: 30},
+			expectedIsMatch:  true,
+			expectedLongLink: "https://www.example.com/b",
+		},
+		{
+			name:            "bucket outside splits",
+			visit:           Visit{Bucket: 50},
+			expectedIsMatch: false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			rule, isMatch := Match(rules, testCase.visit)
+			mdtest.Equal(t, testCase.expectedIsMatch, isMatch)
+			mdtest.Equal(t, testCase.expectedLongLink, rule.LongLink)
+		})
+	}
+}
+
+func TestParseLanguage(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name             string
+		acceptLanguage   string
+		expectedLanguage string
+	}{
+		{
+			name:             "empty header",
+			acceptLanguage:   "",
+			expectedLanguage: "",
+		},
+		{
+			name:             "region subtag",
+			acceptLanguage:   "en-US",
+			expectedLanguage: "en",
+		},
+		{
+			name:             "first language without quality",
+			acceptLanguage:   "fr-CA, fr;q=0.9, en;q=0.8",
+			expectedLanguage: "fr",
+		},
+		{
+			name:             "highest quality",
+			acceptLanguage:   "en;q=0.5, DE;q=0.7, *;q=0.1",
+			expectedLanguage: "de",
+		},
+		{
+			name:             "unacceptable language",
+			acceptLanguage:   "es;q=0, *",
+			expectedLanguage: "",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			mdtest.Equal(t, testCase.expectedLanguage, ParseLanguage(testCase.acceptLanguage))
+		})
+	}
+}
+
+func TestSplitBucket(t *testing.T) {
+	t.Parallel()
+
+	bucket := SplitBucket("220uFicCJj", "203.0.113.7", "Mozilla/5.0")
+	mdtest.Equal(t, true, bucket >= 0 && bucket < 100)
+	mdtest.Equal(t, bucket, SplitBucket("220uFicCJj", "203.0.113.7", "Mozilla/5.0"))
+}
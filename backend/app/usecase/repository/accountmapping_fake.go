@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"errors"
 
 	"github.com/short-d/short/app/entity"
@@ -17,7 +18,7 @@ type AccountMappingFake struct {
 
 // IsSSOUserExist checks whether a external user is linked to any internal
 // user.
-func (a AccountMappingFake) IsSSOUserExist(ssoUser entity.SSOUser) (bool, error) {
+func (a AccountMappingFake) IsSSOUserExist(ctx context.Context, ssoUser entity.SSOUser) (bool, error) {
 	for _, currSSOUser := range a.ssoUsers {
 		if currSSOUser.ID == ssoUser.ID {
 			return true, nil
@@ -42,7 +43,7 @@ func (a AccountMappingFake) IsRelationExist(ssoUser entity.SSOUser, user entity.
 }
 
 // CreateMapping links an external user with an internal user.
-func (a *AccountMappingFake) CreateMapping(ssoUser entity.SSOUser, user entity.User) error {
+func (a *AccountMappingFake) CreateMapping(ctx context.Context, ssoUser entity.SSOUser, user entity.User) error {
 	isExist := a.IsRelationExist(ssoUser, user)
 	if isExist {
 		return errors.New("mapping exists")
@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/short-d/short/app/entity"
+)
+
+// APIKey accesses personal API keys from storage, such as database. Keys are
+// only stored and looked up by their hashes.
+type APIKey interface {
+	Create(ctx context.Context, apiKey entity.APIKey, keyHash string) error
+	Delete(ctx context.Context, id string) error
+	FindByUser(ctx context.Context, user entity.User) ([]entity.APIKey, error)
+	FindByHash(ctx context.Context, keyHash string) (entity.APIKey, error)
+}
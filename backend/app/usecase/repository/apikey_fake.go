@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/short-d/short/app/entity"
+)
+
+var _ APIKey = (*APIKeyFake)(nil)
+
+// APIKeyFake represents in memory implementation of APIKey repository.
+type APIKeyFake struct {
+	apiKeys   []entity.APIKey
+	keyHashes []string
+}
+
+// Create stores a new API key with the hash of its secret.
+func (a *APIKeyFake) Create(ctx context.Context, apiKey entity.APIKey, keyHash string) error {
+	for idx, existingKey := range a.apiKeys {
+		if existingKey.ID == apiKey.ID || a.keyHashes[idx] == keyHash {
+			return errors.New("api key exists")
+		}
+	}
+
+	a.apiKeys = append(a.apiKeys, apiKey)
+	a.keyHashes = append(a.keyHashes, keyHash)
+	return nil
+}
+
+// Delete removes the API key with the given ID.
+func (a *APIKeyFake) Delete(ctx context.Context, id string) error {
+	for idx, apiKey := range a.apiKeys {
+		if apiKey.ID != id {
+			continue
+		}
+		a.apiKeys = append(a.apiKeys[:idx], a.apiKeys[idx+1:]...)
+		a.keyHashes = append(a.keyHashes[:idx], a.keyHashes[idx+1:]...)
+		return nil
+	}
+	return errors.New("api key not found")
+}
+
+// FindByUser fetches the API keys created by the given user in creation
+// order.
+func (a APIKeyFake) FindByUser(ctx context.Context, user entity.User) ([]entity.APIKey, error) {
+	apiKeys := []entity.APIKey{}
+	for _, apiKey := range a.apiKeys {
+		if apiKey.UserEmail == user.Email {
+			apiKeys = append(apiKeys, apiKey)
+		}
+	}
+	return apiKeys, nil
+}
+
+// FindByHash fetches the API key whose secret has the given hash.
+func (a APIKeyFake) FindByHash(ctx context.Context, keyHash string) (entity.APIKey, error) {
+	for idx, hash := range a.keyHashes {
+		if hash == keyHash {
+			return a.apiKeys[idx], nil
+		}
+	}
+	return entity.APIKey{}, errors.New("api key not found")
+}
+
+// NewAPIKeyFake creates APIKeyFake
+func NewAPIKeyFake() APIKeyFake {
+	return APIKeyFake{}
+}
@@ -1,11 +1,13 @@
 package repository
 
 import (
+	"context"
+
 	"github.com/short-d/short/app/entity"
 )
 
 // ChangeLog accesses changelog from storage, such as database.
 type ChangeLog interface {
-	GetChangeLog() ([]entity.Change, error)
-	CreateChange(newChange entity.Change) (entity.Change, error)
+	GetChangeLog(ctx context.Context) ([]entity.Change, error)
+	CreateChange(ctx context.Context, newChange entity.Change) (entity.Change, error)
 }
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"errors"
 
 	"github.com/short-d/short/app/entity"
@@ -14,12 +15,12 @@ type ChangeLogFake struct {
 }
 
 // GetChangeLog fetches full ChangeLog from memory
-func (c ChangeLogFake) GetChangeLog() ([]entity.Change, error) {
+func (c ChangeLogFake) GetChangeLog(ctx context.Context) ([]entity.Change, error) {
 	return c.changeLog, nil
 }
 
 // CreateChange creates and persists new Change in the repository
-func (c *ChangeLogFake) CreateChange(newChange entity.Change) (entity.Change, error) {
+func (c *ChangeLogFake) CreateChange(ctx context.Context, newChange entity.Change) (entity.Change, error) {
 	for _, change := range c.changeLog {
 		if change.ID == newChange.ID {
 			return entity.Change{}, errors.New("change exists")
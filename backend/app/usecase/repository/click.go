@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/short-d/short/app/entity"
+)
+
+// Click accesses clicks on short links from storage, such as database.
+type Click interface {
+	CreateClicks(ctx context.Context, clicks []entity.Click) error
+	CountClicks(ctx context.Context, domain string, alias string) (int, error)
+	CountClicksByDay(ctx context.Context, domain string, alias string, from time.Time, to time.Time) ([]entity.DailyClicks, error)
+	CountClicksByReferrer(ctx context.Context, domain string, alias string, limit int) ([]entity.ReferrerClicks, error)
+	CountClicksByUserAgent(ctx context.Context, domain string, alias string) (map[string]int, error)
+}
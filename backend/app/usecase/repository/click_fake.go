@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/short-d/short/app/entity"
+)
+
+var _ Click = (*ClickFake)(nil)
+
+// ClickFake represents in memory implementation of Click repository.
+type ClickFake struct {
+	clicks []entity.Click
+}
+
+// CreateClicks appends a batch of clicks to the repository.
+func (c *ClickFake) CreateClicks(ctx context.Context, clicks []entity.Click) error {
+	c.clicks = append(c.clicks, clicks...)
+	return nil
+}
+
+// GetClicks fetches all the clicks stored in the repository.
+func (c ClickFake) GetClicks() []entity.Click {
+	return c.clicks
+}
+
+// CountClicks counts the clicks on a given alias.
+func (c ClickFake) CountClicks(ctx context.Context, domain string, alias string) (int, error) {
+	count := 0
+	for _, click := range c.clicks {
+		if click.Domain == domain && click.Alias == alias {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// CountClicksByDay counts the clicks on a given alias within [from, to) for
+// each UTC day with at least one click.
+func (c ClickFake) CountClicksByDay(ctx context.Context, domain string, alias string, from time.Time, to time.Time) ([]entity.DailyClicks, error) {
+	counts := make(map[time.Time]int)
+	for _, click := range c.clicks {
+		if click.Domain != domain || click.Alias != alias {
+			continue
+		}
+		if click.ClickedAt.Before(from) || !click.ClickedAt.Before(to) {
+			continue
+		}
+		day := click.ClickedAt.UTC().Truncate(24 * time.Hour)
+		counts[day]++
+	}
+
+	dailyClicks := make([]entity.DailyClicks, 0, len(counts))
+	for day, count := range counts {
+		dailyClicks = append(dailyClicks, entity.DailyClicks{Day: day, Count: count})
+	}
+	sort.Slice(dailyClicks, func(i, j int) bool {
+		return dailyClicks[i].Day.Before(dailyClicks[j].Day)
+	})
+	return dailyClicks, nil
+}
+
+// CountClicksByReferrer counts the clicks on a given alias for the most
+// common referrers.
+func (c ClickFake) CountClicksByReferrer(ctx context.Context, domain string, alias string, limit int) ([]entity.ReferrerClicks, error) {
+	counts := make(map[string]int)
+	for _, click := range c.clicks {
+		if click.Domain == domain && click.Alias == alias {
+			counts[click.Referrer]++
+		}
+	}
+
+	referrerClicks := make([]entity.ReferrerClicks, 0, len(counts))
+	for referrer, count := range counts {
+		referrerClicks = append(referrerClicks, entity.ReferrerClicks{
+			Referrer: referrer,
+			Count:    count,
+		})
+	}
+	sort.Slice(referrerClicks, func(i, j int) bool {
+		if referrerClicks[i].Count != referrerClicks[j].Count {
+			return referrerClicks[i].Count > referrerClicks[j].Count
+		}
+		return referrerClicks[i].Referrer < referrerClicks[j].Referrer
+	})
+
+	if len(referrerClicks) > limit {
+		referrerClicks = referrerClicks[:limit]
+	}
+	return referrerClicks, nil
+}
+
+// CountClicksByUserAgent counts the clicks on a given alias for each
+// User-Agent.
+func (c ClickFake) CountClicksByUserAgent(ctx context.Context, domain string, alias string) (map[string]int, error) {
+	counts := make(map[string]int)
+	for _, click := range c.clicks {
+		if click.Domain == domain && click.Alias == alias {
+			counts[click.UserAgent]++
+		}
+	}
+	return counts, nil
+}
+
+// NewClickFake creates ClickFake
+func NewClickFake(clicks []entity.Click) ClickFake {
+	return ClickFake{
+		clicks: clicks,
+	}
+}
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/short-d/short/app/entity"
+)
+
+// ErrDomainNotFound represents the error of fetching a domain which is absent
+// from storage.
+type ErrDomainNotFound string
+
+func (e ErrDomainNotFound) Error() string {
+	return fmt.Sprintf("domain not found in storage (hostname=%s)", string(e))
+}
+
+// Domain accesses the custom domains registered by users from storage, such
+// as database.
+type Domain interface {
+	Create(ctx context.Context, domain entity.Domain) error
+	// GetByHostname fails with ErrDomainNotFound when no domain has the given
+	// hostname.
+	GetByHostname(ctx context.Context, hostname string) (entity.Domain, error)
+	FindByOwner(ctx context.Context, user entity.User) ([]entity.Domain, error)
+	MarkVerified(ctx context.Context, hostname string, verifiedAt time.Time) error
+	// ReplaceUnverified replaces the registration of domain's hostname with
+	// domain when it is neither verified nor created after createdBefore. It
+	// fails with ErrDomainNotFound when no such registration exists.
+	ReplaceUnverified(ctx context.Context, domain entity.Domain, createdBefore time.Time) error
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/short-d/short/app/entity"
+)
+
+var _ Domain = (*DomainFake)(nil)
+
+// DomainFake represents in memory implementation of Domain repository.
+type DomainFake struct {
+	domains []entity.Domain
+}
+
+// Create stores a new domain unless its hostname is taken.
+func (d *DomainFake) Create(ctx context.Context, domain entity.Domain) error {
+	for _, existingDomain := range d.domains {
+		if existingDomain.Hostname == domain.Hostname {
+			return errors.New("domain exists")
+		}
+	}
+	d.domains = append(d.domains, domain)
+	return nil
+}
+
+// GetByHostname fetches the domain with the given hostname.
+func (d DomainFake) GetByHostname(ctx context.Context, hostname string) (entity.Domain, error) {
+	for _, domain := range d.domains {
+		if domain.Hostname == hostname {
+			return domain, nil
+		}
+	}
+	return entity.Domain{}, ErrDomainNotFound(hostname)
+}
+
+// FindByOwner fetches the domains registered by the given user in
+// registration order.
+func (d DomainFake) FindByOwner(ctx context.Context, user entity.User) ([]entity.Domain, error) {
+	domains := []entity.Domain{}
+	for _, domain := range d.domains {
+		if domain.OwnerEmail == user.Email {
+			domains = append(domains, domain)
+		}
+	}
+	return domains, nil
+}
+
+// MarkVerified records when the ownership of the domain with the given
+// hostname was proven.
+func (d *DomainFake) MarkVerified(ctx context.Context, hostname string, verifiedAt time.Time) error {
+	for idx, domain := range d.domains {
+		if domain.Hostname != hostname {
+			continue
+		}
+		d.domains[idx].VerifiedAt = &verifiedAt
+		return nil
+	}
+	return ErrDomainNotFound(hostname)
+}
+
+// ReplaceUnverified replaces the registration of domain's hostname with domain
+// when it is neither verified nor created after createdBefore.
+func (d *DomainFake) ReplaceUnverified(ctx context.Context, domain entity.Domain, createdBefore time.Time) error {
+	for idx, existingDomain := range d.domains {
+		if existingDomain.Hostname != domain.Hostname {
+			continue
+		}
+		if existingDomain.IsVerified() || existingDomain.CreatedAt == nil || existingDomain.CreatedAt.After(createdBefore) {
+			break
+		}
+		d.domains[idx] = domain
+		return nil
+	}
+	return ErrDomainNotFound(domain.Hostname)
+}
+
+// NewDomainFake creates DomainFake
+func NewDomainFake(domains []entity.Domain) DomainFake {
+	return DomainFake{
+		domains: domains,
+	}
+}
@@ -0,0 +1,11 @@
+package repository
+
+import "context"
+
+// PublicURL accesses the visibility of URLs from storage, such as database.
+type PublicURL interface {
+	Create(ctx context.Context, domain string, alias string) error
+	Delete(ctx context.Context, domain string, alias string) error
+	IsPublic(ctx context.Context, domain string, alias string) (bool, error)
+	FindKeys(ctx context.Context, limit int, after *URLKey) ([]URLKey, error)
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"sort"
+)
+
+var _ PublicURL = (*PublicURLFake)(nil)
+
+// PublicURLFake represents in memory implementation of PublicURL repository.
+type PublicURLFake struct {
+	keys map[URLKey]bool
+}
+
+// Create marks the URL with the given alias as public.
+func (p *PublicURLFake) Create(ctx context.Context, domain string, alias string) error {
+	p.keys[URLKey{Domain: domain, Alias: alias}] = true
+	return nil
+}
+
+// Delete marks the URL with the given alias as private.
+func (p *PublicURLFake) Delete(ctx context.Context, domain string, alias string) error {
+	delete(p.keys, URLKey{Domain: domain, Alias: alias})
+	return nil
+}
+
+// IsPublic checks whether the URL with the given alias is public.
+func (p PublicURLFake) IsPublic(ctx context.Context, domain string, alias string) (bool, error) {
+	return p.keys[URLKey{Domain: domain, Alias: alias}], nil
+}
+
+// FindKeys fetches the keys of at most limit public URLs in alphabetical
+// order of their aliases and then domains, starting after the given key.
+func (p PublicURLFake) FindKeys(ctx context.Context, limit int, after *URLKey) ([]URLKey, error) {
+	var keys []URLKey
+	for key := range p.keys {
+		if after != nil && !isKeyBefore(*after, key) {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return isKeyBefore(keys[i], keys[j])
+	})
+
+	if len(keys) > limit {
+		keys = keys[:limit]
+	}
+	return keys, nil
+}
+
+func isKeyBefore(key1 URLKey, key2 URLKey) bool {
+	if key1.Alias != key2.Alias {
+		return key1.Alias < key2.Alias
+	}
+	return key1.Domain < key2.Domain
+}
+
+// NewPublicURLFake creates PublicURLFake with the given aliases under the
+// default domain.
+func NewPublicURLFake(aliases []string) PublicURLFake {
+	keys := make(map[URLKey]bool)
+	for _, alias := range aliases {
+		keys[URLKey{Alias: alias}] = true
+	}
+	return PublicURLFake{
+		keys: keys,
+	}
+}
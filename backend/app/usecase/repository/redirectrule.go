@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/short-d/short/app/entity"
+)
+
+// RedirectRule accesses the ordered redirect rules of short links from
+// storage, such as database.
+type RedirectRule interface {
+	FindByAlias(ctx context.Context, domain string, alias string) ([]entity.RedirectRule, error)
+	ReplaceRules(ctx context.Context, domain string, alias string, rules []entity.RedirectRule) error
+}
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/short-d/short/app/entity"
+)
+
+var _ RedirectRule = (*RedirectRuleFake)(nil)
+
+// RedirectRuleFake represents in memory implementation of RedirectRule
+// repository.
+type RedirectRuleFake struct {
+	rules map[URLKey][]entity.RedirectRule
+}
+
+// FindByAlias fetches the redirect rules of the short link with the given
+// alias in evaluation order.
+func (r RedirectRuleFake) FindByAlias(ctx context.Context, domain string, alias string) ([]entity.RedirectRule, error) {
+	rules := []entity.RedirectRule{}
+	return append(rules, r.rules[URLKey{Domain: domain, Alias: alias}]...), nil
+}
+
+// ReplaceRules replaces all redirect rules of the short link with the given
+// alias.
+func (r *RedirectRuleFake) ReplaceRules(ctx context.Context, domain string, alias string, rules []entity.RedirectRule) error {
+	if r.rules == nil {
+		r.rules = make(map[URLKey][]entity.RedirectRule)
+	}
+	r.rules[URLKey{Domain: domain, Alias: alias}] = append([]entity.RedirectRule{}, rules...)
+	return nil
+}
+
+// NewRedirectRuleFake creates RedirectRuleFake with the given rules keyed by
+// alias under the default domain.
+func NewRedirectRuleFake(rules map[string][]entity.RedirectRule) RedirectRuleFake {
+	rulesByKey := make(map[URLKey][]entity.RedirectRule)
+	for alias, aliasRules := range rules {
+		rulesByKey[URLKey{Alias: alias}] = aliasRules
+	}
+	return RedirectRuleFake{rules: rulesByKey}
+}
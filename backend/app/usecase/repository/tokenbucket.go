@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/short-d/short/app/entity"
+)
+
+// TokenBucket accesses rate limiting token buckets from storage, such as
+// memory or database. Taking a token must be atomic so that concurrent
+// requests can't exceed the limit.
+type TokenBucket interface {
+	TakeToken(ctx context.Context, key string, limit entity.RateLimit, now time.Time) (bool, error)
+	// HasToken checks whether a token can be taken from the bucket with the
+	// given key without consuming it.
+	HasToken(ctx context.Context, key string, limit entity.RateLimit, now time.Time) (bool, error)
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/short-d/short/app/entity"
+)
+
+var _ TokenBucket = (*TokenBucketFake)(nil)
+
+// TokenBucketFake represents in memory implementation of TokenBucket
+// repository.
+type TokenBucketFake struct {
+	buckets map[string]entity.TokenBucket
+}
+
+// TakeToken consumes a token from the bucket with the given key, creating a
+// full bucket if it doesn't exist.
+func (t *TokenBucketFake) TakeToken(ctx context.Context, key string, limit entity.RateLimit, now time.Time) (bool, error) {
+	bucket, ok := t.buckets[key]
+	if !ok {
+		bucket = entity.NewTokenBucket(limit, now)
+	}
+
+	bucket, isTaken := bucket.Take(limit, now)
+	t.buckets[key] = bucket
+	return isTaken, nil
+}
+
+// HasToken checks whether a token can be taken from the bucket with the given
+// key without consuming it.
+func (t TokenBucketFake) HasToken(ctx context.Context, key string, limit entity.RateLimit, now time.Time) (bool, error) {
+	bucket, ok := t.buckets[key]
+	if !ok {
+		return true, nil
+	}
+	return bucket.HasToken(limit, now), nil
+}
+
+// NewTokenBucketFake creates TokenBucketFake
+func NewTokenBucketFake() TokenBucketFake {
+	return TokenBucketFake{
+		buckets: make(map[string]entity.TokenBucket),
+	}
+}
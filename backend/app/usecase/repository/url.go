@@ -1,11 +1,44 @@
 package repository
 
-import "github.com/short-d/short/app/entity"
+import (
+	"context"
+	"fmt"
 
-// URL accesses urls from storage, such as database.
+	"github.com/short-d/short/app/entity"
+)
+
+// ErrURLNotFound represents the error of fetching a URL which is absent from
+// storage.
+type ErrURLNotFound string
+
+func (e ErrURLNotFound) Error() string {
+	return fmt.Sprintf("url not found in storage (alias=%s)", string(e))
+}
+
+// URLKey identifies a URL by its alias within its domain. Domain is empty for
+// the URLs served under the default host.
+type URLKey struct {
+	Domain string
+	Alias  string
+}
+
+// NewURLKey creates the URLKey identifying url.
+func NewURLKey(url entity.URL) URLKey {
+	return URLKey{
+		Domain: url.Domain,
+		Alias:  url.Alias,
+	}
+}
+
+// URL accesses urls from storage, such as database. Aliases are unique within
+// a domain.
 type URL interface {
-	IsAliasExist(alias string) (bool, error)
-	GetByAlias(alias string) (entity.URL, error)
-	Create(url entity.URL) error
-	GetByAliases(aliases []string) ([]entity.URL, error)
+	IsAliasExist(ctx context.Context, domain string, alias string) (bool, error)
+	// GetByAlias fails with ErrURLNotFound when no URL has the given alias.
+	GetByAlias(ctx context.Context, domain string, alias string) (entity.URL, error)
+	Create(ctx context.Context, url entity.URL) error
+	GetByKeys(ctx context.Context, keys []URLKey) ([]entity.URL, error)
+	Update(ctx context.Context, url entity.URL) error
+	IncrementClickCount(ctx context.Context, domain string, alias string) (bool, error)
+	Delete(ctx context.Context, domain string, alias string) error
 }
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"errors"
 
 	"github.com/short-d/short/app/entity"
@@ -10,50 +11,50 @@ var _ URL = (*URLFake)(nil)
 
 // URLFake accesses URL information in url table through SQL.
 type URLFake struct {
-	urls map[string]entity.URL
+	urls map[URLKey]entity.URL
 }
 
 // IsAliasExist checks whether a given alias exist in url table.
-func (u URLFake) IsAliasExist(alias string) (bool, error) {
-	_, ok := u.urls[alias]
+func (u URLFake) IsAliasExist(ctx context.Context, domain string, alias string) (bool, error) {
+	_, ok := u.urls[URLKey{Domain: domain, Alias: alias}]
 	return ok, nil
 }
 
 // Create inserts a new URL into url table.
-func (u *URLFake) Create(url entity.URL) error {
-	isExist, err := u.IsAliasExist(url.Alias)
+func (u *URLFake) Create(ctx context.Context, url entity.URL) error {
+	isExist, err := u.IsAliasExist(ctx, url.Domain, url.Alias)
 	if err != nil {
 		return err
 	}
 	if isExist {
 		return errors.New("alias exists")
 	}
-	u.urls[url.Alias] = url
+	u.urls[NewURLKey(url)] = url
 	return nil
 }
 
 // GetByAlias finds an URL in url table given alias.
-func (u URLFake) GetByAlias(alias string) (entity.URL, error) {
-	isExist, err := u.IsAliasExist(alias)
+func (u URLFake) GetByAlias(ctx context.Context, domain string, alias string) (entity.URL, error) {
+	isExist, err := u.IsAliasExist(ctx, domain, alias)
 	if err != nil {
 		return entity.URL{}, err
 	}
 	if !isExist {
-		return entity.URL{}, errors.New("alias not found")
+		return entity.URL{}, ErrURLNotFound(alias)
 	}
-	url := u.urls[alias]
+	url := u.urls[URLKey{Domain: domain, Alias: alias}]
 	return url, nil
 }
 
-// GetByAliases finds all URL for a list of aliases
-func (u URLFake) GetByAliases(aliases []string) ([]entity.URL, error) {
-	if len(aliases) == 0 {
+// GetByKeys finds all URL for a list of keys
+func (u URLFake) GetByKeys(ctx context.Context, keys []URLKey) ([]entity.URL, error) {
+	if len(keys) == 0 {
 		return []entity.URL{}, nil
 	}
 
 	var urls []entity.URL
-	for _, alias := range aliases {
-		url, err := u.GetByAlias(alias)
+	for _, key := range keys {
+		url, err := u.GetByAlias(ctx, key.Domain, key.Alias)
 
 		if err != nil {
 			return urls, err
@@ -63,9 +64,55 @@ func (u URLFake) GetByAliases(aliases []string) ([]entity.URL, error) {
 	return urls, nil
 }
 
-// NewURLFake creates in memory URL repository
+// Update replaces an existing URL in url table.
+func (u *URLFake) Update(ctx context.Context, url entity.URL) error {
+	isExist, err := u.IsAliasExist(ctx, url.Domain, url.Alias)
+	if err != nil {
+		return err
+	}
+	if !isExist {
+		return errors.New("alias not found")
+	}
+	u.urls[NewURLKey(url)] = url
+	return nil
+}
+
+// IncrementClickCount increases the click count of an URL unless the URL has
+// used up its maximum clicks.
+func (u *URLFake) IncrementClickCount(ctx context.Context, domain string, alias string) (bool, error) {
+	url, err := u.GetByAlias(ctx, domain, alias)
+	if err != nil {
+		return false, err
+	}
+	if url.MaxClicks != nil && url.ClickCount >= *url.MaxClicks {
+		return false, nil
+	}
+	url.ClickCount++
+	u.urls[NewURLKey(url)] = url
+	return true, nil
+}
+
+// Delete removes an URL from url table given alias.
+func (u *URLFake) Delete(ctx context.Context, domain string, alias string) error {
+	isExist, err := u.IsAliasExist(ctx, domain, alias)
+	if err != nil {
+		return err
+	}
+	if !isExist {
+		return errors.New("alias not found")
+	}
+	delete(u.urls, URLKey{Domain: domain, Alias: alias})
+	return nil
+}
+
+// NewURLFake creates in memory URL repository with urls keyed by alias. Each
+// URL is stored under its own domain.
 func NewURLFake(urls map[string]entity.URL) URLFake {
+	urlsByKey := make(map[URLKey]entity.URL)
+	for alias, url := range urls {
+		urlsByKey[URLKey{Domain: url.Domain, Alias: alias}] = url
+	}
 	return URLFake{
-		urls: urls,
+		urls: urlsByKey,
 	}
 }
@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/short-d/short/app/entity"
+)
+
+// URLBatch persists URLs together with their owner as a single unit, such as
+// a database transaction. Either all the URLs are persisted or none of them.
+type URLBatch interface {
+	CreateURLs(ctx context.Context, urls []entity.URL, owner entity.User) error
+}
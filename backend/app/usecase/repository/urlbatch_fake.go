@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/short-d/short/app/entity"
+)
+
+var _ URLBatch = (*URLBatchFake)(nil)
+
+// URLBatchFake represents in memory implementation of URLBatch repository
+// which stores URLs in the given URL and User-URL relationship fakes.
+type URLBatchFake struct {
+	urlRepo             *URLFake
+	userURLRelationRepo *UserURLRelationFake
+}
+
+// CreateURLs inserts URLs and their relationships with the owner, leaving the
+// fakes unchanged when any of the aliases is taken.
+func (u URLBatchFake) CreateURLs(ctx context.Context, urls []entity.URL, owner entity.User) error {
+	keys := make(map[URLKey]bool)
+	for _, url := range urls {
+		isExist, err := u.urlRepo.IsAliasExist(ctx, url.Domain, url.Alias)
+		if err != nil {
+			return err
+		}
+		if isExist || keys[NewURLKey(url)] {
+			return fmt.Errorf("alias exists (alias=%s)", url.Alias)
+		}
+		keys[NewURLKey(url)] = true
+	}
+
+	for _, url := range urls {
+		err := u.urlRepo.Create(ctx, url)
+		if err != nil {
+			return err
+		}
+
+		err = u.userURLRelationRepo.CreateRelation(ctx, owner, url)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewURLBatchFake creates URLBatchFake
+func NewURLBatchFake(urlRepo *URLFake, userURLRelationRepo *UserURLRelationFake) URLBatchFake {
+	return URLBatchFake{
+		urlRepo:             urlRepo,
+		userURLRelationRepo: userURLRelationRepo,
+	}
+}
@@ -1,13 +1,17 @@
 package repository
 
-import "github.com/short-d/short/app/entity"
+import (
+	"context"
+
+	"github.com/short-d/short/app/entity"
+)
 
 // User accesses users' information from storage, such as database.
 type User interface {
-	IsIDExist(id string) (bool, error)
-	IsEmailExist(email string) (bool, error)
-	GetUserByID(id string) (entity.User, error)
-	GetUserByEmail(email string) (entity.User, error)
-	CreateUser(user entity.User) error
-	UpdateUserID(email string, userID string) error
+	IsIDExist(ctx context.Context, id string) (bool, error)
+	IsEmailExist(ctx context.Context, email string) (bool, error)
+	GetUserByID(ctx context.Context, id string) (entity.User, error)
+	GetUserByEmail(ctx context.Context, email string) (entity.User, error)
+	CreateUser(ctx context.Context, user entity.User) error
+	UpdateUserID(ctx context.Context, email string, userID string) error
 }
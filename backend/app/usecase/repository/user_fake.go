@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"errors"
 
 	"github.com/short-d/short/app/entity"
@@ -14,7 +15,7 @@ type UserFake struct {
 }
 
 // IsIDExist checks whether a given user id exists in the repository.
-func (u UserFake) IsIDExist(id string) (bool, error) {
+func (u UserFake) IsIDExist(ctx context.Context, id string) (bool, error) {
 	for _, user := range u.users {
 		if user.ID == id {
 			return true, nil
@@ -24,7 +25,7 @@ func (u UserFake) IsIDExist(id string) (bool, error) {
 }
 
 // IsEmailExist checks whether an user with given email exists in the repository.
-func (u UserFake) IsEmailExist(email string) (bool, error) {
+func (u UserFake) IsEmailExist(ctx context.Context, email string) (bool, error) {
 	for _, user := range u.users {
 		if user.Email == email {
 			return true, nil
@@ -44,7 +45,7 @@ func (u UserFake) IsUserIDExist(userID string) bool {
 }
 
 // GetUserByID finds an user with a given user ID.
-func (u UserFake) GetUserByID(id string) (entity.User, error) {
+func (u UserFake) GetUserByID(ctx context.Context, id string) (entity.User, error) {
 	for _, user := range u.users {
 		if user.ID == id {
 			return user, nil
@@ -54,7 +55,7 @@ func (u UserFake) GetUserByID(id string) (entity.User, error) {
 }
 
 // GetUserByEmail finds an user with a given email.
-func (u UserFake) GetUserByEmail(email string) (entity.User, error) {
+func (u UserFake) GetUserByEmail(ctx context.Context, email string) (entity.User, error) {
 	for _, user := range u.users {
 		if user.Email == email {
 			return user, nil
@@ -64,7 +65,7 @@ func (u UserFake) GetUserByEmail(email string) (entity.User, error) {
 }
 
 // CreateUser creates and persists user in the repository for future access.
-func (u *UserFake) CreateUser(user entity.User) error {
+func (u *UserFake) CreateUser(ctx context.Context, user entity.User) error {
 	for _, user := range u.users {
 		if user.Email == user.Email {
 			return errors.New("user exists")
@@ -75,7 +76,7 @@ func (u *UserFake) CreateUser(user entity.User) error {
 }
 
 // UpdateUserID updates the ID of an user in the repository.
-func (u *UserFake) UpdateUserID(email string, userID string) error {
+func (u *UserFake) UpdateUserID(ctx context.Context, email string, userID string) error {
 	for idx, user := range u.users {
 		if user.Email == email {
 			u.users[idx].ID = userID
@@ -1,9 +1,47 @@
 package repository
 
-import "github.com/short-d/short/app/entity"
+import (
+	"context"
+	"time"
+
+	"github.com/short-d/short/app/entity"
+)
+
+// URLSortField represents the attribute used to order URLs.
+type URLSortField string
+
+// The constants enumerate all supported URL sort fields.
+const (
+	URLSortByCreatedAt URLSortField = "createdAt"
+	URLSortByExpireAt  URLSortField = "expireAt"
+	URLSortByAlias     URLSortField = "alias"
+)
+
+// URLCursor represents the position of a URL in a sorted list of URLs.
+// SortValue holds the value of the sort field when it is a timestamp, and is
+// nil when the value is absent or the URLs are sorted by alias.
+type URLCursor struct {
+	Domain    string
+	Alias     string
+	SortValue *time.Time
+}
+
+// URLQuery represents the criteria used to list the URLs created by a user.
+// URLs without a value for the sort field are always listed last, and ties
+// are broken by alias and then domain in the same direction.
+type URLQuery struct {
+	SortBy     URLSortField
+	Descending bool
+	Search     string
+	IsPublic   *bool
+	After      *URLCursor
+}
 
 // UserURLRelation accesses User-URL relationship from storage, such as database.
 type UserURLRelation interface {
-	CreateRelation(user entity.User, url entity.URL) error
-	FindAliasesByUser(user entity.User) ([]string, error)
+	CreateRelation(ctx context.Context, user entity.User, url entity.URL) error
+	FindKeysByUser(ctx context.Context, user entity.User, isPublic *bool) ([]URLKey, error)
+	FindURLsByUser(ctx context.Context, user entity.User, query URLQuery, limit int) ([]entity.URL, error)
+	IsAliasOwner(ctx context.Context, user entity.User, domain string, alias string) (bool, error)
+	FindOwnerEmail(ctx context.Context, domain string, alias string) (string, error)
 }
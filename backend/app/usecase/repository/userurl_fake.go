@@ -1,7 +1,11 @@
 package repository
 
 import (
+	"context"
 	"errors"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/short-d/short/app/entity"
 )
@@ -10,12 +14,13 @@ var _ UserURLRelation = (*UserURLRelationFake)(nil)
 
 // UserURLRelationFake represents in memory implementation of User-URL relationship accessor.
 type UserURLRelationFake struct {
-	users []entity.User
-	urls  []entity.URL
+	users         []entity.User
+	urls          []entity.URL
+	publicURLRepo PublicURL
 }
 
 // CreateRelation creates many to many relationship between User and URL.
-func (u *UserURLRelationFake) CreateRelation(user entity.User, url entity.URL) error {
+func (u *UserURLRelationFake) CreateRelation(ctx context.Context, user entity.User, url entity.URL) error {
 	if u.IsRelationExist(user, url) {
 		return errors.New("relationship exists")
 	}
@@ -24,16 +29,151 @@ func (u *UserURLRelationFake) CreateRelation(user entity.User, url entity.URL) e
 	return nil
 }
 
-// FindAliasesByUser fetches the aliases of all the URLs created by the given user.
-func (u UserURLRelationFake) FindAliasesByUser(user entity.User) ([]string, error) {
-	var aliases []string
+// FindKeysByUser fetches the keys of all the URLs created by the given user.
+// Only the URLs with matching visibility are included when isPublic is
+// provided.
+func (u UserURLRelationFake) FindKeysByUser(ctx context.Context, user entity.User, isPublic *bool) ([]URLKey, error) {
+	var keys []URLKey
 	for idx, currUser := range u.users {
 		if currUser.ID != user.ID {
 			continue
 		}
-		aliases = append(aliases, u.urls[idx].Alias)
+
+		url := u.urls[idx]
+		if isPublic != nil {
+			isURLPublic, err := u.isPublic(ctx, url)
+			if err != nil {
+				return nil, err
+			}
+			if isURLPublic != *isPublic {
+				continue
+			}
+		}
+		keys = append(keys, NewURLKey(url))
+	}
+	return keys, nil
+}
+
+// FindURLsByUser fetches at most limit URLs created by the given user which
+// match the query.
+func (u UserURLRelationFake) FindURLsByUser(ctx context.Context, user entity.User, query URLQuery, limit int) ([]entity.URL, error) {
+	urls := []entity.URL{}
+	for idx, currUser := range u.users {
+		if currUser.Email != user.Email {
+			continue
+		}
+
+		url := u.urls[idx]
+		isMatch, err := u.isMatch(ctx, url, query)
+		if err != nil {
+			return nil, err
+		}
+		if isMatch {
+			urls = append(urls, url)
+		}
+	}
+
+	sort.SliceStable(urls, func(i, j int) bool {
+		return isURLBefore(urls[i], urls[j], query)
+	})
+
+	if len(urls) > limit {
+		urls = urls[:limit]
+	}
+	return urls, nil
+}
+
+func (u UserURLRelationFake) isMatch(ctx context.Context, url entity.URL, query URLQuery) (bool, error) {
+	search := strings.ToLower(query.Search)
+	if !strings.Contains(strings.ToLower(url.Alias), search) &&
+		!strings.Contains(strings.ToLower(url.OriginalURL), search) {
+		return false, nil
+	}
+
+	if query.IsPublic != nil {
+		isPublic, err := u.isPublic(ctx, url)
+		if err != nil {
+			return false, err
+		}
+		if isPublic != *query.IsPublic {
+			return false, nil
+		}
+	}
+
+	if query.After == nil {
+		return true, nil
+	}
+	cursorURL := entity.URL{
+		Domain: query.After.Domain,
+		Alias:  query.After.Alias,
+	}
+	switch query.SortBy {
+	case URLSortByCreatedAt:
+		cursorURL.CreatedAt = query.After.SortValue
+	case URLSortByExpireAt:
+		cursorURL.ExpireAt = query.After.SortValue
+	}
+	return isURLBefore(cursorURL, url, query), nil
+}
+
+func isURLBefore(url1 entity.URL, url2 entity.URL, query URLQuery) bool {
+	var value1, value2 *time.Time
+	switch query.SortBy {
+	case URLSortByCreatedAt:
+		value1, value2 = url1.CreatedAt, url2.CreatedAt
+	case URLSortByExpireAt:
+		value1, value2 = url1.ExpireAt, url2.ExpireAt
+	}
+
+	switch {
+	case value1 == nil && value2 != nil:
+		return false
+	case value1 != nil && value2 == nil:
+		return true
+	case value1 != nil && !value1.Equal(*value2):
+		return value1.Before(*value2) != query.Descending
+	}
+	if url1.Alias != url2.Alias {
+		return (url1.Alias < url2.Alias) != query.Descending
+	}
+	if url1.Domain == url2.Domain {
+		return false
+	}
+	return (url1.Domain < url2.Domain) != query.Descending
+}
+
+func (u UserURLRelationFake) isPublic(ctx context.Context, url entity.URL) (bool, error) {
+	if u.publicURLRepo == nil {
+		return false, nil
+	}
+	return u.publicURLRepo.IsPublic(ctx, url.Domain, url.Alias)
+}
+
+// IsAliasOwner checks whether the given user created the URL with the given
+// alias.
+func (u UserURLRelationFake) IsAliasOwner(ctx context.Context, user entity.User, domain string, alias string) (bool, error) {
+	for idx, currUser := range u.users {
+		if currUser.Email != user.Email {
+			continue
+		}
+
+		url := u.urls[idx]
+		if url.Domain == domain && url.Alias == alias {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// FindOwnerEmail fetches the email of the user who created the URL with the
+// given alias.
+func (u UserURLRelationFake) FindOwnerEmail(ctx context.Context, domain string, alias string) (string, error) {
+	for idx, url := range u.urls {
+		if url.Domain == domain && url.Alias == alias {
+			return u.users[idx].Email, nil
+		}
 	}
-	return aliases, nil
+	return "", errors.New("owner not found")
 }
 
 // IsRelationExist checks whether the an URL is own by a given user.
@@ -43,17 +183,23 @@ func (u UserURLRelationFake) IsRelationExist(user entity.User, url entity.URL) b
 			continue
 		}
 
-		if u.urls[idx].Alias == url.Alias {
+		if NewURLKey(u.urls[idx]) == NewURLKey(url) {
 			return true
 		}
 	}
 	return false
 }
 
-// NewUserURLRepoFake creates UserURLFake
-func NewUserURLRepoFake(users []entity.User, urls []entity.URL) UserURLRelationFake {
+// NewUserURLRepoFake creates UserURLFake. URLs are treated as private when
+// publicURLRepo is nil.
+func NewUserURLRepoFake(
+	users []entity.User,
+	urls []entity.URL,
+	publicURLRepo PublicURL,
+) UserURLRelationFake {
 	return UserURLRelationFake{
-		users: users,
-		urls:  urls,
+		users:         users,
+		urls:          urls,
+		publicURLRepo: publicURLRepo,
 	}
 }
@@ -0,0 +1,11 @@
+package service
+
+import "context"
+
+// DomainVerifier fetches the challenges published by the owners of custom
+// domains to prove the ownership of them.
+type DomainVerifier interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+	// FetchFile returns an empty string when the file doesn't exist.
+	FetchFile(ctx context.Context, link string) (string, error)
+}
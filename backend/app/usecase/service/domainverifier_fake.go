@@ -0,0 +1,32 @@
+package service
+
+import "context"
+
+var _ DomainVerifier = (*DomainVerifierFake)(nil)
+
+// DomainVerifierFake represents in memory domain verifier with predefined DNS
+// records and files, standing in for the network in tests and local
+// development.
+type DomainVerifierFake struct {
+	txtRecords map[string][]string
+	files      map[string]string
+}
+
+// LookupTXT returns the predefined TXT records of the given name.
+func (d DomainVerifierFake) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	return d.txtRecords[name], nil
+}
+
+// FetchFile returns the predefined content of the file at the given link.
+func (d DomainVerifierFake) FetchFile(ctx context.Context, link string) (string, error) {
+	return d.files[link], nil
+}
+
+// NewDomainVerifierFake creates DomainVerifierFake which serves txtRecords by
+// name and files by link.
+func NewDomainVerifierFake(txtRecords map[string][]string, files map[string]string) DomainVerifierFake {
+	return DomainVerifierFake{
+		txtRecords: txtRecords,
+		files:      files,
+	}
+}
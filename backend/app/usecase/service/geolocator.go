@@ -0,0 +1,7 @@
+package service
+
+// GeoLocator finds the ISO 3166-1 alpha-2 code of the country an IP address is
+// located in.
+type GeoLocator interface {
+	GetCountry(ipAddress string) (string, error)
+}
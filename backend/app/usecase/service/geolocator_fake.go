@@ -0,0 +1,26 @@
+package service
+
+import "errors"
+
+var _ GeoLocator = (*GeoLocatorFake)(nil)
+
+// GeoLocatorFake represents in memory geo locator with predefined countries.
+type GeoLocatorFake struct {
+	countries map[string]string
+}
+
+// GetCountry finds the predefined ISO 3166-1 alpha-2 country code of the IP
+// address.
+func (g GeoLocatorFake) GetCountry(ipAddress string) (string, error) {
+	country, ok := g.countries[ipAddress]
+	if !ok {
+		return "", errors.New("country not found")
+	}
+	return country, nil
+}
+
+// NewGeoLocatorFake creates GeoLocatorFake which locates each key of
+// countries in its value.
+func NewGeoLocatorFake(countries map[string]string) GeoLocatorFake {
+	return GeoLocatorFake{countries: countries}
+}
@@ -1,9 +1,11 @@
 package service
 
+import "context"
+
 // Key represents unique identifier
 type Key string
 
 // KeyFetcher fetches keys in batch
 type KeyFetcher interface {
-	FetchKeys(maxCount int) ([]Key, error)
+	FetchKeys(ctx context.Context, maxCount int) ([]Key, error)
 }
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"math"
 )
@@ -13,7 +14,7 @@ type KeyFetcherFake struct {
 }
 
 // FetchKeys returns keys from the buffer
-func (k *KeyFetcherFake) FetchKeys(maxCount int) ([]Key, error) {
+func (k *KeyFetcherFake) FetchKeys(ctx context.Context, maxCount int) ([]Key, error) {
 	if len(k.availableKeys) < 1 {
 		return nil, errors.New("no available key")
 	}
@@ -0,0 +1,14 @@
+package service
+
+// Counter counts how many times an event happens. Label values are given in
+// the order the labels of the counter are declared.
+type Counter interface {
+	Inc(labelValues ...string)
+}
+
+// Histogram records the distribution of observed values, such as latencies.
+// Label values are given in the order the labels of the histogram are
+// declared.
+type Histogram interface {
+	Observe(value float64, labelValues ...string)
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"strings"
+	"sync"
+)
+
+var _ Counter = (*CounterFake)(nil)
+
+// CounterFake represents an in memory counter which remembers the count of
+// each combination of label values.
+type CounterFake struct {
+	mutex  *sync.Mutex
+	counts map[string]int
+}
+
+// Inc increases the count of the given label values by one.
+func (c CounterFake) Inc(labelValues ...string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.counts[strings.Join(labelValues, ",")]++
+}
+
+// Count retrieves how many times the given label values are counted.
+func (c CounterFake) Count(labelValues ...string) int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.counts[strings.Join(labelValues, ",")]
+}
+
+// NewCounterFake creates CounterFake
+func NewCounterFake() CounterFake {
+	return CounterFake{
+		mutex:  &sync.Mutex{},
+		counts: make(map[string]int),
+	}
+}
@@ -0,0 +1,9 @@
+package service
+
+import "context"
+
+// RedirectTracer follows the HTTP redirects of a link, giving up once ctx is
+// done.
+type RedirectTracer interface {
+	TraceRedirects(ctx context.Context, link string, maxHops int) ([]string, error)
+}
@@ -0,0 +1,36 @@
+package service
+
+import "context"
+
+var _ RedirectTracer = (*RedirectTracerFake)(nil)
+
+// RedirectTracerFake represents in memory redirect tracer with predefined
+// redirects.
+type RedirectTracerFake struct {
+	redirects map[string]string
+}
+
+// TraceRedirects follows the predefined redirects starting from the given
+// link, returning at most maxHops redirected links. It stops once ctx is
+// done.
+func (r RedirectTracerFake) TraceRedirects(ctx context.Context, link string, maxHops int) ([]string, error) {
+	var hops []string
+	for len(hops) < maxHops {
+		if ctx.Err() != nil {
+			return hops, ctx.Err()
+		}
+		next, ok := r.redirects[link]
+		if !ok {
+			break
+		}
+		hops = append(hops, next)
+		link = next
+	}
+	return hops, nil
+}
+
+// NewRedirectTracerFake creates RedirectTracerFake which redirects each key
+// of redirects to its value.
+func NewRedirectTracerFake(redirects map[string]string) RedirectTracerFake {
+	return RedirectTracerFake{redirects: redirects}
+}
@@ -1,6 +1,7 @@
 package sso
 
 import (
+	"context"
 	"errors"
 
 	"github.com/short-d/short/app/entity"
@@ -20,7 +21,7 @@ type SingleSignOn struct {
 
 // SignIn generates access token for a user using authorization code obtained
 // from external identity provider.
-func (o SingleSignOn) SignIn(authorizationCode string) (string, error) {
+func (o SingleSignOn) SignIn(ctx context.Context, authorizationCode string) (string, error) {
 	if len(authorizationCode) < 1 {
 		return "", errors.New("authorizationCode can't be empty")
 	}
@@ -36,7 +37,7 @@ func (o SingleSignOn) SignIn(authorizationCode string) (string, error) {
 	}
 
 	email := ssoUser.Email
-	isExist, err := o.accountProvider.IsAccountExist(email)
+	isExist, err := o.accountProvider.IsAccountExist(ctx, email)
 	if err != nil {
 		return "", err
 	}
@@ -53,7 +54,7 @@ func (o SingleSignOn) SignIn(authorizationCode string) (string, error) {
 		return authToken, nil
 	}
 
-	err = o.accountProvider.CreateAccount(email, ssoUser.Name)
+	err = o.accountProvider.CreateAccount(ctx, email, ssoUser.Name)
 	if err != nil {
 		return "", nil
 	}
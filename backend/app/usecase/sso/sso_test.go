@@ -3,6 +3,7 @@
 package sso
 
 import (
+	"context"
 	"encoding/json"
 	"testing"
 	"time"
@@ -69,7 +70,7 @@ func TestSingleSignOn_SignIn(t *testing.T) {
 			authenticator := auth.NewAuthenticatorFake(now, time.Minute)
 
 			singleSignOn := NewSingleSignOn(identityProvider, profileService, accountProvider, authenticator)
-			gotAuthToken, err := singleSignOn.SignIn(testCase.authorizationCode)
+			gotAuthToken, err := singleSignOn.SignIn(context.Background(), testCase.authorizationCode)
 			if testCase.hasErr {
 				mdtest.NotEqual(t, nil, err)
 				return
@@ -1,12 +1,36 @@
 package url
 
 import (
+	"context"
+	"fmt"
+	"net/http"
+	"runtime"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/short-d/app/fw"
 	"github.com/short-d/short/app/entity"
 	"github.com/short-d/short/app/usecase/keygen"
+	"github.com/short-d/short/app/usecase/linksafety"
+	"github.com/short-d/short/app/usecase/password"
 	"github.com/short-d/short/app/usecase/repository"
 	"github.com/short-d/short/app/usecase/validator"
 )
 
+const (
+	maxBulkSize       = 1000
+	maxPasswordLength = 128
+	maxTitleLength    = 200
+)
+
+var redirectStatuses = map[int]bool{
+	http.StatusMovedPermanently:  true,
+	http.StatusFound:             true,
+	http.StatusSeeOther:          true,
+	http.StatusTemporaryRedirect: true,
+	http.StatusPermanentRedirect: true,
+}
+
 var _ Creator = (*CreatorPersist)(nil)
 
 // ErrAliasExist represents alias unavailable error
@@ -23,6 +47,14 @@ func (e ErrInvalidLongLink) Error() string {
 	return string(e)
 }
 
+// ErrDomainNotVerified represents the error of creating a URL under a domain
+// which the user hasn't registered or proven the ownership of
+type ErrDomainNotVerified string
+
+func (e ErrDomainNotVerified) Error() string {
+	return fmt.Sprintf("domain not verified (hostname=%s)", string(e))
+}
+
 // ErrInvalidCustomAlias represents incorrect custom alias format error
 type ErrInvalidCustomAlias string
 
@@ -30,9 +62,72 @@ func (e ErrInvalidCustomAlias) Error() string {
 	return string(e)
 }
 
+// ErrInvalidPassword represents the error of protecting a URL with an empty or
+// overly long password
+type ErrInvalidPassword struct{}
+
+func (e ErrInvalidPassword) Error() string {
+	return fmt.Sprintf("password must have 1 to %d characters", maxPasswordLength)
+}
+
+// ErrInvalidMaxClicks represents the error of limiting a URL to less than one
+// click
+type ErrInvalidMaxClicks int
+
+func (e ErrInvalidMaxClicks) Error() string {
+	return fmt.Sprintf("max clicks must be positive (maxClicks=%d)", int(e))
+}
+
+// ErrInvalidActivateAt represents the error of activating a URL at or after
+// its expiration time
+type ErrInvalidActivateAt struct{}
+
+func (e ErrInvalidActivateAt) Error() string {
+	return "url must be activated before it expires"
+}
+
+// ErrInvalidRedirectStatus represents the error of redirecting visitors of a
+// URL with a status code other than 301, 302, 303, 307 and 308
+type ErrInvalidRedirectStatus int
+
+func (e ErrInvalidRedirectStatus) Error() string {
+	return fmt.Sprintf("unsupported redirect status (redirectStatus=%d)", int(e))
+}
+
+// ErrInvalidTitle represents the error of naming a URL with an empty or overly
+// long title
+type ErrInvalidTitle struct{}
+
+func (e ErrInvalidTitle) Error() string {
+	return fmt.Sprintf("title must have 1 to %d characters", maxTitleLength)
+}
+
+// ErrTooManyURLs represents the error of creating too many URLs at once
+type ErrTooManyURLs string
+
+func (e ErrTooManyURLs) Error() string {
+	return string(e)
+}
+
+// BulkURL represents a URL to be created in bulk with an optional custom alias
+// and password
+type BulkURL struct {
+	URL         entity.URL
+	CustomAlias *string
+	Password    *string
+}
+
+// BulkResult represents the outcome of creating a URL in bulk. Err is nil when
+// the URL is created successfully.
+type BulkResult struct {
+	URL entity.URL
+	Err error
+}
+
 // Creator represents a URL alias creator
 type Creator interface {
-	CreateURL(url entity.URL, alias *string, user entity.User, isPublic bool) (entity.URL, error)
+	CreateURL(ctx context.Context, url entity.URL, alias *string, password *string, user entity.User, isPublic bool) (entity.URL, error)
+	CreateURLs(ctx context.Context, urls []BulkURL, user entity.User) ([]BulkResult, error)
 }
 
 // CreatorPersist represents a URL alias creator which persist the generated
@@ -40,42 +135,95 @@ type Creator interface {
 type CreatorPersist struct {
 	urlRepo             repository.URL
 	userURLRelationRepo repository.UserURLRelation
+	publicURLRepo       repository.PublicURL
+	urlBatchRepo        repository.URLBatch
+	domainRepo          repository.Domain
 	keyGen              keygen.KeyGenerator
 	longLinkValidator   validator.LongLink
 	aliasValidator      validator.CustomAlias
+	linkChecker         linksafety.Checker
+	passwordHasher      password.Hasher
+	timer               fw.Timer
 }
 
-// CreateURL persists a new url with a given or auto generated alias in the repository.
-// TODO(issue#235): add functionality for public URLs
-func (c CreatorPersist) CreateURL(url entity.URL, customAlias *string, user entity.User, isPublic bool) (entity.URL, error) {
+// CreateURL persists a new url with a given or auto generated alias in the
+// repository. Visitors must enter the password before being redirected when
+// it is provided. URLs can only be created under the custom domains verified
+// by the user.
+func (c CreatorPersist) CreateURL(
+	ctx context.Context,
+	url entity.URL,
+	customAlias *string,
+	password *string,
+	user entity.User,
+	isPublic bool,
+) (entity.URL, error) {
 	longLink := url.OriginalURL
 	if !c.longLinkValidator.IsValid(&longLink) {
 		return entity.URL{}, ErrInvalidLongLink(longLink)
 	}
 
+	err := c.linkChecker.Check(ctx, longLink)
+	if err != nil {
+		return entity.URL{}, err
+	}
+
+	if !isMaxClicksValid(url.MaxClicks) {
+		return entity.URL{}, ErrInvalidMaxClicks(*url.MaxClicks)
+	}
+	url.ClickCount = 0
+
+	if !isActivateAtValid(url) {
+		return entity.URL{}, ErrInvalidActivateAt{}
+	}
+
+	if !IsRedirectStatusValid(url.RedirectStatus) {
+		return entity.URL{}, ErrInvalidRedirectStatus(*url.RedirectStatus)
+	}
+
+	if !isTitleValid(url.Title) {
+		return entity.URL{}, ErrInvalidTitle{}
+	}
+
+	url.PasswordHash, err = c.hashPassword(password)
+	if err != nil {
+		return entity.URL{}, err
+	}
+
+	err = c.checkDomain(ctx, url.Domain, user)
+	if err != nil {
+		return entity.URL{}, err
+	}
+
 	if customAlias == nil {
-		return c.createURLWithAutoAlias(url, user)
+		return c.createURLWithAutoAlias(ctx, url, user, isPublic)
 	}
 
 	if !c.aliasValidator.IsValid(customAlias) {
 		return entity.URL{}, ErrInvalidCustomAlias(*customAlias)
 	}
-	return c.createURLWithCustomAlias(url, *customAlias, user)
+	return c.createURLWithCustomAlias(ctx, url, *customAlias, user, isPublic)
 }
 
-func (c CreatorPersist) createURLWithAutoAlias(url entity.URL, user entity.User) (entity.URL, error) {
-	key, err := c.keyGen.NewKey()
+func (c CreatorPersist) createURLWithAutoAlias(ctx context.Context, url entity.URL, user entity.User, isPublic bool) (entity.URL, error) {
+	key, err := c.keyGen.NewKey(ctx)
 	if err != nil {
 		return entity.URL{}, err
 	}
 	randomAlias := string(key)
-	return c.createURLWithCustomAlias(url, randomAlias, user)
+	return c.createURLWithCustomAlias(ctx, url, randomAlias, user, isPublic)
 }
 
-func (c CreatorPersist) createURLWithCustomAlias(url entity.URL, alias string, user entity.User) (entity.URL, error) {
+func (c CreatorPersist) createURLWithCustomAlias(
+	ctx context.Context,
+	url entity.URL,
+	alias string,
+	user entity.User,
+	isPublic bool,
+) (entity.URL, error) {
 	url.Alias = alias
 
-	isExist, err := c.urlRepo.IsAliasExist(alias)
+	isExist, err := c.urlRepo.IsAliasExist(ctx, url.Domain, alias)
 	if err != nil {
 		return entity.URL{}, err
 	}
@@ -84,28 +232,331 @@ func (c CreatorPersist) createURLWithCustomAlias(url entity.URL, alias string, u
 		return entity.URL{}, ErrAliasExist("url alias already exist")
 	}
 
-	err = c.urlRepo.Create(url)
+	now := c.timer.Now().UTC()
+	url.CreatedAt = &now
+
+	err = c.urlRepo.Create(ctx, url)
+	if err != nil {
+		return entity.URL{}, err
+	}
+
+	err = c.userURLRelationRepo.CreateRelation(ctx, user, url)
 	if err != nil {
 		return entity.URL{}, err
 	}
 
-	err = c.userURLRelationRepo.CreateRelation(user, url)
+	if !isPublic {
+		return url, nil
+	}
+	err = c.publicURLRepo.Create(ctx, url.Domain, alias)
 	return url, err
 }
 
+// checkDomain ensures the user has proven the ownership of the custom domain.
+// The default host is open to everyone.
+func (c CreatorPersist) checkDomain(ctx context.Context, hostname string, user entity.User) error {
+	if hostname == "" {
+		return nil
+	}
+
+	domain, err := c.domainRepo.GetByHostname(ctx, hostname)
+	switch err.(type) {
+	case nil:
+	case repository.ErrDomainNotFound:
+		return ErrDomainNotVerified(hostname)
+	default:
+		return err
+	}
+
+	if domain.OwnerEmail != user.Email || !domain.IsVerified() {
+		return ErrDomainNotVerified(hostname)
+	}
+	return nil
+}
+
+// CreateURLs persists many new urls with given or auto generated aliases in
+// the repository at once. The urls failing validation are reported in the
+// results while the others are persisted together.
+func (c CreatorPersist) CreateURLs(ctx context.Context, urls []BulkURL, user entity.User) ([]BulkResult, error) {
+	err := CheckBulkSize(len(urls))
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := c.validateBulkURLs(ctx, urls, user)
+	if err != nil {
+		return nil, err
+	}
+
+	var autoAliasCount int
+	for idx, item := range urls {
+		if results[idx].Err == nil && item.CustomAlias == nil {
+			autoAliasCount++
+		}
+	}
+
+	keys, err := c.keyGen.NewKeys(ctx, autoAliasCount)
+	if err != nil {
+		return nil, err
+	}
+
+	passwordHashes, err := c.hashPasswords(ctx, urls, results)
+	if err != nil {
+		return nil, err
+	}
+
+	now := c.timer.Now().UTC()
+	var validURLs []entity.URL
+	for idx, item := range urls {
+		if results[idx].Err != nil {
+			continue
+		}
+
+		url := item.URL
+		url.ClickCount = 0
+		url.PasswordHash = passwordHashes[idx]
+
+		if item.CustomAlias == nil {
+			url.Alias = string(keys[0])
+			keys = keys[1:]
+		} else {
+			url.Alias = *item.CustomAlias
+		}
+		url.CreatedAt = &now
+
+		results[idx].URL = url
+		validURLs = append(validURLs, url)
+	}
+
+	err = c.urlBatchRepo.CreateURLs(ctx, validURLs, user)
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// CheckBulkSize ensures that no more URLs than allowed are created at once, so
+// that callers can reject oversized batches before doing any work for them.
+func CheckBulkSize(count int) error {
+	if count > maxBulkSize {
+		return ErrTooManyURLs(fmt.Sprintf("can't create more than %d urls at once", maxBulkSize))
+	}
+	return nil
+}
+
+// checkLongLinks validates the long links of urls and checks the valid ones
+// for safety all together, so that their redirects are followed concurrently
+// within a bounded time.
+func (c CreatorPersist) checkLongLinks(ctx context.Context, urls []BulkURL) []error {
+	errs := make([]error, len(urls))
+	var longLinks []string
+	var checkedIndices []int
+	for idx, item := range urls {
+		longLink := item.URL.OriginalURL
+		if !c.longLinkValidator.IsValid(&longLink) {
+			errs[idx] = ErrInvalidLongLink(longLink)
+			continue
+		}
+		longLinks = append(longLinks, longLink)
+		checkedIndices = append(checkedIndices, idx)
+	}
+
+	for idx, err := range c.linkChecker.CheckAll(ctx, longLinks) {
+		errs[checkedIndices[idx]] = err
+	}
+	return errs
+}
+
+func (c CreatorPersist) validateBulkURLs(ctx context.Context, urls []BulkURL, user entity.User) ([]BulkResult, error) {
+	results := make([]BulkResult, len(urls))
+	requestedKeys := make(map[repository.URLKey]bool)
+	longLinkErrs := c.checkLongLinks(ctx, urls)
+	for idx, item := range urls {
+		if longLinkErrs[idx] != nil {
+			results[idx].Err = longLinkErrs[idx]
+			continue
+		}
+
+		if !isMaxClicksValid(item.URL.MaxClicks) {
+			results[idx].Err = ErrInvalidMaxClicks(*item.URL.MaxClicks)
+			continue
+		}
+
+		if !isActivateAtValid(item.URL) {
+			results[idx].Err = ErrInvalidActivateAt{}
+			continue
+		}
+
+		if !IsRedirectStatusValid(item.URL.RedirectStatus) {
+			results[idx].Err = ErrInvalidRedirectStatus(*item.URL.RedirectStatus)
+			continue
+		}
+
+		if !isTitleValid(item.URL.Title) {
+			results[idx].Err = ErrInvalidTitle{}
+			continue
+		}
+
+		if !isPasswordValid(item.Password) {
+			results[idx].Err = ErrInvalidPassword{}
+			continue
+		}
+
+		err := c.checkDomain(ctx, item.URL.Domain, user)
+		switch err.(type) {
+		case nil:
+		case ErrDomainNotVerified:
+			results[idx].Err = err
+			continue
+		default:
+			return nil, err
+		}
+
+		customAlias := item.CustomAlias
+		if customAlias == nil {
+			continue
+		}
+
+		if !c.aliasValidator.IsValid(customAlias) {
+			results[idx].Err = ErrInvalidCustomAlias(*customAlias)
+			continue
+		}
+
+		key := repository.URLKey{Domain: item.URL.Domain, Alias: *customAlias}
+		if requestedKeys[key] {
+			results[idx].Err = ErrAliasExist("url alias already exist")
+			continue
+		}
+		requestedKeys[key] = true
+
+		isExist, err := c.urlRepo.IsAliasExist(ctx, key.Domain, key.Alias)
+		if err != nil {
+			return nil, err
+		}
+		if isExist {
+			results[idx].Err = ErrAliasExist("url alias already exist")
+		}
+	}
+	return results, nil
+}
+
+// hashPasswords hashes the passwords of the valid URLs concurrently, with at
+// most one hash per CPU at a time since hashing is CPU bound. The remaining
+// passwords are no longer hashed once ctx is done. The hash of each password
+// is returned at the same index.
+func (c CreatorPersist) hashPasswords(ctx context.Context, urls []BulkURL, results []BulkResult) ([]*string, error) {
+	hashes := make([]*string, len(urls))
+	errs := make([]error, len(urls))
+
+	var wg sync.WaitGroup
+	tokens := make(chan struct{}, runtime.NumCPU())
+	for idx, item := range urls {
+		if results[idx].Err != nil || item.Password == nil {
+			continue
+		}
+
+		wg.Add(1)
+		go func(idx int, password *string) {
+			defer wg.Done()
+
+			select {
+			case tokens <- struct{}{}:
+			case <-ctx.Done():
+				errs[idx] = ctx.Err()
+				return
+			}
+			defer func() { <-tokens }()
+
+			errs[idx] = ctx.Err()
+			if errs[idx] != nil {
+				return
+			}
+			hashes[idx], errs[idx] = c.hashPassword(password)
+		}(idx, item.Password)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return hashes, nil
+}
+
+func (c CreatorPersist) hashPassword(password *string) (*string, error) {
+	if password == nil {
+		return nil, nil
+	}
+
+	if !isPasswordValid(password) {
+		return nil, ErrInvalidPassword{}
+	}
+
+	hash, err := c.passwordHasher.Hash(*password)
+	if err != nil {
+		return nil, err
+	}
+	return &hash, nil
+}
+
+func isMaxClicksValid(maxClicks *int) bool {
+	return maxClicks == nil || *maxClicks > 0
+}
+
+func isActivateAtValid(url entity.URL) bool {
+	if url.ActivateAt == nil || url.ExpireAt == nil {
+		return true
+	}
+	return url.ActivateAt.Before(*url.ExpireAt)
+}
+
+// IsRedirectStatusValid checks whether visitors can be redirected with the
+// given status code. A missing status code falls back to the system default.
+func IsRedirectStatusValid(redirectStatus *int) bool {
+	return redirectStatus == nil || redirectStatuses[*redirectStatus]
+}
+
+func isTitleValid(title *string) bool {
+	if title == nil {
+		return true
+	}
+	length := utf8.RuneCountInString(*title)
+	return length > 0 && length <= maxTitleLength
+}
+
+func isPasswordValid(password *string) bool {
+	if password == nil {
+		return true
+	}
+	return len(*password) > 0 && len(*password) <= maxPasswordLength
+}
+
 // NewCreatorPersist creates CreatorPersist
 func NewCreatorPersist(
 	urlRepo repository.URL,
 	userURLRelationRepo repository.UserURLRelation,
+	publicURLRepo repository.PublicURL,
+	urlBatchRepo repository.URLBatch,
+	domainRepo repository.Domain,
 	keyGen keygen.KeyGenerator,
 	longLinkValidator validator.LongLink,
 	aliasValidator validator.CustomAlias,
+	linkChecker linksafety.Checker,
+	passwordHasher password.Hasher,
+	timer fw.Timer,
 ) CreatorPersist {
 	return CreatorPersist{
 		urlRepo:             urlRepo,
 		userURLRelationRepo: userURLRelationRepo,
+		publicURLRepo:       publicURLRepo,
+		urlBatchRepo:        urlBatchRepo,
+		domainRepo:          domainRepo,
 		keyGen:              keyGen,
 		longLinkValidator:   longLinkValidator,
 		aliasValidator:      aliasValidator,
+		linkChecker:         linkChecker,
+		passwordHasher:      passwordHasher,
+		timer:               timer,
 	}
 }
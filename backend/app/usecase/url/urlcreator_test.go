@@ -3,6 +3,7 @@
 package url
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -11,6 +12,8 @@ import (
 	"github.com/short-d/app/mdtest"
 	"github.com/short-d/short/app/entity"
 	"github.com/short-d/short/app/usecase/keygen"
+	"github.com/short-d/short/app/usecase/linksafety"
+	"github.com/short-d/short/app/usecase/password"
 	"github.com/short-d/short/app/usecase/repository"
 	"github.com/short-d/short/app/usecase/validator"
 )
@@ -19,19 +22,31 @@ func TestURLCreatorPersist_CreateURL(t *testing.T) {
 	t.Parallel()
 
 	now := time.Now()
+	nowUTC := now.UTC()
 
 	alias := "220uFicCJj"
 	longAlias := "an-alias-cannot-be-used-to-specify-default-arguments"
+	urlPassword := "open sesame"
+	emptyPassword := ""
+	zeroClicks := 0
+	oneClick := 1
+	later := now.Add(time.Hour)
+	okStatus := 200
+	permanentRedirect := 308
+	emptyTitle := ""
+	title := "Search engine"
 
 	testCases := []struct {
 		name          string
 		urls          urlMap
 		alias         *string
+		password      *string
 		availableKeys []service.Key
 		user          entity.User
 		url           entity.URL
 		relationUsers []entity.User
 		relationURLs  []entity.URL
+		domains       []entity.Domain
 		isPublic      bool
 		expHasErr     bool
 		expectedURL   entity.URL
@@ -87,6 +102,28 @@ func TestURLCreatorPersist_CreateURL(t *testing.T) {
 				Alias:       "220uFicCJj",
 				OriginalURL: "https://www.google.com",
 				ExpireAt:    &now,
+				CreatedAt:   &nowUTC,
+			},
+		},
+		{
+			name:  "create public alias successfully",
+			urls:  urlMap{},
+			alias: &alias,
+			user: entity.User{
+				Email: "alpha@example.com",
+			},
+			url: entity.URL{
+				Alias:       "220uFicCJj",
+				OriginalURL: "https://www.google.com",
+				ExpireAt:    &now,
+			},
+			isPublic:  true,
+			expHasErr: false,
+			expectedURL: entity.URL{
+				Alias:       "220uFicCJj",
+				OriginalURL: "https://www.google.com",
+				ExpireAt:    &now,
+				CreatedAt:   &nowUTC,
 			},
 		},
 		{
@@ -111,6 +148,7 @@ func TestURLCreatorPersist_CreateURL(t *testing.T) {
 			expectedURL: entity.URL{
 				Alias:       "test",
 				OriginalURL: "https://www.google.com",
+				CreatedAt:   &nowUTC,
 			},
 		},
 		{
@@ -131,6 +169,241 @@ func TestURLCreatorPersist_CreateURL(t *testing.T) {
 			},
 			expHasErr: true,
 		},
+		{
+			name:          "unsafe long link",
+			urls:          urlMap{},
+			availableKeys: []service.Key{"test"},
+			alias:         nil,
+			user: entity.User{
+				Email: "alpha@example.com",
+			},
+			url: entity.URL{
+				OriginalURL: "https://malware.example.com/download",
+			},
+			expHasErr: true,
+		},
+		{
+			name:          "empty password",
+			urls:          urlMap{},
+			availableKeys: []service.Key{"test"},
+			alias:         nil,
+			password:      &emptyPassword,
+			user: entity.User{
+				Email: "alpha@example.com",
+			},
+			url: entity.URL{
+				OriginalURL: "https://www.google.com",
+			},
+			expHasErr: true,
+		},
+		{
+			name:          "max clicks not positive",
+			urls:          urlMap{},
+			availableKeys: []service.Key{"test"},
+			alias:         nil,
+			user: entity.User{
+				Email: "alpha@example.com",
+			},
+			url: entity.URL{
+				OriginalURL: "https://www.google.com",
+				MaxClicks:   &zeroClicks,
+			},
+			expHasErr: true,
+		},
+		{
+			name:          "activate after expiration time",
+			urls:          urlMap{},
+			availableKeys: []service.Key{"test"},
+			alias:         nil,
+			user: entity.User{
+				Email: "alpha@example.com",
+			},
+			url: entity.URL{
+				OriginalURL: "https://www.google.com",
+				ActivateAt:  &later,
+				ExpireAt:    &now,
+			},
+			expHasErr: true,
+		},
+		{
+			name:          "redirect status not supported",
+			urls:          urlMap{},
+			availableKeys: []service.Key{"test"},
+			alias:         nil,
+			user: entity.User{
+				Email: "alpha@example.com",
+			},
+			url: entity.URL{
+				OriginalURL:    "https://www.google.com",
+				RedirectStatus: &okStatus,
+			},
+			expHasErr: true,
+		},
+		{
+			name:          "empty title",
+			urls:          urlMap{},
+			availableKeys: []service.Key{"test"},
+			alias:         nil,
+			user: entity.User{
+				Email: "alpha@example.com",
+			},
+			url: entity.URL{
+				OriginalURL: "https://www.google.com",
+				Title:       &emptyTitle,
+			},
+			expHasErr: true,
+		},
+		{
+			name:          "create titled alias with redirect status successfully",
+			urls:          urlMap{},
+			availableKeys: []service.Key{"test"},
+			alias:         nil,
+			user: entity.User{
+				Email: "alpha@example.com",
+			},
+			url: entity.URL{
+				OriginalURL:    "https://www.google.com",
+				Title:          &title,
+				RedirectStatus: &permanentRedirect,
+			},
+			expHasErr: false,
+			expectedURL: entity.URL{
+				Alias:          "test",
+				OriginalURL:    "https://www.google.com",
+				Title:          &title,
+				RedirectStatus: &permanentRedirect,
+				CreatedAt:      &nowUTC,
+			},
+		},
+		{
+			name:          "create scheduled alias successfully",
+			urls:          urlMap{},
+			availableKeys: []service.Key{"test"},
+			alias:         nil,
+			user: entity.User{
+				Email: "alpha@example.com",
+			},
+			url: entity.URL{
+				OriginalURL: "https://www.google.com",
+				ActivateAt:  &later,
+			},
+			expHasErr: false,
+			expectedURL: entity.URL{
+				Alias:       "test",
+				OriginalURL: "https://www.google.com",
+				ActivateAt:  &later,
+				CreatedAt:   &nowUTC,
+			},
+		},
+		{
+			name:          "create one time alias successfully",
+			urls:          urlMap{},
+			availableKeys: []service.Key{"test"},
+			alias:         nil,
+			user: entity.User{
+				Email: "alpha@example.com",
+			},
+			url: entity.URL{
+				OriginalURL: "https://www.google.com",
+				MaxClicks:   &oneClick,
+			},
+			expHasErr: false,
+			expectedURL: entity.URL{
+				Alias:       "test",
+				OriginalURL: "https://www.google.com",
+				MaxClicks:   &oneClick,
+				CreatedAt:   &nowUTC,
+			},
+		},
+		{
+			name:          "create password protected alias successfully",
+			urls:          urlMap{},
+			availableKeys: []service.Key{"test"},
+			alias:         nil,
+			password:      &urlPassword,
+			user: entity.User{
+				Email: "alpha@example.com",
+			},
+			url: entity.URL{
+				OriginalURL: "https://www.google.com",
+			},
+			expHasErr: false,
+			expectedURL: entity.URL{
+				Alias:       "test",
+				OriginalURL: "https://www.google.com",
+				CreatedAt:   &nowUTC,
+			},
+		},
+		{
+			name:  "domain not verified",
+			urls:  urlMap{},
+			alias: &alias,
+			domains: []entity.Domain{
+				{
+					Hostname:   "go.example.com",
+					OwnerEmail: "alpha@example.com",
+				},
+			},
+			user: entity.User{
+				Email: "alpha@example.com",
+			},
+			url: entity.URL{
+				Domain:      "go.example.com",
+				OriginalURL: "https://www.google.com",
+			},
+			expHasErr: true,
+		},
+		{
+			name:  "domain owned by another user",
+			urls:  urlMap{},
+			alias: &alias,
+			domains: []entity.Domain{
+				{
+					Hostname:   "go.example.com",
+					OwnerEmail: "beta@example.com",
+					VerifiedAt: &now,
+				},
+			},
+			user: entity.User{
+				Email: "alpha@example.com",
+			},
+			url: entity.URL{
+				Domain:      "go.example.com",
+				OriginalURL: "https://www.google.com",
+			},
+			expHasErr: true,
+		},
+		{
+			name: "alias exists on another domain",
+			urls: urlMap{
+				"220uFicCJj": entity.URL{
+					Alias:       "220uFicCJj",
+					OriginalURL: "https://www.github.com",
+				},
+			},
+			alias: &alias,
+			domains: []entity.Domain{
+				{
+					Hostname:   "go.example.com",
+					OwnerEmail: "alpha@example.com",
+					VerifiedAt: &now,
+				},
+			},
+			user: entity.User{
+				Email: "alpha@example.com",
+			},
+			url: entity.URL{
+				Domain:      "go.example.com",
+				OriginalURL: "https://www.google.com",
+			},
+			expHasErr: false,
+			expectedURL: entity.URL{
+				Domain:      "go.example.com",
+				Alias:       "220uFicCJj",
+				OriginalURL: "https://www.google.com",
+				CreatedAt:   &nowUTC,
+			},
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -139,35 +412,61 @@ func TestURLCreatorPersist_CreateURL(t *testing.T) {
 			t.Parallel()
 
 			urlRepo := repository.NewURLFake(testCase.urls)
+			publicURLRepo := repository.NewPublicURLFake(nil)
 			userURLRepo := repository.NewUserURLRepoFake(
 				testCase.relationUsers,
 				testCase.relationURLs,
+				&publicURLRepo,
 			)
+			urlBatchRepo := repository.NewURLBatchFake(&urlRepo, &userURLRepo)
+			domainRepo := repository.NewDomainFake(testCase.domains)
 			keyFetcher := service.NewKeyFetcherFake(testCase.availableKeys)
-			keyGen, err := keygen.NewKeyGenerator(2, &keyFetcher)
+			keyGen, err := keygen.NewKeyGenerator(2, 0, &keyFetcher, service.NewCounterFake(), mdtest.NewTimerFake(time.Now()))
 			mdtest.Equal(t, nil, err)
 			longLinkValidator := validator.NewLongLink()
 			aliasValidator := validator.NewCustomAlias()
+			linkChecker := linksafety.NewChecker(
+				linksafety.Blocklist{Domains: []string{"malware.example.com"}},
+				nil,
+				service.NewRedirectTracerFake(nil),
+				0,
+				0,
+			)
+			passwordHasher := password.NewHasher()
+			timer := mdtest.NewTimerFake(now)
 
 			creator := NewCreatorPersist(
 				&urlRepo,
 				&userURLRepo,
+				&publicURLRepo,
+				&urlBatchRepo,
+				&domainRepo,
 				keyGen,
 				longLinkValidator,
 				aliasValidator,
+				linkChecker,
+				passwordHasher,
+				timer,
 			)
 
-			_, err = urlRepo.GetByAlias(testCase.url.Alias)
+			_, err = urlRepo.GetByAlias(context.Background(), testCase.url.Domain, testCase.url.Alias)
 			mdtest.NotEqual(t, nil, err)
 
 			isExist := userURLRepo.IsRelationExist(testCase.user, testCase.url)
 			mdtest.Equal(t, false, isExist)
 
-			url, err := creator.CreateURL(testCase.url, testCase.alias, testCase.user, testCase.isPublic)
+			url, err := creator.CreateURL(
+				context.Background(),
+				testCase.url,
+				testCase.alias,
+				testCase.password,
+				testCase.user,
+				testCase.isPublic,
+			)
 			if testCase.expHasErr {
 				mdtest.NotEqual(t, nil, err)
 
-				_, err = urlRepo.GetByAlias(testCase.expectedURL.Alias)
+				_, err = urlRepo.GetByAlias(context.Background(), testCase.expectedURL.Domain, testCase.expectedURL.Alias)
 				mdtest.NotEqual(t, nil, err)
 
 				isExist := userURLRepo.IsRelationExist(testCase.user, testCase.expectedURL)
@@ -175,14 +474,227 @@ func TestURLCreatorPersist_CreateURL(t *testing.T) {
 				return
 			}
 			mdtest.Equal(t, nil, err)
-			mdtest.Equal(t, testCase.expectedURL, url)
 
-			savedURL, err := urlRepo.GetByAlias(testCase.expectedURL.Alias)
+			expectedURL := testCase.expectedURL
+			if testCase.password != nil {
+				mdtest.NotEqual(t, nil, url.PasswordHash)
+				mdtest.Equal(t, true, passwordHasher.IsMatch(*testCase.password, *url.PasswordHash))
+				expectedURL.PasswordHash = url.PasswordHash
+			}
+			mdtest.Equal(t, expectedURL, url)
+
+			savedURL, err := urlRepo.GetByAlias(context.Background(), testCase.expectedURL.Domain, testCase.expectedURL.Alias)
 			mdtest.Equal(t, nil, err)
-			mdtest.Equal(t, testCase.expectedURL, savedURL)
+			mdtest.Equal(t, expectedURL, savedURL)
 
 			isExist = userURLRepo.IsRelationExist(testCase.user, testCase.expectedURL)
 			mdtest.Equal(t, true, isExist)
+
+			isPublic, err := publicURLRepo.IsPublic(context.Background(), testCase.expectedURL.Domain, testCase.expectedURL.Alias)
+			mdtest.Equal(t, nil, err)
+			mdtest.Equal(t, testCase.isPublic, isPublic)
+		})
+	}
+}
+
+func TestCreatorPersist_CreateURLs(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	nowUTC := now.UTC()
+	user := entity.User{Email: "alpha@example.com"}
+	customAlias := "google"
+	takenAlias := "taken"
+	invalidAlias := "an-alias-cannot-be-used-to-specify-default-arguments"
+	emptyPassword := ""
+	urlPassword := "open sesame"
+	otherPassword := "abracadabra"
+
+	testCases := []struct {
+		name            string
+		urls            urlMap
+		availableKeys   []service.Key
+		bulkURLs        []BulkURL
+		isCanceled      bool
+		hasErr          bool
+		expectedResults []BulkResult
+	}{
+		{
+			name:     "too many urls",
+			urls:     urlMap{},
+			bulkURLs: make([]BulkURL, maxBulkSize+1),
+			hasErr:   true,
+		},
+		{
+			name:          "not enough keys",
+			urls:          urlMap{},
+			availableKeys: []service.Key{"0K"},
+			bulkURLs: []BulkURL{
+				{URL: entity.URL{OriginalURL: "https://www.google.com"}},
+				{URL: entity.URL{OriginalURL: "https://www.mozilla.org"}},
+			},
+			hasErr: true,
+		},
+		{
+			name: "report invalid urls",
+			urls: urlMap{
+				"taken": entity.URL{Alias: "taken"},
+			},
+			availableKeys: []service.Key{"0K", "0L"},
+			bulkURLs: []BulkURL{
+				{URL: entity.URL{OriginalURL: "https://www.google.com"}, CustomAlias: &customAlias},
+				{URL: entity.URL{OriginalURL: "invalid"}},
+				{URL: entity.URL{OriginalURL: "https://www.google.com"}, CustomAlias: &invalidAlias},
+				{URL: entity.URL{OriginalURL: "https://www.google.com"}, CustomAlias: &takenAlias},
+				{URL: entity.URL{OriginalURL: "https://www.google.com"}, CustomAlias: &customAlias},
+				{URL: entity.URL{OriginalURL: "https://malware.example.com"}},
+				{URL: entity.URL{OriginalURL: "https://www.google.com"}, Password: &emptyPassword},
+				{URL: entity.URL{OriginalURL: "https://www.mozilla.org"}},
+			},
+			hasErr: false,
+			expectedResults: []BulkResult{
+				{
+					URL: entity.URL{
+						Alias:       "google",
+						OriginalURL: "https://www.google.com",
+						CreatedAt:   &nowUTC,
+					},
+				},
+				{Err: ErrInvalidLongLink("invalid")},
+				{Err: ErrInvalidCustomAlias(invalidAlias)},
+				{Err: ErrAliasExist("url alias already exist")},
+				{Err: ErrAliasExist("url alias already exist")},
+				{Err: linksafety.ErrUnsafeLink(linksafety.ReasonBlockedDomain)},
+				{Err: ErrInvalidPassword{}},
+				{
+					URL: entity.URL{
+						Alias:       "0K",
+						OriginalURL: "https://www.mozilla.org",
+						CreatedAt:   &nowUTC,
+					},
+				},
+			},
+		},
+		{
+			name:          "hash passwords",
+			urls:          urlMap{},
+			availableKeys: []service.Key{"0K", "0L", "0M"},
+			bulkURLs: []BulkURL{
+				{URL: entity.URL{OriginalURL: "https://www.google.com"}, Password: &urlPassword},
+				{URL: entity.URL{OriginalURL: "https://www.mozilla.org"}},
+				{URL: entity.URL{OriginalURL: "https://www.github.com"}, Password: &otherPassword},
+			},
+			hasErr: false,
+			expectedResults: []BulkResult{
+				{
+					URL: entity.URL{
+						Alias:       "0K",
+						OriginalURL: "https://www.google.com",
+						CreatedAt:   &nowUTC,
+					},
+				},
+				{
+					URL: entity.URL{
+						Alias:       "0L",
+						OriginalURL: "https://www.mozilla.org",
+						CreatedAt:   &nowUTC,
+					},
+				},
+				{
+					URL: entity.URL{
+						Alias:       "0M",
+						OriginalURL: "https://www.github.com",
+						CreatedAt:   &nowUTC,
+					},
+				},
+			},
+		},
+		{
+			name:          "canceled before hashing passwords",
+			urls:          urlMap{},
+			availableKeys: []service.Key{"0K", "0L"},
+			bulkURLs: []BulkURL{
+				{URL: entity.URL{OriginalURL: "https://www.google.com"}, Password: &urlPassword},
+				{URL: entity.URL{OriginalURL: "https://www.mozilla.org"}, Password: &otherPassword},
+			},
+			isCanceled: true,
+			hasErr:     true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			urlRepo := repository.NewURLFake(testCase.urls)
+			publicURLRepo := repository.NewPublicURLFake(nil)
+			userURLRepo := repository.NewUserURLRepoFake(nil, nil, &publicURLRepo)
+			urlBatchRepo := repository.NewURLBatchFake(&urlRepo, &userURLRepo)
+			domainRepo := repository.NewDomainFake(nil)
+			keyFetcher := service.NewKeyFetcherFake(testCase.availableKeys)
+			keyGen, err := keygen.NewKeyGenerator(2, 0, &keyFetcher, service.NewCounterFake(), mdtest.NewTimerFake(time.Now()))
+			mdtest.Equal(t, nil, err)
+			linkChecker := linksafety.NewChecker(
+				linksafety.Blocklist{Domains: []string{"malware.example.com"}},
+				nil,
+				service.NewRedirectTracerFake(nil),
+				0,
+				0,
+			)
+			timer := mdtest.NewTimerFake(now)
+			passwordHasher := password.NewHasher()
+
+			creator := NewCreatorPersist(
+				&urlRepo,
+				&userURLRepo,
+				&publicURLRepo,
+				&urlBatchRepo,
+				&domainRepo,
+				keyGen,
+				validator.NewLongLink(),
+				validator.NewCustomAlias(),
+				linkChecker,
+				passwordHasher,
+				timer,
+			)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if testCase.isCanceled {
+				cancel()
+			}
+
+			results, err := creator.CreateURLs(ctx, testCase.bulkURLs, user)
+			if testCase.hasErr {
+				mdtest.NotEqual(t, nil, err)
+				return
+			}
+			mdtest.Equal(t, nil, err)
+
+			for idx, result := range results {
+				bulkURL := testCase.bulkURLs[idx]
+				if result.Err != nil || bulkURL.Password == nil {
+					continue
+				}
+				mdtest.NotEqual(t, nil, result.URL.PasswordHash)
+				mdtest.Equal(t, true, passwordHasher.IsMatch(*bulkURL.Password, *result.URL.PasswordHash))
+				testCase.expectedResults[idx].URL.PasswordHash = result.URL.PasswordHash
+			}
+			mdtest.Equal(t, testCase.expectedResults, results)
+
+			for _, result := range results {
+				if result.Err != nil {
+					continue
+				}
+				savedURL, err := urlRepo.GetByAlias(context.Background(), "", result.URL.Alias)
+				mdtest.Equal(t, nil, err)
+				mdtest.Equal(t, result.URL, savedURL)
+
+				isOwner, err := userURLRepo.IsAliasOwner(context.Background(), user, "", result.URL.Alias)
+				mdtest.Equal(t, nil, err)
+				mdtest.Equal(t, true, isOwner)
+			}
 		})
 	}
 }
@@ -0,0 +1,43 @@
+package url
+
+import (
+	"context"
+
+	"github.com/short-d/short/app/entity"
+	"github.com/short-d/short/app/usecase/repository"
+)
+
+var _ Deleter = (*DeleterPersist)(nil)
+
+// Deleter represents a short link remover
+type Deleter interface {
+	DeleteURL(ctx context.Context, domain string, alias string, user entity.User) error
+}
+
+// DeleterPersist represents a short link remover which removes the short link
+// from the repository
+type DeleterPersist struct {
+	urlRepo             repository.URL
+	userURLRelationRepo repository.UserURLRelation
+}
+
+// DeleteURL removes the short link with the given alias under the given domain
+// if it is created by the given user.
+func (d DeleterPersist) DeleteURL(ctx context.Context, domain string, alias string, user entity.User) error {
+	err := checkOwner(ctx, d.urlRepo, d.userURLRelationRepo, domain, alias, user)
+	if err != nil {
+		return err
+	}
+	return d.urlRepo.Delete(ctx, domain, alias)
+}
+
+// NewDeleterPersist creates DeleterPersist
+func NewDeleterPersist(
+	urlRepo repository.URL,
+	userURLRelationRepo repository.UserURLRelation,
+) DeleterPersist {
+	return DeleterPersist{
+		urlRepo:             urlRepo,
+		userURLRelationRepo: userURLRelationRepo,
+	}
+}
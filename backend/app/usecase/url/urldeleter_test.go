@@ -0,0 +1,84 @@
+// +build !integration all
+
+package url
+
+import (
+	"context"
+	"testing"
+
+	"github.com/short-d/app/mdtest"
+	"github.com/short-d/short/app/entity"
+	"github.com/short-d/short/app/usecase/repository"
+)
+
+func TestDeleterPersist_DeleteURL(t *testing.T) {
+	t.Parallel()
+
+	owner := entity.User{Email: "alpha@example.com"}
+	otherUser := entity.User{Email: "beta@example.com"}
+
+	testCases := []struct {
+		name          string
+		urls          urlMap
+		relationUsers []entity.User
+		relationURLs  []entity.URL
+		alias         string
+		user          entity.User
+		expectedErr   error
+	}{
+		{
+			name:        "url not found",
+			urls:        urlMap{},
+			alias:       "220uFicCJj",
+			user:        owner,
+			expectedErr: ErrURLNotFound("220uFicCJj"),
+		},
+		{
+			name: "user is not owner",
+			urls: urlMap{
+				"220uFicCJj": entity.URL{Alias: "220uFicCJj"},
+			},
+			relationUsers: []entity.User{owner},
+			relationURLs:  []entity.URL{{Alias: "220uFicCJj"}},
+			alias:         "220uFicCJj",
+			user:          otherUser,
+			expectedErr:   ErrNotURLOwner("220uFicCJj"),
+		},
+		{
+			name: "delete url successfully",
+			urls: urlMap{
+				"220uFicCJj": entity.URL{Alias: "220uFicCJj"},
+			},
+			relationUsers: []entity.User{owner},
+			relationURLs:  []entity.URL{{Alias: "220uFicCJj"}},
+			alias:         "220uFicCJj",
+			user:          owner,
+			expectedErr:   nil,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			urlRepo := repository.NewURLFake(testCase.urls)
+			userURLRepo := repository.NewUserURLRepoFake(
+				testCase.relationUsers,
+				testCase.relationURLs,
+				nil,
+			)
+			deleter := NewDeleterPersist(&urlRepo, &userURLRepo)
+
+			err := deleter.DeleteURL(context.Background(), "", testCase.alias, testCase.user)
+			mdtest.Equal(t, testCase.expectedErr, err)
+
+			if testCase.expectedErr != nil {
+				return
+			}
+			isExist, err := urlRepo.IsAliasExist(context.Background(), "", testCase.alias)
+			mdtest.Equal(t, nil, err)
+			mdtest.Equal(t, false, isExist)
+		})
+	}
+}
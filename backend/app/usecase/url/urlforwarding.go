@@ -0,0 +1,193 @@
+package url
+
+import (
+	"fmt"
+	netURL "net/url"
+	"strings"
+
+	"github.com/short-d/short/app/entity"
+)
+
+// ErrInvalidPathSuffix represents the error of visiting a short link with a
+// path suffix which it doesn't forward, or which escapes the path of the long
+// link.
+type ErrInvalidPathSuffix string
+
+func (e ErrInvalidPathSuffix) Error() string {
+	return fmt.Sprintf("invalid path suffix (suffix=%s)", string(e))
+}
+
+// Visit represents the parts of a request to a short link which can be
+// carried over to its long link. PathSuffix is the escaped path following the
+// alias, such as "/api/v2", and RawQuery is the escaped query string.
+type Visit struct {
+	PathSuffix string
+	RawQuery   string
+}
+
+type queryParam struct {
+	key  string
+	pair string
+}
+
+// ExpandLongLink builds the destination of a visit to the short link from the
+// given long link. Depending on the options of the short link, the path suffix
+// is appended to the path of the long link, the query parameters of the visit
+// are forwarded and UTM parameters are added. Query parameters of the long
+// link take precedence over forwarded ones, while UTM parameters take
+// precedence over both. Escaped characters are preserved as is.
+func ExpandLongLink(longLink string, url entity.URL, visit Visit) (string, error) {
+	if visit.PathSuffix != "" && !url.ForwardPath {
+		return "", ErrInvalidPathSuffix(visit.PathSuffix)
+	}
+
+	isQueryForwarded := url.ForwardQuery && visit.RawQuery != ""
+	if visit.PathSuffix == "" && !isQueryForwarded && url.UTM == nil {
+		return longLink, nil
+	}
+
+	target, err := netURL.Parse(longLink)
+	if err != nil {
+		return "", err
+	}
+
+	if visit.PathSuffix != "" {
+		err = appendPath(target, visit.PathSuffix)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	params := mergeQuery(nil, target.RawQuery)
+	if isQueryForwarded {
+		params = mergeQuery(params, visit.RawQuery)
+	}
+	if url.UTM != nil {
+		params = setUTM(params, *url.UTM)
+	}
+
+	pairs := make([]string, 0, len(params))
+	for _, param := range params {
+		pairs = append(pairs, param.pair)
+	}
+	target.RawQuery = strings.Join(pairs, "&")
+	return target.String(), nil
+}
+
+// appendPath appends the escaped path suffix to the path of the target while
+// keeping the escaped form of both. Dot segments are rejected so that the
+// suffix can't climb out of the path of the long link.
+func appendPath(target *netURL.URL, suffix string) error {
+	if !strings.HasPrefix(suffix, "/") {
+		return ErrInvalidPathSuffix(suffix)
+	}
+
+	for _, segment := range strings.Split(suffix, "/") {
+		decoded, err := netURL.PathUnescape(segment)
+		if err != nil || decoded == "." || decoded == ".." {
+			return ErrInvalidPathSuffix(suffix)
+		}
+	}
+
+	escapedPath := strings.TrimSuffix(target.EscapedPath(), "/") + suffix
+	path, err := netURL.PathUnescape(escapedPath)
+	if err != nil {
+		return ErrInvalidPathSuffix(suffix)
+	}
+
+	target.Path = path
+	target.RawPath = escapedPath
+	return nil
+}
+
+// mergeQuery appends the parameters of the raw query whose keys are not in
+// params yet. Well formed pairs are kept as is, while the others are escaped
+// again.
+func mergeQuery(params []queryParam, rawQuery string) []queryParam {
+	existingKeys := make(map[string]bool, len(params))
+	for _, param := range params {
+		existingKeys[param.key] = true
+	}
+
+	for _, pair := range strings.Split(rawQuery, "&") {
+		if pair == "" {
+			continue
+		}
+
+		parts := strings.SplitN(pair, "=", 2)
+		key, err := netURL.QueryUnescape(parts[0])
+		if err != nil || key == "" || existingKeys[key] {
+			continue
+		}
+
+		if !isQueryPairEscaped(pair) {
+			value := ""
+			if len(parts) == 2 {
+				value, err = netURL.QueryUnescape(parts[1])
+				if err != nil {
+					continue
+				}
+			}
+			pair = netURL.QueryEscape(key) + "=" + netURL.QueryEscape(value)
+		}
+		params = append(params, queryParam{key: key, pair: pair})
+	}
+	return params
+}
+
+// setUTM replaces the parameters sharing keys with the non-empty UTM
+// parameters.
+func setUTM(params []queryParam, utm entity.UTM) []queryParam {
+	utmParams := []struct {
+		key   string
+		value string
+	}{
+		{key: "utm_source", value: utm.Source},
+		{key: "utm_medium", value: utm.Medium},
+		{key: "utm_campaign", value: utm.Campaign},
+		{key: "utm_term", value: utm.Term},
+		{key: "utm_content", value: utm.Content},
+	}
+
+	for _, utmParam := range utmParams {
+		if utmParam.value == "" {
+			continue
+		}
+
+		kept := params[:0]
+		for _, param := range params {
+			if param.key != utmParam.key {
+				kept = append(kept, param)
+			}
+		}
+		params = append(kept, queryParam{
+			key:  utmParam.key,
+			pair: utmParam.key + "=" + netURL.QueryEscape(utmParam.value),
+		})
+	}
+	return params
+}
+
+// isQueryPairEscaped checks whether the pair only contains characters allowed
+// in a query by RFC 3986, with valid percent encodings.
+func isQueryPairEscaped(pair string) bool {
+	for idx := 0; idx < len(pair); idx++ {
+		char := pair[idx]
+		switch {
+		case 'a' <= char && char <= 'z', 'A' <= char && char <= 'Z', '0' <= char && char <= '9':
+		case strings.IndexByte("-._~!$'()*+,;=:@/?", char) >= 0:
+		case char == '%':
+			if idx+2 >= len(pair) || !isHex(pair[idx+1]) || !isHex(pair[idx+2]) {
+				return false
+			}
+			idx += 2
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func isHex(char byte) bool {
+	return '0' <= char && char <= '9' || 'a' <= char && char <= 'f' || 'A' <= char && char <= 'F'
+}
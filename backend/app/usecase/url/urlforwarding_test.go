@@ -0,0 +1,163 @@
+// +build !integration all
+
+package url
+
+import (
+	"testing"
+
+	"github.com/short-d/app/mdtest"
+	"github.com/short-d/short/app/entity"
+)
+
+func TestExpandLongLink(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name             string
+		longLink         string
+		url              entity.URL
+		visit            Visit
+		expectedErr      error
+		expectedLongLink string
+	}{
+		{
+			name:             "nothing to forward",
+			longLink:         "https://example.com/docs?lang=en",
+			url:              entity.URL{ForwardQuery: true, ForwardPath: true},
+			visit:            Visit{},
+			expectedLongLink: "https://example.com/docs?lang=en",
+		},
+		{
+			name:             "query not forwarded",
+			longLink:         "https://example.com/docs",
+			url:              entity.URL{},
+			visit:            Visit{RawQuery: "ref=twitter"},
+			expectedLongLink: "https://example.com/docs",
+		},
+		{
+			name:             "forward query",
+			longLink:         "https://example.com/docs",
+			url:              entity.URL{ForwardQuery: true},
+			visit:            Visit{RawQuery: "ref=twitter&q=a+b"},
+			expectedLongLink: "https://example.com/docs?ref=twitter&q=a+b",
+		},
+		{
+			name:             "long link query takes precedence",
+			longLink:         "https://example.com/docs?lang=en&tag=1&tag=2",
+			url:              entity.URL{ForwardQuery: true},
+			visit:            Visit{RawQuery: "lang=fr&tag=3&page=2"},
+			expectedLongLink: "https://example.com/docs?lang=en&tag=1&tag=2&page=2",
+		},
+		{
+			name:             "forwarded escapes preserved",
+			longLink:         "https://example.com/search",
+			url:              entity.URL{ForwardQuery: true},
+			visit:            Visit{RawQuery: "q=%E4%BD%A0%E5%A5%BD&next=%2Fhome%3Fa%3D1%26b%3D2"},
+			expectedLongLink: "https://example.com/search?q=%E4%BD%A0%E5%A5%BD&next=%2Fhome%3Fa%3D1%26b%3D2",
+		},
+		{
+			name:             "forwarded unsafe characters escaped",
+			longLink:         "https://example.com/search",
+			url:              entity.URL{ForwardQuery: true},
+			visit:            Visit{RawQuery: "q=<script>\"x\" y&ok=1"},
+			expectedLongLink: "https://example.com/search?q=%3Cscript%3E%22x%22+y&ok=1",
+		},
+		{
+			name:             "malformed escapes dropped",
+			longLink:         "https://example.com/search",
+			url:              entity.URL{ForwardQuery: true},
+			visit:            Visit{RawQuery: "bad%zz=1&q=%gg&ok=1"},
+			expectedLongLink: "https://example.com/search?ok=1",
+		},
+		{
+			name:     "append utm",
+			longLink: "https://example.com/landing#pricing",
+			url: entity.URL{
+				UTM: &entity.UTM{
+					Source:   "newsletter",
+					Medium:   "email",
+					Campaign: "spring sale & more",
+				},
+			},
+			visit:            Visit{},
+			expectedLongLink: "https://example.com/landing?utm_source=newsletter&utm_medium=email&utm_campaign=spring+sale+%26+more#pricing",
+		},
+		{
+			name:     "utm takes precedence",
+			longLink: "https://example.com/landing?utm_source=blog&id=7",
+			url: entity.URL{
+				ForwardQuery: true,
+				UTM:          &entity.UTM{Source: "newsletter"},
+			},
+			visit:            Visit{RawQuery: "utm_source=ads&utm_medium=cpc"},
+			expectedLongLink: "https://example.com/landing?id=7&utm_medium=cpc&utm_source=newsletter",
+		},
+		{
+			name:             "forward path",
+			longLink:         "https://example.com/docs",
+			url:              entity.URL{ForwardPath: true},
+			visit:            Visit{PathSuffix: "/api/v2"},
+			expectedLongLink: "https://example.com/docs/api/v2",
+		},
+		{
+			name:             "forward path after trailing slash",
+			longLink:         "https://example.com/docs/?lang=en#intro",
+			url:              entity.URL{ForwardPath: true},
+			visit:            Visit{PathSuffix: "/api/v2"},
+			expectedLongLink: "https://example.com/docs/api/v2?lang=en#intro",
+		},
+		{
+			name:             "forward escaped path",
+			longLink:         "https://example.com/files%20and%20more",
+			url:              entity.URL{ForwardPath: true},
+			visit:            Visit{PathSuffix: "/a%2Fb/c%20d"},
+			expectedLongLink: "https://example.com/files%20and%20more/a%2Fb/c%20d",
+		},
+		{
+			name:             "forward path and query",
+			longLink:         "https://example.com",
+			url:              entity.URL{ForwardPath: true, ForwardQuery: true},
+			visit:            Visit{PathSuffix: "/api", RawQuery: "v=2"},
+			expectedLongLink: "https://example.com/api?v=2",
+		},
+		{
+			name:        "path not forwarded",
+			longLink:    "https://example.com/docs",
+			url:         entity.URL{},
+			visit:       Visit{PathSuffix: "/api/v2"},
+			expectedErr: ErrInvalidPathSuffix("/api/v2"),
+		},
+		{
+			name:        "dot segment",
+			longLink:    "https://example.com/docs",
+			url:         entity.URL{ForwardPath: true},
+			visit:       Visit{PathSuffix: "/api/../../admin"},
+			expectedErr: ErrInvalidPathSuffix("/api/../../admin"),
+		},
+		{
+			name:        "escaped dot segment",
+			longLink:    "https://example.com/docs",
+			url:         entity.URL{ForwardPath: true},
+			visit:       Visit{PathSuffix: "/%2e%2E/admin"},
+			expectedErr: ErrInvalidPathSuffix("/%2e%2E/admin"),
+		},
+		{
+			name:        "malformed path escape",
+			longLink:    "https://example.com/docs",
+			url:         entity.URL{ForwardPath: true},
+			visit:       Visit{PathSuffix: "/api%zz"},
+			expectedErr: ErrInvalidPathSuffix("/api%zz"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			longLink, err := ExpandLongLink(testCase.longLink, testCase.url, testCase.visit)
+			mdtest.Equal(t, testCase.expectedErr, err)
+			mdtest.Equal(t, testCase.expectedLongLink, longLink)
+		})
+	}
+}
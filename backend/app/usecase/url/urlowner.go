@@ -0,0 +1,52 @@
+package url
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/short-d/short/app/entity"
+	"github.com/short-d/short/app/usecase/repository"
+)
+
+// ErrURLNotFound represents the error of accessing a short link which does
+// not exist.
+type ErrURLNotFound string
+
+func (e ErrURLNotFound) Error() string {
+	return fmt.Sprintf("url not found (alias=%s)", string(e))
+}
+
+// ErrNotURLOwner represents the error of modifying a short link created by
+// another user.
+type ErrNotURLOwner string
+
+func (e ErrNotURLOwner) Error() string {
+	return fmt.Sprintf("user is not the owner of url (alias=%s)", string(e))
+}
+
+// checkOwner ensures the short link exists and is created by the given user.
+func checkOwner(
+	ctx context.Context,
+	urlRepo repository.URL,
+	userURLRelationRepo repository.UserURLRelation,
+	domain string,
+	alias string,
+	user entity.User,
+) error {
+	isOwner, err := userURLRelationRepo.IsAliasOwner(ctx, user, domain, alias)
+	if err != nil {
+		return err
+	}
+	if isOwner {
+		return nil
+	}
+
+	isExist, err := urlRepo.IsAliasExist(ctx, domain, alias)
+	if err != nil {
+		return err
+	}
+	if !isExist {
+		return ErrURLNotFound(alias)
+	}
+	return ErrNotURLOwner(alias)
+}
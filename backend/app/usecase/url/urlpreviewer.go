@@ -0,0 +1,90 @@
+package url
+
+import (
+	"context"
+
+	"github.com/short-d/app/fw"
+	"github.com/short-d/short/app/entity"
+	"github.com/short-d/short/app/usecase/repository"
+)
+
+var _ Previewer = (*PreviewerPersist)(nil)
+
+// Preview represents what visitors learn about a short link before following
+// it. The long link of password protected short links is kept secret.
+type Preview struct {
+	Domain      string
+	Alias       string
+	LongLink    *string
+	Title       *string
+	Owner       entity.User
+	IsProtected bool
+}
+
+// Previewer represents the provider of short link previews
+type Previewer interface {
+	PreviewURL(ctx context.Context, domain string, alias string) (Preview, error)
+}
+
+// PreviewerPersist represents a provider of short link previews which looks
+// up the short links and their owners in persistent storage
+type PreviewerPersist struct {
+	urlRetriever        Retriever
+	userURLRelationRepo repository.UserURLRelation
+	userRepo            repository.User
+	timer               fw.Timer
+}
+
+// PreviewURL describes the short link with the given alias under the given
+// domain if it can be visited now, without counting a click. The long link
+// shown is the one visitors land on when none of the redirect rules match.
+func (p PreviewerPersist) PreviewURL(ctx context.Context, domain string, alias string) (Preview, error) {
+	now := p.timer.Now()
+	url, err := p.urlRetriever.GetURL(ctx, domain, alias, &now)
+	if err != nil {
+		return Preview{}, err
+	}
+
+	ownerEmail, err := p.userURLRelationRepo.FindOwnerEmail(ctx, url.Domain, url.Alias)
+	if err != nil {
+		return Preview{}, err
+	}
+
+	owner, err := p.userRepo.GetUserByEmail(ctx, ownerEmail)
+	if err != nil {
+		return Preview{}, err
+	}
+
+	preview := Preview{
+		Domain:      url.Domain,
+		Alias:       url.Alias,
+		Title:       url.Title,
+		Owner:       owner,
+		IsProtected: url.PasswordHash != nil,
+	}
+	if preview.IsProtected {
+		return preview, nil
+	}
+
+	longLink, err := ExpandLongLink(url.OriginalURL, url, Visit{})
+	if err != nil {
+		return Preview{}, err
+	}
+	preview.LongLink = &longLink
+	return preview, nil
+}
+
+// NewPreviewerPersist creates PreviewerPersist
+func NewPreviewerPersist(
+	urlRetriever Retriever,
+	userURLRelationRepo repository.UserURLRelation,
+	userRepo repository.User,
+	timer fw.Timer,
+) PreviewerPersist {
+	return PreviewerPersist{
+		urlRetriever:        urlRetriever,
+		userURLRelationRepo: userURLRelationRepo,
+		userRepo:            userRepo,
+		timer:               timer,
+	}
+}
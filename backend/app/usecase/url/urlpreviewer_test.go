@@ -0,0 +1,147 @@
+// +build !integration all
+
+package url
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/mdtest"
+	"github.com/short-d/short/app/entity"
+	"github.com/short-d/short/app/usecase/repository"
+)
+
+func TestPreviewerPersist_PreviewURL(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now().UTC()
+	expireAt := now.Add(-time.Hour)
+	activateAt := now.Add(time.Hour)
+	title := "Search engine"
+	passwordHash := "pbkdf2-sha256$100000$c2FsdA$a2V5"
+	longLink := "https://www.google.com"
+	taggedLongLink := "https://www.google.com?utm_source=newsletter"
+
+	owner := entity.User{
+		Email: "alpha@example.com",
+		Name:  "Alpha",
+	}
+
+	testCases := []struct {
+		name            string
+		urls            urlMap
+		relationUsers   []entity.User
+		relationURLs    []entity.URL
+		alias           string
+		expHasErr       bool
+		expectedErr     error
+		expectedPreview Preview
+	}{
+		{
+			name:      "url not found",
+			urls:      urlMap{},
+			alias:     "220uFicCJj",
+			expHasErr: true,
+		},
+		{
+			name: "url expired",
+			urls: urlMap{
+				"220uFicCJj": entity.URL{
+					Alias:       "220uFicCJj",
+					OriginalURL: longLink,
+					ExpireAt:    &expireAt,
+				},
+			},
+			relationUsers: []entity.User{owner},
+			relationURLs:  []entity.URL{{Alias: "220uFicCJj"}},
+			alias:         "220uFicCJj",
+			expHasErr:     true,
+		},
+		{
+			name: "url not active",
+			urls: urlMap{
+				"220uFicCJj": entity.URL{
+					Alias:       "220uFicCJj",
+					OriginalURL: longLink,
+					ActivateAt:  &activateAt,
+				},
+			},
+			relationUsers: []entity.User{owner},
+			relationURLs:  []entity.URL{{Alias: "220uFicCJj"}},
+			alias:         "220uFicCJj",
+			expHasErr:     true,
+			expectedErr:   ErrURLNotActive("220uFicCJj"),
+		},
+		{
+			name: "preview url with campaign parameters",
+			urls: urlMap{
+				"220uFicCJj": entity.URL{
+					Alias:       "220uFicCJj",
+					OriginalURL: longLink,
+					Title:       &title,
+					UTM:         &entity.UTM{Source: "newsletter"},
+				},
+			},
+			relationUsers: []entity.User{owner},
+			relationURLs:  []entity.URL{{Alias: "220uFicCJj"}},
+			alias:         "220uFicCJj",
+			expHasErr:     false,
+			expectedPreview: Preview{
+				Alias:    "220uFicCJj",
+				LongLink: &taggedLongLink,
+				Title:    &title,
+				Owner:    owner,
+			},
+		},
+		{
+			name: "hide long link of password protected url",
+			urls: urlMap{
+				"220uFicCJj": entity.URL{
+					Alias:        "220uFicCJj",
+					OriginalURL:  longLink,
+					PasswordHash: &passwordHash,
+				},
+			},
+			relationUsers: []entity.User{owner},
+			relationURLs:  []entity.URL{{Alias: "220uFicCJj"}},
+			alias:         "220uFicCJj",
+			expHasErr:     false,
+			expectedPreview: Preview{
+				Alias:       "220uFicCJj",
+				Owner:       owner,
+				IsProtected: true,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			urlRepo := repository.NewURLFake(testCase.urls)
+			publicURLRepo := repository.NewPublicURLFake(nil)
+			userURLRepo := repository.NewUserURLRepoFake(
+				testCase.relationUsers,
+				testCase.relationURLs,
+				&publicURLRepo,
+			)
+			userRepo := repository.NewUserFake([]entity.User{owner})
+			retriever := NewRetrieverPersist(&urlRepo, &userURLRepo, &publicURLRepo)
+			timer := mdtest.NewTimerFake(now)
+			previewer := NewPreviewerPersist(retriever, &userURLRepo, &userRepo, timer)
+
+			preview, err := previewer.PreviewURL(context.Background(), "", testCase.alias)
+			if testCase.expHasErr {
+				mdtest.NotEqual(t, nil, err)
+				if testCase.expectedErr != nil {
+					mdtest.Equal(t, testCase.expectedErr, err)
+				}
+				return
+			}
+			mdtest.Equal(t, nil, err)
+			mdtest.Equal(t, testCase.expectedPreview, preview)
+		})
+	}
+}
@@ -1,19 +1,74 @@
 package url
 
 import (
+	"context"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/short-d/short/app/entity"
 	"github.com/short-d/short/app/usecase/repository"
 )
 
+const maxPageSize = 100
+
 var _ Retriever = (*RetrieverPersist)(nil)
 
+// ErrInvalidPageSize represents incorrect number of URLs requested in a page.
+type ErrInvalidPageSize string
+
+func (e ErrInvalidPageSize) Error() string {
+	return string(e)
+}
+
+// ErrURLExhausted represents the error of visiting a URL which has used up its
+// maximum clicks.
+type ErrURLExhausted string
+
+func (e ErrURLExhausted) Error() string {
+	return fmt.Sprintf("url exhausted (alias=%s)", string(e))
+}
+
+// ErrURLExpired represents the error of visiting a URL after its expiration
+// time.
+type ErrURLExpired string
+
+func (e ErrURLExpired) Error() string {
+	return fmt.Sprintf("url expired (alias=%s)", string(e))
+}
+
+// ErrStorageFailure represents the error of failing to access the persistent
+// storage of URLs, such as when the database is down.
+type ErrStorageFailure struct {
+	Err error
+}
+
+func (e ErrStorageFailure) Error() string {
+	return fmt.Sprintf("url storage failure: %v", e.Err)
+}
+
+// ErrURLNotActive represents the error of visiting a URL before its activation
+// time.
+type ErrURLNotActive string
+
+func (e ErrURLNotActive) Error() string {
+	return fmt.Sprintf("url not active yet (alias=%s)", string(e))
+}
+
+// Page represents a consecutive slice of a list of URLs.
+type Page struct {
+	URLs        []entity.URL
+	HasNextPage bool
+}
+
 // Retriever represents URL retriever
 type Retriever interface {
-	GetURL(alias string, expiringAt *time.Time) (entity.URL, error)
-	GetURLsByUser(user entity.User) ([]entity.URL, error)
+	GetURL(ctx context.Context, domain string, alias string, expiringAt *time.Time) (entity.URL, error)
+	CountClick(ctx context.Context, url entity.URL) error
+	GetURLsByUser(ctx context.Context, user entity.User, isPublic *bool) ([]entity.URL, error)
+	GetURLPageByUser(ctx context.Context, user entity.User, query repository.URLQuery, first int) (Page, error)
+	GetPublicURLs(ctx context.Context, first int, after *repository.URLKey) (Page, error)
+	IsOwner(ctx context.Context, domain string, alias string, user entity.User) (bool, error)
 }
 
 // RetrieverPersist represents URL retriever that fetches URL from persistent
@@ -21,56 +76,170 @@ type Retriever interface {
 type RetrieverPersist struct {
 	urlRepo             repository.URL
 	userURLRelationRepo repository.UserURLRelation
+	publicURLRepo       repository.PublicURL
 }
 
-// GetURL retrieves URL from persistent storage given alias
-func (r RetrieverPersist) GetURL(alias string, expiringAt *time.Time) (entity.URL, error) {
+// GetURL retrieves URL from persistent storage given its domain and alias. It
+// fails with ErrURLNotFound when the alias doesn't exist under the domain and
+// ErrStorageFailure when the storage can't be accessed. When expiringAt is
+// provided, URLs which expire before it are reported with ErrURLExpired, URLs
+// which have used up their maximum clicks with ErrURLExhausted, and URLs
+// activated after it with ErrURLNotActive.
+func (r RetrieverPersist) GetURL(ctx context.Context, domain string, alias string, expiringAt *time.Time) (entity.URL, error) {
 	if expiringAt == nil {
-		return r.getURL(alias)
+		return r.getURL(ctx, domain, alias)
 	}
-	return r.getURLExpireAfter(alias, *expiringAt)
+	return r.getURLExpireAfter(ctx, domain, alias, *expiringAt)
 }
 
-func (r RetrieverPersist) getURLExpireAfter(alias string, expiringAt time.Time) (entity.URL, error) {
-	url, err := r.getURL(alias)
+func (r RetrieverPersist) getURLExpireAfter(ctx context.Context, domain string, alias string, expiringAt time.Time) (entity.URL, error) {
+	url, err := r.getURL(ctx, domain, alias)
 	if err != nil {
 		return entity.URL{}, err
 	}
 
-	if url.ExpireAt == nil {
-		return url, nil
+	if url.ExpireAt != nil && expiringAt.After(*url.ExpireAt) {
+		return entity.URL{}, ErrURLExpired(alias)
 	}
 
-	if expiringAt.After(*url.ExpireAt) {
-		return entity.URL{}, fmt.Errorf("url expired (alias=%s,expiringAt=%v)", alias, expiringAt)
+	if url.ActivateAt != nil && expiringAt.Before(*url.ActivateAt) {
+		return entity.URL{}, ErrURLNotActive(alias)
+	}
+
+	if isExhausted(url) {
+		return entity.URL{}, ErrURLExhausted(alias)
 	}
 
 	return url, nil
 }
 
-func (r RetrieverPersist) getURL(alias string) (entity.URL, error) {
-	url, err := r.urlRepo.GetByAlias(alias)
-	if err != nil {
-		return entity.URL{}, err
+func (r RetrieverPersist) getURL(ctx context.Context, domain string, alias string) (entity.URL, error) {
+	url, err := r.urlRepo.GetByAlias(ctx, domain, alias)
+	switch err.(type) {
+	case nil:
+		return url, nil
+	case repository.ErrURLNotFound:
+		return entity.URL{}, ErrURLNotFound(alias)
+	default:
+		return entity.URL{}, ErrStorageFailure{Err: err}
 	}
+}
 
-	return url, nil
+func isExhausted(url entity.URL) bool {
+	return url.MaxClicks != nil && url.ClickCount >= *url.MaxClicks
 }
 
-// GetURLsByUser retrieves URLs created by given user from persistent storage
-func (r RetrieverPersist) GetURLsByUser(user entity.User) ([]entity.URL, error) {
-	aliases, err := r.userURLRelationRepo.FindAliasesByUser(user)
+// CountClick records a visit to the URL before redirecting the visitor. The
+// clicks on URLs with a maximum are counted atomically in persistent storage,
+// so that concurrent visitors can't exceed the maximum. ErrURLExhausted is
+// returned when no click is left.
+func (r RetrieverPersist) CountClick(ctx context.Context, url entity.URL) error {
+	if url.MaxClicks == nil {
+		return nil
+	}
+
+	isCounted, err := r.urlRepo.IncrementClickCount(ctx, url.Domain, url.Alias)
+	if err != nil {
+		return ErrStorageFailure{Err: err}
+	}
+	if !isCounted {
+		return ErrURLExhausted(url.Alias)
+	}
+	return nil
+}
+
+// GetURLsByUser retrieves URLs created by given user from persistent storage.
+// Only the URLs with matching visibility are included when isPublic is
+// provided.
+func (r RetrieverPersist) GetURLsByUser(ctx context.Context, user entity.User, isPublic *bool) ([]entity.URL, error) {
+	keys, err := r.userURLRelationRepo.FindKeysByUser(ctx, user, isPublic)
 	if err != nil {
 		return []entity.URL{}, err
 	}
 
-	return r.urlRepo.GetByAliases(aliases)
+	return r.urlRepo.GetByKeys(ctx, keys)
+}
+
+// GetURLPageByUser retrieves at most first URLs created by given user which
+// match the query from persistent storage.
+func (r RetrieverPersist) GetURLPageByUser(ctx context.Context, user entity.User, query repository.URLQuery, first int) (Page, error) {
+	err := checkPageSize(first)
+	if err != nil {
+		return Page{}, err
+	}
+
+	urls, err := r.userURLRelationRepo.FindURLsByUser(ctx, user, query, first+1)
+	if err != nil {
+		return Page{}, err
+	}
+
+	hasNextPage := len(urls) > first
+	if hasNextPage {
+		urls = urls[:first]
+	}
+	return Page{
+		URLs:        urls,
+		HasNextPage: hasNextPage,
+	}, nil
+}
+
+// GetPublicURLs retrieves at most first public URLs in alphabetical order of
+// their aliases, then their domains, starting after the given key.
+func (r RetrieverPersist) GetPublicURLs(ctx context.Context, first int, after *repository.URLKey) (Page, error) {
+	err := checkPageSize(first)
+	if err != nil {
+		return Page{}, err
+	}
+
+	keys, err := r.publicURLRepo.FindKeys(ctx, first+1, after)
+	if err != nil {
+		return Page{}, err
+	}
+
+	hasNextPage := len(keys) > first
+	if hasNextPage {
+		keys = keys[:first]
+	}
+
+	urls, err := r.urlRepo.GetByKeys(ctx, keys)
+	if err != nil {
+		return Page{}, err
+	}
+
+	sort.SliceStable(urls, func(i, j int) bool {
+		if urls[i].Alias != urls[j].Alias {
+			return urls[i].Alias < urls[j].Alias
+		}
+		return urls[i].Domain < urls[j].Domain
+	})
+	return Page{
+		URLs:        urls,
+		HasNextPage: hasNextPage,
+	}, nil
+}
+
+func checkPageSize(first int) error {
+	if first < 1 || first > maxPageSize {
+		return ErrInvalidPageSize(fmt.Sprintf("first must be between 1 and %d", maxPageSize))
+	}
+	return nil
+}
+
+// IsOwner checks whether the short link with the given alias under the given
+// domain is created by the given user.
+func (r RetrieverPersist) IsOwner(ctx context.Context, domain string, alias string, user entity.User) (bool, error) {
+	return r.userURLRelationRepo.IsAliasOwner(ctx, user, domain, alias)
 }
 
 // NewRetrieverPersist creates persistent URL retriever
-func NewRetrieverPersist(urlRepo repository.URL, userURLRelationRepo repository.UserURLRelation) RetrieverPersist {
+func NewRetrieverPersist(
+	urlRepo repository.URL,
+	userURLRelationRepo repository.UserURLRelation,
+	publicURLRepo repository.PublicURL,
+) RetrieverPersist {
 	return RetrieverPersist{
 		urlRepo:             urlRepo,
 		userURLRelationRepo: userURLRelationRepo,
+		publicURLRepo:       publicURLRepo,
 	}
 }
@@ -3,6 +3,7 @@
 package url
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -19,6 +20,7 @@ func TestUrlRetriever_GetURL(t *testing.T) {
 	now := time.Now()
 	before := now.Add(-5 * time.Second)
 	after := now.Add(5 * time.Second)
+	maxClicks := 2
 
 	testCases := []struct {
 		name        string
@@ -26,6 +28,7 @@ func TestUrlRetriever_GetURL(t *testing.T) {
 		alias       string
 		expiringAt  *time.Time
 		hasErr      bool
+		expectedErr error
 		expectedURL entity.URL
 	}{
 		{
@@ -34,6 +37,7 @@ func TestUrlRetriever_GetURL(t *testing.T) {
 			alias:       "220uFicCJj",
 			expiringAt:  &now,
 			hasErr:      true,
+			expectedErr: ErrURLNotFound("220uFicCJj"),
 			expectedURL: entity.URL{},
 		},
 		{
@@ -47,6 +51,7 @@ func TestUrlRetriever_GetURL(t *testing.T) {
 			alias:       "220uFicCJj",
 			expiringAt:  &now,
 			hasErr:      true,
+			expectedErr: ErrURLExpired("220uFicCJj"),
 			expectedURL: entity.URL{},
 		},
 		{
@@ -81,6 +86,71 @@ func TestUrlRetriever_GetURL(t *testing.T) {
 				ExpireAt: &after,
 			},
 		},
+		{
+			name: "url not active yet",
+			urls: urlMap{
+				"220uFicCJj": entity.URL{
+					Alias:      "220uFicCJj",
+					ActivateAt: &after,
+				},
+			},
+			alias:       "220uFicCJj",
+			expiringAt:  &now,
+			hasErr:      true,
+			expectedErr: ErrURLNotActive("220uFicCJj"),
+			expectedURL: entity.URL{},
+		},
+		{
+			name: "url within activation window",
+			urls: urlMap{
+				"220uFicCJj": entity.URL{
+					Alias:      "220uFicCJj",
+					ActivateAt: &before,
+					ExpireAt:   &after,
+				},
+			},
+			alias:      "220uFicCJj",
+			expiringAt: &now,
+			hasErr:     false,
+			expectedURL: entity.URL{
+				Alias:      "220uFicCJj",
+				ActivateAt: &before,
+				ExpireAt:   &after,
+			},
+		},
+		{
+			name: "url exhausted",
+			urls: urlMap{
+				"220uFicCJj": entity.URL{
+					Alias:      "220uFicCJj",
+					MaxClicks:  &maxClicks,
+					ClickCount: 2,
+				},
+			},
+			alias:       "220uFicCJj",
+			expiringAt:  &now,
+			hasErr:      true,
+			expectedErr: ErrURLExhausted("220uFicCJj"),
+			expectedURL: entity.URL{},
+		},
+		{
+			name: "url has clicks left",
+			urls: urlMap{
+				"220uFicCJj": entity.URL{
+					Alias:      "220uFicCJj",
+					MaxClicks:  &maxClicks,
+					ClickCount: 1,
+				},
+			},
+			alias:      "220uFicCJj",
+			expiringAt: &now,
+			hasErr:     false,
+			expectedURL: entity.URL{
+				Alias:      "220uFicCJj",
+				MaxClicks:  &maxClicks,
+				ClickCount: 1,
+			},
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -89,12 +159,14 @@ func TestUrlRetriever_GetURL(t *testing.T) {
 			t.Parallel()
 
 			fakeURLRepo := repository.NewURLFake(testCase.urls)
-			fakeUserURLRelationRepo := repository.NewUserURLRepoFake([]entity.User{}, []entity.URL{})
-			retriever := NewRetrieverPersist(&fakeURLRepo, &fakeUserURLRelationRepo)
-			url, err := retriever.GetURL(testCase.alias, testCase.expiringAt)
+			fakeUserURLRelationRepo := repository.NewUserURLRepoFake([]entity.User{}, []entity.URL{}, nil)
+			fakePublicURLRepo := repository.NewPublicURLFake(nil)
+			retriever := NewRetrieverPersist(&fakeURLRepo, &fakeUserURLRelationRepo, &fakePublicURLRepo)
+			url, err := retriever.GetURL(context.Background(), "", testCase.alias, testCase.expiringAt)
 
 			if testCase.hasErr {
 				mdtest.NotEqual(t, nil, err)
+				mdtest.Equal(t, testCase.expectedErr, err)
 				return
 			}
 			mdtest.Equal(t, nil, err)
@@ -103,17 +175,83 @@ func TestUrlRetriever_GetURL(t *testing.T) {
 	}
 }
 
+func TestRetrieverPersist_CountClick(t *testing.T) {
+	t.Parallel()
+
+	maxClicks := 2
+
+	testCases := []struct {
+		name               string
+		url                entity.URL
+		expectedErr        error
+		expectedClickCount int
+	}{
+		{
+			name:               "url without max clicks",
+			url:                entity.URL{Alias: "220uFicCJj"},
+			expectedErr:        nil,
+			expectedClickCount: 0,
+		},
+		{
+			name: "url has clicks left",
+			url: entity.URL{
+				Alias:      "220uFicCJj",
+				MaxClicks:  &maxClicks,
+				ClickCount: 1,
+			},
+			expectedErr:        nil,
+			expectedClickCount: 2,
+		},
+		{
+			name: "url exhausted",
+			url: entity.URL{
+				Alias:      "220uFicCJj",
+				MaxClicks:  &maxClicks,
+				ClickCount: 2,
+			},
+			expectedErr:        ErrURLExhausted("220uFicCJj"),
+			expectedClickCount: 2,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			fakeURLRepo := repository.NewURLFake(urlMap{
+				testCase.url.Alias: testCase.url,
+			})
+			fakeUserURLRelationRepo := repository.NewUserURLRepoFake([]entity.User{}, []entity.URL{}, nil)
+			fakePublicURLRepo := repository.NewPublicURLFake(nil)
+			retriever := NewRetrieverPersist(&fakeURLRepo, &fakeUserURLRelationRepo, &fakePublicURLRepo)
+
+			err := retriever.CountClick(context.Background(), testCase.url)
+			mdtest.Equal(t, testCase.expectedErr, err)
+
+			url, err := fakeURLRepo.GetByAlias(context.Background(), "", testCase.url.Alias)
+			mdtest.Equal(t, nil, err)
+			mdtest.Equal(t, testCase.expectedClickCount, url.ClickCount)
+		})
+	}
+}
+
 func TestRetrieverPersist_GetURLs(t *testing.T) {
 	t.Parallel()
 
+	isPublic := true
+	isPrivate := false
+
 	testCases := []struct {
-		name         string
-		urls         urlMap
-		users        []entity.User
-		createdURLs  []entity.URL
-		user         entity.User
-		hasErr       bool
-		expectedURLs []entity.URL
+		name          string
+		urls          urlMap
+		users         []entity.User
+		createdURLs   []entity.URL
+		publicAliases []string
+		user          entity.User
+		isPublic      *bool
+		hasErr        bool
+		expectedURLs  []entity.URL
 	}{
 		{
 			name: "user created URLs",
@@ -230,6 +368,102 @@ func TestRetrieverPersist_GetURLs(t *testing.T) {
 			hasErr:       false,
 			expectedURLs: []entity.URL{},
 		},
+		{
+			name: "user created public URLs",
+			urls: urlMap{
+				"google": entity.URL{
+					Alias:       "google",
+					OriginalURL: "https://www.google.com/",
+				},
+				"short": entity.URL{
+					Alias:       "short",
+					OriginalURL: "https://github.com/short-d/short/",
+				},
+			},
+			users: []entity.User{
+				{
+					ID:    "12345",
+					Name:  "Test User",
+					Email: "test@gmail.com",
+				}, {
+					ID:    "12345",
+					Name:  "Test User",
+					Email: "test@gmail.com",
+				},
+			},
+			createdURLs: []entity.URL{
+				{
+					Alias:       "google",
+					OriginalURL: "https://www.google.com/",
+				},
+				{
+					Alias:       "short",
+					OriginalURL: "https://github.com/short-d/short/",
+				},
+			},
+			publicAliases: []string{"short"},
+			user: entity.User{
+				ID:    "12345",
+				Name:  "Test User",
+				Email: "test@gmail.com",
+			},
+			isPublic: &isPublic,
+			hasErr:   false,
+			expectedURLs: []entity.URL{
+				{
+					Alias:       "short",
+					OriginalURL: "https://github.com/short-d/short/",
+				},
+			},
+		},
+		{
+			name: "user created private URLs",
+			urls: urlMap{
+				"google": entity.URL{
+					Alias:       "google",
+					OriginalURL: "https://www.google.com/",
+				},
+				"short": entity.URL{
+					Alias:       "short",
+					OriginalURL: "https://github.com/short-d/short/",
+				},
+			},
+			users: []entity.User{
+				{
+					ID:    "12345",
+					Name:  "Test User",
+					Email: "test@gmail.com",
+				}, {
+					ID:    "12345",
+					Name:  "Test User",
+					Email: "test@gmail.com",
+				},
+			},
+			createdURLs: []entity.URL{
+				{
+					Alias:       "google",
+					OriginalURL: "https://www.google.com/",
+				},
+				{
+					Alias:       "short",
+					OriginalURL: "https://github.com/short-d/short/",
+				},
+			},
+			publicAliases: []string{"short"},
+			user: entity.User{
+				ID:    "12345",
+				Name:  "Test User",
+				Email: "test@gmail.com",
+			},
+			isPublic: &isPrivate,
+			hasErr:   false,
+			expectedURLs: []entity.URL{
+				{
+					Alias:       "google",
+					OriginalURL: "https://www.google.com/",
+				},
+			},
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -238,10 +472,15 @@ func TestRetrieverPersist_GetURLs(t *testing.T) {
 			t.Parallel()
 
 			fakeURLRepo := repository.NewURLFake(testCase.urls)
-			fakeUserURLRelationRepo := repository.NewUserURLRepoFake(testCase.users, testCase.createdURLs)
-			retriever := NewRetrieverPersist(&fakeURLRepo, &fakeUserURLRelationRepo)
+			fakePublicURLRepo := repository.NewPublicURLFake(testCase.publicAliases)
+			fakeUserURLRelationRepo := repository.NewUserURLRepoFake(
+				testCase.users,
+				testCase.createdURLs,
+				&fakePublicURLRepo,
+			)
+			retriever := NewRetrieverPersist(&fakeURLRepo, &fakeUserURLRelationRepo, &fakePublicURLRepo)
 
-			urls, err := retriever.GetURLsByUser(testCase.user)
+			urls, err := retriever.GetURLsByUser(context.Background(), testCase.user, testCase.isPublic)
 			if testCase.hasErr {
 				mdtest.NotEqual(t, nil, err)
 				return
@@ -252,3 +491,267 @@ func TestRetrieverPersist_GetURLs(t *testing.T) {
 		})
 	}
 }
+
+func TestRetrieverPersist_GetPublicURLs(t *testing.T) {
+	t.Parallel()
+
+	afterKey := repository.URLKey{Alias: "google"}
+	urls := urlMap{
+		"google": entity.URL{
+			Alias:       "google",
+			OriginalURL: "https://www.google.com/",
+		},
+		"short": entity.URL{
+			Alias:       "short",
+			OriginalURL: "https://github.com/short-d/short/",
+		},
+		"mozilla": entity.URL{
+			Alias:       "mozilla",
+			OriginalURL: "https://www.mozilla.org/",
+		},
+		"private": entity.URL{
+			Alias:       "private",
+			OriginalURL: "https://www.example.com/",
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		publicAliases []string
+		first         int
+		afterKey      *repository.URLKey
+		hasErr        bool
+		expectedPage  Page
+	}{
+		{
+			name:          "first too small",
+			publicAliases: []string{"google"},
+			first:         0,
+			hasErr:        true,
+		},
+		{
+			name:          "first too large",
+			publicAliases: []string{"google"},
+			first:         101,
+			hasErr:        true,
+		},
+		{
+			name:          "no public URLs",
+			publicAliases: []string{},
+			first:         2,
+			hasErr:        false,
+			expectedPage: Page{
+				URLs:        []entity.URL{},
+				HasNextPage: false,
+			},
+		},
+		{
+			name:          "first page",
+			publicAliases: []string{"short", "google", "mozilla"},
+			first:         2,
+			hasErr:        false,
+			expectedPage: Page{
+				URLs: []entity.URL{
+					{
+						Alias:       "google",
+						OriginalURL: "https://www.google.com/",
+					},
+					{
+						Alias:       "mozilla",
+						OriginalURL: "https://www.mozilla.org/",
+					},
+				},
+				HasNextPage: true,
+			},
+		},
+		{
+			name:          "last page",
+			publicAliases: []string{"short", "google", "mozilla"},
+			first:         2,
+			afterKey:      &afterKey,
+			hasErr:        false,
+			expectedPage: Page{
+				URLs: []entity.URL{
+					{
+						Alias:       "mozilla",
+						OriginalURL: "https://www.mozilla.org/",
+					},
+					{
+						Alias:       "short",
+						OriginalURL: "https://github.com/short-d/short/",
+					},
+				},
+				HasNextPage: false,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			fakeURLRepo := repository.NewURLFake(urls)
+			fakePublicURLRepo := repository.NewPublicURLFake(testCase.publicAliases)
+			fakeUserURLRelationRepo := repository.NewUserURLRepoFake(nil, nil, &fakePublicURLRepo)
+			retriever := NewRetrieverPersist(&fakeURLRepo, &fakeUserURLRelationRepo, &fakePublicURLRepo)
+
+			page, err := retriever.GetPublicURLs(context.Background(), testCase.first, testCase.afterKey)
+			if testCase.hasErr {
+				mdtest.NotEqual(t, nil, err)
+				return
+			}
+
+			mdtest.Equal(t, nil, err)
+			mdtest.Equal(t, testCase.expectedPage, page)
+		})
+	}
+}
+
+func TestRetrieverPersist_GetURLPageByUser(t *testing.T) {
+	t.Parallel()
+
+	twoDaysAgo := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	yesterday := twoDaysAgo.Add(24 * time.Hour)
+	user := entity.User{Email: "test@gmail.com"}
+	otherUser := entity.User{Email: "test2@gmail.com"}
+
+	google := entity.URL{
+		Alias:       "google",
+		OriginalURL: "https://www.google.com/",
+		CreatedAt:   &twoDaysAgo,
+		ExpireAt:    &yesterday,
+	}
+	short := entity.URL{
+		Alias:       "short",
+		OriginalURL: "https://github.com/short-d/short/",
+		CreatedAt:   &yesterday,
+	}
+	mozilla := entity.URL{
+		Alias:       "mozilla",
+		OriginalURL: "https://www.mozilla.org/",
+		ExpireAt:    &twoDaysAgo,
+	}
+	docs := entity.URL{
+		Alias:       "docs",
+		OriginalURL: "https://docs.google.com/",
+		CreatedAt:   &yesterday,
+	}
+	other := entity.URL{
+		Alias:       "other",
+		OriginalURL: "https://www.google.com/",
+	}
+
+	testCases := []struct {
+		name         string
+		query        repository.URLQuery
+		first        int
+		hasErr       bool
+		expectedPage Page
+	}{
+		{
+			name:   "first too small",
+			query:  repository.URLQuery{SortBy: repository.URLSortByAlias},
+			first:  0,
+			hasErr: true,
+		},
+		{
+			name:  "sort by alias",
+			query: repository.URLQuery{SortBy: repository.URLSortByAlias},
+			first: 3,
+			expectedPage: Page{
+				URLs:        []entity.URL{docs, google, mozilla},
+				HasNextPage: true,
+			},
+		},
+		{
+			name: "sort by alias after cursor",
+			query: repository.URLQuery{
+				SortBy: repository.URLSortByAlias,
+				After:  &repository.URLCursor{Alias: "mozilla"},
+			},
+			first: 3,
+			expectedPage: Page{
+				URLs:        []entity.URL{short},
+				HasNextPage: false,
+			},
+		},
+		{
+			name: "sort by created time descending",
+			query: repository.URLQuery{
+				SortBy:     repository.URLSortByCreatedAt,
+				Descending: true,
+			},
+			first: 4,
+			expectedPage: Page{
+				URLs:        []entity.URL{short, docs, google, mozilla},
+				HasNextPage: false,
+			},
+		},
+		{
+			name: "sort by created time descending after cursor",
+			query: repository.URLQuery{
+				SortBy:     repository.URLSortByCreatedAt,
+				Descending: true,
+				After: &repository.URLCursor{
+					Alias:     "short",
+					SortValue: &yesterday,
+				},
+			},
+			first: 2,
+			expectedPage: Page{
+				URLs:        []entity.URL{docs, google},
+				HasNextPage: true,
+			},
+		},
+		{
+			name: "sort by expiration time after cursor without expiration",
+			query: repository.URLQuery{
+				SortBy: repository.URLSortByExpireAt,
+				After:  &repository.URLCursor{Alias: "docs"},
+			},
+			first: 4,
+			expectedPage: Page{
+				URLs:        []entity.URL{short},
+				HasNextPage: false,
+			},
+		},
+		{
+			name: "search alias and long link",
+			query: repository.URLQuery{
+				SortBy: repository.URLSortByAlias,
+				Search: "GOOGLE",
+			},
+			first: 4,
+			expectedPage: Page{
+				URLs:        []entity.URL{docs, google},
+				HasNextPage: false,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			fakeURLRepo := repository.NewURLFake(urlMap{})
+			fakePublicURLRepo := repository.NewPublicURLFake(nil)
+			fakeUserURLRelationRepo := repository.NewUserURLRepoFake(
+				[]entity.User{user, user, user, user, otherUser},
+				[]entity.URL{google, short, mozilla, docs, other},
+				&fakePublicURLRepo,
+			)
+			retriever := NewRetrieverPersist(&fakeURLRepo, &fakeUserURLRelationRepo, &fakePublicURLRepo)
+
+			page, err := retriever.GetURLPageByUser(context.Background(), user, testCase.query, testCase.first)
+			if testCase.hasErr {
+				mdtest.NotEqual(t, nil, err)
+				return
+			}
+
+			mdtest.Equal(t, nil, err)
+			mdtest.Equal(t, testCase.expectedPage, page)
+		})
+	}
+}
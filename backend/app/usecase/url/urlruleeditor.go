@@ -0,0 +1,99 @@
+package url
+
+import (
+	"context"
+
+	"github.com/short-d/short/app/entity"
+	"github.com/short-d/short/app/usecase/linksafety"
+	"github.com/short-d/short/app/usecase/redirectrule"
+	"github.com/short-d/short/app/usecase/repository"
+	"github.com/short-d/short/app/usecase/validator"
+)
+
+var _ RuleEditor = (*RuleEditorPersist)(nil)
+
+// RuleEditor represents the editor of the redirect rules of short links
+type RuleEditor interface {
+	GetRules(ctx context.Context, domain string, alias string, user entity.User) ([]entity.RedirectRule, error)
+	UpdateRules(ctx context.Context, domain string, alias string, rules []entity.RedirectRule, user entity.User) ([]entity.RedirectRule, error)
+}
+
+// RuleEditorPersist represents a redirect rule editor which persists the
+// rules in the repository
+type RuleEditorPersist struct {
+	urlRepo             repository.URL
+	userURLRelationRepo repository.UserURLRelation
+	redirectRuleRepo    repository.RedirectRule
+	longLinkValidator   validator.LongLink
+	linkChecker         linksafety.Checker
+}
+
+// GetRules fetches the redirect rules of the short link with the given alias
+// under the given domain in evaluation order if it is created by the given
+// user.
+func (r RuleEditorPersist) GetRules(ctx context.Context, domain string, alias string, user entity.User) ([]entity.RedirectRule, error) {
+	err := checkOwner(ctx, r.urlRepo, r.userURLRelationRepo, domain, alias, user)
+	if err != nil {
+		return nil, err
+	}
+	return r.redirectRuleRepo.FindByAlias(ctx, domain, alias)
+}
+
+// UpdateRules replaces the redirect rules of the short link with the given
+// alias under the given domain if it is created by the given user. The long
+// links of the rules are screened the same way as the original long link.
+func (r RuleEditorPersist) UpdateRules(
+	ctx context.Context,
+	domain string,
+	alias string,
+	rules []entity.RedirectRule,
+	user entity.User,
+) ([]entity.RedirectRule, error) {
+	err := checkOwner(ctx, r.urlRepo, r.userURLRelationRepo, domain, alias, user)
+	if err != nil {
+		return nil, err
+	}
+
+	err = redirectrule.Validate(rules)
+	if err != nil {
+		return nil, err
+	}
+
+	longLinks := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		longLink := rule.LongLink
+		if !r.longLinkValidator.IsValid(&longLink) {
+			return nil, ErrInvalidLongLink(longLink)
+		}
+		longLinks = append(longLinks, longLink)
+	}
+
+	for _, err := range r.linkChecker.CheckAll(ctx, longLinks) {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = r.redirectRuleRepo.ReplaceRules(ctx, domain, alias, rules)
+	if err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// NewRuleEditorPersist creates RuleEditorPersist
+func NewRuleEditorPersist(
+	urlRepo repository.URL,
+	userURLRelationRepo repository.UserURLRelation,
+	redirectRuleRepo repository.RedirectRule,
+	longLinkValidator validator.LongLink,
+	linkChecker linksafety.Checker,
+) RuleEditorPersist {
+	return RuleEditorPersist{
+		urlRepo:             urlRepo,
+		userURLRelationRepo: userURLRelationRepo,
+		redirectRuleRepo:    redirectRuleRepo,
+		longLinkValidator:   longLinkValidator,
+		linkChecker:         linkChecker,
+	}
+}
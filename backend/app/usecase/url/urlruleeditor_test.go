@@ -0,0 +1,185 @@
+// +build !integration all
+
+package url
+
+import (
+	"context"
+	"testing"
+
+	"github.com/short-d/app/mdtest"
+	"github.com/short-d/short/app/entity"
+	"github.com/short-d/short/app/usecase/linksafety"
+	"github.com/short-d/short/app/usecase/redirectrule"
+	"github.com/short-d/short/app/usecase/repository"
+	"github.com/short-d/short/app/usecase/service"
+	"github.com/short-d/short/app/usecase/validator"
+)
+
+func TestRuleEditorPersist_UpdateRules(t *testing.T) {
+	t.Parallel()
+
+	owner := entity.User{Email: "alpha@example.com"}
+	otherUser := entity.User{Email: "beta@example.com"}
+	urls := urlMap{
+		"220uFicCJj": entity.URL{
+			Alias:       "220uFicCJj",
+			OriginalURL: "https://www.example.com",
+		},
+	}
+	existingRules := map[string][]entity.RedirectRule{
+		"220uFicCJj": {
+			{
+				Condition: entity.RedirectConditionCountry,
+				Values:    []string{"CA"},
+				LongLink:  "https://www.example.ca",
+			},
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		alias         string
+		rules         []entity.RedirectRule
+		user          entity.User
+		expectedErr   error
+		expectedRules []entity.RedirectRule
+	}{
+		{
+			name:        "url not found",
+			alias:       "BlMUy9cLq2",
+			rules:       []entity.RedirectRule{},
+			user:        owner,
+			expectedErr: ErrURLNotFound("BlMUy9cLq2"),
+		},
+		{
+			name:        "user is not owner",
+			alias:       "220uFicCJj",
+			rules:       []entity.RedirectRule{},
+			user:        otherUser,
+			expectedErr: ErrNotURLOwner("220uFicCJj"),
+		},
+		{
+			name:  "invalid rule",
+			alias: "220uFicCJj",
+			rules: []entity.RedirectRule{
+				{
+					Condition: entity.RedirectConditionSplit,
+					Weight:    101,
+					LongLink:  "https://www.example.com/b",
+				},
+			},
+			user: owner,
+			expectedErr: redirectrule.ErrInvalidRule{
+				Position: 0,
+				Reason:   "weight out of range",
+			},
+		},
+		{
+			name:  "invalid long link",
+			alias: "220uFicCJj",
+			rules: []entity.RedirectRule{
+				{
+					Condition: entity.RedirectConditionDevice,
+					Values:    []string{"mobile"},
+					LongLink:  "m.example.com",
+				},
+			},
+			user:        owner,
+			expectedErr: ErrInvalidLongLink("m.example.com"),
+		},
+		{
+			name:  "unsafe long link",
+			alias: "220uFicCJj",
+			rules: []entity.RedirectRule{
+				{
+					Condition: entity.RedirectConditionDevice,
+					Values:    []string{"mobile"},
+					LongLink:  "https://malware.example.com",
+				},
+			},
+			user:        owner,
+			expectedErr: linksafety.ErrUnsafeLink(linksafety.ReasonBlockedDomain),
+		},
+		{
+			name:  "replace rules successfully",
+			alias: "220uFicCJj",
+			rules: []entity.RedirectRule{
+				{
+					Condition: entity.RedirectConditionDevice,
+					Values:    []string{"mobile"},
+					LongLink:  "https://m.example.com",
+				},
+				{
+					Condition: entity.RedirectConditionSplit,
+					Weight:    50,
+					LongLink:  "https://www.example.com/b",
+				},
+			},
+			user: owner,
+			expectedRules: []entity.RedirectRule{
+				{
+					Condition: entity.RedirectConditionDevice,
+					Values:    []string{"mobile"},
+					LongLink:  "https://m.example.com",
+				},
+				{
+					Condition: entity.RedirectConditionSplit,
+					Weight:    50,
+					LongLink:  "https://www.example.com/b",
+				},
+			},
+		},
+		{
+			name:          "remove all rules",
+			alias:         "220uFicCJj",
+			rules:         []entity.RedirectRule{},
+			user:          owner,
+			expectedRules: []entity.RedirectRule{},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			urlRepo := repository.NewURLFake(urls)
+			userURLRepo := repository.NewUserURLRepoFake(
+				[]entity.User{owner},
+				[]entity.URL{urls["220uFicCJj"]},
+				nil,
+			)
+			ruleRepo := repository.NewRedirectRuleFake(map[string][]entity.RedirectRule{
+				"220uFicCJj": existingRules["220uFicCJj"],
+			})
+			linkChecker := linksafety.NewChecker(
+				linksafety.Blocklist{Domains: []string{"malware.example.com"}},
+				nil,
+				service.NewRedirectTracerFake(nil),
+				0,
+				0,
+			)
+			editor := NewRuleEditorPersist(
+				&urlRepo,
+				&userURLRepo,
+				&ruleRepo,
+				validator.NewLongLink(),
+				linkChecker,
+			)
+
+			rules, err := editor.UpdateRules(context.Background(), "", testCase.alias, testCase.rules, testCase.user)
+			mdtest.Equal(t, testCase.expectedErr, err)
+			if testCase.expectedErr != nil {
+				rules, err = ruleRepo.FindByAlias(context.Background(), "", "220uFicCJj")
+				mdtest.Equal(t, nil, err)
+				mdtest.Equal(t, existingRules["220uFicCJj"], rules)
+				return
+			}
+			mdtest.Equal(t, testCase.expectedRules, rules)
+
+			rules, err = editor.GetRules(context.Background(), "", testCase.alias, testCase.user)
+			mdtest.Equal(t, nil, err)
+			mdtest.Equal(t, testCase.expectedRules, rules)
+		})
+	}
+}
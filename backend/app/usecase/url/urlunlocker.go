@@ -0,0 +1,60 @@
+package url
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/short-d/short/app/entity"
+	"github.com/short-d/short/app/usecase/password"
+)
+
+var _ Unlocker = (*UnlockerPersist)(nil)
+
+// ErrIncorrectPassword represents the error of unlocking a password protected
+// URL with a wrong password
+type ErrIncorrectPassword string
+
+func (e ErrIncorrectPassword) Error() string {
+	return fmt.Sprintf("incorrect password (alias=%s)", string(e))
+}
+
+// Unlocker represents the gate keeper of password protected short links
+type Unlocker interface {
+	UnlockURL(ctx context.Context, domain string, alias string, password string, unlockingAt time.Time) (entity.URL, error)
+}
+
+// UnlockerPersist represents a gate keeper which verifies passwords against
+// the hashes persisted along with the short links
+type UnlockerPersist struct {
+	urlRetriever   Retriever
+	passwordHasher password.Hasher
+}
+
+// UnlockURL retrieves the short link with the given alias under the given
+// domain which hasn't expired yet if the password matches, counting the click
+// on success. Short links without passwords are always unlocked.
+func (u UnlockerPersist) UnlockURL(ctx context.Context, domain string, alias string, password string, unlockingAt time.Time) (entity.URL, error) {
+	url, err := u.urlRetriever.GetURL(ctx, domain, alias, &unlockingAt)
+	if err != nil {
+		return entity.URL{}, err
+	}
+
+	if url.PasswordHash != nil && !u.passwordHasher.IsMatch(password, *url.PasswordHash) {
+		return entity.URL{}, ErrIncorrectPassword(alias)
+	}
+
+	err = u.urlRetriever.CountClick(ctx, url)
+	if err != nil {
+		return entity.URL{}, err
+	}
+	return url, nil
+}
+
+// NewUnlockerPersist creates UnlockerPersist
+func NewUnlockerPersist(urlRetriever Retriever, passwordHasher password.Hasher) UnlockerPersist {
+	return UnlockerPersist{
+		urlRetriever:   urlRetriever,
+		passwordHasher: passwordHasher,
+	}
+}
@@ -0,0 +1,129 @@
+// +build !integration all
+
+package url
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/mdtest"
+	"github.com/short-d/short/app/entity"
+	"github.com/short-d/short/app/usecase/password"
+	"github.com/short-d/short/app/usecase/repository"
+)
+
+func TestUnlockerPersist_UnlockURL(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now().UTC()
+	expireAt := now.Add(-time.Hour)
+
+	passwordHasher := password.NewHasher()
+	passwordHash, err := passwordHasher.Hash("open sesame")
+	mdtest.Equal(t, nil, err)
+
+	testCases := []struct {
+		name        string
+		urls        urlMap
+		alias       string
+		password    string
+		expHasErr   bool
+		expectedErr error
+		expectedURL entity.URL
+	}{
+		{
+			name:      "url not found",
+			urls:      urlMap{},
+			alias:     "220uFicCJj",
+			password:  "open sesame",
+			expHasErr: true,
+		},
+		{
+			name: "url expired",
+			urls: urlMap{
+				"220uFicCJj": entity.URL{
+					Alias:        "220uFicCJj",
+					OriginalURL:  "https://www.google.com",
+					ExpireAt:     &expireAt,
+					PasswordHash: &passwordHash,
+				},
+			},
+			alias:     "220uFicCJj",
+			password:  "open sesame",
+			expHasErr: true,
+		},
+		{
+			name: "incorrect password",
+			urls: urlMap{
+				"220uFicCJj": entity.URL{
+					Alias:        "220uFicCJj",
+					OriginalURL:  "https://www.google.com",
+					PasswordHash: &passwordHash,
+				},
+			},
+			alias:       "220uFicCJj",
+			password:    "open barley",
+			expHasErr:   true,
+			expectedErr: ErrIncorrectPassword("220uFicCJj"),
+		},
+		{
+			name: "correct password",
+			urls: urlMap{
+				"220uFicCJj": entity.URL{
+					Alias:        "220uFicCJj",
+					OriginalURL:  "https://www.google.com",
+					PasswordHash: &passwordHash,
+				},
+			},
+			alias:     "220uFicCJj",
+			password:  "open sesame",
+			expHasErr: false,
+			expectedURL: entity.URL{
+				Alias:        "220uFicCJj",
+				OriginalURL:  "https://www.google.com",
+				PasswordHash: &passwordHash,
+			},
+		},
+		{
+			name: "url without password",
+			urls: urlMap{
+				"220uFicCJj": entity.URL{
+					Alias:       "220uFicCJj",
+					OriginalURL: "https://www.google.com",
+				},
+			},
+			alias:     "220uFicCJj",
+			password:  "",
+			expHasErr: false,
+			expectedURL: entity.URL{
+				Alias:       "220uFicCJj",
+				OriginalURL: "https://www.google.com",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			urlRepo := repository.NewURLFake(testCase.urls)
+			publicURLRepo := repository.NewPublicURLFake(nil)
+			userURLRepo := repository.NewUserURLRepoFake(nil, nil, &publicURLRepo)
+			retriever := NewRetrieverPersist(&urlRepo, &userURLRepo, &publicURLRepo)
+			unlocker := NewUnlockerPersist(retriever, passwordHasher)
+
+			url, err := unlocker.UnlockURL(context.Background(), "", testCase.alias, testCase.password, now)
+			if testCase.expHasErr {
+				mdtest.NotEqual(t, nil, err)
+				if testCase.expectedErr != nil {
+					mdtest.Equal(t, testCase.expectedErr, err)
+				}
+				return
+			}
+			mdtest.Equal(t, nil, err)
+			mdtest.Equal(t, testCase.expectedURL, url)
+		})
+	}
+}
@@ -0,0 +1,224 @@
+package url
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/short-d/app/fw"
+	"github.com/short-d/short/app/entity"
+	"github.com/short-d/short/app/usecase/linksafety"
+	"github.com/short-d/short/app/usecase/repository"
+	"github.com/short-d/short/app/usecase/validator"
+)
+
+var _ Updater = (*UpdaterPersist)(nil)
+
+// Field represents an optional attribute of a short link which a patch can
+// clear.
+type Field string
+
+// The constants enumerate all the fields a patch can clear.
+const (
+	FieldExpireAt       Field = "expireAt"
+	FieldActivateAt     Field = "activateAt"
+	FieldUTM            Field = "utm"
+	FieldTitle          Field = "title"
+	FieldRedirectStatus Field = "redirectStatus"
+)
+
+// ErrConflictingPatch represents the error of setting and clearing the same
+// field of a short link in one patch
+type ErrConflictingPatch Field
+
+func (e ErrConflictingPatch) Error() string {
+	return fmt.Sprintf("field can't be both set and cleared (field=%s)", string(e))
+}
+
+// Patch represents the changes to an existing short link. Fields left nil are
+// not changed, while the ones listed in Clear are reset, so that the short
+// link never expires, is active right away, carries no UTM parameters, has
+// no title or redirects with the system default status.
+type Patch struct {
+	OriginalURL    *string
+	ExpireAt       *time.Time
+	ActivateAt     *time.Time
+	IsPublic       *bool
+	ForwardQuery   *bool
+	ForwardPath    *bool
+	UTM            *entity.UTM
+	Title          *string
+	RedirectStatus *int
+	Clear          []Field
+}
+
+// isSet checks whether the patch assigns a new value to the given field.
+func (p Patch) isSet(field Field) bool {
+	switch field {
+	case FieldExpireAt:
+		return p.ExpireAt != nil
+	case FieldActivateAt:
+		return p.ActivateAt != nil
+	case FieldUTM:
+		return p.UTM != nil
+	case FieldTitle:
+		return p.Title != nil
+	case FieldRedirectStatus:
+		return p.RedirectStatus != nil
+	default:
+		return false
+	}
+}
+
+// Updater represents a short link modifier
+type Updater interface {
+	UpdateURL(ctx context.Context, domain string, alias string, patch Patch, user entity.User) (entity.URL, error)
+}
+
+// UpdaterPersist represents a short link modifier which persists the changes
+// in the repository
+type UpdaterPersist struct {
+	urlRepo             repository.URL
+	userURLRelationRepo repository.UserURLRelation
+	publicURLRepo       repository.PublicURL
+	longLinkValidator   validator.LongLink
+	linkChecker         linksafety.Checker
+	timer               fw.Timer
+}
+
+// UpdateURL applies the patch to the short link with the given alias under the
+// given domain if it is created by the given user. The visibility of the short
+// link is changed after the rest of the patch is persisted, in a separate
+// write. When changing the visibility fails, the error is returned without the
+// short link even though the rest of the patch is already applied, so the
+// caller should fetch the short link again before retrying.
+func (u UpdaterPersist) UpdateURL(ctx context.Context, domain string, alias string, patch Patch, user entity.User) (entity.URL, error) {
+	err := checkOwner(ctx, u.urlRepo, u.userURLRelationRepo, domain, alias, user)
+	if err != nil {
+		return entity.URL{}, err
+	}
+
+	url, err := u.urlRepo.GetByAlias(ctx, domain, alias)
+	if err != nil {
+		return entity.URL{}, err
+	}
+
+	for _, field := range patch.Clear {
+		if patch.isSet(field) {
+			return entity.URL{}, ErrConflictingPatch(field)
+		}
+		clearField(&url, field)
+	}
+
+	if patch.OriginalURL != nil {
+		longLink := *patch.OriginalURL
+		if !u.longLinkValidator.IsValid(&longLink) {
+			return entity.URL{}, ErrInvalidLongLink(longLink)
+		}
+
+		err = u.linkChecker.Check(ctx, longLink)
+		if err != nil {
+			return entity.URL{}, err
+		}
+		url.OriginalURL = longLink
+	}
+
+	if patch.ExpireAt != nil {
+		expireAt := *patch.ExpireAt
+		url.ExpireAt = &expireAt
+	}
+
+	if patch.ActivateAt != nil {
+		activateAt := *patch.ActivateAt
+		url.ActivateAt = &activateAt
+	}
+
+	if patch.ForwardQuery != nil {
+		url.ForwardQuery = *patch.ForwardQuery
+	}
+
+	if patch.ForwardPath != nil {
+		url.ForwardPath = *patch.ForwardPath
+	}
+
+	if patch.UTM != nil {
+		utm := *patch.UTM
+		url.UTM = &utm
+	}
+
+	if patch.Title != nil {
+		if !isTitleValid(patch.Title) {
+			return entity.URL{}, ErrInvalidTitle{}
+		}
+		title := *patch.Title
+		url.Title = &title
+	}
+
+	if patch.RedirectStatus != nil {
+		if !IsRedirectStatusValid(patch.RedirectStatus) {
+			return entity.URL{}, ErrInvalidRedirectStatus(*patch.RedirectStatus)
+		}
+		redirectStatus := *patch.RedirectStatus
+		url.RedirectStatus = &redirectStatus
+	}
+
+	if !isActivateAtValid(url) {
+		return entity.URL{}, ErrInvalidActivateAt{}
+	}
+
+	now := u.timer.Now().UTC()
+	url.UpdatedAt = &now
+
+	err = u.urlRepo.Update(ctx, url)
+	if err != nil {
+		return entity.URL{}, err
+	}
+
+	if patch.IsPublic == nil {
+		return url, nil
+	}
+
+	if *patch.IsPublic {
+		err = u.publicURLRepo.Create(ctx, domain, alias)
+	} else {
+		err = u.publicURLRepo.Delete(ctx, domain, alias)
+	}
+	if err != nil {
+		return entity.URL{}, err
+	}
+	return url, nil
+}
+
+func clearField(url *entity.URL, field Field) {
+	switch field {
+	case FieldExpireAt:
+		url.ExpireAt = nil
+	case FieldActivateAt:
+		url.ActivateAt = nil
+	case FieldUTM:
+		url.UTM = nil
+	case FieldTitle:
+		url.Title = nil
+	case FieldRedirectStatus:
+		url.RedirectStatus = nil
+	}
+}
+
+// NewUpdaterPersist creates UpdaterPersist
+func NewUpdaterPersist(
+	urlRepo repository.URL,
+	userURLRelationRepo repository.UserURLRelation,
+	publicURLRepo repository.PublicURL,
+	longLinkValidator validator.LongLink,
+	linkChecker linksafety.Checker,
+	timer fw.Timer,
+) UpdaterPersist {
+	return UpdaterPersist{
+		urlRepo:             urlRepo,
+		userURLRelationRepo: userURLRelationRepo,
+		publicURLRepo:       publicURLRepo,
+		longLinkValidator:   longLinkValidator,
+		linkChecker:         linkChecker,
+		timer:               timer,
+	}
+}
@@ -0,0 +1,359 @@
+// +build !integration all
+
+package url
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/mdtest"
+	"github.com/short-d/short/app/entity"
+	"github.com/short-d/short/app/usecase/linksafety"
+	"github.com/short-d/short/app/usecase/repository"
+	"github.com/short-d/short/app/usecase/service"
+	"github.com/short-d/short/app/usecase/validator"
+)
+
+func TestUpdaterPersist_UpdateURL(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now().UTC()
+	createdAt := now.Add(-time.Hour)
+	expireAt := now.Add(time.Hour)
+	newLongLink := "https://www.short-d.com"
+	invalidLongLink := "invalid"
+	unsafeLongLink := "https://malware.example.com"
+	isPublic := true
+	isPrivate := false
+	isForwarded := true
+	utm := entity.UTM{Source: "newsletter", Medium: "email"}
+	title := "Search engine"
+	movedPermanently := 301
+	okStatus := 200
+
+	owner := entity.User{Email: "alpha@example.com"}
+	otherUser := entity.User{Email: "beta@example.com"}
+
+	testCases := []struct {
+		name             string
+		urls             urlMap
+		relationUsers    []entity.User
+		relationURLs     []entity.URL
+		publicAliases    []string
+		alias            string
+		patch            Patch
+		user             entity.User
+		expHasErr        bool
+		expectedErr      error
+		expectedURL      entity.URL
+		expectedIsPublic bool
+	}{
+		{
+			name:        "url not found",
+			urls:        urlMap{},
+			alias:       "220uFicCJj",
+			patch:       Patch{OriginalURL: &newLongLink},
+			user:        owner,
+			expHasErr:   true,
+			expectedErr: ErrURLNotFound("220uFicCJj"),
+		},
+		{
+			name: "user is not owner",
+			urls: urlMap{
+				"220uFicCJj": entity.URL{
+					Alias:       "220uFicCJj",
+					OriginalURL: "https://www.google.com",
+				},
+			},
+			relationUsers: []entity.User{owner},
+			relationURLs:  []entity.URL{{Alias: "220uFicCJj"}},
+			alias:         "220uFicCJj",
+			patch:         Patch{OriginalURL: &newLongLink},
+			user:          otherUser,
+			expHasErr:     true,
+			expectedErr:   ErrNotURLOwner("220uFicCJj"),
+		},
+		{
+			name: "invalid long link",
+			urls: urlMap{
+				"220uFicCJj": entity.URL{
+					Alias:       "220uFicCJj",
+					OriginalURL: "https://www.google.com",
+				},
+			},
+			relationUsers: []entity.User{owner},
+			relationURLs:  []entity.URL{{Alias: "220uFicCJj"}},
+			alias:         "220uFicCJj",
+			patch:         Patch{OriginalURL: &invalidLongLink},
+			user:          owner,
+			expHasErr:     true,
+			expectedErr:   ErrInvalidLongLink(invalidLongLink),
+		},
+		{
+			name: "unsafe long link",
+			urls: urlMap{
+				"220uFicCJj": entity.URL{
+					Alias:       "220uFicCJj",
+					OriginalURL: "https://www.google.com",
+				},
+			},
+			relationUsers: []entity.User{owner},
+			relationURLs:  []entity.URL{{Alias: "220uFicCJj"}},
+			alias:         "220uFicCJj",
+			patch:         Patch{OriginalURL: &unsafeLongLink},
+			user:          owner,
+			expHasErr:     true,
+			expectedErr:   linksafety.ErrUnsafeLink(linksafety.ReasonBlockedDomain),
+		},
+		{
+			name: "activate after expiration time",
+			urls: urlMap{
+				"220uFicCJj": entity.URL{
+					Alias:       "220uFicCJj",
+					OriginalURL: "https://www.google.com",
+					ExpireAt:    &expireAt,
+				},
+			},
+			relationUsers: []entity.User{owner},
+			relationURLs:  []entity.URL{{Alias: "220uFicCJj"}},
+			alias:         "220uFicCJj",
+			patch:         Patch{ActivateAt: &expireAt},
+			user:          owner,
+			expHasErr:     true,
+			expectedErr:   ErrInvalidActivateAt{},
+		},
+		{
+			name: "update long link and expiration time",
+			urls: urlMap{
+				"220uFicCJj": entity.URL{
+					Alias:       "220uFicCJj",
+					OriginalURL: "https://www.google.com",
+					CreatedAt:   &createdAt,
+				},
+			},
+			relationUsers: []entity.User{owner},
+			relationURLs:  []entity.URL{{Alias: "220uFicCJj"}},
+			alias:         "220uFicCJj",
+			patch: Patch{
+				OriginalURL: &newLongLink,
+				ExpireAt:    &expireAt,
+			},
+			user: owner,
+			expectedURL: entity.URL{
+				Alias:       "220uFicCJj",
+				OriginalURL: newLongLink,
+				ExpireAt:    &expireAt,
+				CreatedAt:   &createdAt,
+				UpdatedAt:   &now,
+			},
+		},
+		{
+			name: "forward visits",
+			urls: urlMap{
+				"220uFicCJj": entity.URL{
+					Alias:       "220uFicCJj",
+					OriginalURL: "https://www.google.com",
+				},
+			},
+			relationUsers: []entity.User{owner},
+			relationURLs:  []entity.URL{{Alias: "220uFicCJj"}},
+			alias:         "220uFicCJj",
+			patch: Patch{
+				ForwardQuery: &isForwarded,
+				ForwardPath:  &isForwarded,
+				UTM:          &utm,
+			},
+			user: owner,
+			expectedURL: entity.URL{
+				Alias:        "220uFicCJj",
+				OriginalURL:  "https://www.google.com",
+				UpdatedAt:    &now,
+				ForwardQuery: true,
+				ForwardPath:  true,
+				UTM:          &utm,
+			},
+		},
+		{
+			name: "redirect status not supported",
+			urls: urlMap{
+				"220uFicCJj": entity.URL{
+					Alias:       "220uFicCJj",
+					OriginalURL: "https://www.google.com",
+				},
+			},
+			relationUsers: []entity.User{owner},
+			relationURLs:  []entity.URL{{Alias: "220uFicCJj"}},
+			alias:         "220uFicCJj",
+			patch:         Patch{RedirectStatus: &okStatus},
+			user:          owner,
+			expHasErr:     true,
+			expectedErr:   ErrInvalidRedirectStatus(okStatus),
+		},
+		{
+			name: "update title and redirect status",
+			urls: urlMap{
+				"220uFicCJj": entity.URL{
+					Alias:       "220uFicCJj",
+					OriginalURL: "https://www.google.com",
+				},
+			},
+			relationUsers: []entity.User{owner},
+			relationURLs:  []entity.URL{{Alias: "220uFicCJj"}},
+			alias:         "220uFicCJj",
+			patch: Patch{
+				Title:          &title,
+				RedirectStatus: &movedPermanently,
+			},
+			user: owner,
+			expectedURL: entity.URL{
+				Alias:          "220uFicCJj",
+				OriginalURL:    "https://www.google.com",
+				UpdatedAt:      &now,
+				Title:          &title,
+				RedirectStatus: &movedPermanently,
+			},
+		},
+		{
+			name: "make url public",
+			urls: urlMap{
+				"220uFicCJj": entity.URL{
+					Alias:       "220uFicCJj",
+					OriginalURL: "https://www.google.com",
+				},
+			},
+			relationUsers: []entity.User{owner},
+			relationURLs:  []entity.URL{{Alias: "220uFicCJj"}},
+			alias:         "220uFicCJj",
+			patch:         Patch{IsPublic: &isPublic},
+			user:          owner,
+			expectedURL: entity.URL{
+				Alias:       "220uFicCJj",
+				OriginalURL: "https://www.google.com",
+				UpdatedAt:   &now,
+			},
+			expectedIsPublic: true,
+		},
+		{
+			name: "make url private",
+			urls: urlMap{
+				"220uFicCJj": entity.URL{
+					Alias:       "220uFicCJj",
+					OriginalURL: "https://www.google.com",
+				},
+			},
+			relationUsers: []entity.User{owner},
+			relationURLs:  []entity.URL{{Alias: "220uFicCJj"}},
+			publicAliases: []string{"220uFicCJj"},
+			alias:         "220uFicCJj",
+			patch:         Patch{IsPublic: &isPrivate},
+			user:          owner,
+			expectedURL: entity.URL{
+				Alias:       "220uFicCJj",
+				OriginalURL: "https://www.google.com",
+				UpdatedAt:   &now,
+			},
+			expectedIsPublic: false,
+		},
+		{
+			name: "clear optional fields",
+			urls: urlMap{
+				"220uFicCJj": entity.URL{
+					Alias:          "220uFicCJj",
+					OriginalURL:    "https://www.google.com",
+					ExpireAt:       &expireAt,
+					ActivateAt:     &createdAt,
+					UTM:            &utm,
+					Title:          &title,
+					RedirectStatus: &movedPermanently,
+				},
+			},
+			relationUsers: []entity.User{owner},
+			relationURLs:  []entity.URL{{Alias: "220uFicCJj"}},
+			alias:         "220uFicCJj",
+			patch: Patch{
+				Clear: []Field{
+					FieldExpireAt,
+					FieldActivateAt,
+					FieldUTM,
+					FieldTitle,
+					FieldRedirectStatus,
+				},
+			},
+			user: owner,
+			expectedURL: entity.URL{
+				Alias:       "220uFicCJj",
+				OriginalURL: "https://www.google.com",
+				UpdatedAt:   &now,
+			},
+		},
+		{
+			name: "set and clear the same field",
+			urls: urlMap{
+				"220uFicCJj": entity.URL{
+					Alias:       "220uFicCJj",
+					OriginalURL: "https://www.google.com",
+				},
+			},
+			relationUsers: []entity.User{owner},
+			relationURLs:  []entity.URL{{Alias: "220uFicCJj"}},
+			alias:         "220uFicCJj",
+			patch: Patch{
+				ExpireAt: &expireAt,
+				Clear:    []Field{FieldExpireAt},
+			},
+			user:        owner,
+			expHasErr:   true,
+			expectedErr: ErrConflictingPatch(FieldExpireAt),
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			urlRepo := repository.NewURLFake(testCase.urls)
+			userURLRepo := repository.NewUserURLRepoFake(
+				testCase.relationUsers,
+				testCase.relationURLs,
+				nil,
+			)
+			publicURLRepo := repository.NewPublicURLFake(testCase.publicAliases)
+			longLinkValidator := validator.NewLongLink()
+			linkChecker := linksafety.NewChecker(
+				linksafety.Blocklist{Domains: []string{"malware.example.com"}},
+				nil,
+				service.NewRedirectTracerFake(nil),
+				0,
+				0,
+			)
+			timer := mdtest.NewTimerFake(now)
+
+			updater := NewUpdaterPersist(
+				&urlRepo,
+				&userURLRepo,
+				&publicURLRepo,
+				longLinkValidator,
+				linkChecker,
+				timer,
+			)
+
+			url, err := updater.UpdateURL(context.Background(), "", testCase.alias, testCase.patch, testCase.user)
+			if testCase.expHasErr {
+				mdtest.Equal(t, testCase.expectedErr, err)
+				return
+			}
+			mdtest.Equal(t, nil, err)
+			mdtest.Equal(t, testCase.expectedURL, url)
+
+			savedURL, err := urlRepo.GetByAlias(context.Background(), "", testCase.alias)
+			mdtest.Equal(t, nil, err)
+			mdtest.Equal(t, testCase.expectedURL, savedURL)
+
+			isPublic, err := publicURLRepo.IsPublic(context.Background(), "", testCase.alias)
+			mdtest.Equal(t, nil, err)
+			mdtest.Equal(t, testCase.expectedIsPublic, isPublic)
+		})
+	}
+}
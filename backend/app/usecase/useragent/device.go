@@ -0,0 +1,57 @@
+package useragent
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Device represents the class of device a visitor uses.
+type Device string
+
+// The constants enumerate all supported device classes.
+const (
+	DeviceUnknown Device = "unknown"
+	DeviceDesktop Device = "desktop"
+	DeviceMobile  Device = "mobile"
+	DeviceTablet  Device = "tablet"
+	DeviceBot     Device = "bot"
+)
+
+var (
+	botPattern     = regexp.MustCompile(`(?i)bot|crawl|spider|slurp|curl|wget|python-requests|go-http-client|facebookexternalhit`)
+	tabletPattern  = regexp.MustCompile(`(?i)ipad|tablet|kindle|silk|playbook`)
+	androidPattern = regexp.MustCompile(`(?i)android`)
+	mobilePattern  = regexp.MustCompile(`(?i)mobi|iphone|ipod|blackberry|opera mini|windows phone`)
+	desktopPattern = regexp.MustCompile(`(?i)windows nt|macintosh|x11|linux|cros`)
+)
+
+// ParseDevice classifies the device of a visitor given its User-Agent header.
+func ParseDevice(userAgent string) Device {
+	userAgent = strings.TrimSpace(userAgent)
+	if userAgent == "" {
+		return DeviceUnknown
+	}
+
+	if botPattern.MatchString(userAgent) {
+		return DeviceBot
+	}
+
+	if tabletPattern.MatchString(userAgent) {
+		return DeviceTablet
+	}
+
+	isMobile := mobilePattern.MatchString(userAgent)
+	if androidPattern.MatchString(userAgent) && !isMobile {
+		// Android tablets omit "Mobile" from their User-Agent.
+		return DeviceTablet
+	}
+
+	if isMobile {
+		return DeviceMobile
+	}
+
+	if desktopPattern.MatchString(userAgent) {
+		return DeviceDesktop
+	}
+	return DeviceUnknown
+}
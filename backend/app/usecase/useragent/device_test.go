@@ -0,0 +1,78 @@
+// +build !integration all
+
+package useragent
+
+import (
+	"testing"
+
+	"github.com/short-d/app/mdtest"
+)
+
+func TestParseDevice(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name           string
+		userAgent      string
+		expectedDevice Device
+	}{
+		{
+			name:           "empty user agent",
+			userAgent:      "",
+			expectedDevice: DeviceUnknown,
+		},
+		{
+			name:           "unrecognized user agent",
+			userAgent:      "SomeClient/1.0",
+			expectedDevice: DeviceUnknown,
+		},
+		{
+			name:           "Googlebot",
+			userAgent:      "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)",
+			expectedDevice: DeviceBot,
+		},
+		{
+			name:           "curl",
+			userAgent:      "curl/7.64.1",
+			expectedDevice: DeviceBot,
+		},
+		{
+			name:           "iPad",
+			userAgent:      "Mozilla/5.0 (iPad; CPU OS 13_3 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/13.0.4 Mobile/15E148 Safari/604.1",
+			expectedDevice: DeviceTablet,
+		},
+		{
+			name:           "Android tablet",
+			userAgent:      "Mozilla/5.0 (Linux; Android 9; SM-T820) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/79.0.3945.136 Safari/537.36",
+			expectedDevice: DeviceTablet,
+		},
+		{
+			name:           "Android phone",
+			userAgent:      "Mozilla/5.0 (Linux; Android 10; Pixel 3) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/79.0.3945.136 Mobile Safari/537.36",
+			expectedDevice: DeviceMobile,
+		},
+		{
+			name:           "iPhone",
+			userAgent:      "Mozilla/5.0 (iPhone; CPU iPhone OS 13_3 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/13.0.4 Mobile/15E148 Safari/604.1",
+			expectedDevice: DeviceMobile,
+		},
+		{
+			name:           "Windows desktop",
+			userAgent:      "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/79.0.3945.130 Safari/537.36",
+			expectedDevice: DeviceDesktop,
+		},
+		{
+			name:           "Mac desktop",
+			userAgent:      "Mozilla/5.0 (Macintosh; Intel Mac OS X 10.15; rv:72.0) Gecko/20100101 Firefox/72.0",
+			expectedDevice: DeviceDesktop,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			mdtest.Equal(t, testCase.expectedDevice, ParseDevice(testCase.userAgent))
+		})
+	}
+}
@@ -1,9 +1,13 @@
 package validator
 
-import "regexp"
+import (
+	"regexp"
+	"strings"
+)
 
 const (
 	customAliasMaxLength = 50
+	previewSuffix        = "+"
 )
 
 // CustomAlias represents format validator for custom alias
@@ -11,7 +15,8 @@ type CustomAlias struct {
 	uriPattern *regexp.Regexp
 }
 
-// IsValid checks whether the given alias has valid format.
+// IsValid checks whether the given alias has valid format. Aliases can't end
+// with "+", which is reserved for previewing short links.
 func (c CustomAlias) IsValid(alias *string) bool {
 	if alias == nil {
 		return true
@@ -24,6 +29,10 @@ func (c CustomAlias) IsValid(alias *string) bool {
 	if len(*alias) >= customAliasMaxLength {
 		return false
 	}
+
+	if strings.HasSuffix(*alias, previewSuffix) {
+		return false
+	}
 	return true
 }
 
@@ -26,6 +26,11 @@ func TestCustomAlias_IsValid(t *testing.T) {
 			alias:      strings.Repeat("helloworld", 5),
 			expIsValid: false,
 		},
+		{
+			name:       "alias reserved for preview",
+			alias:      "fb+",
+			expIsValid: false,
+		},
 		{
 			name:       "alias valid",
 			alias:      "fb",
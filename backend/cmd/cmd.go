@@ -25,12 +25,54 @@ type ServiceConfig struct {
 	GoogleRedirectURI    string
 	JwtSecret            string
 	WebFrontendURL       string
+	ComingSoonURL        string
+	RedirectStatus       int
 	GraphQLAPIPort       int
 	HTTPAPIPort          int
 	KeyGenBufferSize     int
+	KeyGenLowWaterMark   int
 	KgsHostname          string
 	KgsPort              int
+	KgsPlaintext         bool
+	KgsCACertFile        string
+	KgsClientCertFile    string
+	KgsClientKeyFile     string
+	KgsServerName        string
+	KgsCallTimeout       time.Duration
+	KgsMaxAttempts       int
+	KgsInitialBackoff    time.Duration
+	KgsMaxBackoff        time.Duration
+	KgsFailureThreshold  int
+	KgsOpenDuration      time.Duration
+	KeyGenSource         string
+	KeyGenLocalKeyLength int
 	AuthTokenLifetime    time.Duration
+	ClickBufferSize      int
+	ClickBatchSize       int
+	ClickFlushInterval   time.Duration
+	RateLimitBackend     string
+	CreateURLRateLimit   int
+	CreateURLRatePeriod  time.Duration
+	RedirectRateLimit    int
+	RedirectRatePeriod   time.Duration
+	UnlockURLRateLimit   int
+	UnlockURLRatePeriod  time.Duration
+	BlockedDomainsFile   string
+	BlockedPatternsFile  string
+	ShortLinkHostnames   string
+	LinkRedirectMaxHops  int
+	LinkRedirectTimeout  time.Duration
+	LinkCheckTimeout     time.Duration
+	DomainVerifyTimeout  time.Duration
+	TrustedProxies       string
+	GeoIPDatabaseFile    string
+	URLCacheEnabled      bool
+	URLCacheCapacity     int
+	URLCacheTTL          time.Duration
+	URLCacheNegativeTTL  time.Duration
+	MetricsToken         string
+	RequestTimeout       time.Duration
+	ShutdownTimeout      time.Duration
 }
 
 // NewRootCmd creates the base command.
@@ -64,12 +106,54 @@ func NewRootCmd(
 					GoogleRedirectURI:    config.GoogleRedirectURI,
 					JwtSecret:            config.JwtSecret,
 					WebFrontendURL:       config.WebFrontendURL,
+					ComingSoonURL:        config.ComingSoonURL,
+					RedirectStatus:       config.RedirectStatus,
 					GraphQLAPIPort:       config.GraphQLAPIPort,
 					HTTPAPIPort:          config.HTTPAPIPort,
 					KeyGenBufferSize:     config.KeyGenBufferSize,
+					KeyGenLowWaterMark:   config.KeyGenLowWaterMark,
 					KgsHostname:          config.KgsHostname,
 					KgsPort:              config.KgsPort,
+					KgsPlaintext:         config.KgsPlaintext,
+					KgsCACertFile:        config.KgsCACertFile,
+					KgsClientCertFile:    config.KgsClientCertFile,
+					KgsClientKeyFile:     config.KgsClientKeyFile,
+					KgsServerName:        config.KgsServerName,
+					KgsCallTimeout:       config.KgsCallTimeout,
+					KgsMaxAttempts:       config.KgsMaxAttempts,
+					KgsInitialBackoff:    config.KgsInitialBackoff,
+					KgsMaxBackoff:        config.KgsMaxBackoff,
+					KgsFailureThreshold:  config.KgsFailureThreshold,
+					KgsOpenDuration:      config.KgsOpenDuration,
+					KeyGenSource:         config.KeyGenSource,
+					KeyGenLocalKeyLength: config.KeyGenLocalKeyLength,
 					AuthTokenLifetime:    config.AuthTokenLifetime,
+					ClickBufferSize:      config.ClickBufferSize,
+					ClickBatchSize:       config.ClickBatchSize,
+					ClickFlushInterval:   config.ClickFlushInterval,
+					RateLimitBackend:     config.RateLimitBackend,
+					CreateURLRateLimit:   config.CreateURLRateLimit,
+					CreateURLRatePeriod:  config.CreateURLRatePeriod,
+					RedirectRateLimit:    config.RedirectRateLimit,
+					RedirectRatePeriod:   config.RedirectRatePeriod,
+					UnlockURLRateLimit:   config.UnlockURLRateLimit,
+					UnlockURLRatePeriod:  config.UnlockURLRatePeriod,
+					BlockedDomainsFile:   config.BlockedDomainsFile,
+					BlockedPatternsFile:  config.BlockedPatternsFile,
+					ShortLinkHostnames:   config.ShortLinkHostnames,
+					LinkRedirectMaxHops:  config.LinkRedirectMaxHops,
+					LinkRedirectTimeout:  config.LinkRedirectTimeout,
+					LinkCheckTimeout:     config.LinkCheckTimeout,
+					DomainVerifyTimeout:  config.DomainVerifyTimeout,
+					TrustedProxies:       config.TrustedProxies,
+					GeoIPDatabaseFile:    config.GeoIPDatabaseFile,
+					URLCacheEnabled:      config.URLCacheEnabled,
+					URLCacheCapacity:     config.URLCacheCapacity,
+					URLCacheTTL:          config.URLCacheTTL,
+					URLCacheNegativeTTL:  config.URLCacheNegativeTTL,
+					MetricsToken:         config.MetricsToken,
+					RequestTimeout:       config.RequestTimeout,
+					ShutdownTimeout:      config.ShutdownTimeout,
 				}
 
 				app.Start(
@@ -0,0 +1,33 @@
+package provider
+
+import (
+	"time"
+
+	"github.com/short-d/app/fw"
+	"github.com/short-d/short/app/usecase/analytics"
+	"github.com/short-d/short/app/usecase/repository"
+)
+
+// ClickRecorderConfig includes buffering parameters for persisting clicks in
+// batch.
+type ClickRecorderConfig struct {
+	BufferSize    int
+	BatchSize     int
+	FlushInterval time.Duration
+}
+
+// NewBatchRecorder creates BatchRecorder with ClickRecorderConfig to uniquely
+// identify buffering parameters during dependency injection.
+func NewBatchRecorder(
+	config ClickRecorderConfig,
+	clickRepo repository.Click,
+	logger fw.Logger,
+) (analytics.BatchRecorder, error) {
+	return analytics.NewBatchRecorder(
+		clickRepo,
+		logger,
+		config.BufferSize,
+		config.BatchSize,
+		config.FlushInterval,
+	)
+}
@@ -0,0 +1,36 @@
+package provider
+
+import (
+	"time"
+
+	"github.com/short-d/app/fw"
+	"github.com/short-d/short/app/adapter/domainverifier"
+	"github.com/short-d/short/app/usecase/customdomain"
+	"github.com/short-d/short/app/usecase/repository"
+	"github.com/short-d/short/app/usecase/service"
+)
+
+// DomainVerifyTimeout represents how long fetching the challenge of a custom
+// domain is allowed to take.
+type DomainVerifyTimeout time.Duration
+
+// DefaultHostnames represents the hostnames serving short links under the
+// default domain rather than a custom domain.
+type DefaultHostnames []string
+
+// NewDomainVerifier creates domain verifier which gives up fetching challenges
+// after DomainVerifyTimeout.
+func NewDomainVerifier(timeout DomainVerifyTimeout) domainverifier.Verifier {
+	return domainverifier.NewVerifier(time.Duration(timeout))
+}
+
+// NewDomainManager creates custom domain Manager which resolves
+// DefaultHostnames without looking them up in storage.
+func NewDomainManager(
+	domainRepo repository.Domain,
+	verifier service.DomainVerifier,
+	timer fw.Timer,
+	defaultHostnames DefaultHostnames,
+) customdomain.Manager {
+	return customdomain.NewManager(domainRepo, verifier, timer, defaultHostnames)
+}
@@ -0,0 +1,26 @@
+package provider
+
+import (
+	"os"
+
+	"github.com/short-d/short/app/adapter/geoip"
+)
+
+// GeoIPDatabaseFile represents the path of the local GeoIP database in CSV
+// format
+type GeoIPDatabaseFile string
+
+// NewGeoLocator creates geoip Locator with the IP ranges loaded from the
+// database file. Visitors can't be located when the path is empty.
+func NewGeoLocator(databaseFile GeoIPDatabaseFile) (geoip.Locator, error) {
+	if databaseFile == "" {
+		return geoip.Locator{}, nil
+	}
+
+	file, err := os.Open(string(databaseFile))
+	if err != nil {
+		return geoip.Locator{}, err
+	}
+	defer file.Close()
+	return geoip.ParseDatabase(file)
+}
@@ -1,14 +1,46 @@
 package provider
 
 import (
+	"net/http"
+	"time"
+
+	graphqlgo "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
 	"github.com/short-d/app/fw"
-	"github.com/short-d/app/modern/mdgraphql"
+	"github.com/short-d/app/modern/mdhttp"
+	"github.com/short-d/short/app/adapter/graphql"
 )
 
 // GraphQlPath represents the path for GraphQL APIs.
 type GraphQlPath string
 
 // NewGraphGophers creates GraphGopher GraphQL server with GraphQlPath to uniquely identify graphqlPath during dependency injection.
-func NewGraphGophers(graphqlPath GraphQlPath, logger fw.Logger, tracer fw.Tracer, g fw.GraphQLAPI) fw.Server {
-	return mdgraphql.NewGraphGophers(string(graphqlPath), logger, tracer, g)
+// The latency of each resolver is measured, and the metrics are exported on
+// /metrics to the scrapers presenting MetricsToken. Queries are answered with 503 once they run longer than
+// RequestTimeout.
+func NewGraphGophers(
+	graphqlPath GraphQlPath,
+	requestTimeout RequestTimeout,
+	logger fw.Logger,
+	tracer fw.Tracer,
+	g fw.GraphQLAPI,
+	metrics Metrics,
+	timer fw.Timer,
+) fw.Server {
+	schema := graphqlgo.MustParseSchema(
+		g.GetSchema(),
+		g.GetResolver(),
+		graphqlgo.UseStringDescriptions(),
+		graphqlgo.Tracer(graphql.NewLatencyTracer(metrics.resolverLatency, timer)),
+	)
+
+	server := mdhttp.NewServer(logger, tracer)
+	handler := http.TimeoutHandler(
+		&relay.Handler{Schema: schema},
+		time.Duration(requestTimeout),
+		`{"errors":[{"message":"request timed out"}]}`,
+	)
+	server.HandleFunc(string(graphqlPath), handler)
+	server.HandleFunc("/metrics", metrics.exporter)
+	return &server
 }
@@ -0,0 +1,43 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/short-d/short/app/adapter/db"
+	"github.com/short-d/short/app/usecase/health"
+	"github.com/short-d/short/app/usecase/keygen"
+)
+
+const healthCheckTimeout = 3 * time.Second
+
+// MigrationRoot represents the directory of database migrations
+type MigrationRoot string
+
+// NewHealthChecker creates health Checker which probes the connection to the
+// database, whether all the migrations under MigrationRoot are applied, and
+// whether the buffer of KeyGenerator can be refilled.
+func NewHealthChecker(
+	sqlDB *sql.DB,
+	migrationRoot MigrationRoot,
+	keyGenerator keygen.KeyGenerator,
+) health.Checker {
+	migrationStatus := db.NewMigrationStatusSQL(sqlDB, string(migrationRoot))
+	return health.NewChecker(map[string]health.Probe{
+		"database": sqlDB.PingContext,
+		"migrations": func(ctx context.Context) error {
+			pending, err := migrationStatus.FindPendingMigrations(ctx)
+			if err != nil {
+				return err
+			}
+			if len(pending) > 0 {
+				return fmt.Errorf("migrations not applied: %s", strings.Join(pending, ", "))
+			}
+			return nil
+		},
+		"keyGenerator": keyGenerator.CheckRefill,
+	}, healthCheckTimeout)
+}
@@ -1,18 +1,82 @@
 package provider
 
 import (
+	"fmt"
+
+	"github.com/short-d/app/fw"
+	"github.com/short-d/short/app/adapter/kgs"
 	"github.com/short-d/short/app/usecase/keygen"
+	"github.com/short-d/short/app/usecase/repository"
 	"github.com/short-d/short/app/usecase/service"
 )
 
+// The constants enumerate all supported sources of keys.
+const (
+	KeyFetcherKgs                  = "kgs"
+	KeyFetcherLocal                = "local"
+	KeyFetcherKgsWithLocalFallback = "kgs_with_local_fallback"
+)
+
 // KeyGenBufferSize specifies the size of the local cache for fetched keys
 type KeyGenBufferSize int
 
+// KeyGenLowWaterMark specifies how few keys are left in the local cache before
+// it is refilled in the background.
+type KeyGenLowWaterMark int
+
+// KeyFetcherConfig includes the source of keys and the length of the keys
+// generated locally.
+type KeyFetcherConfig struct {
+	Source         string
+	LocalKeyLength int
+}
+
+// NewKeyFetcher creates KeyFetcher with the source in KeyFetcherConfig. The
+// local source generates keys in process without key generation service, while
+// kgs_with_local_fallback only generates keys locally when key generation
+// service fails.
+func NewKeyFetcher(
+	config KeyFetcherConfig,
+	kgsRPC kgs.RPC,
+	urlRepo repository.URL,
+	logger fw.Logger,
+) (service.KeyFetcher, error) {
+	switch config.Source {
+	case KeyFetcherKgs:
+		return kgsRPC, nil
+	case KeyFetcherLocal:
+		return keygen.NewLocalKeyFetcher(config.LocalKeyLength, urlRepo)
+	case KeyFetcherKgsWithLocalFallback:
+		localKeyFetcher, err := keygen.NewLocalKeyFetcher(config.LocalKeyLength, urlRepo)
+		if err != nil {
+			return nil, err
+		}
+		return keygen.NewFallbackKeyFetcher(kgsRPC, localKeyFetcher, logger), nil
+	default:
+		return nil, fmt.Errorf("unknown key fetcher (source=%s)", config.Source)
+	}
+}
+
 // NewKeyGenerator creates KeyGenerator with KeyGenBufferSize to uniquely identify
-// bufferSize
+// bufferSize, refilling the buffer once it holds fewer keys than
+// KeyGenLowWaterMark. The depth of the buffer is exported with metrics.
 func NewKeyGenerator(
 	bufferSize KeyGenBufferSize,
+	lowWaterMark KeyGenLowWaterMark,
 	keyFetcher service.KeyFetcher,
+	metrics Metrics,
+	timer fw.Timer,
 ) (keygen.KeyGenerator, error) {
-	return keygen.NewKeyGenerator(int(bufferSize), keyFetcher)
+	keyGenerator, err := keygen.NewKeyGenerator(
+		int(bufferSize),
+		int(lowWaterMark),
+		keyFetcher,
+		metrics.keyGenRefillCounter,
+		timer,
+	)
+	if err != nil {
+		return keygen.KeyGenerator{}, err
+	}
+	metrics.observeKeyGenerator(keyGenerator)
+	return keyGenerator, nil
 }
@@ -0,0 +1,76 @@
+package provider
+
+import (
+	"os"
+	"regexp"
+	"time"
+
+	"github.com/short-d/short/app/adapter/redirect"
+	"github.com/short-d/short/app/usecase/linksafety"
+)
+
+// LinkSafetyConfig includes the blocklist files, the hostnames serving short
+// links and the limits of following the redirects of long links. Following
+// redirects takes at most CheckTimeout for each check. Blocklist
+// files are skipped when their paths are empty.
+type LinkSafetyConfig struct {
+	BlockedDomainsFile  string
+	BlockedPatternsFile string
+	ShortLinkHostnames  []string
+	MaxRedirectHops     int
+	RedirectTimeout     time.Duration
+	CheckTimeout        time.Duration
+}
+
+// NewLinkSafetyChecker creates link safety Checker with the blocklists loaded
+// from the files in LinkSafetyConfig.
+func NewLinkSafetyChecker(config LinkSafetyConfig) (linksafety.Checker, error) {
+	domains, err := loadBlockedDomains(config.BlockedDomainsFile)
+	if err != nil {
+		return linksafety.Checker{}, err
+	}
+
+	patterns, err := loadBlockedPatterns(config.BlockedPatternsFile)
+	if err != nil {
+		return linksafety.Checker{}, err
+	}
+
+	blocklist := linksafety.Blocklist{
+		Domains:  domains,
+		Patterns: patterns,
+	}
+	tracer := redirect.NewTracer(config.RedirectTimeout)
+	return linksafety.NewChecker(
+		blocklist,
+		config.ShortLinkHostnames,
+		tracer,
+		config.MaxRedirectHops,
+		config.CheckTimeout,
+	), nil
+}
+
+func loadBlockedDomains(path string) ([]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return linksafety.ParseDomains(file)
+}
+
+func loadBlockedPatterns(path string) ([]*regexp.Regexp, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return linksafety.ParsePatterns(file)
+}
@@ -0,0 +1,112 @@
+package provider
+
+import (
+	"github.com/short-d/short/app/adapter/prometheus"
+	"github.com/short-d/short/app/usecase/keygen"
+)
+
+// MetricsToken represents the bearer token Prometheus presents to scrape the
+// metrics. The metrics are not exported when it is empty.
+type MetricsToken string
+
+// Metrics represents the measurements of the system, shared by all the
+// services running in the same process and exported in Prometheus text
+// format.
+type Metrics struct {
+	registry            prometheus.Registry
+	exporter            prometheus.Exporter
+	redirectCounter     prometheus.Counter
+	signInCounter       prometheus.Counter
+	resolverLatency     prometheus.Histogram
+	keyGenRefillCounter prometheus.Counter
+}
+
+// NewMetrics declares the metrics of the system, including the hits and
+// misses of URLCache when it is enabled, and exports them to the scrapers
+// presenting metricsToken.
+func NewMetrics(urlCache URLCache, metricsToken MetricsToken) Metrics {
+	registry := prometheus.NewRegistry()
+	metrics := Metrics{
+		registry: registry,
+		exporter: prometheus.NewExporter(registry, string(metricsToken)),
+		redirectCounter: registry.NewCounter(
+			"short_redirects_total",
+			"Visits to short links by outcome.",
+			"outcome",
+		),
+		signInCounter: registry.NewCounter(
+			"short_sso_sign_ins_total",
+			"Single sign ons by identity provider and result.",
+			"provider",
+			"result",
+		),
+		resolverLatency: registry.NewHistogram(
+			"short_graphql_resolver_duration_seconds",
+			"Time taken to resolve GraphQL fields.",
+			prometheus.DefaultBuckets,
+			"type",
+			"field",
+		),
+		keyGenRefillCounter: registry.NewCounter(
+			"short_keygen_buffer_refills_total",
+			"Refills of the key generation buffer by result.",
+			"result",
+		),
+	}
+
+	_, isEnabled := urlCache.Stats()
+	if !isEnabled {
+		return metrics
+	}
+	registry.NewCounterFunc(
+		"short_url_cache_hits_total",
+		"Alias look ups answered by the URL cache.",
+		func() float64 {
+			stats, _ := urlCache.Stats()
+			return float64(stats.Hits)
+		},
+	)
+	registry.NewCounterFunc(
+		"short_url_cache_misses_total",
+		"Alias look ups read through to the database.",
+		func() float64 {
+			stats, _ := urlCache.Stats()
+			return float64(stats.Misses)
+		},
+	)
+	registry.NewGaugeFunc(
+		"short_url_cache_size",
+		"URLs held by the URL cache.",
+		func() float64 {
+			stats, _ := urlCache.Stats()
+			return float64(stats.Size)
+		},
+	)
+	return metrics
+}
+
+// observeKeyGenerator exports the depth of the buffer of keyGenerator. It must
+// be called at most once, with the KeyGenerator shared by the services.
+func (m Metrics) observeKeyGenerator(keyGenerator keygen.KeyGenerator) {
+	m.registry.NewGaugeFunc(
+		"short_keygen_buffer_keys",
+		"Keys held by the key generation buffer.",
+		func() float64 {
+			return float64(keyGenerator.Stats().Depth)
+		},
+	)
+	m.registry.NewGaugeFunc(
+		"short_keygen_buffer_capacity",
+		"Keys the key generation buffer holds when it is full.",
+		func() float64 {
+			return float64(keyGenerator.Stats().Capacity)
+		},
+	)
+	m.registry.NewGaugeFunc(
+		"short_keygen_waiters",
+		"Requests waiting for the key generation buffer to be refilled.",
+		func() float64 {
+			return float64(keyGenerator.Stats().Waiters)
+		},
+	)
+}
@@ -0,0 +1,57 @@
+package provider
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/short-d/app/fw"
+	"github.com/short-d/short/app/adapter/db"
+	"github.com/short-d/short/app/adapter/memory"
+	"github.com/short-d/short/app/entity"
+	"github.com/short-d/short/app/usecase/ratelimit"
+	"github.com/short-d/short/app/usecase/repository"
+)
+
+// The constants enumerate all supported storages of rate limiting token
+// buckets.
+const (
+	RateLimitBackendMemory   = "memory"
+	RateLimitBackendPostgres = "postgres"
+)
+
+// RateLimitConfig includes the storage of token buckets and the rate limit of
+// each action.
+type RateLimitConfig struct {
+	Backend   string
+	CreateURL entity.RateLimit
+	Redirect  entity.RateLimit
+	UnlockURL entity.RateLimit
+}
+
+// NewTokenBucket creates TokenBucket repository with the backend in
+// RateLimitConfig. The memory backend only limits requests to a single
+// instance while the postgres backend shares limits across instances.
+func NewTokenBucket(config RateLimitConfig, sqlDB *sql.DB) (repository.TokenBucket, error) {
+	switch config.Backend {
+	case RateLimitBackendMemory:
+		return memory.NewTokenBucket(), nil
+	case RateLimitBackendPostgres:
+		return db.NewTokenBucketSQL(sqlDB), nil
+	default:
+		return nil, fmt.Errorf("unknown rate limit backend (backend=%s)", config.Backend)
+	}
+}
+
+// NewRateLimiter creates Limiter with RateLimitConfig to uniquely identify
+// the rate limit of each action during dependency injection.
+func NewRateLimiter(
+	config RateLimitConfig,
+	tokenBucketRepo repository.TokenBucket,
+	timer fw.Timer,
+) ratelimit.Limiter {
+	return ratelimit.NewLimiter(tokenBucketRepo, timer, map[ratelimit.Action]entity.RateLimit{
+		ratelimit.ActionCreateURL: config.CreateURL,
+		ratelimit.ActionRedirect:  config.Redirect,
+		ratelimit.ActionUnlockURL: config.UnlockURL,
+	})
+}
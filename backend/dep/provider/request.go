@@ -0,0 +1,25 @@
+package provider
+
+import (
+	"time"
+
+	"github.com/short-d/short/app/adapter/routing"
+)
+
+// RequestTimeout represents how long requests are served before their
+// contexts are cancelled.
+type RequestTimeout time.Duration
+
+// TrustedProxies represents the IP addresses and CIDR ranges of the reverse
+// proxies allowed to report the address of visitors in X-Forwarded-For header
+type TrustedProxies []string
+
+// NewClientIPResolver creates routing ClientIPResolver which only honors
+// X-Forwarded-For header set by the trusted proxies.
+func NewClientIPResolver(trustedProxies TrustedProxies) (routing.ClientIPResolver, error) {
+	networks, err := routing.ParseTrustedProxies(trustedProxies)
+	if err != nil {
+		return routing.ClientIPResolver{}, err
+	}
+	return routing.NewClientIPResolver(networks), nil
+}
@@ -1,46 +1,89 @@
 package provider
 
 import (
+	"time"
+
 	"github.com/short-d/app/fw"
 	"github.com/short-d/short/app/adapter/facebook"
 	"github.com/short-d/short/app/adapter/github"
 	"github.com/short-d/short/app/adapter/google"
 	"github.com/short-d/short/app/adapter/routing"
 	"github.com/short-d/short/app/usecase/account"
+	"github.com/short-d/short/app/usecase/analytics"
 	"github.com/short-d/short/app/usecase/auth"
+	"github.com/short-d/short/app/usecase/customdomain"
+	"github.com/short-d/short/app/usecase/health"
+	"github.com/short-d/short/app/usecase/ratelimit"
+	"github.com/short-d/short/app/usecase/redirectrule"
 	"github.com/short-d/short/app/usecase/url"
 )
 
 // WebFrontendURL represents the URL of the web frontend
 type WebFrontendURL string
 
+// ComingSoonURL represents the destination of the short links which are not
+// activated yet
+type ComingSoonURL string
+
+// DefaultRedirectStatus represents the HTTP status code visitors are
+// redirected with when their short links don't specify one
+type DefaultRedirectStatus int
+
 // NewShortRoutes creates HTTP routes for Short API with WwwRoot to uniquely identify WwwRoot during dependency injection.
 func NewShortRoutes(
 	logger fw.Logger,
 	tracer fw.Tracer,
+	metrics Metrics,
 	webFrontendURL WebFrontendURL,
+	comingSoonURL ComingSoonURL,
+	defaultRedirectStatus DefaultRedirectStatus,
+	requestTimeout RequestTimeout,
 	timer fw.Timer,
 	urlRetriever url.Retriever,
+	urlCreator url.Creator,
+	urlUnlocker url.Unlocker,
+	domainManager customdomain.Manager,
+	ruleResolver redirectrule.Resolver,
+	clickRecorder analytics.Recorder,
+	rateLimiter ratelimit.Limiter,
+	ipResolver routing.ClientIPResolver,
 	githubAPI github.API,
 	facebookAPI facebook.API,
 	googleAPI google.API,
 	authenticator auth.Authenticator,
 	accountProvider account.Provider,
+	healthChecker health.Checker,
 ) []fw.Route {
 	observability := routing.Observability{
 		Logger: logger,
 		Tracer: tracer,
+		Metrics: routing.Metrics{
+			RedirectCounter: metrics.redirectCounter,
+			SignInCounter:   metrics.signInCounter,
+			Exporter:        metrics.exporter,
+		},
 	}
 
 	return routing.NewShort(
 		observability,
 		string(webFrontendURL),
+		string(comingSoonURL),
+		int(defaultRedirectStatus),
+		time.Duration(requestTimeout),
 		timer,
 		urlRetriever,
+		urlCreator,
+		urlUnlocker,
+		domainManager,
+		ruleResolver,
+		clickRecorder,
+		rateLimiter,
+		ipResolver,
 		githubAPI,
 		facebookAPI,
 		googleAPI,
 		authenticator,
 		accountProvider,
+		healthChecker,
 	)
 }
@@ -0,0 +1,88 @@
+package provider
+
+import (
+	"time"
+
+	"github.com/short-d/app/fw"
+	"github.com/short-d/short/app/adapter/db"
+	"github.com/short-d/short/app/adapter/memory"
+	"github.com/short-d/short/app/usecase/repository"
+)
+
+// URLCacheConfig controls whether URLs are cached in memory, how many of them
+// are kept, and how long found and missing URLs stay in the cache.
+type URLCacheConfig struct {
+	IsEnabled   bool
+	Capacity    int
+	TTL         time.Duration
+	NegativeTTL time.Duration
+}
+
+// URLCache represents the cache of URLs, redirect rules and custom domains
+// shared by all the services running in the same process, so that changes
+// made through one service invalidate the entries cached by the others.
+type URLCache struct {
+	isEnabled bool
+	cache     memory.URLCache
+}
+
+// Stats retrieves the hits and misses of the cache, and whether the cache is
+// enabled.
+func (u URLCache) Stats() (memory.CacheStats, bool) {
+	if !u.isEnabled {
+		return memory.CacheStats{}, false
+	}
+	return u.cache.Stats(), true
+}
+
+// NewURLCache creates URLCache with URLCacheConfig.
+func NewURLCache(config URLCacheConfig, timer fw.Timer) URLCache {
+	if !config.IsEnabled {
+		return URLCache{}
+	}
+	return URLCache{
+		isEnabled: true,
+		cache: memory.NewURLCache(
+			config.Capacity,
+			config.TTL,
+			config.NegativeTTL,
+			timer,
+		),
+	}
+}
+
+// NewURLRepo creates URL repository backed by the database, reading through
+// URLCache when it is enabled.
+func NewURLRepo(urlSQL *db.URLSql, urlCache URLCache) repository.URL {
+	if !urlCache.isEnabled {
+		return urlSQL
+	}
+	return memory.NewCachedURL(urlSQL, urlCache.cache)
+}
+
+// NewURLBatchRepo creates URLBatch repository backed by the database,
+// invalidating the URLs created in URLCache when it is enabled.
+func NewURLBatchRepo(urlBatchSQL db.URLBatchSQL, urlCache URLCache) repository.URLBatch {
+	if !urlCache.isEnabled {
+		return urlBatchSQL
+	}
+	return memory.NewCachedURLBatch(urlBatchSQL, urlCache.cache)
+}
+
+// NewRedirectRuleRepo creates RedirectRule repository backed by the database,
+// reading through URLCache when it is enabled.
+func NewRedirectRuleRepo(ruleSQL db.RedirectRuleSQL, urlCache URLCache) repository.RedirectRule {
+	if !urlCache.isEnabled {
+		return ruleSQL
+	}
+	return memory.NewCachedRedirectRule(ruleSQL, urlCache.cache)
+}
+
+// NewDomainRepo creates Domain repository backed by the database, reading
+// through URLCache when it is enabled.
+func NewDomainRepo(domainSQL db.DomainSQL, urlCache URLCache) repository.Domain {
+	if !urlCache.isEnabled {
+		return domainSQL
+	}
+	return memory.NewCachedDomain(domainSQL, urlCache.cache)
+}
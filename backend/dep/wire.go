@@ -1,4 +1,5 @@
-//+build wireinject
+//go:build wireinject
+// +build wireinject
 
 package dep
 
@@ -20,13 +21,20 @@ import (
 	"github.com/short-d/app/modern/mdtimer"
 	"github.com/short-d/app/modern/mdtracer"
 	"github.com/short-d/short/app/adapter/db"
+	"github.com/short-d/short/app/adapter/domainverifier"
 	"github.com/short-d/short/app/adapter/facebook"
+	"github.com/short-d/short/app/adapter/geoip"
 	"github.com/short-d/short/app/adapter/github"
 	"github.com/short-d/short/app/adapter/google"
 	"github.com/short-d/short/app/adapter/graphql"
 	"github.com/short-d/short/app/adapter/kgs"
 	"github.com/short-d/short/app/usecase/account"
+	"github.com/short-d/short/app/usecase/analytics"
+	"github.com/short-d/short/app/usecase/apikey"
 	"github.com/short-d/short/app/usecase/changelog"
+	"github.com/short-d/short/app/usecase/keygen"
+	"github.com/short-d/short/app/usecase/password"
+	"github.com/short-d/short/app/usecase/redirectrule"
 	"github.com/short-d/short/app/usecase/repository"
 	"github.com/short-d/short/app/usecase/requester"
 	"github.com/short-d/short/app/usecase/service"
@@ -101,6 +109,93 @@ func InjectEnvironment() fw.Environment {
 	return mdenv.GoDotEnv{}
 }
 
+// InjectURLCache creates URLCache shared by the services in the same process.
+func InjectURLCache(config provider.URLCacheConfig) provider.URLCache {
+	wire.Build(
+		mdtimer.NewTimer,
+		provider.NewURLCache,
+	)
+	return provider.URLCache{}
+}
+
+// InjectMetrics creates Metrics shared by the services in the same process.
+func InjectMetrics(
+	urlCache provider.URLCache,
+	metricsToken provider.MetricsToken,
+) provider.Metrics {
+	wire.Build(
+		provider.NewMetrics,
+	)
+	return provider.Metrics{}
+}
+
+// InjectKgsRPC creates the connection to key generation service shared by the
+// services in the same process.
+func InjectKgsRPC(config kgs.Config) (kgs.RPC, error) {
+	wire.Build(
+		mdtimer.NewTimer,
+		kgs.NewRPC,
+	)
+	return kgs.RPC{}, nil
+}
+
+// InjectKeyGenerator creates KeyGenerator shared by the services in the same
+// process, so that keys are buffered and refilled in one place.
+func InjectKeyGenerator(
+	prefix provider.LogPrefix,
+	logLevel fw.LogLevel,
+	sqlDB *sql.DB,
+	bufferSize provider.KeyGenBufferSize,
+	lowWaterMark provider.KeyGenLowWaterMark,
+	kgsRPC kgs.RPC,
+	keyFetcherConfig provider.KeyFetcherConfig,
+	urlCache provider.URLCache,
+	metrics provider.Metrics,
+) (keygen.KeyGenerator, error) {
+	wire.Build(
+		wire.Bind(new(fw.StdOut), new(mdio.StdOut)),
+		wire.Bind(new(fw.ProgramRuntime), new(mdruntime.BuildIn)),
+		wire.Bind(new(fw.Logger), new(mdlogger.Local)),
+
+		provider.NewLocalLogger,
+		mdio.NewBuildInStdOut,
+		mdruntime.NewBuildIn,
+		mdtimer.NewTimer,
+
+		db.NewURLSql,
+		provider.NewURLRepo,
+		provider.NewKeyFetcher,
+		provider.NewKeyGenerator,
+	)
+	return keygen.KeyGenerator{}, nil
+}
+
+// InjectClickRecorder creates BatchRecorder shared by the services in the same
+// process, so that the buffered clicks can be persisted before the process
+// exits.
+func InjectClickRecorder(
+	prefix provider.LogPrefix,
+	logLevel fw.LogLevel,
+	sqlDB *sql.DB,
+	clickRecorderConfig provider.ClickRecorderConfig,
+) (analytics.BatchRecorder, error) {
+	wire.Build(
+		wire.Bind(new(fw.StdOut), new(mdio.StdOut)),
+		wire.Bind(new(fw.ProgramRuntime), new(mdruntime.BuildIn)),
+		wire.Bind(new(fw.Logger), new(mdlogger.Local)),
+		wire.Bind(new(repository.Click), new(db.ClickSQL)),
+
+		provider.NewLocalLogger,
+		mdio.NewBuildInStdOut,
+		mdruntime.NewBuildIn,
+		mdtimer.NewTimer,
+
+		db.NewClickSQL,
+		provider.NewBatchRecorder,
+	)
+	return analytics.BatchRecorder{}, nil
+}
+
 // InjectGraphQLService creates GraphQL service with configured dependencies.
 func InjectGraphQLService(
 	name string,
@@ -108,11 +203,17 @@ func InjectGraphQLService(
 	logLevel fw.LogLevel,
 	sqlDB *sql.DB,
 	graphqlPath provider.GraphQlPath,
+	requestTimeout provider.RequestTimeout,
 	secret provider.ReCaptchaSecret,
 	jwtSecret provider.JwtSecret,
-	bufferSize provider.KeyGenBufferSize,
-	kgsRPCConfig provider.KgsRPCConfig,
+	keyGenerator keygen.KeyGenerator,
 	tokenValidDuration provider.TokenValidDuration,
+	rateLimitConfig provider.RateLimitConfig,
+	linkSafetyConfig provider.LinkSafetyConfig,
+	domainVerifyTimeout provider.DomainVerifyTimeout,
+	defaultHostnames provider.DefaultHostnames,
+	urlCache provider.URLCache,
+	metrics provider.Metrics,
 ) (mdservice.Service, error) {
 	wire.Build(
 		wire.Bind(new(fw.StdOut), new(mdio.StdOut)),
@@ -121,10 +222,18 @@ func InjectGraphQLService(
 		wire.Bind(new(changelog.ChangeLog), new(changelog.Persist)),
 		wire.Bind(new(url.Retriever), new(url.RetrieverPersist)),
 		wire.Bind(new(url.Creator), new(url.CreatorPersist)),
+		wire.Bind(new(url.Updater), new(url.UpdaterPersist)),
+		wire.Bind(new(url.Deleter), new(url.DeleterPersist)),
+		wire.Bind(new(url.RuleEditor), new(url.RuleEditorPersist)),
+		wire.Bind(new(url.Previewer), new(url.PreviewerPersist)),
+		wire.Bind(new(analytics.Retriever), new(analytics.RetrieverPersist)),
 		wire.Bind(new(repository.UserURLRelation), new(db.UserURLRelationSQL)),
+		wire.Bind(new(repository.User), new(*(db.UserSQL))),
 		wire.Bind(new(repository.ChangeLog), new(db.ChangeLogSQL)),
-		wire.Bind(new(repository.URL), new(*db.URLSql)),
-		wire.Bind(new(service.KeyFetcher), new(kgs.RPC)),
+		wire.Bind(new(repository.Click), new(db.ClickSQL)),
+		wire.Bind(new(repository.PublicURL), new(db.PublicURLSQL)),
+		wire.Bind(new(repository.APIKey), new(db.APIKeySQL)),
+		wire.Bind(new(service.DomainVerifier), new(domainverifier.Verifier)),
 		wire.Bind(new(fw.HTTPRequest), new(mdrequest.HTTP)),
 
 		observabilitySet,
@@ -141,13 +250,34 @@ func InjectGraphQLService(
 		db.NewChangeLogSQL,
 		db.NewURLSql,
 		db.NewUserURLRelationSQL,
-		provider.NewKeyGenerator,
+		db.NewClickSQL,
+		db.NewPublicURLSQL,
+		db.NewURLBatchSQL,
+		provider.NewURLRepo,
+		provider.NewURLBatchRepo,
+		db.NewAPIKeySQL,
+		db.NewRedirectRuleSQL,
+		db.NewDomainSQL,
+		provider.NewRedirectRuleRepo,
+		provider.NewDomainRepo,
+		db.NewUserSQL,
 		validator.NewLongLink,
 		validator.NewCustomAlias,
+		provider.NewLinkSafetyChecker,
+		password.NewHasher,
 		changelog.NewPersist,
 		url.NewRetrieverPersist,
 		url.NewCreatorPersist,
-		provider.NewKgsRPC,
+		url.NewUpdaterPersist,
+		url.NewDeleterPersist,
+		url.NewRuleEditorPersist,
+		url.NewPreviewerPersist,
+		analytics.NewRetrieverPersist,
+		apikey.NewManager,
+		provider.NewDomainVerifier,
+		provider.NewDomainManager,
+		provider.NewTokenBucket,
+		provider.NewRateLimiter,
 		provider.NewReCaptchaService,
 		requester.NewVerifier,
 		graphql.NewShort,
@@ -161,6 +291,7 @@ func InjectRoutingService(
 	prefix provider.LogPrefix,
 	logLevel fw.LogLevel,
 	sqlDB *sql.DB,
+	migrationRoot provider.MigrationRoot,
 	githubClientID provider.GithubClientID,
 	githubClientSecret provider.GithubClientSecret,
 	facebookClientID provider.FacebookClientID,
@@ -171,15 +302,33 @@ func InjectRoutingService(
 	googleRedirectURI provider.GoogleRedirectURI,
 	jwtSecret provider.JwtSecret,
 	webFrontendURL provider.WebFrontendURL,
+	comingSoonURL provider.ComingSoonURL,
+	defaultRedirectStatus provider.DefaultRedirectStatus,
+	requestTimeout provider.RequestTimeout,
 	tokenValidDuration provider.TokenValidDuration,
-) mdservice.Service {
+	batchRecorder analytics.BatchRecorder,
+	keyGenerator keygen.KeyGenerator,
+	rateLimitConfig provider.RateLimitConfig,
+	linkSafetyConfig provider.LinkSafetyConfig,
+	geoIPDatabaseFile provider.GeoIPDatabaseFile,
+	domainVerifyTimeout provider.DomainVerifyTimeout,
+	defaultHostnames provider.DefaultHostnames,
+	trustedProxies provider.TrustedProxies,
+	urlCache provider.URLCache,
+	metrics provider.Metrics,
+) (mdservice.Service, error) {
 	wire.Build(
 		wire.Bind(new(fw.StdOut), new(mdio.StdOut)),
 		wire.Bind(new(fw.ProgramRuntime), new(mdruntime.BuildIn)),
 		wire.Bind(new(url.Retriever), new(url.RetrieverPersist)),
+		wire.Bind(new(url.Creator), new(url.CreatorPersist)),
+		wire.Bind(new(url.Unlocker), new(url.UnlockerPersist)),
+		wire.Bind(new(analytics.Recorder), new(analytics.BatchRecorder)),
 		wire.Bind(new(repository.UserURLRelation), new(db.UserURLRelationSQL)),
 		wire.Bind(new(repository.User), new(*(db.UserSQL))),
-		wire.Bind(new(repository.URL), new(*db.URLSql)),
+		wire.Bind(new(repository.PublicURL), new(db.PublicURLSQL)),
+		wire.Bind(new(service.GeoLocator), new(geoip.Locator)),
+		wire.Bind(new(service.DomainVerifier), new(domainverifier.Verifier)),
 		wire.Bind(new(fw.HTTPRequest), new(mdrequest.HTTP)),
 		wire.Bind(new(fw.GraphQlRequest), new(mdrequest.GraphQL)),
 
@@ -201,9 +350,31 @@ func InjectRoutingService(
 		db.NewUserSQL,
 		db.NewURLSql,
 		db.NewUserURLRelationSQL,
+		db.NewPublicURLSQL,
+		db.NewURLBatchSQL,
+		provider.NewURLRepo,
+		provider.NewURLBatchRepo,
+		db.NewRedirectRuleSQL,
+		db.NewDomainSQL,
+		provider.NewRedirectRuleRepo,
+		provider.NewDomainRepo,
+		validator.NewLongLink,
+		validator.NewCustomAlias,
+		provider.NewLinkSafetyChecker,
+		password.NewHasher,
 		url.NewRetrieverPersist,
+		url.NewCreatorPersist,
+		url.NewUnlockerPersist,
+		provider.NewDomainVerifier,
+		provider.NewDomainManager,
+		provider.NewGeoLocator,
+		provider.NewClientIPResolver,
+		redirectrule.NewResolver,
+		provider.NewTokenBucket,
+		provider.NewRateLimiter,
 		account.NewProvider,
+		provider.NewHealthChecker,
 		provider.NewShortRoutes,
 	)
-	return mdservice.Service{}
+	return mdservice.Service{}, nil
 }
@@ -1,7 +1,8 @@
 // Code generated by Wire. DO NOT EDIT.
 
 //go:generate wire
-//+build !wireinject
+//go:build !wireinject
+// +build !wireinject
 
 package dep
 
@@ -27,8 +28,14 @@ import (
 	"github.com/short-d/short/app/adapter/github"
 	"github.com/short-d/short/app/adapter/google"
 	"github.com/short-d/short/app/adapter/graphql"
+	"github.com/short-d/short/app/adapter/kgs"
 	"github.com/short-d/short/app/usecase/account"
+	"github.com/short-d/short/app/usecase/analytics"
+	"github.com/short-d/short/app/usecase/apikey"
 	"github.com/short-d/short/app/usecase/changelog"
+	"github.com/short-d/short/app/usecase/keygen"
+	"github.com/short-d/short/app/usecase/password"
+	"github.com/short-d/short/app/usecase/redirectrule"
 	"github.com/short-d/short/app/usecase/requester"
 	"github.com/short-d/short/app/usecase/url"
 	"github.com/short-d/short/app/usecase/validator"
@@ -57,26 +64,87 @@ func InjectEnvironment() fw.Environment {
 	return goDotEnv
 }
 
-func InjectGraphQLService(name string, prefix provider.LogPrefix, logLevel fw.LogLevel, sqlDB *sql.DB, graphqlPath provider.GraphQlPath, secret provider.ReCaptchaSecret, jwtSecret provider.JwtSecret, bufferSize provider.KeyGenBufferSize, kgsRPCConfig provider.KgsRPCConfig, tokenValidDuration provider.TokenValidDuration) (mdservice.Service, error) {
+func InjectURLCache(config provider.URLCacheConfig) provider.URLCache {
+	timer := mdtimer.NewTimer()
+	urlCache := provider.NewURLCache(config, timer)
+	return urlCache
+}
+
+func InjectMetrics(urlCache provider.URLCache, metricsToken provider.MetricsToken) provider.Metrics {
+	metrics := provider.NewMetrics(urlCache, metricsToken)
+	return metrics
+}
+
+func InjectKgsRPC(config kgs.Config) (kgs.RPC, error) {
+	timer := mdtimer.NewTimer()
+	rpc, err := kgs.NewRPC(config, timer)
+	if err != nil {
+		return kgs.RPC{}, err
+	}
+	return rpc, nil
+}
+
+func InjectKeyGenerator(prefix provider.LogPrefix, logLevel fw.LogLevel, sqlDB *sql.DB, bufferSize provider.KeyGenBufferSize, lowWaterMark provider.KeyGenLowWaterMark, kgsRPC kgs.RPC, keyFetcherConfig provider.KeyFetcherConfig, urlCache provider.URLCache, metrics provider.Metrics) (keygen.KeyGenerator, error) {
+	urlSql := db.NewURLSql(sqlDB)
+	repositoryURL := provider.NewURLRepo(urlSql, urlCache)
 	stdOut := mdio.NewBuildInStdOut()
 	timer := mdtimer.NewTimer()
 	buildIn := mdruntime.NewBuildIn()
 	local := provider.NewLocalLogger(prefix, logLevel, stdOut, timer, buildIn)
-	tracer := mdtracer.NewLocal()
-	urlSql := db.NewURLSql(sqlDB)
-	userURLRelationSQL := db.NewUserURLRelationSQL(sqlDB)
-	retrieverPersist := url.NewRetrieverPersist(urlSql, userURLRelationSQL)
-	rpc, err := provider.NewKgsRPC(kgsRPCConfig)
+	keyFetcher, err := provider.NewKeyFetcher(keyFetcherConfig, kgsRPC, repositoryURL, local)
 	if err != nil {
-		return mdservice.Service{}, err
+		return keygen.KeyGenerator{}, err
 	}
-	keyGenerator, err := provider.NewKeyGenerator(bufferSize, rpc)
+	keyGenerator, err := provider.NewKeyGenerator(bufferSize, lowWaterMark, keyFetcher, metrics, timer)
 	if err != nil {
-		return mdservice.Service{}, err
+		return keygen.KeyGenerator{}, err
+	}
+	return keyGenerator, nil
+}
+
+func InjectClickRecorder(prefix provider.LogPrefix, logLevel fw.LogLevel, sqlDB *sql.DB, clickRecorderConfig provider.ClickRecorderConfig) (analytics.BatchRecorder, error) {
+	clickSQL := db.NewClickSQL(sqlDB)
+	stdOut := mdio.NewBuildInStdOut()
+	timer := mdtimer.NewTimer()
+	buildIn := mdruntime.NewBuildIn()
+	local := provider.NewLocalLogger(prefix, logLevel, stdOut, timer, buildIn)
+	batchRecorder, err := provider.NewBatchRecorder(clickRecorderConfig, clickSQL, local)
+	if err != nil {
+		return analytics.BatchRecorder{}, err
 	}
+	return batchRecorder, nil
+}
+
+func InjectGraphQLService(name string, prefix provider.LogPrefix, logLevel fw.LogLevel, sqlDB *sql.DB, graphqlPath provider.GraphQlPath, requestTimeout provider.RequestTimeout, secret provider.ReCaptchaSecret, jwtSecret provider.JwtSecret, keyGenerator keygen.KeyGenerator, tokenValidDuration provider.TokenValidDuration, rateLimitConfig provider.RateLimitConfig, linkSafetyConfig provider.LinkSafetyConfig, domainVerifyTimeout provider.DomainVerifyTimeout, defaultHostnames provider.DefaultHostnames, urlCache provider.URLCache, metrics provider.Metrics) (mdservice.Service, error) {
+	stdOut := mdio.NewBuildInStdOut()
+	timer := mdtimer.NewTimer()
+	buildIn := mdruntime.NewBuildIn()
+	local := provider.NewLocalLogger(prefix, logLevel, stdOut, timer, buildIn)
+	tracer := mdtracer.NewLocal()
+	urlSql := db.NewURLSql(sqlDB)
+	repositoryURL := provider.NewURLRepo(urlSql, urlCache)
+	userURLRelationSQL := db.NewUserURLRelationSQL(sqlDB)
+	publicURLSQL := db.NewPublicURLSQL(sqlDB)
+	retrieverPersist := url.NewRetrieverPersist(repositoryURL, userURLRelationSQL, publicURLSQL)
+	urlBatchSQL := db.NewURLBatchSQL(sqlDB)
+	urlBatch := provider.NewURLBatchRepo(urlBatchSQL, urlCache)
 	longLink := validator.NewLongLink()
 	customAlias := validator.NewCustomAlias()
-	creatorPersist := url.NewCreatorPersist(urlSql, userURLRelationSQL, keyGenerator, longLink, customAlias)
+	checker, err := provider.NewLinkSafetyChecker(linkSafetyConfig)
+	if err != nil {
+		return mdservice.Service{}, err
+	}
+	domainSQL := db.NewDomainSQL(sqlDB)
+	domain := provider.NewDomainRepo(domainSQL, urlCache)
+	hasher := password.NewHasher()
+	creatorPersist := url.NewCreatorPersist(repositoryURL, userURLRelationSQL, publicURLSQL, urlBatch, domain, keyGenerator, longLink, customAlias, checker, hasher, timer)
+	updaterPersist := url.NewUpdaterPersist(repositoryURL, userURLRelationSQL, publicURLSQL, longLink, checker, timer)
+	deleterPersist := url.NewDeleterPersist(repositoryURL, userURLRelationSQL)
+	redirectRuleSQL := db.NewRedirectRuleSQL(sqlDB)
+	redirectRule := provider.NewRedirectRuleRepo(redirectRuleSQL, urlCache)
+	ruleEditorPersist := url.NewRuleEditorPersist(repositoryURL, userURLRelationSQL, redirectRule, longLink, checker)
+	userSQL := db.NewUserSQL(sqlDB)
+	previewerPersist := url.NewPreviewerPersist(retrieverPersist, userURLRelationSQL, userSQL, timer)
 	changeLogSQL := db.NewChangeLogSQL(sqlDB)
 	persist := changelog.NewPersist(keyGenerator, timer, changeLogSQL)
 	client := mdhttp.NewClient()
@@ -85,21 +153,65 @@ func InjectGraphQLService(name string, prefix provider.LogPrefix, logLevel fw.Lo
 	verifier := requester.NewVerifier(reCaptcha)
 	cryptoTokenizer := provider.NewJwtGo(jwtSecret)
 	authenticator := provider.NewAuthenticator(cryptoTokenizer, timer, tokenValidDuration)
-	short := graphql.NewShort(local, tracer, retrieverPersist, creatorPersist, persist, verifier, authenticator)
-	server := provider.NewGraphGophers(graphqlPath, local, tracer, short)
+	clickSQL := db.NewClickSQL(sqlDB)
+	analyticsRetrieverPersist := analytics.NewRetrieverPersist(clickSQL, userURLRelationSQL)
+	apiKeySQL := db.NewAPIKeySQL(sqlDB)
+	manager := apikey.NewManager(apiKeySQL, timer)
+	domainverifierVerifier := provider.NewDomainVerifier(domainVerifyTimeout)
+	customdomainManager := provider.NewDomainManager(domain, domainverifierVerifier, timer, defaultHostnames)
+	tokenBucket, err := provider.NewTokenBucket(rateLimitConfig, sqlDB)
+	if err != nil {
+		return mdservice.Service{}, err
+	}
+	limiter := provider.NewRateLimiter(rateLimitConfig, tokenBucket, timer)
+	short := graphql.NewShort(local, tracer, retrieverPersist, creatorPersist, updaterPersist, deleterPersist, ruleEditorPersist, previewerPersist, persist, verifier, authenticator, analyticsRetrieverPersist, manager, customdomainManager, limiter)
+	server := provider.NewGraphGophers(graphqlPath, requestTimeout, local, tracer, short, metrics, timer)
 	service := mdservice.New(name, server, local)
 	return service, nil
 }
 
-func InjectRoutingService(name string, prefix provider.LogPrefix, logLevel fw.LogLevel, sqlDB *sql.DB, githubClientID provider.GithubClientID, githubClientSecret provider.GithubClientSecret, facebookClientID provider.FacebookClientID, facebookClientSecret provider.FacebookClientSecret, facebookRedirectURI provider.FacebookRedirectURI, googleClientID provider.GoogleClientID, googleClientSecret provider.GoogleClientSecret, googleRedirectURI provider.GoogleRedirectURI, jwtSecret provider.JwtSecret, webFrontendURL provider.WebFrontendURL, tokenValidDuration provider.TokenValidDuration) mdservice.Service {
+func InjectRoutingService(name string, prefix provider.LogPrefix, logLevel fw.LogLevel, sqlDB *sql.DB, migrationRoot provider.MigrationRoot, githubClientID provider.GithubClientID, githubClientSecret provider.GithubClientSecret, facebookClientID provider.FacebookClientID, facebookClientSecret provider.FacebookClientSecret, facebookRedirectURI provider.FacebookRedirectURI, googleClientID provider.GoogleClientID, googleClientSecret provider.GoogleClientSecret, googleRedirectURI provider.GoogleRedirectURI, jwtSecret provider.JwtSecret, webFrontendURL provider.WebFrontendURL, comingSoonURL provider.ComingSoonURL, defaultRedirectStatus provider.DefaultRedirectStatus, requestTimeout provider.RequestTimeout, tokenValidDuration provider.TokenValidDuration, batchRecorder analytics.BatchRecorder, keyGenerator keygen.KeyGenerator, rateLimitConfig provider.RateLimitConfig, linkSafetyConfig provider.LinkSafetyConfig, geoIPDatabaseFile provider.GeoIPDatabaseFile, domainVerifyTimeout provider.DomainVerifyTimeout, defaultHostnames provider.DefaultHostnames, trustedProxies provider.TrustedProxies, urlCache provider.URLCache, metrics provider.Metrics) (mdservice.Service, error) {
 	stdOut := mdio.NewBuildInStdOut()
 	timer := mdtimer.NewTimer()
 	buildIn := mdruntime.NewBuildIn()
 	local := provider.NewLocalLogger(prefix, logLevel, stdOut, timer, buildIn)
 	tracer := mdtracer.NewLocal()
 	urlSql := db.NewURLSql(sqlDB)
+	repositoryURL := provider.NewURLRepo(urlSql, urlCache)
 	userURLRelationSQL := db.NewUserURLRelationSQL(sqlDB)
-	retrieverPersist := url.NewRetrieverPersist(urlSql, userURLRelationSQL)
+	publicURLSQL := db.NewPublicURLSQL(sqlDB)
+	retrieverPersist := url.NewRetrieverPersist(repositoryURL, userURLRelationSQL, publicURLSQL)
+	urlBatchSQL := db.NewURLBatchSQL(sqlDB)
+	urlBatch := provider.NewURLBatchRepo(urlBatchSQL, urlCache)
+	longLink := validator.NewLongLink()
+	customAlias := validator.NewCustomAlias()
+	checker, err := provider.NewLinkSafetyChecker(linkSafetyConfig)
+	if err != nil {
+		return mdservice.Service{}, err
+	}
+	domainSQL := db.NewDomainSQL(sqlDB)
+	domain := provider.NewDomainRepo(domainSQL, urlCache)
+	hasher := password.NewHasher()
+	creatorPersist := url.NewCreatorPersist(repositoryURL, userURLRelationSQL, publicURLSQL, urlBatch, domain, keyGenerator, longLink, customAlias, checker, hasher, timer)
+	unlockerPersist := url.NewUnlockerPersist(retrieverPersist, hasher)
+	verifier := provider.NewDomainVerifier(domainVerifyTimeout)
+	manager := provider.NewDomainManager(domain, verifier, timer, defaultHostnames)
+	redirectRuleSQL := db.NewRedirectRuleSQL(sqlDB)
+	redirectRule := provider.NewRedirectRuleRepo(redirectRuleSQL, urlCache)
+	locator, err := provider.NewGeoLocator(geoIPDatabaseFile)
+	if err != nil {
+		return mdservice.Service{}, err
+	}
+	resolver := redirectrule.NewResolver(redirectRule, locator)
+	tokenBucket, err := provider.NewTokenBucket(rateLimitConfig, sqlDB)
+	if err != nil {
+		return mdservice.Service{}, err
+	}
+	limiter := provider.NewRateLimiter(rateLimitConfig, tokenBucket, timer)
+	clientIPResolver, err := provider.NewClientIPResolver(trustedProxies)
+	if err != nil {
+		return mdservice.Service{}, err
+	}
 	client := mdhttp.NewClient()
 	http := mdrequest.NewHTTP(client)
 	identityProvider := provider.NewGithubIdentityProvider(http, githubClientID, githubClientSecret)
@@ -116,10 +228,11 @@ func InjectRoutingService(name string, prefix provider.LogPrefix, logLevel fw.Lo
 	authenticator := provider.NewAuthenticator(cryptoTokenizer, timer, tokenValidDuration)
 	userSQL := db.NewUserSQL(sqlDB)
 	accountProvider := account.NewProvider(userSQL, timer)
-	v := provider.NewShortRoutes(local, tracer, webFrontendURL, timer, retrieverPersist, api, facebookAPI, googleAPI, authenticator, accountProvider)
+	healthChecker := provider.NewHealthChecker(sqlDB, migrationRoot, keyGenerator)
+	v := provider.NewShortRoutes(local, tracer, metrics, webFrontendURL, comingSoonURL, defaultRedirectStatus, requestTimeout, timer, retrieverPersist, creatorPersist, unlockerPersist, manager, resolver, batchRecorder, limiter, clientIPResolver, api, facebookAPI, googleAPI, authenticator, accountProvider, healthChecker)
 	server := mdrouting.NewBuiltIn(local, tracer, v)
 	service := mdservice.New(name, server, local)
-	return service
+	return service, nil
 }
 
 // wire.go:
@@ -4,6 +4,7 @@ go 1.13
 
 require (
 	github.com/google/wire v0.4.0
+	github.com/graph-gophers/graphql-go v0.0.0-20190902214650-641ae197eec7
 	github.com/short-d/app v0.0.0-20200108075430-a7a081c61daf
 	github.com/short-d/kgs v0.0.0-20200105183048-3be4c3acc728
 	google.golang.org/grpc v1.26.0
@@ -32,12 +32,54 @@ func main() {
 		GoogleRedirectURI    string        `env:"GOOGLE_REDIRECT_URI" default:""`
 		JWTSecret            string        `env:"JWT_SECRET" default:""`
 		WebFrontendURL       string        `env:"WEB_FRONTEND_URL" default:""`
+		ComingSoonURL        string        `env:"COMING_SOON_URL" default:""`
+		RedirectStatus       int           `env:"DEFAULT_REDIRECT_STATUS" default:"303"`
 		KeyGenBufferSize     int           `env:"KEY_GEN_BUFFER_SIZE" default:"50"`
+		KeyGenLowWaterMark   int           `env:"KEY_GEN_LOW_WATER_MARK" default:"25"`
 		KgsHostname          string        `env:"KEY_GEN_HOSTNAME" default:"localhost"`
 		KgsPort              int           `env:"KEY_GEN_PORT" default:"8080"`
+		KgsPlaintext         bool          `env:"KEY_GEN_PLAINTEXT" default:"false"`
+		KgsCACertFile        string        `env:"KEY_GEN_CA_CERT_FILE" default:""`
+		KgsClientCertFile    string        `env:"KEY_GEN_CLIENT_CERT_FILE" default:""`
+		KgsClientKeyFile     string        `env:"KEY_GEN_CLIENT_KEY_FILE" default:""`
+		KgsServerName        string        `env:"KEY_GEN_SERVER_NAME" default:""`
+		KgsCallTimeout       time.Duration `env:"KEY_GEN_CALL_TIMEOUT" default:"2s"`
+		KgsMaxAttempts       int           `env:"KEY_GEN_MAX_ATTEMPTS" default:"3"`
+		KgsInitialBackoff    time.Duration `env:"KEY_GEN_INITIAL_BACKOFF" default:"100ms"`
+		KgsMaxBackoff        time.Duration `env:"KEY_GEN_MAX_BACKOFF" default:"1s"`
+		KgsFailureThreshold  int           `env:"KEY_GEN_FAILURE_THRESHOLD" default:"5"`
+		KgsOpenDuration      time.Duration `env:"KEY_GEN_OPEN_DURATION" default:"30s"`
+		KeyGenSource         string        `env:"KEY_GEN_SOURCE" default:"kgs"`
+		KeyGenLocalKeyLength int           `env:"KEY_GEN_LOCAL_KEY_LENGTH" default:"6"`
 		GraphQLAPIPort       int           `env:"GRAPHQL_API_PORT" default:"8080"`
 		HTTPAPIPort          int           `env:"HTTP_API_PORT" default:"80"`
 		AuthTokenLifeTime    time.Duration `env:"AUTH_TOKEN_LIFETIME" default:"1w"`
+		ClickBufferSize      int           `env:"CLICK_BUFFER_SIZE" default:"1000"`
+		ClickBatchSize       int           `env:"CLICK_BATCH_SIZE" default:"100"`
+		ClickFlushInterval   time.Duration `env:"CLICK_FLUSH_INTERVAL" default:"5s"`
+		RateLimitBackend     string        `env:"RATE_LIMIT_BACKEND" default:"memory"`
+		CreateURLRateLimit   int           `env:"CREATE_URL_RATE_LIMIT" default:"30"`
+		CreateURLRatePeriod  time.Duration `env:"CREATE_URL_RATE_PERIOD" default:"1m"`
+		RedirectRateLimit    int           `env:"REDIRECT_RATE_LIMIT" default:"300"`
+		RedirectRatePeriod   time.Duration `env:"REDIRECT_RATE_PERIOD" default:"1m"`
+		UnlockURLRateLimit   int           `env:"UNLOCK_URL_RATE_LIMIT" default:"5"`
+		UnlockURLRatePeriod  time.Duration `env:"UNLOCK_URL_RATE_PERIOD" default:"1m"`
+		BlockedDomainsFile   string        `env:"BLOCKED_DOMAINS_FILE" default:""`
+		BlockedPatternsFile  string        `env:"BLOCKED_PATTERNS_FILE" default:""`
+		ShortLinkHostnames   string        `env:"SHORT_LINK_HOSTNAMES" default:""`
+		LinkRedirectMaxHops  int           `env:"LINK_REDIRECT_MAX_HOPS" default:"5"`
+		LinkRedirectTimeout  time.Duration `env:"LINK_REDIRECT_TIMEOUT" default:"3s"`
+		LinkCheckTimeout     time.Duration `env:"LINK_CHECK_TIMEOUT" default:"10s"`
+		DomainVerifyTimeout  time.Duration `env:"DOMAIN_VERIFY_TIMEOUT" default:"5s"`
+		TrustedProxies       string        `env:"TRUSTED_PROXIES" default:""`
+		GeoIPDatabaseFile    string        `env:"GEOIP_DATABASE_FILE" default:""`
+		URLCacheEnabled      bool          `env:"URL_CACHE_ENABLED" default:"false"`
+		URLCacheCapacity     int           `env:"URL_CACHE_CAPACITY" default:"10000"`
+		URLCacheTTL          time.Duration `env:"URL_CACHE_TTL" default:"1m"`
+		URLCacheNegativeTTL  time.Duration `env:"URL_CACHE_NEGATIVE_TTL" default:"10s"`
+		MetricsToken         string        `env:"METRICS_TOKEN" default:""`
+		RequestTimeout       time.Duration `env:"REQUEST_TIMEOUT" default:"15s"`
+		ShutdownTimeout      time.Duration `env:"SHUTDOWN_TIMEOUT" default:"30s"`
 	}{}
 
 	err := envConfig.ParseConfigFromEnv(&config)
@@ -70,12 +112,54 @@ func main() {
 		GoogleRedirectURI:    config.GoogleRedirectURI,
 		JwtSecret:            config.JWTSecret,
 		WebFrontendURL:       config.WebFrontendURL,
+		ComingSoonURL:        config.ComingSoonURL,
+		RedirectStatus:       config.RedirectStatus,
 		GraphQLAPIPort:       config.GraphQLAPIPort,
 		HTTPAPIPort:          config.HTTPAPIPort,
 		KeyGenBufferSize:     config.KeyGenBufferSize,
+		KeyGenLowWaterMark:   config.KeyGenLowWaterMark,
 		KgsHostname:          config.KgsHostname,
 		KgsPort:              config.KgsPort,
+		KgsPlaintext:         config.KgsPlaintext,
+		KgsCACertFile:        config.KgsCACertFile,
+		KgsClientCertFile:    config.KgsClientCertFile,
+		KgsClientKeyFile:     config.KgsClientKeyFile,
+		KgsServerName:        config.KgsServerName,
+		KgsCallTimeout:       config.KgsCallTimeout,
+		KgsMaxAttempts:       config.KgsMaxAttempts,
+		KgsInitialBackoff:    config.KgsInitialBackoff,
+		KgsMaxBackoff:        config.KgsMaxBackoff,
+		KgsFailureThreshold:  config.KgsFailureThreshold,
+		KgsOpenDuration:      config.KgsOpenDuration,
+		KeyGenSource:         config.KeyGenSource,
+		KeyGenLocalKeyLength: config.KeyGenLocalKeyLength,
 		AuthTokenLifetime:    config.AuthTokenLifeTime,
+		ClickBufferSize:      config.ClickBufferSize,
+		ClickBatchSize:       config.ClickBatchSize,
+		ClickFlushInterval:   config.ClickFlushInterval,
+		RateLimitBackend:     config.RateLimitBackend,
+		CreateURLRateLimit:   config.CreateURLRateLimit,
+		CreateURLRatePeriod:  config.CreateURLRatePeriod,
+		RedirectRateLimit:    config.RedirectRateLimit,
+		RedirectRatePeriod:   config.RedirectRatePeriod,
+		UnlockURLRateLimit:   config.UnlockURLRateLimit,
+		UnlockURLRatePeriod:  config.UnlockURLRatePeriod,
+		BlockedDomainsFile:   config.BlockedDomainsFile,
+		BlockedPatternsFile:  config.BlockedPatternsFile,
+		ShortLinkHostnames:   config.ShortLinkHostnames,
+		LinkRedirectMaxHops:  config.LinkRedirectMaxHops,
+		LinkRedirectTimeout:  config.LinkRedirectTimeout,
+		LinkCheckTimeout:     config.LinkCheckTimeout,
+		DomainVerifyTimeout:  config.DomainVerifyTimeout,
+		TrustedProxies:       config.TrustedProxies,
+		GeoIPDatabaseFile:    config.GeoIPDatabaseFile,
+		URLCacheEnabled:      config.URLCacheEnabled,
+		URLCacheCapacity:     config.URLCacheCapacity,
+		URLCacheTTL:          config.URLCacheTTL,
+		URLCacheNegativeTTL:  config.URLCacheNegativeTTL,
+		MetricsToken:         config.MetricsToken,
+		RequestTimeout:       config.RequestTimeout,
+		ShutdownTimeout:      config.ShutdownTimeout,
 	}
 
 	rootCmd := cmd.NewRootCmd(